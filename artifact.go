@@ -0,0 +1,220 @@
+package testclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// ArtifactPolicy says when WithArtifacts writes the session report.
+type ArtifactPolicy int
+
+const (
+	// ArtifactsOnFailure writes the report of tests that failed.
+	ArtifactsOnFailure ArtifactPolicy = iota
+	// ArtifactsAlways writes the report of every test.
+	ArtifactsAlways
+)
+
+type artifactPolicy struct {
+	dir  string
+	when ArtifactPolicy
+	html bool
+}
+
+// Report is the session of a client as WithArtifacts and WriteReport write
+// it, in JSON, for CI artifacts and post-mortem tooling.
+type Report struct {
+	// Test is the name of the test of a NewT client.
+	Test      string           `json:"test,omitempty"`
+	Failed    bool             `json:"failed"`
+	Exchanges []ReportExchange `json:"exchanges"`
+	// Failures are those reported on the T of the client.
+	Failures []ReportFailure `json:"failures,omitempty"`
+}
+
+// ReportExchange is an Exchange of the history in a Report. Bodies that
+// are not valid UTF-8 are given in base64 instead.
+type ReportExchange struct {
+	Method             string      `json:"method"`
+	URL                string      `json:"url"`
+	RequestID          string      `json:"request_id,omitempty"`
+	RequestHeader      http.Header `json:"request_header,omitempty"`
+	RequestBody        string      `json:"request_body,omitempty"`
+	RequestBodyBase64  []byte      `json:"request_body_base64,omitempty"`
+	Status             int         `json:"status,omitempty"`
+	ResponseHeader     http.Header `json:"response_header,omitempty"`
+	ResponseBody       string      `json:"response_body,omitempty"`
+	ResponseBodyBase64 []byte      `json:"response_body_base64,omitempty"`
+	Error              string      `json:"error,omitempty"`
+	DurationMS         float64     `json:"duration_ms"`
+}
+
+// ReportFailure is a failure reported on the T of the client, after the
+// first AfterExchanges exchanges of the session.
+type ReportFailure struct {
+	Message        string `json:"message"`
+	Fatal          bool   `json:"fatal,omitempty"`
+	AfterExchanges int    `json:"after_exchanges"`
+}
+
+// WithArtifacts keeps the history, as WithHistory does, and, when the test
+// of a NewT client ends, writes the session as a Report to dir, in a file
+// named after the test, if the test failed or under ArtifactsAlways. The
+// test logs the path of the file. Assertions given the T of the client
+// instead of the test itself have their failures in the report too.
+func WithArtifacts(dir string, when ArtifactPolicy) Option {
+	return func(c *Client) {
+		c.keepHistory = true
+		c.artifacts = append(c.artifacts, artifactPolicy{dir: dir, when: when})
+	}
+}
+
+// T returns the test of a NewT client, recording the failures reported on
+// it for the session report of WithArtifacts:
+//
+//	testclient.ExpectStatus(c.T(), res, http.StatusOK)
+//
+// It returns nil for a client made with New.
+func (c *Client) T() testing.TB {
+	if c.tb == nil {
+		return nil
+	}
+	return &reportT{TB: c.tb, c: c}
+}
+
+// reportT records the failures of the test of a client.
+type reportT struct {
+	testing.TB
+	c *Client
+}
+
+func (r *reportT) note(msg string, fatal bool) {
+	r.c.failures = append(r.c.failures, ReportFailure{Message: msg, Fatal: fatal, AfterExchanges: len(r.c.history)})
+}
+
+func (r *reportT) Errorf(format string, args ...any) {
+	r.TB.Helper()
+	r.note(fmt.Sprintf(format, args...), false)
+	r.TB.Errorf(format, args...)
+}
+
+func (r *reportT) Error(args ...any) {
+	r.TB.Helper()
+	r.note(strings.TrimSuffix(fmt.Sprintln(args...), "\n"), false)
+	r.TB.Error(args...)
+}
+
+func (r *reportT) Fatalf(format string, args ...any) {
+	r.TB.Helper()
+	r.note(fmt.Sprintf(format, args...), true)
+	r.TB.Fatalf(format, args...)
+}
+
+func (r *reportT) Fatal(args ...any) {
+	r.TB.Helper()
+	r.note(strings.TrimSuffix(fmt.Sprintln(args...), "\n"), true)
+	r.TB.Fatal(args...)
+}
+
+// Report returns the session of the client: its history, so far, and the
+// failures reported on its T.
+func (c *Client) Report() Report {
+	r := Report{Exchanges: make([]ReportExchange, len(c.history))}
+	if c.tb != nil {
+		r.Test, r.Failed = c.tb.Name(), c.tb.Failed()
+	}
+	for i, ex := range c.history {
+		r.Exchanges[i] = ex.report()
+	}
+	r.Failures = append(r.Failures, c.failures...)
+	return r
+}
+
+// WriteReport writes the Report of the client to w as indented JSON.
+func (c *Client) WriteReport(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(c.Report())
+}
+
+func (ex exchange) report() ReportExchange {
+	rid, _ := requestIDOf(ex.req)
+	out := ReportExchange{
+		Method:        ex.req.Method,
+		URL:           requestURL(ex.req).String(),
+		RequestID:     rid.id,
+		RequestHeader: ex.req.Header,
+		DurationMS:    float64(ex.took.Microseconds()) / 1000,
+	}
+	out.RequestBody, out.RequestBodyBase64 = reportBody(ex.body)
+	if ex.err != nil {
+		out.Error = ex.err.Error()
+	}
+	if ex.res != nil {
+		out.Status = ex.res.StatusCode
+		out.ResponseHeader = ex.res.Header
+		out.ResponseBody, out.ResponseBodyBase64 = reportBody(bufferedBody(ex.res).data)
+	}
+	return out
+}
+
+func reportBody(b []byte) (string, []byte) {
+	if utf8.Valid(b) {
+		return string(b), nil
+	}
+	return "", b
+}
+
+// writeArtifacts writes the reports of a NewT client as its policies say.
+func (c *Client) writeArtifacts() {
+	for _, p := range c.artifacts {
+		if p.when == ArtifactsOnFailure && !c.tb.Failed() {
+			continue
+		}
+		write, ext := c.WriteReport, ".json"
+		if p.html {
+			write, ext = c.WriteHTMLReport, ".html"
+		}
+		path := filepath.Join(p.dir, artifactName(c.tb.Name())+ext)
+		if err := writeFile(path, write); err != nil {
+			c.tb.Errorf("testclient: writing the session report: %v", err)
+			continue
+		}
+		c.tb.Logf("testclient: session report written to %s", path)
+	}
+}
+
+// writeFile creates path, and its directory, with what write writes.
+func writeFile(path string, write func(io.Writer) error) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = write(f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// artifactName turns a test name, with its subtests, into a file name.
+func artifactName(test string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '.':
+			return r
+		}
+		return '_'
+	}, test)
+}
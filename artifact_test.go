@@ -0,0 +1,88 @@
+package testclient
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// failingT is a cleanupT that fails with its first failure.
+type failingT struct {
+	*cleanupT
+}
+
+func (f *failingT) Failed() bool { return len(f.failures) > 0 }
+
+func newFailingT(t *testing.T) *failingT {
+	return &failingT{&cleanupT{fakeT: &fakeT{TB: t}}}
+}
+
+func TestArtifactsOnFailure(t *testing.T) {
+	s := NewStub()
+	s.On(http.MethodPost, "/cart").ReplyJSON(http.StatusCreated, map[string]int{"id": 7})
+	s.On(http.MethodGet, "/logo").Reply(http.StatusOK, "\x89PNG\xff")
+	dir := filepath.Join(t.TempDir(), "artifacts")
+
+	ft := newFailingT(t)
+	c := NewT(ft, s, WithArtifacts(dir, ArtifactsOnFailure))
+	c.PostJSON("/cart", map[string]string{"sku": "A1"})
+	res, _ := c.Get("/logo", nil)
+	ExpectStatus(c.T(), res, http.StatusNotFound)
+	ft.run()
+
+	data, err := os.ReadFile(filepath.Join(dir, artifactName(t.Name())+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r Report
+	if err := json.Unmarshal(data, &r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Test != t.Name() || !r.Failed || len(r.Exchanges) != 2 {
+		t.Fatalf("report = %+v", r)
+	}
+	cart, logo := r.Exchanges[0], r.Exchanges[1]
+	if cart.Method != http.MethodPost || cart.URL != "http://example.com/cart" || cart.RequestBody != `{"sku":"A1"}` || cart.Status != http.StatusCreated || cart.ResponseBody != `{"id":7}` {
+		t.Errorf("cart exchange = %+v", cart)
+	}
+	if got := cart.RequestHeader.Get("Content-Type"); got != "application/json" {
+		t.Errorf("request Content-Type = %q", got)
+	}
+	if logo.ResponseBody != "" || string(logo.ResponseBodyBase64) != "\x89PNG\xff" {
+		t.Errorf("binary body = %q, %q", logo.ResponseBody, logo.ResponseBodyBase64)
+	}
+	if len(r.Failures) != 1 || !r.Failures[0].Fatal || r.Failures[0].AfterExchanges != 2 || !strings.HasPrefix(r.Failures[0].Message, "expected status 404 Not Found, got 200 OK") {
+		t.Errorf("failures = %+v", r.Failures)
+	}
+	if len(c.History()) != 0 {
+		t.Error("history kept after the test")
+	}
+}
+
+func TestArtifactsPolicy(t *testing.T) {
+	dir := t.TempDir()
+	ft := newFailingT(t)
+	c := NewT(ft, http.NotFoundHandler(), WithArtifacts(dir, ArtifactsOnFailure))
+	c.Get("/", nil)
+	ft.run()
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("report written for a passing test: %v", entries)
+	}
+
+	ft = newFailingT(t)
+	c = NewT(ft, http.NotFoundHandler(), WithArtifacts(dir, ArtifactsAlways))
+	c.Get("/", nil)
+	ft.run()
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("report files = %v", entries)
+	}
+}
+
+func TestArtifactName(t *testing.T) {
+	if got := artifactName("TestCheckout/guest user#01"); got != "TestCheckout_guest_user_01" {
+		t.Errorf("artifactName = %q", got)
+	}
+}
@@ -0,0 +1,47 @@
+package testclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"time"
+)
+
+// Await sends req every interval until cond accepts the response or timeout
+// expires, and returns the last response. cond may read the body; it is
+// rewound before the next check and before it is returned. On timeout the
+// error includes a dump of the last response.
+func (c *Client) Await(req *http.Request, cond func(*http.Response) bool, timeout, interval time.Duration) (*http.Response, error) {
+	body, err := bufferBody(req)
+	if err != nil {
+		return nil, err
+	}
+	deadline := c.clock.Now().Add(timeout)
+	for {
+		res, err := c.Request(withBody(req, body))
+		if err == nil {
+			rewindBody(res)
+			ok := cond(res)
+			rewindBody(res)
+			if ok {
+				return res, nil
+			}
+		}
+		if c.clock.Now().Add(interval).After(deadline) {
+			if err != nil {
+				return nil, fmt.Errorf("testclient: condition not met after %v: %w", timeout, err)
+			}
+			dump, _ := httputil.DumpResponse(res, true)
+			rewindBody(res)
+			return res, fmt.Errorf("testclient: condition not met after %v; last response:\n%s", timeout, dump)
+		}
+		if err := c.clock.Sleep(req.Context(), interval); err != nil {
+			return res, fmt.Errorf("testclient: condition not met: %w", err)
+		}
+	}
+}
+
+// rewindBody makes the body of res readable from the start again.
+func rewindBody(res *http.Response) {
+	bufferedBody(res).rewind()
+}
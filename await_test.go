@@ -0,0 +1,54 @@
+package testclient
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func jobDone(res *http.Response) bool {
+	var job struct{ State string }
+	return res.StatusCode == http.StatusOK && json.NewDecoder(res.Body).Decode(&job) == nil && job.State == "done"
+}
+
+func TestAwait(t *testing.T) {
+	calls := 0
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		state := "running"
+		if calls == 3 {
+			state = "done"
+		}
+		w.Write([]byte(`{"state":"` + state + `"}`))
+	})
+	c := New(h)
+	res, err := c.Await(c.NewRequest(http.MethodGet, "/jobs/1", nil), jobDone, time.Second, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 3 {
+		t.Errorf("%d requests, want 3", calls)
+	}
+	if got := body(t, res); got != `{"state":"done"}` {
+		t.Errorf("body = %q, want the body read by the condition", got)
+	}
+}
+
+func TestAwaitTimeout(t *testing.T) {
+	s := NewStub()
+	s.On("POST", "/jobs").ReplyJSON(http.StatusAccepted, map[string]string{"state": "queued"})
+	c := New(s)
+	req := c.NewRequest(http.MethodPost, "/jobs", strings.NewReader("{}"))
+	res, err := c.Await(req, jobDone, 20*time.Millisecond, 5*time.Millisecond)
+	if err == nil {
+		t.Fatal("Await succeeded")
+	}
+	if !strings.Contains(err.Error(), "202 Accepted") || !strings.Contains(err.Error(), `"queued"`) {
+		t.Errorf("error does not dump the last response: %v", err)
+	}
+	if res == nil || body(t, res) != `{"state":"queued"}` {
+		t.Error("Await did not return the last response")
+	}
+}
@@ -0,0 +1,121 @@
+package testclient
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+)
+
+// RequestSpec describes the request sent by Benchmark.
+type RequestSpec struct {
+	Method string // defaults to GET
+	Target string // defaults to /
+	Header http.Header
+	Body   []byte
+}
+
+// Benchmark serves spec to h b.N times and reports allocs/op along with the
+// median and 99th percentile latency. The request and response writer are
+// allocated once and reset between iterations, and the body is discarded
+// unread, so the figures are those of the handler rather than of the
+// client. Handlers must not keep the request after returning. A 5xx
+// response fails the benchmark.
+func Benchmark(b *testing.B, h http.Handler, spec RequestSpec) {
+	b.Helper()
+	req, body := spec.reusableRequest()
+	w := &benchWriter{header: http.Header{}}
+	latencies := make([]time.Duration, b.N)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		body.Reset(spec.Body)
+		w.reset()
+		start := time.Now()
+		h.ServeHTTP(w, req)
+		latencies[i] = time.Since(start)
+		if w.status >= 500 {
+			b.StopTimer()
+			b.Fatalf("testclient: %s %s: status %d", req.Method, req.URL.RequestURI(), w.status)
+		}
+	}
+	b.StopTimer()
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	b.ReportMetric(float64(latencies[len(latencies)/2]), "p50-ns")
+	b.ReportMetric(float64(latencies[len(latencies)*99/100]), "p99-ns")
+}
+
+// HandlerAllocs returns the average number of allocations h makes serving
+// spec, measured with testing.AllocsPerRun over runs requests. As with
+// Benchmark, the request and response writer are reused and the body is
+// discarded, so the count is that of the handler alone.
+func HandlerAllocs(h http.Handler, spec RequestSpec, runs int) float64 {
+	req, body := spec.reusableRequest()
+	w := &benchWriter{header: http.Header{}}
+	return testing.AllocsPerRun(runs, func() {
+		body.Reset(spec.Body)
+		w.reset()
+		h.ServeHTTP(w, req)
+	})
+}
+
+// ExpectMaxAllocs asserts that h makes at most max allocations serving
+// spec, on average over 100 requests.
+func ExpectMaxAllocs(t testing.TB, h http.Handler, spec RequestSpec, max float64) {
+	t.Helper()
+	if got := HandlerAllocs(h, spec, 100); got > max {
+		t.Errorf("expected at most %v allocations per request, got %v", max, got)
+	}
+}
+
+// reusableRequest returns the request spec describes and the reader of its
+// body, to be reset before each use.
+func (spec RequestSpec) reusableRequest() (*http.Request, *bytes.Reader) {
+	method, target := spec.Method, spec.Target
+	if method == "" {
+		method = http.MethodGet
+	}
+	if target == "" {
+		target = "/"
+	}
+	body := bytes.NewReader(spec.Body)
+	req := httptest.NewRequest(method, target, body)
+	for k, vv := range spec.Header {
+		req.Header[k] = vv
+	}
+	return req, body
+}
+
+// benchWriter is a reusable ResponseWriter that discards the body.
+type benchWriter struct {
+	header  http.Header
+	status  int
+	written int
+}
+
+func (w *benchWriter) reset() {
+	for k := range w.header {
+		delete(w.header, k)
+	}
+	w.status, w.written = 0, 0
+}
+
+func (w *benchWriter) Header() http.Header { return w.header }
+
+func (w *benchWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+func (w *benchWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	w.written += len(b)
+	return len(b), nil
+}
+
+func (w *benchWriter) Flush() {}
@@ -0,0 +1,79 @@
+package testclient
+
+import (
+	"flag"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func BenchmarkHandler(b *testing.B) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("ok"))
+	})
+	Benchmark(b, h, RequestSpec{Method: http.MethodPost, Target: "/echo", Body: []byte("payload")})
+}
+
+func TestBenchmarkAllocations(t *testing.T) {
+	ok := []byte("ok")
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(ok)
+	})
+	defer setFlag(t, "test.benchtime", "1000x")()
+	res := testing.Benchmark(func(b *testing.B) {
+		Benchmark(b, h, RequestSpec{})
+	})
+	if a := res.AllocsPerOp(); a != 0 {
+		t.Errorf("%d allocs/op for a handler that allocates nothing", a)
+	}
+	if _, ok := res.Extra["p99-ns"]; !ok {
+		t.Error("no p99-ns metric reported")
+	}
+}
+
+func TestBenchmarkFailsOnServerError(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	res := testing.Benchmark(func(b *testing.B) {
+		Benchmark(b, h, RequestSpec{})
+	})
+	if res.N != 0 {
+		t.Errorf("benchmark ran %d iterations past a 500", res.N)
+	}
+}
+
+func setFlag(t *testing.T, name, value string) (restore func()) {
+	old := flag.Lookup(name).Value.String()
+	if err := flag.Set(name, value); err != nil {
+		t.Fatal(err)
+	}
+	return func() { flag.Set(name, old) }
+}
+
+func TestHandlerAllocs(t *testing.T) {
+	ok := []byte("ok")
+	quiet := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Write(ok)
+	})
+	if a := HandlerAllocs(quiet, RequestSpec{Method: http.MethodPost, Body: []byte("payload")}, 50); a != 0 {
+		t.Errorf("HandlerAllocs = %v for a handler that allocates nothing", a)
+	}
+	ExpectMaxAllocs(t, quiet, RequestSpec{}, 0)
+
+	var sink [][]byte
+	busy := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < 3; i++ {
+			sink = append(sink[:0], make([]byte, 1024))
+		}
+	})
+	ft := &fakeT{}
+	ExpectMaxAllocs(ft, busy, RequestSpec{}, 2)
+	if len(ft.failures) != 1 || !strings.Contains(ft.failures[0], "expected at most 2 allocations per request, got 3") {
+		t.Errorf("failures = %q", ft.failures)
+	}
+}
@@ -0,0 +1,61 @@
+package testclient
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+)
+
+// responseBody is the body of a buffered response. The bytes are captured
+// once when the handler returns and shared, never copied, by everything
+// that exposes them; reading copies them out like any reader.
+type responseBody struct {
+	data []byte
+	r    bytes.Reader
+	// err ends the body in place of io.EOF, e.g. for a truncated response.
+	err error
+}
+
+func newResponseBody(data []byte, err error) *responseBody {
+	b := &responseBody{data: data, err: err}
+	b.r.Reset(data)
+	return b
+}
+
+func (b *responseBody) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	if err == io.EOF && b.err != nil {
+		err = b.err
+	}
+	return n, err
+}
+
+func (b *responseBody) Close() error { return nil }
+
+// rewind makes the body readable from the start again.
+func (b *responseBody) rewind() {
+	b.r.Reset(b.data)
+}
+
+// bufferedBody returns the body of res as a responseBody, reading and
+// replacing it if it is not one already.
+func bufferedBody(res *http.Response) *responseBody {
+	if b, ok := res.Body.(*responseBody); ok {
+		return b
+	}
+	data, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	b := newResponseBody(data, err)
+	res.Body = b
+	return b
+}
+
+// BodyBytes returns the body of the last response without consuming it, as
+// Response().Body yields it. The slice is shared with the response and must
+// not be modified. A streamed body is read to the end first.
+func (c *Client) BodyBytes() []byte {
+	if c.response == nil {
+		return nil
+	}
+	return bufferedBody(c.response).data
+}
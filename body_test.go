@@ -0,0 +1,60 @@
+package testclient
+
+import (
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestBodyBytesShared(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "hello")
+	})
+	c := New(h)
+	res, err := c.Request(c.NewRequest(http.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b1 := c.BodyBytes()
+	if string(b1) != "hello" {
+		t.Fatalf("BodyBytes = %q, want hello", b1)
+	}
+	if got := body(t, res); got != "hello" {
+		t.Errorf("body after BodyBytes = %q, want hello", got)
+	}
+	if b2 := c.BodyBytes(); &b1[0] != &b2[0] {
+		t.Error("BodyBytes copied the body")
+	}
+}
+
+func TestBodyBytesDecoded(t *testing.T) {
+	raw := compress(t, "gzip", []byte("plain"))
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(raw)
+	})
+	c := New(h, WithDecompression())
+	c.Request(c.NewRequest(http.MethodGet, "/", nil))
+	if got := string(c.BodyBytes()); got != "plain" {
+		t.Errorf("BodyBytes = %q, want the decoded body", got)
+	}
+}
+
+func TestBodyBytesStream(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "one ")
+		w.(http.Flusher).Flush()
+		io.WriteString(w, "two")
+	})
+	c := New(h)
+	res, err := c.Stream(c.NewRequest(http.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(c.BodyBytes()); got != "one two" {
+		t.Errorf("BodyBytes = %q, want one two", got)
+	}
+	if got := body(t, res); got != "one two" {
+		t.Errorf("body after BodyBytes = %q, want one two", got)
+	}
+}
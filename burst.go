@@ -0,0 +1,78 @@
+package testclient
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Result is the outcome of one request of a Burst.
+type Result struct {
+	Index    int
+	Response *http.Response
+	Body     []byte
+	Err      error
+	Duration time.Duration
+}
+
+// Burst sends the n requests built by newRequest concurrently and waits for
+// all of them. Each request runs in its own session: a copy of the client
+// with the same handler and options but an empty cookie jar. Results are in
+// index order, with bodies read.
+func (c *Client) Burst(n int, newRequest func(i int) *http.Request) []Result {
+	results := make([]Result, n)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		s := c.session()
+		req := newRequest(i)
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			r := Result{Index: i}
+			r.Response, r.Err = s.Request(req)
+			r.Duration = s.LastDuration()
+			if r.Err == nil {
+				r.Body, r.Err = io.ReadAll(r.Response.Body)
+			}
+			results[i] = r
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+	return results
+}
+
+// session returns a copy of c with its configuration but none of its state.
+func (c *Client) session() *Client {
+	s := &Client{
+		server:        c.server,
+		tls:           c.tls,
+		scheme:        c.scheme,
+		host:          c.host,
+		handlers:      c.handlers,
+		middleware:    c.middleware,
+		contextValues: c.contextValues,
+		decompress:    c.decompress,
+		headers:       c.headers.Clone(),
+		query:         cloneValues(c.query),
+		signer:        c.signer,
+		faults:        c.faults,
+		retry:         c.retry,
+		clock:         c.clock,
+		cacheAudit:    c.cacheAudit,
+		cookieAudit:   c.cookieAudit,
+		securityAudit: c.securityAudit,
+		redirectAudit: c.redirectAudit,
+		strictWrites:  c.strictWrites,
+		trace:         c.trace,
+		metrics:       c.metrics,
+		rand:          c.rand,
+
+		requestIDHeader: c.requestIDHeader,
+	}
+	s.jar = newClockJar(func() time.Time { return s.clock.Now() })
+	return s
+}
@@ -0,0 +1,45 @@
+package testclient
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestBurst(t *testing.T) {
+	var mu sync.Mutex
+	seen := map[string]int{}
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := r.Cookie("session"); err == nil {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		mu.Lock()
+		seen[r.URL.Query().Get("i")]++
+		mu.Unlock()
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "x"})
+		fmt.Fprint(w, r.URL.Query().Get("i"))
+	})
+	c := New(h)
+	c.SetHeader("X-Test", "burst")
+	results := c.Burst(50, func(i int) *http.Request {
+		return c.NewRequest(http.MethodGet, "/?i="+strconv.Itoa(i), nil)
+	})
+	if len(results) != 50 || len(seen) != 50 {
+		t.Fatalf("%d results for %d distinct requests, want 50", len(results), len(seen))
+	}
+	for i, r := range results {
+		if r.Err != nil {
+			t.Errorf("result %d: %v", i, r.Err)
+			continue
+		}
+		if r.Index != i || r.Response.StatusCode != http.StatusOK || string(r.Body) != strconv.Itoa(i) {
+			t.Errorf("result %d = %d %q, want 200 %q", i, r.Response.StatusCode, r.Body, strconv.Itoa(i))
+		}
+	}
+	if c.Response() != nil {
+		t.Error("Burst changed the state of the client")
+	}
+}
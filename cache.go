@@ -0,0 +1,115 @@
+package testclient
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// WithCacheAudit checks every response for HTTP caching mistakes, as
+// reported by AuditCaching, and reports each one with t.Errorf.
+func WithCacheAudit(t testing.TB) Option {
+	return func(c *Client) {
+		c.cacheAudit = t
+	}
+}
+
+// AuditCaching returns the caching mistakes in res as an answer to req,
+// each with an explanation:
+//   - Cache-Control and Expires that disagree,
+//   - a content-negotiated response without the matching Vary,
+//   - Set-Cookie on a response shared caches may store,
+//   - a response to an authenticated request that caches may store
+//     heuristically for lack of Cache-Control.
+func AuditCaching(req *http.Request, res *http.Response) []string {
+	var issues []string
+	cc := parseCacheControl(res.Header.Values("Cache-Control"))
+
+	if expires := res.Header.Get("Expires"); expires != "" {
+		exp, err := http.ParseTime(expires)
+		date := time.Now()
+		if d, err := http.ParseTime(res.Header.Get("Date")); err == nil {
+			date = d
+		}
+		switch maxAge, hasMaxAge := cc.seconds("max-age"); {
+		case err != nil:
+			// an invalid Expires means already expired; nothing conflicts
+		case cc.has("no-store") && exp.After(date):
+			issues = append(issues, "Cache-Control: no-store with an Expires in the future; caches that ignore no-store will keep it")
+		case hasMaxAge && absDuration(exp.Sub(date)-time.Duration(maxAge)*time.Second) > time.Minute:
+			issues = append(issues, fmt.Sprintf("Cache-Control max-age=%d disagrees with Expires %s; HTTP/1.0 caches use Expires", maxAge, expires))
+		}
+	}
+
+	vary := strings.ToLower(strings.Join(res.Header.Values("Vary"), ","))
+	varies := func(h string) bool {
+		return strings.Contains(vary, "*") || strings.Contains(vary, strings.ToLower(h))
+	}
+	if ce := res.Header.Get("Content-Encoding"); ce != "" && ce != "identity" && !varies("Accept-Encoding") {
+		issues = append(issues, fmt.Sprintf("Content-Encoding: %s without Vary: Accept-Encoding; caches may serve it to clients that cannot decode it", ce))
+	}
+	if res.Header.Get("Content-Language") != "" && req.Header.Get("Accept-Language") != "" && !varies("Accept-Language") {
+		issues = append(issues, "Content-Language negotiated from Accept-Language without Vary: Accept-Language")
+	}
+
+	private := cc.has("private") || cc.has("no-store") || cc.has("no-cache")
+	_, maxAge := cc.seconds("max-age")
+	_, sMaxAge := cc.seconds("s-maxage")
+	explicit := cc.has("public") || maxAge || sMaxAge || res.Header.Get("Expires") != ""
+	if explicit && !private && len(res.Header.Values("Set-Cookie")) > 0 {
+		issues = append(issues, "Set-Cookie on a response shared caches may store; one user's cookie can be served to others (add Cache-Control: private)")
+	}
+
+	authenticated := req.Header.Get("Authorization") != "" || req.Header.Get("Cookie") != ""
+	if authenticated && len(res.Header.Values("Cache-Control")) == 0 && heuristicallyCacheable(res.StatusCode) {
+		issues = append(issues, fmt.Sprintf("%d response to an authenticated request has no Cache-Control; caches may store it heuristically (add Cache-Control: private or no-store)", res.StatusCode))
+	}
+	return issues
+}
+
+// heuristicallyCacheable reports whether responses with status may be
+// cached without explicit freshness, per RFC 9110 section 15.1.
+func heuristicallyCacheable(status int) bool {
+	switch status {
+	case 200, 203, 204, 206, 300, 301, 308, 404, 405, 410, 414, 501:
+		return true
+	}
+	return false
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+type cacheControl map[string]string
+
+func parseCacheControl(values []string) cacheControl {
+	cc := cacheControl{}
+	for _, v := range values {
+		for _, d := range splitList(v) {
+			name, arg, _ := strings.Cut(d, "=")
+			cc[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(arg), `"`)
+		}
+	}
+	return cc
+}
+
+func (cc cacheControl) has(name string) bool {
+	_, ok := cc[name]
+	return ok
+}
+
+func (cc cacheControl) seconds(name string) (int, bool) {
+	v, ok := cc[name]
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(v)
+	return n, err == nil
+}
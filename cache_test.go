@@ -0,0 +1,71 @@
+package testclient
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuditCaching(t *testing.T) {
+	date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		reqHdr http.Header
+		resHdr http.Header
+		status int
+		want   string
+	}{
+		{name: "clean", resHdr: http.Header{"Cache-Control": {"max-age=60"}, "Expires": {date.Add(time.Minute).Format(http.TimeFormat)}}},
+		{name: "no-store with future Expires", resHdr: http.Header{"Cache-Control": {"no-store"}, "Expires": {date.Add(time.Hour).Format(http.TimeFormat)}}, want: "no-store"},
+		{name: "max-age disagrees", resHdr: http.Header{"Cache-Control": {"public, max-age=60"}, "Expires": {date.Add(time.Hour).Format(http.TimeFormat)}}, want: "disagrees"},
+		{name: "gzip without Vary", resHdr: http.Header{"Content-Encoding": {"gzip"}}, want: "Vary: Accept-Encoding"},
+		{name: "gzip with Vary", resHdr: http.Header{"Content-Encoding": {"gzip"}, "Vary": {"Origin, Accept-Encoding"}}},
+		{name: "language without Vary", reqHdr: http.Header{"Accept-Language": {"ja"}}, resHdr: http.Header{"Content-Language": {"ja"}}, want: "Vary: Accept-Language"},
+		{name: "public Set-Cookie", resHdr: http.Header{"Cache-Control": {"max-age=60"}, "Set-Cookie": {"session=1"}}, want: "Set-Cookie"},
+		{name: "private Set-Cookie", resHdr: http.Header{"Cache-Control": {"private, max-age=60"}, "Set-Cookie": {"session=1"}}},
+		{name: "authenticated without Cache-Control", reqHdr: http.Header{"Authorization": {"Bearer x"}}, want: "authenticated"},
+		{name: "authenticated private", reqHdr: http.Header{"Cookie": {"session=1"}}, resHdr: http.Header{"Cache-Control": {"private"}}},
+		{name: "authenticated created", reqHdr: http.Header{"Authorization": {"Bearer x"}}, status: http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "http://example.com/", nil)
+			if tt.reqHdr != nil {
+				req.Header = tt.reqHdr
+			}
+			res := &http.Response{StatusCode: http.StatusOK, Header: http.Header{"Date": {date.Format(http.TimeFormat)}}}
+			if tt.status != 0 {
+				res.StatusCode = tt.status
+			}
+			for k, v := range tt.resHdr {
+				res.Header[k] = v
+			}
+			issues := AuditCaching(req, res)
+			if tt.want == "" {
+				if len(issues) != 0 {
+					t.Errorf("unexpected issues %q", issues)
+				}
+				return
+			}
+			if len(issues) != 1 || !strings.Contains(issues[0], tt.want) {
+				t.Errorf("expected one issue mentioning %q, got %q", tt.want, issues)
+			}
+		})
+	}
+}
+
+func TestWithCacheAudit(t *testing.T) {
+	stub := NewStub()
+	stub.On(http.MethodGet, "/me").Reply(http.StatusOK, "alice").SetHeader("Content-Encoding", "gzip")
+	ft := &fakeT{TB: t}
+	c := New(stub, WithCacheAudit(ft))
+	req := c.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Authorization", "Bearer x")
+	if _, err := c.Request(req); err != nil {
+		t.Fatal(err)
+	}
+	if len(ft.failures) != 2 || !strings.Contains(ft.failures[0], "GET http://example.com/me") {
+		t.Errorf("failures = %q", ft.failures)
+	}
+}
@@ -0,0 +1,91 @@
+package testclient
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// Captured is a handler that records every request it receives before
+// passing it on, so tests can assert on what the handler actually saw.
+type Captured struct {
+	next http.Handler
+
+	mu       sync.Mutex
+	requests []capturedRequest
+}
+
+type capturedRequest struct {
+	req  *http.Request
+	body []byte
+}
+
+// Capture wraps h, recording each inbound request with a copy of its body.
+func Capture(h http.Handler) *Captured {
+	return &Captured{next: h}
+}
+
+func (c *Captured) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body []byte
+	if r.Body != nil && r.Body != http.NoBody {
+		body, _ = io.ReadAll(r.Body)
+		r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	c.mu.Lock()
+	c.requests = append(c.requests, capturedRequest{req: r.Clone(r.Context()), body: body})
+	c.mu.Unlock()
+
+	c.next.ServeHTTP(w, r)
+}
+
+// Requests returns the recorded requests in arrival order. Each has a fresh
+// body reader over the captured bytes.
+func (c *Captured) Requests() []*http.Request {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	reqs := make([]*http.Request, len(c.requests))
+	for i, cr := range c.requests {
+		reqs[i] = cr.open()
+	}
+	return reqs
+}
+
+// Last returns the most recent request, or nil if there was none.
+func (c *Captured) Last() *http.Request {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.requests) == 0 {
+		return nil
+	}
+	return c.requests[len(c.requests)-1].open()
+}
+
+// Bodies returns the captured request bodies in arrival order.
+func (c *Captured) Bodies() [][]byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	bodies := make([][]byte, len(c.requests))
+	for i, cr := range c.requests {
+		bodies[i] = cr.body
+	}
+	return bodies
+}
+
+// Reset forgets the recorded requests.
+func (c *Captured) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests = nil
+}
+
+func (cr capturedRequest) open() *http.Request {
+	r := cr.req.Clone(cr.req.Context())
+	if cr.body == nil {
+		r.Body = http.NoBody
+	} else {
+		r.Body = io.NopCloser(bytes.NewReader(cr.body))
+	}
+	return r
+}
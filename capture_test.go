@@ -0,0 +1,71 @@
+package testclient
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+)
+
+func TestCapture(t *testing.T) {
+	var handlerBody string
+	captured := Capture(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		handlerBody = string(b)
+	}))
+	c := New(captured)
+
+	if captured.Last() != nil {
+		t.Error("Last before any request is not nil")
+	}
+	c.PostForm("/a", url.Values{"x": {"1"}})
+	c.PostJSON("/b", map[string]int{"y": 2}, Header("X-Test", "yes"))
+
+	if handlerBody != `{"y":2}` {
+		t.Errorf("handler read %q; the body must still reach it", handlerBody)
+	}
+	reqs := captured.Requests()
+	if len(reqs) != 2 {
+		t.Fatalf("captured %d requests, want 2", len(reqs))
+	}
+	if reqs[0].URL.Path != "/a" || reqs[0].Header.Get("Content-Type") != "application/x-www-form-urlencoded" {
+		t.Errorf("first request = %s %v", reqs[0].URL, reqs[0].Header)
+	}
+	if reqs[0].FormValue("x") != "1" {
+		t.Errorf("first request form x = %q", reqs[0].FormValue("x"))
+	}
+	last := captured.Last()
+	if last.Header.Get("X-Test") != "yes" {
+		t.Errorf("last request headers = %v", last.Header)
+	}
+	for i := 0; i < 2; i++ {
+		if b, _ := io.ReadAll(captured.Last().Body); string(b) != `{"y":2}` {
+			t.Errorf("read %d of the captured body = %q", i, b)
+		}
+	}
+	if got := string(captured.Bodies()[1]); got != `{"y":2}` {
+		t.Errorf("Bodies()[1] = %q", got)
+	}
+
+	captured.Reset()
+	if len(captured.Requests()) != 0 {
+		t.Error("Reset kept requests")
+	}
+}
+
+func TestCaptureConcurrent(t *testing.T) {
+	captured := Capture(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			New(captured).PostForm("/", nil)
+		}()
+	}
+	wg.Wait()
+	if n := len(captured.Requests()); n != 20 {
+		t.Errorf("captured %d requests, want 20", n)
+	}
+}
@@ -0,0 +1,42 @@
+package testclient
+
+import (
+	"io"
+	"net/http"
+	"testing"
+)
+
+// NewT returns a client for server like New, bound to tb: when the test
+// ends, the client closes the Stream bodies, event streams and WebSocket
+// connections it opened that are still open, so that their handlers
+// return, reports on tb a panic of such a handler, and forgets its
+// history. Assertions take tb as usual and report the line of the test.
+func NewT(tb testing.TB, server http.Handler, opts ...Option) *Client {
+	tb.Helper()
+	c := New(server, opts...)
+	c.tb = tb
+	tb.Cleanup(c.cleanup)
+	return c
+}
+
+// track keeps closer to be closed when the test of a NewT client ends.
+func (c *Client) track(closer io.Closer) {
+	if c.tb != nil {
+		c.open = append(c.open, closer)
+	}
+}
+
+func (c *Client) cleanup() {
+	for i := len(c.open) - 1; i >= 0; i-- {
+		if err := c.open[i].Close(); err != nil {
+			c.tb.Errorf("testclient: closing a connection left open: %v", err)
+		}
+	}
+	c.open = nil
+	c.writeArtifacts()
+	c.ClearHistory()
+}
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error { return f() }
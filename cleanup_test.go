@@ -0,0 +1,133 @@
+package testclient
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewT(t *testing.T) {
+	streamDone := make(chan struct{})
+	wsDone := make(chan struct{})
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/stream":
+			defer close(streamDone)
+			for {
+				// writes fail once the body is closed
+				if _, err := w.Write([]byte("tick\n")); err != nil {
+					return
+				}
+				w.(http.Flusher).Flush()
+			}
+		case "/ws":
+			defer close(wsDone)
+			conn, rw := wsAccept(t, w, r)
+			if conn == nil {
+				return
+			}
+			defer conn.Close()
+			rw.ReadByte() // until the client goes away
+		}
+	})
+
+	var c *Client
+	t.Run("test", func(t *testing.T) {
+		c = NewT(t, h, WithHistory())
+		c.PostForm("/", url.Values{"a": {"1"}})
+		res, err := c.Stream(c.NewRequest(http.MethodGet, "/stream", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf := make([]byte, 5)
+		res.Body.Read(buf)
+		if _, err := c.Dial("/ws"); err != nil {
+			t.Fatal(err)
+		}
+		// both handlers still run when the test ends
+	})
+	for name, done := range map[string]chan struct{}{"stream": streamDone, "WebSocket": wsDone} {
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Errorf("%s handler still running after the test ended", name)
+		}
+	}
+	if len(c.History()) != 0 {
+		t.Error("history kept after the test ended")
+	}
+}
+
+func TestNewTReportsPanics(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw := wsAccept(t, w, r)
+		if conn == nil {
+			return
+		}
+		rw.ReadByte()
+		panic("after close")
+	})
+	ft := &cleanupT{fakeT: &fakeT{TB: t}}
+	c := NewT(ft, h)
+	c.Dial("/ws")
+	ft.run()
+	if len(ft.failures) != 1 || !strings.Contains(ft.failures[0], "after close") {
+		t.Errorf("failures = %q", ft.failures)
+	}
+
+	// connections the test closed are not closed again
+	ft = &cleanupT{fakeT: &fakeT{TB: t}}
+	c = NewT(ft, h)
+	ws, _ := c.Dial("/ws")
+	ws.Close()
+	ft.run()
+	if len(ft.failures) != 0 {
+		t.Errorf("failures after Close = %q", ft.failures)
+	}
+}
+
+func TestNewTReportsStreamPanics(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for {
+			if _, err := w.Write([]byte("tick\n")); err != nil {
+				panic("after close")
+			}
+			w.(http.Flusher).Flush()
+		}
+	})
+	ft := &cleanupT{fakeT: &fakeT{TB: t}}
+	c := NewT(ft, h)
+	if _, err := c.Stream(c.NewRequest(http.MethodGet, "/", nil)); err != nil {
+		t.Fatal(err)
+	}
+	ft.run()
+	if len(ft.failures) != 1 || !strings.Contains(ft.failures[0], "after close") {
+		t.Errorf("failures = %q", ft.failures)
+	}
+
+	// bodies the test closed are not reported
+	ft = &cleanupT{fakeT: &fakeT{TB: t}}
+	c = NewT(ft, h)
+	res, _ := c.Stream(c.NewRequest(http.MethodGet, "/", nil))
+	res.Body.Close()
+	ft.run()
+	if len(ft.failures) != 0 {
+		t.Errorf("failures after Close = %q", ft.failures)
+	}
+}
+
+// cleanupT runs cleanups when asked to.
+type cleanupT struct {
+	*fakeT
+	cleanups []func()
+}
+
+func (c *cleanupT) Cleanup(f func()) { c.cleanups = append(c.cleanups, f) }
+
+func (c *cleanupT) run() {
+	for i := len(c.cleanups) - 1; i >= 0; i-- {
+		c.cleanups[i]()
+	}
+}
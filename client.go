@@ -1,44 +1,302 @@
 package testclient
 
 import (
+	"crypto/tls"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strings"
+	"testing"
+	"time"
 )
 
 type Client struct {
-	server   http.Handler
-	response *http.Response
+	server        http.Handler
+	response      *http.Response
+	request       *http.Request
+	jar           *clockJar
+	tls           bool
+	host          string
+	scheme        string
+	handlers      map[string]http.Handler
+	middleware    []func(http.Handler) http.Handler
+	contextValues []contextValue
+	timeline      WriteTimeline
+	recorder      *httptest.ResponseRecorder
+
+	decompress    bool
+	headers       http.Header
+	query         url.Values
+	signer        Signer
+	faults        *Faults
+	retry         *retryPolicy
+	attempts      []Attempt
+	clock         Clock
+	durations     []time.Duration
+	cacheAudit    testing.TB
+	cookieAudit   *cookieAudit
+	securityAudit *securityAudit
+	redirectAudit *redirectAudit
+	strictWrites  testing.TB
+	continued     bool
+	trace         *traceContext
+	metrics       *metrics
+	rand          *seededRand
+
+	requestIDHeader string
+	shadow          *shadowPolicy
+
+	tb   testing.TB
+	open []io.Closer
+
+	keepHistory bool
+	history     []exchange
+	trail       []step
+	transfers   []Transfer
+	artifacts   []artifactPolicy
+	failures    []ReportFailure
 }
 
-func New(server http.Handler) *Client {
-	return &Client{
-		server: server,
+type Option func(*Client)
+
+// RequestOption adjusts a single request before it is sent.
+type RequestOption func(*http.Request)
+
+// WithTLS makes every request look as if it arrived over https: req.TLS is
+// populated with a synthetic connection state and the URL scheme is https.
+func WithTLS() Option {
+	return func(c *Client) {
+		c.tls = true
 	}
 }
 
-func (c *Client) Request(req *http.Request) {
-	rec := httptest.NewRecorder()
-	c.server.ServeHTTP(rec, req)
-	c.response = rec.Result()
+func New(server http.Handler, opts ...Option) *Client {
+	c := &Client{
+		server:  server,
+		clock:   realClock{},
+		metrics: newMetrics(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.jar = newClockJar(func() time.Time { return c.clock.Now() })
+	return c
 }
 
-func (c *Client) PostForm(uri string, params map[string]string) {
-	p := url.Values{}
-	for key, value := range params {
-		p.Add(key, value)
+// SetHost sets the Host of subsequent requests. It applies to requests left
+// on the httptest default host (example.com) with a path-only URL; absolute
+// URLs and explicit Host values are kept.
+func (c *Client) SetHost(host string) {
+	c.host = host
+}
+
+// Host overrides the Host of a single request.
+func Host(host string) RequestOption {
+	return func(req *http.Request) {
+		req.Host = host
+		if req.URL.Host != "" {
+			req.URL.Host = host
+		}
+	}
+}
+
+// Request sends req to the handler and returns the buffered response. It
+// fails if the request could not be prepared, e.g. by the signer, if the
+// handler panicked (with a *PanicError), or if it aborted the connection
+// before responding. With WithRetry, the
+// request is repeated as the policy says and the last attempt is returned.
+func (c *Client) Request(req *http.Request, opts ...RequestOption) (*http.Response, error) {
+	if err := c.prepare(req, opts); err != nil {
+		c.request, c.response, c.attempts = req, nil, nil
+		c.addStep(req, nil, err)
+		return nil, err
+	}
+	var body []byte
+	if c.keepHistory || c.shadow != nil {
+		var err error
+		if body, err = keepBody(req); err != nil {
+			c.request, c.response, c.attempts = req, nil, nil
+			c.addStep(req, nil, err)
+			return nil, err
+		}
+	}
+	var res *http.Response
+	var err error
+	if c.retry != nil {
+		res, err = c.retry.do(c, req)
+	} else {
+		start := c.clock.Now()
+		res, err = c.serve(req)
+		c.attempts = []Attempt{{Request: req, Response: res, Err: err, Start: start, Duration: c.LastDuration()}}
+	}
+	if c.shadow != nil {
+		c.compareShadow(req, body, res, err)
+	}
+	if c.keepHistory {
+		c.history = append(c.history, exchange{req: req, body: body, res: res, err: err, took: c.LastDuration()})
+	}
+	c.addStep(req, res, err)
+	return res, err
+}
+
+// serve runs a prepared request through the handler once.
+func (c *Client) serve(req *http.Request) (*http.Response, error) {
+	handler := c.handlerFor(req)
+	if c.faults != nil {
+		handler = c.faults.wrap(handler, c.rand)
+	}
+	var read int64
+	if req.Body != nil && req.Body != http.NoBody && req.ContentLength <= 0 {
+		req.Body = countingBody{ReadCloser: req.Body, n: &read}
+	}
+	rec := getRecorder(c)
+	defer putRecorder(rec)
+	w, served, continued := armContinue(rec, req, c.clock)
+	w, served, disconnected := armDisconnect(w, served)
+	start := c.clock.Now()
+	aborted, perr := serveRecovering(handler, w, served)
+	c.continued = continued()
+	elapsed := c.clock.Now().Sub(start)
+	c.durations = append(c.durations, elapsed)
+	status := 0
+	defer func() { c.metrics.observe(req.Method, status, elapsed) }()
+	transfer := requestTransfer(req, read)
+	defer func() { c.transfers = append(c.transfers, transfer) }()
+	gone := disconnected()
+	if perr != nil {
+		c.recorder = rec.snapshot(nil)
+		c.request, c.response = req, nil
+		return nil, perr
+	}
+	c.timeline = rec.timeline
+	if aborted && !rec.wroteHeader {
+		c.recorder = rec.snapshot(nil)
+		c.request, c.response = req, nil
+		if gone {
+			return nil, ErrClientDisconnected
+		}
+		return nil, ErrConnectionReset
+	}
+	var cut error
+	if aborted {
+		// a dropped connection cuts the body short
+		cut = io.ErrUnexpectedEOF
+	}
+	res := rec.result(cut)
+	c.recorder = rec.snapshot(bufferedBody(res).data)
+	transfer.addResponse(res, len(bufferedBody(res).data))
+	res.Request = req
+	if c.strictWrites != nil && !aborted {
+		for _, problem := range writeProblems(req, rec, res) {
+			c.strictWrites.Errorf("testclient: %s %s: %s", req.Method, requestURL(req), problem)
+		}
+	}
+	status = res.StatusCode
+	res.Trailer = finalTrailers(rec.HeaderMap, declaredTrailers(res.Header))
+	stripTrailerHeaders(res.Header)
+	if c.decompress {
+		decodeBuffered(res)
+	}
+	if gone {
+		// the client is not there to receive the response or its cookies
+		c.request, c.response = req, res
+		return nil, ErrClientDisconnected
+	}
+	c.record(req, res)
+	return res, nil
+}
+
+// prepare applies the client's context values, opts and the client state
+// (TLS, headers, cookies) to req and signs it.
+func (c *Client) prepare(req *http.Request, opts []RequestOption) error {
+	c.applyHost(req)
+	c.applyContextValues(req)
+	c.applyTrail(req)
+	for _, opt := range opts {
+		opt(req)
+	}
+	c.applyHeaders(req)
+	c.applyQuery(req)
+	c.applyRequestID(req)
+	if err := c.applyTraceContext(req); err != nil {
+		return err
+	}
+	if c.tls {
+		setTLS(req)
+	}
+	if c.decompress && req.Header.Get("Accept-Encoding") == "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	// cookies set on the request win over jar cookies of the same name; the
+	// jar may hold several of a name for different paths
+	explicit := map[string]bool{}
+	for _, cookie := range req.Cookies() {
+		explicit[cookie.Name] = true
+	}
+	for _, cookie := range c.jar.Cookies(requestURL(req)) {
+		if !explicit[cookie.Name] {
+			req.AddCookie(cookie)
+		}
 	}
-	form := strings.NewReader(p.Encode())
+	if c.signer != nil {
+		if err := c.signer.Sign(req); err != nil {
+			return fmt.Errorf("signing request: %w", err)
+		}
+	}
+	return nil
+}
+
+// record stores res as the response to req.
+func (c *Client) record(req *http.Request, res *http.Response) {
+	c.request = req
+	c.response = res
+	if c.cacheAudit != nil {
+		for _, issue := range AuditCaching(req, res) {
+			c.cacheAudit.Errorf("testclient: %s %s: %s", req.Method, requestURL(req), issue)
+		}
+	}
+	if c.cookieAudit != nil {
+		for _, issue := range AuditCookies(res, c.cookieAudit.policy) {
+			c.cookieAudit.tb.Errorf("testclient: %s %s: %s", req.Method, requestURL(req), issue)
+		}
+	}
+	if c.securityAudit != nil {
+		for _, issue := range AuditSecurityHeaders(req, res, c.securityAudit.policy) {
+			c.securityAudit.tb.Errorf("testclient: %s %s: %s", req.Method, requestURL(req), issue)
+		}
+	}
+	if c.redirectAudit != nil {
+		for _, issue := range AuditRedirect(req, res, c.redirectAudit.allow) {
+			c.redirectAudit.tb.Errorf("testclient: %s %s: %s", req.Method, requestURL(req), issue)
+		}
+	}
+	c.jar.SetCookies(requestURL(req), res.Cookies())
+}
 
-	req := httptest.NewRequest(http.MethodPost, uri, form)
+// PostForm posts form URL-encoded to uri. Repeated keys, as sent by
+// checkbox groups and multi-selects, are kept.
+func (c *Client) PostForm(uri string, form url.Values, opts ...RequestOption) (*http.Response, error) {
+	req := c.NewRequest(http.MethodPost, uri, strings.NewReader(form.Encode()))
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	c.Request(req)
+	return c.Request(req, opts...)
+}
+
+// PostFormMap posts params, one value per key, like PostForm.
+func (c *Client) PostFormMap(uri string, params map[string]string, opts ...RequestOption) (*http.Response, error) {
+	form := url.Values{}
+	for key, value := range params {
+		form.Add(key, value)
+	}
+	return c.PostForm(uri, form, opts...)
 }
 
 func (c *Client) FollowRedirect() error {
+	if c.request == nil || c.response == nil {
+		return fmt.Errorf("testclient: no request to follow a redirect from")
+	}
 	// check redirect conditions
 	if !(300 <= c.response.StatusCode && c.response.StatusCode < 400) {
 		return fmt.Errorf("bad http status code for redirect: %d", c.response.StatusCode)
@@ -47,15 +305,80 @@ func (c *Client) FollowRedirect() error {
 	if location == "" {
 		return fmt.Errorf("no Location header error")
 	}
+	origin := requestURL(c.request)
+	target, err := origin.Parse(location)
+	if err != nil {
+		return fmt.Errorf("bad Location header %q: %w", location, err)
+	}
+	if !sameHost(origin, target) && len(c.handlers) == 0 {
+		return fmt.Errorf("testclient: cross-host redirect from %s to %s; register a handler for %s with Handle or WithHandlers to follow it", origin.Host, target, target.Hostname())
+	}
 
-	cookie := c.response.Header.Get("Set-Cookie")
-	req := httptest.NewRequest(http.MethodGet, location, nil)
-	req.Header.Set("Cookie", cookie)
-	c.Request(req)
-
-	return nil
+	// cookies are carried over by the jar
+	req := c.NewRequest(http.MethodGet, target.String(), nil)
+	_, err = c.Request(req)
+	return err
 }
 
 func (c *Client) Response() *http.Response {
 	return c.response
 }
+
+// NewRequest builds a request for target with the client's Host applied.
+func (c *Client) NewRequest(method, target string, body io.Reader) *http.Request {
+	req := httptest.NewRequest(method, target, body)
+	c.applyHost(req)
+	return req
+}
+
+// SetScheme sets the scheme of subsequent requests with a path-only URL.
+// "https" makes them arrive over TLS as with WithTLS, but leaves absolute
+// http URLs alone.
+func (c *Client) SetScheme(scheme string) {
+	c.scheme = strings.ToLower(scheme)
+}
+
+func (c *Client) applyHost(req *http.Request) {
+	if c.host != "" && req.Host == "example.com" && req.URL.Host == "" {
+		req.Host = c.host
+	}
+	if c.scheme == "https" && req.URL.Host == "" {
+		setTLS(req)
+	}
+}
+
+// sameHost reports whether a and b name the same host. Ports and schemes
+// are ignored: every port of a host reaches the same handler.
+func sameHost(a, b *url.URL) bool {
+	return strings.EqualFold(a.Hostname(), b.Hostname())
+}
+
+func setTLS(req *http.Request) {
+	if req.TLS == nil {
+		req.TLS = &tls.ConnectionState{
+			Version:           tls.VersionTLS12,
+			HandshakeComplete: true,
+			ServerName:        req.Host,
+		}
+	}
+	req.URL.Scheme = "https"
+	if req.URL.Host == "" {
+		req.URL.Host = req.Host
+	}
+}
+
+// requestURL returns the absolute URL a request was addressed to, as seen by
+// the cookie jar.
+func requestURL(req *http.Request) *url.URL {
+	u := *req.URL
+	if u.Host == "" {
+		u.Host = req.Host
+	}
+	if u.Scheme == "" {
+		u.Scheme = "http"
+		if req.TLS != nil {
+			u.Scheme = "https"
+		}
+	}
+	return &u
+}
@@ -0,0 +1,185 @@
+package testclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestWithTLS(t *testing.T) {
+	var got *http.Request
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+	})
+
+	c := New(h, WithTLS())
+	c.PostForm("/", nil)
+	if got.TLS == nil {
+		t.Error("r.TLS is nil under WithTLS")
+	}
+	if got.URL.Scheme != "https" {
+		t.Errorf("r.URL.Scheme = %q, want https", got.URL.Scheme)
+	}
+
+	c = New(h)
+	c.PostForm("/", nil)
+	if got.TLS != nil {
+		t.Error("r.TLS is set without WithTLS")
+	}
+}
+
+func TestSecureCookie(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "1", Path: "/", Secure: true})
+			http.Redirect(w, r, "/home", http.StatusFound)
+			return
+		}
+		if _, err := r.Cookie("session"); err == nil {
+			w.Header().Set("X-Session", "yes")
+		}
+	})
+
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{"https", []Option{WithTLS()}, "yes"},
+		{"http", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(h, tt.opts...)
+			c.PostForm("/login", nil)
+			if err := c.FollowRedirect(); err != nil {
+				t.Fatal(err)
+			}
+			if got := c.Response().Header.Get("X-Session"); got != tt.want {
+				t.Errorf("X-Session = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetHost(t *testing.T) {
+	var host string
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host = r.Host
+	})
+
+	c := New(h)
+	c.SetHost("admin.example.com")
+	c.PostForm("/", nil)
+	if host != "admin.example.com" {
+		t.Errorf("PostForm: r.Host = %q, want admin.example.com", host)
+	}
+	c.Request(httptest.NewRequest(http.MethodGet, "/", nil))
+	if host != "admin.example.com" {
+		t.Errorf("Request: r.Host = %q, want admin.example.com", host)
+	}
+	c.PostForm("/", nil, Host("tenant.example.com"))
+	if host != "tenant.example.com" {
+		t.Errorf("Host option: r.Host = %q, want tenant.example.com", host)
+	}
+}
+
+func TestFollowRedirectRelative(t *testing.T) {
+	var got *http.Request
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		if r.URL.Path == "/a/start" {
+			http.Redirect(w, r, "next", http.StatusFound)
+		}
+	})
+
+	c := New(h)
+	c.SetHost("admin.example.com")
+	c.PostForm("/a/start", nil)
+	if err := c.FollowRedirect(); err != nil {
+		t.Fatal(err)
+	}
+	if got.Host != "admin.example.com" || got.URL.Path != "/a/next" {
+		t.Errorf("redirected to %s%s, want admin.example.com/a/next", got.Host, got.URL.Path)
+	}
+}
+
+func TestPostForm(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		fmt.Fprint(w, r.PostForm["color"], r.PostForm.Get("name"))
+	})
+	c := New(h)
+	res, err := c.PostForm("/", url.Values{"color": {"red", "blue"}, "name": {"x"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := body(t, res); got != "[red blue]x" {
+		t.Errorf("handler saw %q", got)
+	}
+	res, err = c.PostFormMap("/", map[string]string{"name": "y"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := body(t, res); got != "[]y" {
+		t.Errorf("handler saw %q", got)
+	}
+}
+
+func TestFollowRedirectAbsolute(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/upgrade":
+			http.Redirect(w, r, "https://api.example.com/secure?x=1", http.StatusMovedPermanently)
+		case "/away":
+			http.Redirect(w, r, "https://evil.example.com/", http.StatusFound)
+		default:
+			fmt.Fprintf(w, "%s %s %v", r.Host, r.URL.Path, r.TLS != nil)
+		}
+	})
+	c := New(h)
+	c.SetHost("api.example.com")
+	c.Request(c.NewRequest(http.MethodGet, "/upgrade", nil))
+	if err := c.FollowRedirect(); err != nil {
+		t.Fatal(err)
+	}
+	if got := body(t, c.Response()); got != "api.example.com /secure true" {
+		t.Errorf("redirect served %q", got)
+	}
+
+	c.Request(c.NewRequest(http.MethodGet, "/away", nil))
+	if err := c.FollowRedirect(); err == nil || !strings.Contains(err.Error(), "cross-host redirect from api.example.com to https://evil.example.com/") {
+		t.Errorf("err = %v, want a cross-host redirect error", err)
+	}
+}
+
+func TestSetScheme(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/start" {
+			http.Redirect(w, r, "/next", http.StatusFound)
+			return
+		}
+		fmt.Fprintf(w, "%s %v", r.Host, r.TLS != nil)
+	})
+	c := New(h)
+	c.SetHost("api.example.com")
+	c.SetScheme("https")
+	res, _ := c.Request(c.NewRequest(http.MethodGet, "/", nil))
+	if got := body(t, res); got != "api.example.com true" {
+		t.Errorf("path-only request served %q", got)
+	}
+	res, _ = c.Request(c.NewRequest(http.MethodGet, "http://plain.example.com/", nil))
+	if got := body(t, res); got != "plain.example.com false" {
+		t.Errorf("absolute http request served %q", got)
+	}
+	c.Request(c.NewRequest(http.MethodGet, "/start", nil))
+	if err := c.FollowRedirect(); err != nil {
+		t.Fatal(err)
+	}
+	if got := body(t, c.Response()); got != "api.example.com true" || c.request.URL.String() != "https://api.example.com/next" {
+		t.Errorf("redirect served %q at %s", got, c.request.URL)
+	}
+}
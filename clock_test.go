@@ -0,0 +1,75 @@
+package testclient
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestFakeClockCookieExpiry(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "s", MaxAge: 60})
+			http.SetCookie(w, &http.Cookie{Name: "remember", Value: "r", Path: "/", Domain: "example.com", Expires: clock.Now().Add(time.Hour)})
+			http.SetCookie(w, &http.Cookie{Name: "forever", Value: "f"})
+		case "/logout":
+			http.SetCookie(w, &http.Cookie{Name: "forever", MaxAge: -1})
+		}
+		for _, c := range r.Cookies() {
+			w.Write([]byte(c.Name + ";"))
+		}
+	})
+	c := New(h, WithClock(clock))
+	get := func(path string) string {
+		res, err := c.Request(c.NewRequest(http.MethodGet, path, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return body(t, res)
+	}
+
+	get("/login")
+	if got := get("/"); got != "session;remember;forever;" {
+		t.Errorf("cookies = %q, want all three", got)
+	}
+	clock.Advance(2 * time.Minute)
+	if got := get("/"); got != "remember;forever;" {
+		t.Errorf("cookies after 2m = %q, want session expired", got)
+	}
+	clock.Advance(time.Hour)
+	if got := get("/logout"); got != "forever;" {
+		t.Errorf("cookies after 1h = %q, want remember expired", got)
+	}
+	if got := get("/"); got != "" {
+		t.Errorf("cookies after logout = %q, want none", got)
+	}
+}
+
+func TestFakeClockTimings(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(t0)
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("pending"))
+	})
+	c := New(h, WithClock(clock))
+	start := time.Now()
+	_, err := c.Await(c.NewRequest(http.MethodGet, "/", nil), func(*http.Response) bool { return false }, time.Hour, time.Minute)
+	if err == nil {
+		t.Fatal("Await succeeded")
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("Await took %v of real time", d)
+	}
+	if got := len(clock.Sleeps()); got != 60 {
+		t.Errorf("Await slept %d times, want 60", got)
+	}
+	want := t0.Add(time.Hour)
+	if got := c.Attempts()[0].Start; !got.Equal(want) {
+		t.Errorf("attempt start = %v, want %v", got, want)
+	}
+	if got := c.LastWriteTimeline()[0].Time; !got.Equal(want) {
+		t.Errorf("write time = %v, want %v", got, want)
+	}
+}
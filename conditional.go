@@ -0,0 +1,59 @@
+package testclient
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// Revalidate repeats the last request as a conditional GET or HEAD, sending
+// the ETag of the last response as If-None-Match and its Last-Modified as
+// If-Modified-Since. It fails if the last response carried neither
+// validator.
+func (c *Client) Revalidate(opts ...RequestOption) (*http.Response, error) {
+	if c.request == nil || c.response == nil {
+		return nil, fmt.Errorf("testclient: no response to revalidate")
+	}
+	etag := c.response.Header.Get("ETag")
+	modified := c.response.Header.Get("Last-Modified")
+	if etag == "" && modified == "" {
+		return nil, fmt.Errorf("testclient: last response has no ETag or Last-Modified")
+	}
+	method := c.request.Method
+	if method != http.MethodHead {
+		method = http.MethodGet
+	}
+	req := c.NewRequest(method, requestURL(c.request).String(), nil)
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if modified != "" {
+		req.Header.Set("If-Modified-Since", modified)
+	}
+	return c.Request(req, opts...)
+}
+
+// ExpectNotModified fails the test unless res is a well-formed 304 Not
+// Modified answer to a revalidation of original: no body, and the ETag
+// and caching headers as the full response would have sent them.
+func ExpectNotModified(t testing.TB, res, original *http.Response) {
+	t.Helper()
+	if res.StatusCode != http.StatusNotModified {
+		t.Fatalf("expected status 304 Not Modified, got %s", dumpResponse(res))
+		return
+	}
+	if b := bufferedBody(res).data; len(b) > 0 {
+		t.Errorf("304 response has a %d-byte body", len(b))
+	}
+	// RFC 9110 section 15.4.5 lists the headers a 200 would have sent;
+	// Date naturally differs
+	for _, k := range []string{"ETag", "Cache-Control", "Content-Location", "Expires", "Vary"} {
+		want := original.Header.Get(k)
+		if want == "" {
+			continue
+		}
+		if got := res.Header.Get(k); got != want {
+			t.Errorf("304 response header %s = %q, want %q as in the full response", k, got, want)
+		}
+	}
+}
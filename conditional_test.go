@@ -0,0 +1,65 @@
+package testclient
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRevalidate(t *testing.T) {
+	modified := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Vary", "Accept")
+		http.ServeContent(w, r, "doc.txt", modified, strings.NewReader("document"))
+	})
+	c := New(h)
+	full, err := c.Request(c.NewRequest(http.MethodGet, "/doc?x=1", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := c.Revalidate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.request.Header.Get("If-None-Match") != `"v1"` || c.request.Header.Get("If-Modified-Since") != modified.Format(http.TimeFormat) || c.request.URL.RawQuery != "x=1" {
+		t.Errorf("revalidation request = %v %v", c.request.URL, c.request.Header)
+	}
+	ExpectNotModified(t, res, full)
+}
+
+func TestExpectNotModifiedFailures(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == "" {
+			w.Header().Set("ETag", `"v1"`)
+			w.Header().Set("Cache-Control", "max-age=60")
+			io.WriteString(w, "document")
+			return
+		}
+		w.Header().Set("ETag", `"v2"`)
+		w.WriteHeader(http.StatusNotModified)
+		io.WriteString(w, "junk")
+	})
+	c := New(h)
+	full, _ := c.Request(c.NewRequest(http.MethodGet, "/", nil))
+	res, _ := c.Revalidate()
+	ft := &fakeT{}
+	ExpectNotModified(ft, res, full)
+	ExpectNotModified(ft, full, full)
+	if len(ft.failures) != 4 ||
+		!strings.Contains(ft.failures[0], "4-byte body") ||
+		!strings.Contains(ft.failures[1], `ETag = "\"v2\"", want "\"v1\""`) ||
+		!strings.Contains(ft.failures[2], "Cache-Control") ||
+		!strings.Contains(ft.failures[3], "expected status 304") {
+		t.Errorf("failures = %q", ft.failures)
+	}
+
+	c = New(NewStub())
+	c.Request(c.NewRequest(http.MethodGet, "/", nil))
+	if _, err := c.Revalidate(); err == nil {
+		t.Error("Revalidate without validators succeeded")
+	}
+}
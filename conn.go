@@ -0,0 +1,131 @@
+package testclient
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// newConnPair returns two connected in-memory net.Conns. Unlike net.Pipe,
+// writes are buffered and never block, so a test and a handler can both be
+// writing without deadlocking each other.
+func newConnPair() (net.Conn, net.Conn) {
+	a, b := newPipeBuffer(), newPipeBuffer()
+	return &memConn{in: a, out: b}, &memConn{in: b, out: a}
+}
+
+// pipeBuffer is one direction of a memConn.
+type pipeBuffer struct {
+	mu       sync.Mutex
+	buf      bytes.Buffer
+	closed   bool
+	deadline time.Time
+	changed  chan struct{}
+}
+
+func newPipeBuffer() *pipeBuffer {
+	return &pipeBuffer{changed: make(chan struct{})}
+}
+
+// broadcast wakes up all waiting readers. p.mu must be held.
+func (p *pipeBuffer) broadcast() {
+	close(p.changed)
+	p.changed = make(chan struct{})
+}
+
+func (p *pipeBuffer) read(b []byte) (int, error) {
+	for {
+		p.mu.Lock()
+		if p.buf.Len() > 0 {
+			n, _ := p.buf.Read(b)
+			p.mu.Unlock()
+			return n, nil
+		}
+		if p.closed {
+			p.mu.Unlock()
+			return 0, io.EOF
+		}
+		deadline, changed := p.deadline, p.changed
+		p.mu.Unlock()
+
+		if deadline.IsZero() {
+			<-changed
+			continue
+		}
+		d := time.Until(deadline)
+		if d <= 0 {
+			return 0, os.ErrDeadlineExceeded
+		}
+		timer := time.NewTimer(d)
+		select {
+		case <-changed:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+func (p *pipeBuffer) write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return 0, io.ErrClosedPipe
+	}
+	p.buf.Write(b)
+	p.broadcast()
+	return len(b), nil
+}
+
+func (p *pipeBuffer) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.closed {
+		p.closed = true
+		p.broadcast()
+	}
+}
+
+func (p *pipeBuffer) setDeadline(t time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.deadline = t
+	p.broadcast()
+}
+
+type memConn struct {
+	in, out *pipeBuffer
+}
+
+func (c *memConn) Read(b []byte) (int, error)  { return c.in.read(b) }
+func (c *memConn) Write(b []byte) (int, error) { return c.out.write(b) }
+
+func (c *memConn) Close() error {
+	c.in.close()
+	c.out.close()
+	return nil
+}
+
+func (c *memConn) LocalAddr() net.Addr  { return memAddr{} }
+func (c *memConn) RemoteAddr() net.Addr { return memAddr{} }
+
+func (c *memConn) SetDeadline(t time.Time) error {
+	return c.SetReadDeadline(t)
+}
+
+func (c *memConn) SetReadDeadline(t time.Time) error {
+	c.in.setDeadline(t)
+	return nil
+}
+
+// SetWriteDeadline is a no-op: writes never block.
+func (c *memConn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
+
+type memAddr struct{}
+
+func (memAddr) Network() string { return "mem" }
+func (memAddr) String() string  { return "testclient" }
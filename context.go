@@ -0,0 +1,38 @@
+package testclient
+
+import (
+	"context"
+	"net/http"
+)
+
+type contextValue struct {
+	key, val any
+}
+
+// WithContextValue adds key and val to the context of every request, as
+// upstream middleware would, so handlers that read the authenticated user or
+// tenant from r.Context() can be driven without the real middleware.
+func WithContextValue(key, val any) Option {
+	return func(c *Client) {
+		c.contextValues = append(c.contextValues, contextValue{key, val})
+	}
+}
+
+// ContextValue adds key and val to the context of a single request. It
+// takes precedence over a client-level value for the same key.
+func ContextValue(key, val any) RequestOption {
+	return func(req *http.Request) {
+		*req = *req.WithContext(context.WithValue(req.Context(), key, val))
+	}
+}
+
+func (c *Client) applyContextValues(req *http.Request) {
+	if len(c.contextValues) == 0 {
+		return
+	}
+	ctx := req.Context()
+	for _, v := range c.contextValues {
+		ctx = context.WithValue(ctx, v.key, v.val)
+	}
+	*req = *req.WithContext(ctx)
+}
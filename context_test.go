@@ -0,0 +1,24 @@
+package testclient
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+type ctxKey string
+
+func TestContextValues(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%v/%v", r.Context().Value(ctxKey("user")), r.Context().Value(ctxKey("tenant")))
+	})
+	c := New(h, WithContextValue(ctxKey("user"), "alice"), WithContextValue(ctxKey("tenant"), "acme"))
+	res, _ := c.Request(c.NewRequest(http.MethodGet, "/", nil))
+	if got := body(t, res); got != "alice/acme" {
+		t.Errorf("context = %q, want alice/acme", got)
+	}
+	res, _ = c.Request(c.NewRequest(http.MethodGet, "/", nil), ContextValue(ctxKey("user"), "bob"))
+	if got := body(t, res); got != "bob/acme" {
+		t.Errorf("context with a request value = %q, want bob/acme", got)
+	}
+}
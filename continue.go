@@ -0,0 +1,128 @@
+package testclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type continueKey struct{}
+
+// continueState tracks an Expect: 100-continue exchange.
+type continueState struct {
+	timeout time.Duration
+	start   time.Time
+	now     func() time.Time
+
+	mu        sync.Mutex
+	continued bool
+	committed bool
+}
+
+// ExpectContinue sends a single request with Expect: 100-continue. Like a
+// real client, it holds the body back until the handler asks for it — by
+// reading it or writing a 100 Continue — or until timeout has passed; a
+// handler that answers before either happens never gets the body, and its
+// reads fail with io.ErrUnexpectedEOF. Continued reports whether the 100
+// was sent.
+func ExpectContinue(timeout time.Duration) RequestOption {
+	return func(req *http.Request) {
+		req.Header.Set("Expect", "100-continue")
+		*req = *req.WithContext(context.WithValue(req.Context(), continueKey{}, timeout))
+	}
+}
+
+// Continued reports whether the handler sent a 100 Continue for the last
+// request, which must have been sent with ExpectContinue.
+func (c *Client) Continued() bool {
+	return c.continued
+}
+
+// armContinue returns w and req wired to a fresh 100-continue exchange if
+// req was sent with ExpectContinue, its timeout running on clock from now,
+// and a function reporting whether the 100 was sent.
+func armContinue(w http.ResponseWriter, req *http.Request, clock Clock) (http.ResponseWriter, *http.Request, func() bool) {
+	timeout, ok := req.Context().Value(continueKey{}).(time.Duration)
+	if !ok {
+		return w, req, func() bool { return false }
+	}
+	st := &continueState{timeout: timeout, start: clock.Now(), now: clock.Now}
+	if req.Body != nil && req.Body != http.NoBody {
+		req = req.Clone(req.Context())
+		req.Body = &continueBody{body: req.Body, st: st}
+	}
+	return &continueWriter{ResponseWriter: w, st: st}, req, func() bool {
+		st.mu.Lock()
+		defer st.mu.Unlock()
+		return st.continued
+	}
+}
+
+// send records the handler asking for the body, which sends the 100 unless
+// the final response is already out.
+func (st *continueState) send() {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if !st.committed {
+		st.continued = true
+	}
+}
+
+func (st *continueState) commit() {
+	st.mu.Lock()
+	st.committed = true
+	st.mu.Unlock()
+}
+
+// bodySent reports whether the client has let the body go.
+func (st *continueState) bodySent() bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.continued || st.now().Sub(st.start) >= st.timeout
+}
+
+type continueWriter struct {
+	http.ResponseWriter
+	st *continueState
+}
+
+func (w *continueWriter) WriteHeader(code int) {
+	if code == http.StatusContinue {
+		// interim: the final status is still to come
+		w.st.send()
+		return
+	}
+	w.st.commit()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *continueWriter) Write(b []byte) (int, error) {
+	w.st.commit()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *continueWriter) Flush() {
+	w.st.commit()
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+type continueBody struct {
+	body io.ReadCloser
+	st   *continueState
+}
+
+func (b *continueBody) Read(p []byte) (int, error) {
+	b.st.send()
+	if !b.st.bodySent() {
+		return 0, io.ErrUnexpectedEOF
+	}
+	return b.body.Read(p)
+}
+
+func (b *continueBody) Close() error {
+	return b.body.Close()
+}
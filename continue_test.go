@@ -0,0 +1,77 @@
+package testclient
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpectContinue(t *testing.T) {
+	var readErr error
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > 4 {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			_, readErr = io.ReadAll(r.Body)
+			return
+		}
+		if r.URL.Path == "/explicit" {
+			w.WriteHeader(http.StatusContinue)
+		}
+		b, _ := io.ReadAll(r.Body)
+		w.Write(b)
+	})
+	clock := NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	c := New(h, WithClock(clock))
+
+	for _, path := range []string{"/read", "/explicit"} {
+		res, err := c.Request(c.NewRequest(http.MethodPut, path, strings.NewReader("tiny")), ExpectContinue(time.Second))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.request.Header.Get("Expect") != "100-continue" {
+			t.Errorf("Expect = %q", c.request.Header.Get("Expect"))
+		}
+		if !c.Continued() || res.StatusCode != http.StatusOK || body(t, res) != "tiny" {
+			t.Errorf("%s: continued = %v, status %d", path, c.Continued(), res.StatusCode)
+		}
+	}
+
+	res, err := c.Request(c.NewRequest(http.MethodPut, "/read", strings.NewReader("too large")), ExpectContinue(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Continued() || res.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("continued = %v, status %d", c.Continued(), res.StatusCode)
+	}
+	if !errors.Is(readErr, io.ErrUnexpectedEOF) {
+		t.Errorf("reading the withheld body: %v", readErr)
+	}
+
+	if _, err := c.Request(c.NewRequest(http.MethodPut, "/read", strings.NewReader("tiny"))); err != nil {
+		t.Fatal(err)
+	}
+	if c.Continued() {
+		t.Error("continued without ExpectContinue")
+	}
+}
+
+func TestExpectContinueTimeout(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	var got string
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		clock.Advance(2 * time.Second)
+		b, _ := io.ReadAll(r.Body)
+		got = string(b)
+	})
+	c := New(h, WithClock(clock))
+	if _, err := c.Request(c.NewRequest(http.MethodPost, "/", strings.NewReader("late")), ExpectContinue(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if c.Continued() || got != "late" {
+		t.Errorf("continued = %v, body %q; want the body sent after the timeout", c.Continued(), got)
+	}
+}
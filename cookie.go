@@ -0,0 +1,133 @@
+package testclient
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// CookieAssertion checks the attributes of a cookie set by a response. Each
+// method reports a mismatch with t.Errorf and returns the assertion, so
+// checks can be chained.
+type CookieAssertion struct {
+	t      testing.TB
+	cookie *http.Cookie
+}
+
+// ExpectCookie fails the test unless res sets the cookie name, and returns
+// an assertion on its attributes:
+//
+//	testclient.ExpectCookie(t, res, "session").Secure().HTTPOnly().SameSite(http.SameSiteLaxMode)
+func ExpectCookie(t testing.TB, res *http.Response, name string) *CookieAssertion {
+	t.Helper()
+	var names []string
+	for _, c := range res.Cookies() {
+		if c.Name == name {
+			return &CookieAssertion{t: t, cookie: c}
+		}
+		names = append(names, c.Name)
+	}
+	t.Fatalf("expected cookie %s, got none (cookies: %v)", name, names)
+	return &CookieAssertion{t: t}
+}
+
+// Cookie returns the cookie under test, or nil if it was not set.
+func (a *CookieAssertion) Cookie() *http.Cookie {
+	return a.cookie
+}
+
+// attr returns the cookie, or a zero cookie if it was not set; checks on a
+// missing cookie are not reported again.
+func (a *CookieAssertion) attr() http.Cookie {
+	if a.cookie == nil {
+		return http.Cookie{}
+	}
+	return *a.cookie
+}
+
+func (a *CookieAssertion) check(ok bool, format string, args ...any) *CookieAssertion {
+	a.t.Helper()
+	if a.cookie != nil && !ok {
+		a.t.Errorf("cookie %s: "+format, append([]any{a.cookie.Name}, args...)...)
+	}
+	return a
+}
+
+// Value checks the cookie value.
+func (a *CookieAssertion) Value(want string) *CookieAssertion {
+	a.t.Helper()
+	got := a.attr().Value
+	return a.check(got == want, "expected value %q, got %q", want, got)
+}
+
+// MaxAge checks the Max-Age attribute in seconds. As in http.Cookie, 0
+// means none was set and a negative value means Max-Age=0.
+func (a *CookieAssertion) MaxAge(want int) *CookieAssertion {
+	a.t.Helper()
+	got := a.attr().MaxAge
+	return a.check(got == want, "expected Max-Age %d, got %d", want, got)
+}
+
+// Expires checks the Expires attribute to the second.
+func (a *CookieAssertion) Expires(want time.Time) *CookieAssertion {
+	a.t.Helper()
+	got := a.attr().Expires
+	return a.check(got.Truncate(time.Second).Equal(want.Truncate(time.Second)), "expected Expires %v, got %v", want.UTC(), got.UTC())
+}
+
+// Expired checks that the cookie deletes itself: Max-Age=0 or an Expires in
+// the past.
+func (a *CookieAssertion) Expired() *CookieAssertion {
+	a.t.Helper()
+	c := a.attr()
+	return a.check(cookieExpired(&c), "expected to be expired, got Max-Age %d, Expires %v", c.MaxAge, c.Expires)
+}
+
+func cookieExpired(c *http.Cookie) bool {
+	return c.MaxAge < 0 || (!c.Expires.IsZero() && c.Expires.Before(time.Now()))
+}
+
+// Secure checks the Secure attribute.
+func (a *CookieAssertion) Secure() *CookieAssertion {
+	a.t.Helper()
+	return a.check(a.attr().Secure, "expected Secure")
+}
+
+// HTTPOnly checks the HttpOnly attribute.
+func (a *CookieAssertion) HTTPOnly() *CookieAssertion {
+	a.t.Helper()
+	return a.check(a.attr().HttpOnly, "expected HttpOnly")
+}
+
+// SameSite checks the SameSite attribute.
+func (a *CookieAssertion) SameSite(want http.SameSite) *CookieAssertion {
+	a.t.Helper()
+	got := a.attr().SameSite
+	return a.check(got == want, "expected SameSite %s, got %s", sameSiteName(want), sameSiteName(got))
+}
+
+func sameSiteName(s http.SameSite) string {
+	switch s {
+	case http.SameSiteLaxMode:
+		return "Lax"
+	case http.SameSiteStrictMode:
+		return "Strict"
+	case http.SameSiteNoneMode:
+		return "None"
+	}
+	return "(unset)"
+}
+
+// Path checks the Path attribute.
+func (a *CookieAssertion) Path(want string) *CookieAssertion {
+	a.t.Helper()
+	got := a.attr().Path
+	return a.check(got == want, "expected Path %q, got %q", want, got)
+}
+
+// Domain checks the Domain attribute.
+func (a *CookieAssertion) Domain(want string) *CookieAssertion {
+	a.t.Helper()
+	got := a.attr().Domain
+	return a.check(got == want, "expected Domain %q, got %q", want, got)
+}
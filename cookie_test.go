@@ -0,0 +1,34 @@
+package testclient
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpectCookie(t *testing.T) {
+	expires := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", Path: "/app", Domain: "example.com", MaxAge: 3600, Expires: expires, Secure: true, HttpOnly: true, SameSite: http.SameSiteLaxMode})
+		http.SetCookie(w, &http.Cookie{Name: "old", Value: "", MaxAge: -1})
+	})
+	c := New(h)
+	res, _ := c.Request(c.NewRequest(http.MethodGet, "/", nil))
+
+	ExpectCookie(t, res, "session").Value("abc").Path("/app").Domain("example.com").MaxAge(3600).
+		Expires(expires).Secure().HTTPOnly().SameSite(http.SameSiteLaxMode)
+	ExpectCookie(t, res, "old").Expired()
+
+	ft := &fakeT{}
+	ExpectCookie(ft, res, "old").Secure().SameSite(http.SameSiteStrictMode).Value("x")
+	if len(ft.failures) != 3 || !strings.Contains(ft.failures[1], "expected SameSite Strict, got (unset)") {
+		t.Errorf("failures = %q", ft.failures)
+	}
+
+	ft = &fakeT{}
+	ExpectCookie(ft, res, "missing").Secure().Value("x")
+	if len(ft.failures) != 1 || !strings.Contains(ft.failures[0], "expected cookie missing, got none (cookies: [session old])") {
+		t.Errorf("failures for a missing cookie = %q", ft.failures)
+	}
+}
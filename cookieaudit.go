@@ -0,0 +1,88 @@
+package testclient
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// CookiePolicy is what WithCookieAudit requires of every cookie a response
+// sets. Cookies being deleted are not audited.
+type CookiePolicy struct {
+	Secure   bool
+	HttpOnly bool
+	// SameSite is the laxest SameSite attribute allowed: SameSiteStrictMode
+	// allows only Strict, SameSiteLaxMode Lax or Strict, and SameSiteNoneMode
+	// any explicit value. Zero requires none.
+	SameSite http.SameSite
+	// Exempt names cookies the policy does not apply to, such as a CSRF
+	// token that scripts have to read.
+	Exempt []string
+}
+
+// StrictCookies is the policy for session cookies: Secure, HttpOnly and
+// SameSite=Lax or Strict.
+var StrictCookies = CookiePolicy{Secure: true, HttpOnly: true, SameSite: http.SameSiteLaxMode}
+
+// WithCookieAudit checks every cookie set by a response against policy, as
+// AuditCookies does, and reports each violation with t.Errorf.
+func WithCookieAudit(t testing.TB, policy CookiePolicy) Option {
+	return func(c *Client) {
+		c.cookieAudit = &cookieAudit{tb: t, policy: policy}
+	}
+}
+
+type cookieAudit struct {
+	tb     testing.TB
+	policy CookiePolicy
+}
+
+// AuditCookies returns the violations of policy by the cookies res sets.
+// Whatever the policy, it also reports what browsers reject: SameSite=None
+// without Secure, and __Secure- and __Host- cookies without the attributes
+// their prefix requires.
+func AuditCookies(res *http.Response, policy CookiePolicy) []string {
+	var issues []string
+	for _, cookie := range res.Cookies() {
+		if cookie.MaxAge < 0 {
+			continue
+		}
+		name := cookie.Name
+		if cookie.SameSite == http.SameSiteNoneMode && !cookie.Secure {
+			issues = append(issues, fmt.Sprintf("cookie %s has SameSite=None without Secure; browsers reject it", name))
+		}
+		if strings.HasPrefix(name, "__Secure-") && !cookie.Secure {
+			issues = append(issues, fmt.Sprintf("cookie %s lacks the Secure its prefix requires", name))
+		}
+		if strings.HasPrefix(name, "__Host-") && (!cookie.Secure || cookie.Path != "/" || cookie.Domain != "") {
+			issues = append(issues, fmt.Sprintf("cookie %s needs Secure, Path=/ and no Domain for its prefix", name))
+		}
+		if contains(policy.Exempt, name) {
+			continue
+		}
+		if policy.Secure && !cookie.Secure {
+			issues = append(issues, fmt.Sprintf("cookie %s lacks Secure; it is sent over plain http", name))
+		}
+		if policy.HttpOnly && !cookie.HttpOnly {
+			issues = append(issues, fmt.Sprintf("cookie %s lacks HttpOnly; scripts can read it", name))
+		}
+		if policy.SameSite != 0 && sameSiteRank(cookie.SameSite) < sameSiteRank(policy.SameSite) {
+			issues = append(issues, fmt.Sprintf("cookie %s has SameSite %s, the policy requires at least %s", name, sameSiteName(cookie.SameSite), sameSiteName(policy.SameSite)))
+		}
+	}
+	return issues
+}
+
+// sameSiteRank orders SameSite values from laxest to strictest.
+func sameSiteRank(s http.SameSite) int {
+	switch s {
+	case http.SameSiteNoneMode:
+		return 1
+	case http.SameSiteLaxMode:
+		return 2
+	case http.SameSiteStrictMode:
+		return 3
+	}
+	return 0
+}
@@ -0,0 +1,55 @@
+package testclient
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestAuditCookies(t *testing.T) {
+	tests := []struct {
+		name      string
+		setCookie string
+		policy    CookiePolicy
+		want      string
+	}{
+		{name: "compliant", setCookie: "session=a; Secure; HttpOnly; SameSite=Strict", policy: StrictCookies},
+		{name: "no Secure", setCookie: "session=a; HttpOnly; SameSite=Lax", policy: StrictCookies, want: "cookie session lacks Secure"},
+		{name: "no HttpOnly", setCookie: "session=a; Secure; SameSite=Lax", policy: StrictCookies, want: "cookie session lacks HttpOnly"},
+		{name: "no SameSite", setCookie: "session=a; Secure; HttpOnly", policy: StrictCookies, want: "cookie session has SameSite (unset), the policy requires at least Lax"},
+		{name: "too lax", setCookie: "session=a; Secure; HttpOnly; SameSite=None", policy: CookiePolicy{SameSite: http.SameSiteStrictMode}, want: "has SameSite None, the policy requires at least Strict"},
+		{name: "explicit None allowed", setCookie: "widget=a; Secure; SameSite=None", policy: CookiePolicy{SameSite: http.SameSiteNoneMode}},
+		{name: "exempt", setCookie: "csrf=a; Secure; SameSite=Strict", policy: CookiePolicy{Secure: true, HttpOnly: true, Exempt: []string{"csrf"}}},
+		{name: "deleted", setCookie: "session=; Max-Age=0", policy: StrictCookies},
+		{name: "None without Secure", setCookie: "widget=a; SameSite=None", want: "SameSite=None without Secure"},
+		{name: "Secure prefix", setCookie: "__Secure-id=a", want: "cookie __Secure-id lacks the Secure its prefix requires"},
+		{name: "Host prefix", setCookie: "__Host-id=a; Secure; Path=/; Domain=example.com", want: "cookie __Host-id needs Secure, Path=/ and no Domain"},
+		{name: "Host prefix ok", setCookie: "__Host-id=a; Secure; Path=/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := &http.Response{Header: http.Header{"Set-Cookie": {tt.setCookie}}}
+			issues := AuditCookies(res, tt.policy)
+			switch {
+			case tt.want == "" && len(issues) > 0:
+				t.Errorf("unexpected issues %q", issues)
+			case tt.want != "" && (len(issues) != 1 || !strings.Contains(issues[0], tt.want)):
+				t.Errorf("issues = %q, want one containing %q", issues, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithCookieAudit(t *testing.T) {
+	stub := NewStub()
+	stub.On(http.MethodPost, "/login").ReplyFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "s", HttpOnly: true, SameSite: http.SameSiteLaxMode})
+		http.SetCookie(w, &http.Cookie{Name: "theme", Value: "dark"})
+	})
+	ft := &fakeT{TB: t}
+	c := New(stub, WithCookieAudit(ft, CookiePolicy{Secure: true, Exempt: []string{"theme"}}))
+	c.PostForm("/login", nil)
+	if len(ft.failures) != 1 || ft.failures[0] != "testclient: POST http://example.com/login: cookie session lacks Secure; it is sent over plain http" {
+		t.Errorf("failures = %q", ft.failures)
+	}
+}
@@ -0,0 +1,138 @@
+package testclient
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// Origin sets the Origin header of a single request, making it a
+// cross-origin request from origin.
+func Origin(origin string) RequestOption {
+	return func(req *http.Request) {
+		req.Header.Set("Origin", origin)
+	}
+}
+
+// Preflight sends the OPTIONS request a browser would send from origin
+// before a method request to path carrying headers.
+func (c *Client) Preflight(path, origin, method string, headers ...string) (*http.Response, error) {
+	req := c.NewRequest(http.MethodOptions, path, nil)
+	req.Header.Set("Origin", origin)
+	req.Header.Set("Access-Control-Request-Method", method)
+	if len(headers) > 0 {
+		names := make([]string, len(headers))
+		for i, h := range headers {
+			names[i] = strings.ToLower(h)
+		}
+		sort.Strings(names)
+		req.Header.Set("Access-Control-Request-Headers", strings.Join(names, ","))
+	}
+	return c.Request(req)
+}
+
+// ExpectPreflight fails the test unless res, the answer to a Preflight,
+// lets a browser at origin go on to send a method request with headers.
+func ExpectPreflight(t testing.TB, res *http.Response, origin, method string, headers ...string) {
+	t.Helper()
+	for _, p := range corsProblems(res, origin, method, headers) {
+		t.Errorf("CORS preflight from %s for %s: %s", origin, method, p)
+	}
+	if w := corsCacheWarning(res, origin); w != "" {
+		t.Errorf("CORS preflight from %s for %s: %s", origin, method, w)
+	}
+}
+
+// ExpectPreflightDenied fails the test if res, the answer to a Preflight,
+// would let a browser at origin send a method request with headers.
+func ExpectPreflightDenied(t testing.TB, res *http.Response, origin, method string, headers ...string) {
+	t.Helper()
+	if len(corsProblems(res, origin, method, headers)) == 0 {
+		t.Errorf("expected CORS preflight from %s for %s to be denied, got Access-Control-Allow-Origin %q", origin, method, res.Header.Get("Access-Control-Allow-Origin"))
+	}
+}
+
+// ExpectCORS fails the test unless a browser at origin may read res, the
+// response to the actual cross-origin request.
+func ExpectCORS(t testing.TB, res *http.Response, origin string) {
+	t.Helper()
+	for _, p := range corsProblems(res, origin, "", nil) {
+		t.Errorf("CORS response to %s: %s", origin, p)
+	}
+	if w := corsCacheWarning(res, origin); w != "" {
+		t.Errorf("CORS response to %s: %s", origin, w)
+	}
+}
+
+// corsProblems returns why a browser at origin would reject res, following
+// the CORS check of the Fetch standard and, with a method, the preflight
+// checks as well.
+func corsProblems(res *http.Response, origin, method string, headers []string) []string {
+	var problems []string
+	credentials := res.Header.Get("Access-Control-Allow-Credentials") == "true"
+	switch allow := res.Header.Get("Access-Control-Allow-Origin"); {
+	case allow == "":
+		return append(problems, "no Access-Control-Allow-Origin")
+	case len(res.Header.Values("Access-Control-Allow-Origin")) > 1:
+		problems = append(problems, "Access-Control-Allow-Origin sent more than once")
+	case allow == "*" && credentials:
+		problems = append(problems, `Access-Control-Allow-Origin "*" is not allowed with credentials`)
+	case allow != "*" && allow != origin:
+		problems = append(problems, "Access-Control-Allow-Origin is "+allow)
+	}
+	if method == "" {
+		return problems
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		problems = append(problems, "preflight status "+http.StatusText(res.StatusCode)+" is not ok")
+	}
+	methods := headerValues(res.Header, "Access-Control-Allow-Methods")
+	if !corsSafeMethod(method) && !contains(methods, method) && (credentials || !contains(methods, "*")) {
+		problems = append(problems, "method not in Access-Control-Allow-Methods "+strings.Join(methods, ", "))
+	}
+	allowed := headerValues(res.Header, "Access-Control-Allow-Headers")
+	wildcard := !credentials && contains(allowed, "*")
+	for _, h := range headers {
+		// the wildcard never covers Authorization
+		if containsFold(allowed, h) || wildcard && !strings.EqualFold(h, "Authorization") {
+			continue
+		}
+		problems = append(problems, "header "+h+" not in Access-Control-Allow-Headers "+strings.Join(allowed, ", "))
+	}
+	return problems
+}
+
+// corsCacheWarning returns why caches may serve res to other origins: an
+// Access-Control-Allow-Origin echoing origin without Vary: Origin.
+// Browsers do not check Vary, so corsProblems leaves it out.
+func corsCacheWarning(res *http.Response, origin string) string {
+	if res.Header.Get("Access-Control-Allow-Origin") != origin || containsFold(headerValues(res.Header, "Vary"), "Origin") {
+		return ""
+	}
+	return "Access-Control-Allow-Origin echoes the origin without Vary: Origin, so caches may serve it to other origins"
+}
+
+// corsSafeMethod reports whether method needs no Access-Control-Allow-Methods.
+func corsSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodPost
+}
+
+func contains(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(values []string, want string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, want) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package testclient
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// corsMiddleware allows app.example with credentials and a fixed set of
+// methods and headers.
+func corsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if origin := r.Header.Get("Origin"); origin == "https://app.example" {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+			w.Header().Add("Vary", "Origin")
+		}
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", "GET, PUT, DELETE")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-Request-Id")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func TestPreflight(t *testing.T) {
+	c := New(corsMiddleware(NewStub()))
+	res, err := c.Preflight("/items", "https://app.example", http.MethodPut, "Content-Type", "x-request-id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.request.Header.Get("Access-Control-Request-Headers"); got != "content-type,x-request-id" {
+		t.Errorf("Access-Control-Request-Headers = %q", got)
+	}
+	ExpectPreflight(t, res, "https://app.example", http.MethodPut, "Content-Type", "X-Request-Id")
+
+	tests := []struct {
+		origin, method string
+		headers        []string
+		msg            string
+	}{
+		{"https://evil.example", http.MethodPut, nil, "no Access-Control-Allow-Origin"},
+		{"https://app.example", http.MethodPatch, nil, "method not in Access-Control-Allow-Methods"},
+		{"https://app.example", http.MethodGet, []string{"Authorization"}, "header Authorization"},
+	}
+	for _, tt := range tests {
+		res, err := c.Preflight("/items", tt.origin, tt.method, tt.headers...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ExpectPreflightDenied(t, res, tt.origin, tt.method, tt.headers...)
+		ft := &fakeT{TB: t}
+		ExpectPreflight(ft, res, tt.origin, tt.method, tt.headers...)
+		if len(ft.failures) != 1 || !strings.Contains(ft.failures[0], tt.msg) {
+			t.Errorf("%s %s: failures = %q, want %q", tt.origin, tt.method, ft.failures, tt.msg)
+		}
+	}
+}
+
+func TestPreflightDeniedIgnoresVary(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", r.Header.Get("Origin"))
+		w.Header().Set("Access-Control-Allow-Methods", "PUT")
+		w.WriteHeader(http.StatusNoContent)
+	})
+	c := New(h)
+	res, err := c.Preflight("/items", "https://evil.example", http.MethodPut)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ft := &fakeT{TB: t}
+	ExpectPreflightDenied(ft, res, "https://evil.example", http.MethodPut)
+	if len(ft.failures) != 1 || !strings.Contains(ft.failures[0], "to be denied") {
+		t.Errorf("denied failures = %q", ft.failures)
+	}
+	ft = &fakeT{TB: t}
+	ExpectPreflight(ft, res, "https://evil.example", http.MethodPut)
+	if len(ft.failures) != 1 || !strings.Contains(ft.failures[0], "without Vary: Origin") {
+		t.Errorf("preflight failures = %q", ft.failures)
+	}
+}
+
+func TestExpectCORS(t *testing.T) {
+	c := New(corsMiddleware(NewStub()))
+	res, err := c.Request(c.NewRequest(http.MethodGet, "/items", nil), Origin("https://app.example"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ExpectCORS(t, res, "https://app.example")
+
+	tests := []struct {
+		header http.Header
+		msg    string
+	}{
+		{http.Header{"Access-Control-Allow-Origin": {"*"}, "Access-Control-Allow-Credentials": {"true"}}, "not allowed with credentials"},
+		{http.Header{"Access-Control-Allow-Origin": {"https://app.example"}}, "without Vary: Origin"},
+		{http.Header{"Access-Control-Allow-Origin": {"https://other.example"}}, "is https://other.example"},
+	}
+	for _, tt := range tests {
+		ft := &fakeT{TB: t}
+		ExpectCORS(ft, &http.Response{StatusCode: http.StatusOK, Header: tt.header}, "https://app.example")
+		if len(ft.failures) != 1 || !strings.Contains(ft.failures[0], tt.msg) {
+			t.Errorf("%v: failures = %q, want %q", tt.header, ft.failures, tt.msg)
+		}
+	}
+}
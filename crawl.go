@@ -0,0 +1,336 @@
+package testclient
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+// maxRedirects is how many redirects in a row the crawler follows.
+const maxRedirects = 10
+
+// Audit checks a response, returning its problems. AuditCaching is one;
+// the other audits of the package adapt with a closure:
+//
+//	func(req *http.Request, res *http.Response) []string {
+//		return testclient.AuditCookies(res, testclient.StrictCookies)
+//	}
+type Audit func(req *http.Request, res *http.Response) []string
+
+// CrawlOptions configures Crawl.
+type CrawlOptions struct {
+	// MaxDepth is how many links away from the seed pages are fetched; 0
+	// means 3.
+	MaxDepth int
+	// MaxPages stops the crawl after that many pages; 0 means 500.
+	MaxPages int
+	// Skip, if set, leaves out the links it returns true for, such as a
+	// logout link ending the session of the crawl.
+	Skip func(u *url.URL) bool
+	// Audits run on every response, redirects included; what they find are
+	// problems of the crawl.
+	Audits []Audit
+	// Client are the options of the client that crawls, such as a host, so
+	// that a signed-in session can be crawled by setting a cookie.
+	Client []Option
+}
+
+// CrawlProblem is a problem Crawl found at URL.
+type CrawlProblem struct {
+	URL string
+	// From is the page linking to URL, or "" for the seed.
+	From    string
+	Problem string
+}
+
+func (p CrawlProblem) String() string {
+	if p.From == "" {
+		return fmt.Sprintf("%s: %s", p.URL, p.Problem)
+	}
+	return fmt.Sprintf("%s (linked from %s): %s", p.URL, p.From, p.Problem)
+}
+
+// CrawlReport is the outcome of Crawl.
+type CrawlReport struct {
+	// Pages are the URLs fetched, in order, redirects resolved.
+	Pages    []string
+	Problems []CrawlProblem
+	// Truncated reports that MaxPages stopped the crawl.
+	Truncated bool
+}
+
+// Crawl fetches seed from handler and then, breadth first up to the depth
+// limit, every page on the same host that an HTML page links to with
+// <a> or <area>, or submits a <form> to with GET and its default values.
+// It follows redirects on the host and reports as problems pages answered
+// 4xx or 5xx, requests that fail, redirect loops and chains of more than
+// 10 redirects, and what the audits of opts find. The client keeps its
+// cookies across the crawl.
+func Crawl(handler http.Handler, seed string, opts CrawlOptions) *CrawlReport {
+	if opts.MaxDepth == 0 {
+		opts.MaxDepth = 3
+	}
+	if opts.MaxPages == 0 {
+		opts.MaxPages = 500
+	}
+	cr := &crawler{c: New(handler, opts.Client...), opts: opts, seen: map[string]bool{}, report: &CrawlReport{}}
+	start := requestURL(cr.c.NewRequest(http.MethodGet, seed, nil))
+	cr.origin = start
+	cr.seen[visitKey(start)] = true
+
+	queue := []crawlItem{{u: start}}
+	for len(queue) > 0 {
+		if len(cr.report.Pages) == opts.MaxPages {
+			cr.report.Truncated = true
+			break
+		}
+		item := queue[0]
+		queue = queue[1:]
+		page, res := cr.fetch(item)
+		if res == nil || item.depth == opts.MaxDepth {
+			continue
+		}
+		for _, link := range pageLinks(page, res) {
+			key := visitKey(link)
+			if cr.seen[key] || !sameHost(cr.origin, link) || (opts.Skip != nil && opts.Skip(link)) {
+				continue
+			}
+			cr.seen[key] = true
+			queue = append(queue, crawlItem{u: link, from: page.String(), depth: item.depth + 1})
+		}
+	}
+	return cr.report
+}
+
+type crawler struct {
+	c      *Client
+	opts   CrawlOptions
+	origin *url.URL
+	seen   map[string]bool
+	report *CrawlReport
+}
+
+type crawlItem struct {
+	u     *url.URL
+	from  string
+	depth int
+}
+
+// fetch gets item, following redirects on the host, and returns the page
+// it ended on and its response, or a nil response if there is nothing to
+// crawl further.
+func (cr *crawler) fetch(item crawlItem) (*url.URL, *http.Response) {
+	u := item.u
+	chain := []string{u.String()}
+	for {
+		res, err := cr.c.Request(cr.c.NewRequest(http.MethodGet, u.String(), nil))
+		if err != nil {
+			cr.problem(u, item.from, err.Error())
+			return u, nil
+		}
+		for _, audit := range cr.opts.Audits {
+			for _, issue := range audit(res.Request, res) {
+				cr.problem(u, item.from, issue)
+			}
+		}
+		location := res.Header.Get("Location")
+		if res.StatusCode < 300 || res.StatusCode >= 400 || location == "" {
+			cr.report.Pages = append(cr.report.Pages, u.String())
+			if res.StatusCode >= 400 {
+				cr.problem(u, item.from, fmt.Sprintf("status %d %s", res.StatusCode, http.StatusText(res.StatusCode)))
+				return u, nil
+			}
+			return u, res
+		}
+		next, err := u.Parse(location)
+		switch {
+		case err != nil:
+			cr.problem(u, item.from, fmt.Sprintf("redirect to an invalid Location %q", location))
+			return u, nil
+		case !sameHost(cr.origin, next):
+			return u, nil
+		case contains(chain, next.String()):
+			cr.problem(item.u, item.from, "redirect loop: "+strings.Join(append(chain, next.String()), " → "))
+			return u, nil
+		case len(chain) > maxRedirects:
+			cr.problem(item.u, item.from, fmt.Sprintf("more than %d redirects in a row", maxRedirects))
+			return u, nil
+		case cr.seen[visitKey(next)]:
+			// crawled, or to be, on its own
+			return u, nil
+		}
+		chain = append(chain, next.String())
+		cr.seen[visitKey(next)] = true
+		u = next
+	}
+}
+
+func (cr *crawler) problem(u *url.URL, from, problem string) {
+	cr.report.Problems = append(cr.report.Problems, CrawlProblem{URL: u.String(), From: from, Problem: problem})
+}
+
+// visitKey identifies the page of u: fragments point into the same page.
+func visitKey(u *url.URL) string {
+	v := *u
+	v.Fragment, v.RawFragment = "", ""
+	return v.String()
+}
+
+// pageLinks returns the http and https URLs the HTML page at base links
+// to or submits GET forms to.
+func pageLinks(base *url.URL, res *http.Response) []*url.URL {
+	mt, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if mt != "text/html" && mt != "application/xhtml+xml" {
+		return nil
+	}
+	doc, err := html.Parse(bytes.NewReader(bufferedBody(res).data))
+	if err != nil {
+		return nil
+	}
+	var links []*url.URL
+	add := func(ref string) *url.URL {
+		u, err := base.Parse(strings.TrimSpace(ref))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return nil
+		}
+		links = append(links, u)
+		return u
+	}
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.Data {
+			case "a", "area":
+				if href, ok := attr(n, "href"); ok {
+					add(href)
+				}
+			case "form":
+				if method, _ := attr(n, "method"); method == "" || strings.EqualFold(method, http.MethodGet) {
+					action, _ := attr(n, "action")
+					if u := add(action); u != nil {
+						u.RawQuery = formDefaults(n).Encode()
+					}
+				}
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(doc)
+	return links
+}
+
+// formDefaults returns the values form submits when left as is, without a
+// submit button.
+func formDefaults(form *html.Node) url.Values {
+	values := url.Values{}
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		name, named := attr(n, "name")
+		if _, disabled := attr(n, "disabled"); n.Type == html.ElementNode && named && !disabled {
+			switch n.Data {
+			case "input":
+				typ, _ := attr(n, "type")
+				value, _ := attr(n, "value")
+				_, checked := attr(n, "checked")
+				switch strings.ToLower(typ) {
+				case "submit", "button", "reset", "image", "file":
+				case "checkbox", "radio":
+					if checked {
+						if value == "" {
+							value = "on"
+						}
+						values.Add(name, value)
+					}
+				default:
+					values.Add(name, value)
+				}
+			case "textarea":
+				values.Add(name, textOf(n))
+			case "select":
+				if value, ok := selectedOption(n); ok {
+					values.Add(name, value)
+				}
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(form)
+	return values
+}
+
+// selectedOption returns the value of the selected option of a select, or
+// of its first option.
+func selectedOption(sel *html.Node) (string, bool) {
+	var first, selected *html.Node
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "option" {
+			if first == nil {
+				first = n
+			}
+			if _, ok := attr(n, "selected"); ok && selected == nil {
+				selected = n
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(sel)
+	if selected == nil {
+		selected = first
+	}
+	if selected == nil {
+		return "", false
+	}
+	if value, ok := attr(selected, "value"); ok {
+		return value, true
+	}
+	return strings.TrimSpace(textOf(selected)), true
+}
+
+func attr(n *html.Node, name string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && a.Key == name {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+func textOf(n *html.Node) string {
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.TextNode {
+			b.WriteString(child.Data)
+		}
+	}
+	return b.String()
+}
+
+// ExpectCrawlClean crawls handler from seed, as Crawl does, and fails the
+// test with every problem found. It returns the report.
+func ExpectCrawlClean(t testing.TB, handler http.Handler, seed string, opts CrawlOptions) *CrawlReport {
+	t.Helper()
+	report := Crawl(handler, seed, opts)
+	if len(report.Problems) == 0 {
+		return report
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "crawling %d pages from %s found %d problems:\n", len(report.Pages), seed, len(report.Problems))
+	for _, p := range report.Problems {
+		fmt.Fprintf(&b, "  - %s\n", p)
+	}
+	t.Errorf("%s", strings.TrimSuffix(b.String(), "\n"))
+	return report
+}
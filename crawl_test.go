@@ -0,0 +1,121 @@
+package testclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// site serves pages of HTML by path.
+func site(pages map[string]string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, ok := pages[r.URL.Path]
+		switch {
+		case !ok:
+			http.NotFound(w, r)
+		case strings.HasPrefix(page, "redirect:"):
+			http.Redirect(w, r, strings.TrimPrefix(page, "redirect:"), http.StatusFound)
+		default:
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			fmt.Fprint(w, page)
+		}
+	})
+}
+
+func TestCrawl(t *testing.T) {
+	var searched url.Values
+	pages := map[string]string{
+		"/": `<a href="/about">About</a> <a href="/about#team">Team</a> <a href="https://elsewhere.example/">out</a>
+			<a href="mailto:hi@example.com">mail</a> <a href="/old">old</a> <a href="/loop">loop</a> <a href="/logout">log out</a>
+			<form action="/search"><input name="q" value="shoes"><input type="checkbox" name="new" checked>
+			<input type="checkbox" name="used"><select name="sort"><option value="price">Price</option><option selected>Rating</option></select>
+			<input type="submit" name="go" value="Go"></form>
+			<form method="post" action="/subscribe"></form>`,
+		"/about":  `<a href="/team/missing">Alice</a> <a href="/deep/1">deeper</a>`,
+		"/old":    "redirect:/about",
+		"/loop":   "redirect:/loop2",
+		"/loop2":  "redirect:/loop",
+		"/search": `results`,
+		"/deep/1": `<a href="/deep/2">deeper</a>`,
+		"/deep/2": `<a href="/deep/3">deeper</a>`,
+	}
+	h := site(pages)
+	report := Crawl(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/search" {
+			searched = r.URL.Query()
+		}
+		if r.URL.Path == "/logout" || r.URL.Path == "/subscribe" {
+			t.Errorf("crawled %s", r.URL.Path)
+		}
+		h.ServeHTTP(w, r)
+	}), "/", CrawlOptions{MaxDepth: 2, Skip: func(u *url.URL) bool { return u.Path == "/logout" }})
+
+	wantPages := []string{
+		"http://example.com/", "http://example.com/about", "http://example.com/search?new=on&q=shoes&sort=Rating",
+		"http://example.com/team/missing", "http://example.com/deep/1",
+	}
+	if fmt.Sprint(report.Pages) != fmt.Sprint(wantPages) {
+		t.Errorf("pages = %q", report.Pages)
+	}
+	if got := searched.Encode(); got != "new=on&q=shoes&sort=Rating" {
+		t.Errorf("form submitted %q", got)
+	}
+	want := []string{
+		"http://example.com/loop (linked from http://example.com/): redirect loop: http://example.com/loop → http://example.com/loop2 → http://example.com/loop",
+		"http://example.com/team/missing (linked from http://example.com/about): status 404 Not Found",
+	}
+	if len(report.Problems) != len(want) {
+		t.Fatalf("problems = %q", report.Problems)
+	}
+	for i, p := range report.Problems {
+		if p.String() != want[i] {
+			t.Errorf("problem %d = %q, want %q", i, p, want[i])
+		}
+	}
+	if report.Truncated {
+		t.Error("crawl truncated")
+	}
+}
+
+func TestCrawlLimitsAndAudits(t *testing.T) {
+	h := site(map[string]string{
+		"/":  `<a href="/a">a</a> <a href="/b">b</a>`,
+		"/a": `a`,
+		"/b": `b`,
+	})
+	report := Crawl(h, "/", CrawlOptions{MaxPages: 2})
+	if !report.Truncated || len(report.Pages) != 2 {
+		t.Errorf("report = %+v", report)
+	}
+
+	nosniff := func(req *http.Request, res *http.Response) []string {
+		if res.Header.Get("X-Content-Type-Options") == "" {
+			return []string{"no X-Content-Type-Options"}
+		}
+		return nil
+	}
+	ft := &fakeT{TB: t}
+	ExpectCrawlClean(ft, h, "/", CrawlOptions{Audits: []Audit{nosniff}})
+	if len(ft.failures) != 1 || !strings.HasPrefix(ft.failures[0], "crawling 3 pages from / found 3 problems:\n  - http://example.com/: no X-Content-Type-Options\n") {
+		t.Errorf("failures = %q", ft.failures)
+	}
+
+	ft = &fakeT{TB: t}
+	ExpectCrawlClean(ft, h, "/", CrawlOptions{})
+	if len(ft.failures) != 0 {
+		t.Errorf("failures = %q", ft.failures)
+	}
+}
+
+func TestCrawlRedirectChain(t *testing.T) {
+	pages := map[string]string{"/": `<a href="/r/0">start</a>`}
+	for i := 0; i < 12; i++ {
+		pages[fmt.Sprintf("/r/%d", i)] = fmt.Sprintf("redirect:/r/%d", i+1)
+	}
+	report := Crawl(site(pages), "/", CrawlOptions{})
+	if len(report.Problems) != 1 || report.Problems[0].Problem != "more than 10 redirects in a row" {
+		t.Errorf("problems = %q", report.Problems)
+	}
+}
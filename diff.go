@@ -0,0 +1,209 @@
+package testclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DiffOptions loosen the comparison of Diff.
+type DiffOptions struct {
+	// IgnoreHeaders are names of headers left out, such as Date.
+	IgnoreHeaders []string
+	// IgnorePaths are JSON paths such as $.created_at or $.items[*].id,
+	// where [*] stands for any index, whose values are left out of JSON
+	// bodies.
+	IgnorePaths []string
+}
+
+// Difference is one way two responses differ: in the status, in a header,
+// at a JSON path into the body, or in the body as a whole if it is not
+// JSON. A and B render the values; an empty one is absent.
+type Difference struct {
+	// Where is "status", "header Name", "body" or a JSON path like
+	// $.items[0].sku.
+	Where string
+	A, B  string
+}
+
+func (d Difference) String() string {
+	return fmt.Sprintf("%s: %s vs %s", d.Where, orAbsent(d.A), orAbsent(d.B))
+}
+
+func orAbsent(s string) string {
+	if s == "" {
+		return "(absent)"
+	}
+	return s
+}
+
+// ResponseDiff lists the differences between two responses; it is empty
+// if they match.
+type ResponseDiff []Difference
+
+func (d ResponseDiff) String() string {
+	lines := make([]string, len(d))
+	for i, diff := range d {
+		lines[i] = diff.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Diff compares two responses, such as those of a rewritten handler and
+// the legacy one to the same request: their status, their headers, and
+// their bodies, property by property when both are JSON. The bodies stay
+// readable.
+func Diff(a, b *http.Response, opts DiffOptions) ResponseDiff {
+	var out ResponseDiff
+	if a.StatusCode != b.StatusCode {
+		out = append(out, Difference{"status", fmt.Sprint(a.StatusCode), fmt.Sprint(b.StatusCode)})
+	}
+
+	ignored := map[string]bool{}
+	for _, h := range opts.IgnoreHeaders {
+		ignored[http.CanonicalHeaderKey(h)] = true
+	}
+	var names []string
+	for _, h := range []http.Header{a.Header, b.Header} {
+		for k := range h {
+			if k = http.CanonicalHeaderKey(k); !ignored[k] && !contains(names, k) {
+				names = append(names, k)
+			}
+		}
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		va, vb := strings.Join(a.Header.Values(k), ", "), strings.Join(b.Header.Values(k), ", ")
+		if va != vb {
+			out = append(out, Difference{"header " + k, quoteNonEmpty(va), quoteNonEmpty(vb)})
+		}
+	}
+
+	ba, bb := bufferedBody(a).data, bufferedBody(b).data
+	docA, okA := decodeDiffJSON(a, ba)
+	docB, okB := decodeDiffJSON(b, bb)
+	if okA && okB {
+		ignore := compileIgnorePaths(opts.IgnorePaths)
+		return append(out, diffJSON("$", docA, docB, ignore)...)
+	}
+	if !bytes.Equal(ba, bb) {
+		out = append(out, Difference{"body", quoteBody(ba), quoteBody(bb)})
+	}
+	return out
+}
+
+func quoteNonEmpty(s string) string {
+	if s == "" {
+		return ""
+	}
+	return fmt.Sprintf("%q", s)
+}
+
+func quoteBody(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	if len(b) > maxDumpBody {
+		return fmt.Sprintf("%q... (%d bytes)", b[:maxDumpBody], len(b))
+	}
+	return fmt.Sprintf("%q", b)
+}
+
+func decodeDiffJSON(res *http.Response, body []byte) (any, bool) {
+	mt, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if mt != "application/json" && !strings.HasSuffix(mt, "+json") {
+		return nil, false
+	}
+	var doc any
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if dec.Decode(&doc) != nil {
+		return nil, false
+	}
+	return doc, true
+}
+
+// compileIgnorePaths turns JSON paths with [*] wildcards into one pattern.
+func compileIgnorePaths(paths []string) *regexp.Regexp {
+	if len(paths) == 0 {
+		return nil
+	}
+	alts := make([]string, len(paths))
+	for i, p := range paths {
+		alts[i] = strings.ReplaceAll(regexp.QuoteMeta(p), `\[\*\]`, `\[\d+\]`)
+	}
+	return regexp.MustCompile("^(?:" + strings.Join(alts, "|") + ")$")
+}
+
+func diffJSON(path string, a, b any, ignore *regexp.Regexp) []Difference {
+	if ignore != nil && ignore.MatchString(path) {
+		return nil
+	}
+	switch va := a.(type) {
+	case map[string]any:
+		vb, ok := b.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(va)+len(vb))
+		for k := range va {
+			keys = append(keys, k)
+		}
+		for k := range vb {
+			if _, ok := va[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		var out []Difference
+		for _, k := range keys {
+			ea, inA := va[k]
+			eb, inB := vb[k]
+			p := path + "." + k
+			switch {
+			case ignore != nil && ignore.MatchString(p):
+			case !inA:
+				out = append(out, Difference{p, "", jsonText(eb)})
+			case !inB:
+				out = append(out, Difference{p, jsonText(ea), ""})
+			default:
+				out = append(out, diffJSON(p, ea, eb, ignore)...)
+			}
+		}
+		return out
+	case []any:
+		vb, ok := b.([]any)
+		if !ok {
+			break
+		}
+		var out []Difference
+		for i := 0; i < len(va) || i < len(vb); i++ {
+			p := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case ignore != nil && ignore.MatchString(p):
+			case i >= len(va):
+				out = append(out, Difference{p, "", jsonText(vb[i])})
+			case i >= len(vb):
+				out = append(out, Difference{p, jsonText(va[i]), ""})
+			default:
+				out = append(out, diffJSON(p, va[i], vb[i], ignore)...)
+			}
+		}
+		return out
+	}
+	if reflect.DeepEqual(a, b) {
+		return nil
+	}
+	return []Difference{{path, jsonText(a), jsonText(b)}}
+}
+
+func jsonText(v any) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
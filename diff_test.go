@@ -0,0 +1,57 @@
+package testclient
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	handler := func(status int, body string, extra ...string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Date", fmt.Sprint(status))
+			for i := 0; i+1 < len(extra); i += 2 {
+				w.Header().Set(extra[i], extra[i+1])
+			}
+			w.WriteHeader(status)
+			fmt.Fprint(w, body)
+		})
+	}
+	legacy, _ := New(handler(200, `{"id": 1, "at": "a", "items": [{"sku": "A", "n": 1}, {"sku": "B", "n": 2}], "old": true}`, "X-Legacy", "1")).Get("/", nil)
+	rewrite, _ := New(handler(201, `{"id": 1, "at": "b", "items": [{"sku": "A", "n": 9}], "new": null}`)).Get("/", nil)
+
+	got := Diff(legacy, rewrite, DiffOptions{IgnoreHeaders: []string{"date"}, IgnorePaths: []string{"$.at", "$.items[*].n"}})
+	want := ResponseDiff{
+		{"status", "200", "201"},
+		{"header X-Legacy", `"1"`, ""},
+		{"$.items[1]", `{"n":2,"sku":"B"}`, ""},
+		{"$.new", "", "null"},
+		{"$.old", "true", ""},
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Diff =\n%s\nwant\n%s", got, want)
+	}
+	if got := got[1].String(); got != `header X-Legacy: "1" vs (absent)` {
+		t.Errorf("String() = %s", got)
+	}
+	if d := Diff(legacy, legacy, DiffOptions{}); len(d) != 0 {
+		t.Errorf("Diff of a response with itself = %s", d)
+	}
+	if body(t, legacy) == "" {
+		t.Error("body consumed by Diff")
+	}
+}
+
+func TestDiffText(t *testing.T) {
+	text := func(s string) *http.Response {
+		res, _ := New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, s)
+		})).Get("/", nil)
+		return res
+	}
+	got := Diff(text("<p>a</p>"), text("<p>b</p>"), DiffOptions{})
+	if got.String() != `body: "<p>a</p>" vs "<p>b</p>"` {
+		t.Errorf("Diff = %s", got)
+	}
+}
@@ -0,0 +1,117 @@
+package testclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrClientDisconnected is returned by Request when the request was sent
+// with Disconnect and the simulated client went away. It wraps
+// context.Canceled.
+var ErrClientDisconnected = fmt.Errorf("testclient: client disconnected: %w", context.Canceled)
+
+// DisconnectPlan says when a simulated client goes away. The first point
+// reached wins; zero fields are ignored.
+type DisconnectPlan struct {
+	// AfterBytes disconnects once the handler has written that many body
+	// bytes.
+	AfterBytes int
+	// After disconnects after that much real time.
+	After time.Duration
+	// OnFlush disconnects at the handler's first Flush.
+	OnFlush bool
+}
+
+type disconnectKey struct{}
+
+// Disconnect makes the client go away while the handler is serving the
+// request, as plan says: the request context is canceled and later writes
+// fail with io.ErrClosedPipe. Request then returns ErrClientDisconnected,
+// and Response reports what the handler wrote up to that point.
+func Disconnect(plan DisconnectPlan) RequestOption {
+	return func(req *http.Request) {
+		*req = *req.WithContext(context.WithValue(req.Context(), disconnectKey{}, plan))
+	}
+}
+
+// disconnectWriter cancels the request context at the planned point and
+// fails the writes that follow.
+type disconnectWriter struct {
+	http.ResponseWriter
+	plan    DisconnectPlan
+	cancel  context.CancelFunc
+	written int
+
+	mu   sync.Mutex
+	gone bool
+}
+
+// armDisconnect returns w and req wired to the plan stored in req, if any,
+// and a stop function reporting whether the client disconnected.
+func armDisconnect(w http.ResponseWriter, req *http.Request) (http.ResponseWriter, *http.Request, func() bool) {
+	plan, ok := req.Context().Value(disconnectKey{}).(DisconnectPlan)
+	if !ok {
+		return w, req, func() bool { return false }
+	}
+	ctx, cancel := context.WithCancel(req.Context())
+	dw := &disconnectWriter{ResponseWriter: w, plan: plan, cancel: cancel}
+	var timer *time.Timer
+	if plan.After > 0 {
+		timer = time.AfterFunc(plan.After, dw.disconnect)
+	}
+	return dw, req.WithContext(ctx), func() bool {
+		if timer != nil {
+			timer.Stop()
+		}
+		gone := dw.isGone()
+		cancel()
+		return gone
+	}
+}
+
+func (w *disconnectWriter) disconnect() {
+	w.mu.Lock()
+	w.gone = true
+	w.mu.Unlock()
+	w.cancel()
+}
+
+func (w *disconnectWriter) isGone() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.gone
+}
+
+func (w *disconnectWriter) Write(b []byte) (int, error) {
+	if w.isGone() {
+		return 0, io.ErrClosedPipe
+	}
+	if w.plan.AfterBytes > 0 && w.written+len(b) >= w.plan.AfterBytes {
+		n, _ := w.ResponseWriter.Write(b[:w.plan.AfterBytes-w.written])
+		w.written += n
+		w.disconnect()
+		if n < len(b) {
+			return n, io.ErrClosedPipe
+		}
+		return n, nil
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.written += n
+	return n, err
+}
+
+func (w *disconnectWriter) Flush() {
+	if w.isGone() {
+		return
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+	if w.plan.OnFlush {
+		w.disconnect()
+	}
+}
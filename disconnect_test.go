@@ -0,0 +1,88 @@
+package testclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// cleanupHandler writes until a write fails or the request is canceled and
+// reports which happened.
+func cleanupHandler(done chan<- error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < 100; i++ {
+			if _, err := io.WriteString(w, "0123456789"); err != nil {
+				done <- err
+				return
+			}
+			w.(http.Flusher).Flush()
+			if err := r.Context().Err(); err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	})
+}
+
+func TestDisconnectAfterBytes(t *testing.T) {
+	done := make(chan error, 1)
+	c := New(cleanupHandler(done))
+	res, err := c.Request(c.NewRequest(http.MethodGet, "/", nil), Disconnect(DisconnectPlan{AfterBytes: 25}))
+	if !errors.Is(err, ErrClientDisconnected) || !errors.Is(err, context.Canceled) || res != nil {
+		t.Errorf("Request = %v, %v; want ErrClientDisconnected", res, err)
+	}
+	if herr := <-done; !errors.Is(herr, io.ErrClosedPipe) {
+		t.Errorf("handler saw %v, want io.ErrClosedPipe", herr)
+	}
+	if got := string(c.BodyBytes()); got != "0123456789012345678901234" {
+		t.Errorf("partial body = %q, want 25 bytes", got)
+	}
+}
+
+func TestDisconnectOnFlush(t *testing.T) {
+	done := make(chan error, 1)
+	c := New(cleanupHandler(done))
+	_, err := c.Request(c.NewRequest(http.MethodGet, "/", nil), Disconnect(DisconnectPlan{OnFlush: true}))
+	if !errors.Is(err, ErrClientDisconnected) {
+		t.Errorf("err = %v, want ErrClientDisconnected", err)
+	}
+	if herr := <-done; !errors.Is(herr, context.Canceled) {
+		t.Errorf("handler saw %v, want context.Canceled", herr)
+	}
+	if got := string(c.BodyBytes()); got != "0123456789" {
+		t.Errorf("partial body = %q, want the first write", got)
+	}
+}
+
+func TestDisconnectAfter(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+			w.WriteHeader(http.StatusOK)
+		}
+	})
+	c := New(h)
+	start := time.Now()
+	if _, err := c.Request(c.NewRequest(http.MethodGet, "/", nil), Disconnect(DisconnectPlan{After: 10 * time.Millisecond})); !errors.Is(err, ErrClientDisconnected) {
+		t.Errorf("err = %v, want ErrClientDisconnected", err)
+	}
+	if d := time.Since(start); d > 500*time.Millisecond {
+		t.Errorf("disconnect took %v", d)
+	}
+}
+
+func TestDisconnectNotReached(t *testing.T) {
+	done := make(chan error, 1)
+	c := New(cleanupHandler(done))
+	if _, err := c.Request(c.NewRequest(http.MethodGet, "/", nil), Disconnect(DisconnectPlan{AfterBytes: 5000})); err != nil {
+		t.Errorf("err = %v, want none", err)
+	}
+	if herr := <-done; herr != nil {
+		t.Errorf("handler saw %v", herr)
+	}
+}
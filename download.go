@@ -0,0 +1,73 @@
+package testclient
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Download is a response body saved to a file by Client.Download.
+type Download struct {
+	// Response is the response, whose body went to the file.
+	Response *http.Response
+	Path     string
+	Size     int64
+	// SHA256 is the hex-encoded SHA-256 digest of the body.
+	SHA256 string
+}
+
+// Download gets uri like Stream and writes the body to the file dst as it
+// arrives, hashing it on the way, so that bodies of hundreds of megabytes
+// are never held in memory. The body is saved whatever the status; check
+// it on the Response of the result, whose body is then empty. With dst ""
+// the file goes into the test's temporary directory, under the last
+// element of the path of uri, which takes a client made with NewT.
+func (c *Client) Download(uri, dst string, opts ...RequestOption) (*Download, error) {
+	req := c.NewRequest(http.MethodGet, uri, nil)
+	if dst == "" {
+		if c.tb == nil {
+			return nil, fmt.Errorf("testclient: Download to a temporary file needs a client made with NewT")
+		}
+		name := path.Base(req.URL.Path)
+		if name == "/" || name == "." {
+			name = "download"
+		}
+		dst = filepath.Join(c.tb.TempDir(), name)
+	}
+	res, err := c.Stream(req, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	f, err := os.Create(dst)
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(f, h), res.Body)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("testclient: downloading %s: %w", uri, err)
+	}
+	res.Body = http.NoBody
+	return &Download{Response: res, Path: dst, Size: n, SHA256: hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+// ExpectChecksum fails the test unless the body saved by d has the
+// hex-encoded SHA-256 digest sum.
+func ExpectChecksum(t testing.TB, d *Download, sum string) {
+	t.Helper()
+	if !strings.EqualFold(d.SHA256, sum) {
+		t.Fatalf("expected %s to have SHA-256 %s, got %s (%d bytes)", d.Path, sum, d.SHA256, d.Size)
+	}
+}
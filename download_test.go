@@ -0,0 +1,84 @@
+package testclient
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func exportHandler(chunk []byte, chunks int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/csv")
+		for i := 0; i < chunks; i++ {
+			w.Write(chunk)
+		}
+	})
+}
+
+func TestDownload(t *testing.T) {
+	chunk := bytes.Repeat([]byte("id,name\n"), 4096)
+	h := sha256.New()
+	for i := 0; i < 64; i++ {
+		h.Write(chunk)
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+
+	c := New(exportHandler(chunk, 64))
+	dst := filepath.Join(t.TempDir(), "export.csv")
+	d, err := c.Download("/exports/1.csv", dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := int64(64 * len(chunk)); d.Size != want {
+		t.Errorf("Size = %d, want %d", d.Size, want)
+	}
+	ExpectChecksum(t, d, sum)
+	ExpectChecksum(t, d, strings.ToUpper(sum))
+	ExpectStatus(t, d.Response, http.StatusOK)
+	if fi, err := os.Stat(dst); err != nil || fi.Size() != d.Size {
+		t.Errorf("file: %v, %v", fi, err)
+	}
+	if len(c.BodyBytes()) != 0 {
+		t.Errorf("body of the last response is %d bytes, want none", len(c.BodyBytes()))
+	}
+
+	ft := &fakeT{}
+	ExpectChecksum(ft, d, strings.Repeat("0", 64))
+	if len(ft.failures) != 1 || !strings.Contains(ft.failures[0], "got "+sum+" (2097152 bytes)") {
+		t.Errorf("failures = %q", ft.failures)
+	}
+}
+
+func TestDownloadTempDir(t *testing.T) {
+	c := NewT(t, exportHandler([]byte("a,b\n"), 1))
+	d, err := c.Download("/exports/report.csv?day=1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(d.Path) != "report.csv" {
+		t.Errorf("Path = %s", d.Path)
+	}
+	if b, _ := os.ReadFile(d.Path); string(b) != "a,b\n" {
+		t.Errorf("file = %q", b)
+	}
+
+	if _, err := New(exportHandler(nil, 0)).Download("/x", ""); err == nil || !strings.Contains(err.Error(), "NewT") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestDownloadHandlerPanic(t *testing.T) {
+	c := New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("partial"))
+		panic("export failed")
+	}))
+	_, err := c.Download("/x", filepath.Join(t.TempDir(), "x"))
+	if err == nil || !strings.Contains(err.Error(), "export failed") {
+		t.Errorf("err = %v", err)
+	}
+}
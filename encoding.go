@@ -0,0 +1,139 @@
+package testclient
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing/iotest"
+
+	"github.com/andybalholm/brotli"
+)
+
+const acceptEncoding = "gzip, deflate, br"
+
+// WithDecompression makes the client ask for compressed responses and
+// transparently decode gzip, deflate and br bodies. Content-Encoding and the
+// other raw headers are kept for assertions; res.Uncompressed reports that
+// the body was decoded.
+func WithDecompression() Option {
+	return func(c *Client) {
+		c.decompress = true
+	}
+}
+
+// decodeBody replaces res.Body with one that decodes its Content-Encoding,
+// undoing the encodings in the reverse of the order they were applied. The
+// decoders are set up on the first Read so that a streamed body does not
+// block before the handler writes.
+func decodeBody(res *http.Response) {
+	var encodings []string
+	for _, v := range res.Header.Values("Content-Encoding") {
+		for _, e := range strings.Split(v, ",") {
+			if e = strings.ToLower(strings.TrimSpace(e)); e != "" && e != "identity" {
+				encodings = append(encodings, e)
+			}
+		}
+	}
+	if len(encodings) == 0 {
+		return
+	}
+	res.Body = &decodedBody{body: res.Body, encodings: encodings}
+	res.ContentLength = -1
+	res.Uncompressed = true
+}
+
+// decodeBuffered decodes the body of a buffered response up front, so the
+// decoded bytes are what the response shares. A decoding error ends the
+// body after whatever could be decoded.
+func decodeBuffered(res *http.Response) {
+	decodeBody(res)
+	if d, ok := res.Body.(*decodedBody); ok {
+		data, err := io.ReadAll(d)
+		res.Body = newResponseBody(data, err)
+	}
+}
+
+type decodedBody struct {
+	body      io.ReadCloser
+	encodings []string
+	r         io.Reader
+	err       error
+}
+
+func (b *decodedBody) Read(p []byte) (int, error) {
+	if b.r == nil && b.err == nil {
+		b.r, b.err = newDecoder(b.body, b.encodings)
+	}
+	if b.err != nil {
+		return 0, b.err
+	}
+	return b.r.Read(p)
+}
+
+func (b *decodedBody) Close() error {
+	return b.body.Close()
+}
+
+func newDecoder(r io.Reader, encodings []string) (io.Reader, error) {
+	for i := len(encodings) - 1; i >= 0; i-- {
+		var err error
+		switch encodings[i] {
+		case "gzip", "x-gzip":
+			r, err = gzip.NewReader(r)
+		case "deflate":
+			r, err = newDeflateReader(r)
+		case "br":
+			r = brotli.NewReader(r)
+		default:
+			return nil, fmt.Errorf("unsupported Content-Encoding %q", encodings[i])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("decoding %s body: %w", encodings[i], err)
+		}
+	}
+	return r, nil
+}
+
+// newDeflateReader reads "deflate" bodies, which are meant to be zlib
+// streams but are often sent as raw DEFLATE.
+func newDeflateReader(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	head, err := br.Peek(2)
+	if err != nil {
+		return nil, err
+	}
+	if head[0]&0x0f == 8 && (uint16(head[0])<<8|uint16(head[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
+
+// Gzip compresses the request body and sets Content-Encoding: gzip.
+func Gzip() RequestOption {
+	return func(req *http.Request) {
+		if req.Body == nil || req.Body == http.NoBody {
+			return
+		}
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		_, err := io.Copy(zw, req.Body)
+		req.Body.Close()
+		if err == nil {
+			err = zw.Close()
+		}
+		if err != nil {
+			req.Body = io.NopCloser(iotest.ErrReader(err))
+			return
+		}
+		req.Body = io.NopCloser(&buf)
+		req.ContentLength = int64(buf.Len())
+		req.Header.Set("Content-Encoding", "gzip")
+		req.Header.Del("Content-Length")
+	}
+}
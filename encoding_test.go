@@ -0,0 +1,123 @@
+package testclient
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+)
+
+func compress(t *testing.T, encoding string, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "deflate":
+		w = zlib.NewWriter(&buf)
+	case "raw-deflate":
+		w, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+	case "br":
+		w = brotli.NewWriter(&buf)
+	}
+	w.Write(data)
+	w.Close()
+	return buf.Bytes()
+}
+
+func TestDecompression(t *testing.T) {
+	const text = "hello, compressed world"
+	tests := []struct {
+		name     string
+		encoding string
+		body     []byte
+	}{
+		{"gzip", "gzip", compress(t, "gzip", []byte(text))},
+		{"zlib deflate", "deflate", compress(t, "deflate", []byte(text))},
+		{"raw deflate", "deflate", compress(t, "raw-deflate", []byte(text))},
+		{"br", "br", compress(t, "br", []byte(text))},
+		{"stacked", "deflate, gzip", compress(t, "gzip", compress(t, "deflate", []byte(text)))},
+		{"identity", "", []byte(text)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var accept string
+			h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				accept = r.Header.Get("Accept-Encoding")
+				if tt.encoding != "" {
+					w.Header().Set("Content-Encoding", tt.encoding)
+				}
+				w.Write(tt.body)
+			})
+
+			c := New(h, WithDecompression())
+			c.PostForm("/", nil)
+			res := c.Response()
+			if accept != acceptEncoding {
+				t.Errorf("Accept-Encoding = %q, want %q", accept, acceptEncoding)
+			}
+			if got := body(t, res); got != text {
+				t.Errorf("body = %q, want %q", got, text)
+			}
+			if got := res.Header.Get("Content-Encoding"); got != tt.encoding {
+				t.Errorf("Content-Encoding = %q, want the raw %q", got, tt.encoding)
+			}
+			if res.Uncompressed != (tt.encoding != "") {
+				t.Errorf("Uncompressed = %v", res.Uncompressed)
+			}
+		})
+	}
+}
+
+func TestDecompressionOff(t *testing.T) {
+	raw := compress(t, "gzip", []byte("x"))
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != "" {
+			t.Errorf("Accept-Encoding sent without WithDecompression")
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(raw)
+	})
+	c := New(h)
+	c.PostForm("/", nil)
+	if got := body(t, c.Response()); got != string(raw) {
+		t.Errorf("body was altered without WithDecompression")
+	}
+}
+
+func TestDecompressionStream(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.(http.Flusher).Flush()
+		zw := gzip.NewWriter(w)
+		io.WriteString(zw, "streamed")
+		zw.Close()
+	})
+	c := New(h, WithDecompression())
+	res, err := c.Stream(c.NewRequest(http.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if got := body(t, res); got != "streamed" {
+		t.Errorf("body = %q, want streamed", got)
+	}
+}
+
+func TestDecompressionBadBody(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		io.WriteString(w, "not gzip")
+	})
+	c := New(h, WithDecompression())
+	c.PostForm("/", nil)
+	if _, err := io.ReadAll(c.Response().Body); err == nil {
+		t.Error("reading a corrupt gzip body succeeded")
+	}
+}
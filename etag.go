@@ -0,0 +1,117 @@
+package testclient
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// entityTag is a parsed ETag.
+type entityTag struct {
+	weak   bool
+	opaque string
+}
+
+// parseETag parses an ETag: a quoted opaque tag, optionally prefixed with
+// W/ for a weak one.
+func parseETag(s string) (entityTag, bool) {
+	s = strings.TrimSpace(s)
+	weak := strings.HasPrefix(s, "W/")
+	s = strings.TrimPrefix(s, "W/")
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' || strings.Contains(s[1:len(s)-1], `"`) {
+		return entityTag{}, false
+	}
+	return entityTag{weak: weak, opaque: s}, true
+}
+
+func (e entityTag) String() string {
+	if e.weak {
+		return "W/" + e.opaque
+	}
+	return e.opaque
+}
+
+// ExpectETagConsistency checks the ETag of the resource at uri, fetched
+// with GET, against HTTP semantics, and fails the test for each way it
+// falls short:
+//   - the ETag is missing or malformed,
+//   - a second fetch gets the same body under another ETag, or a
+//     different body under the same strong ETag,
+//   - If-None-Match with the ETag, its weak form or "*" is not answered
+//     304, or with another ETag not answered 200 (If-None-Match compares
+//     weakly),
+//   - for a resource with Accept-Ranges: bytes, If-Range with the ETag
+//     does not get a 206 for a strong ETag, or gets one for a weak ETag
+//     (If-Range compares strongly).
+//
+// If mutate is not nil, it is called next to change the resource, say by
+// a PUT through c, and the test fails unless the ETag then changes and
+// If-None-Match with the old one gets the new content.
+func ExpectETagConsistency(t testing.TB, c *Client, uri string, mutate func()) {
+	t.Helper()
+	get := func(header ...string) *http.Response {
+		req := c.NewRequest(http.MethodGet, uri, nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		res, err := c.Request(req)
+		if err != nil {
+			t.Fatalf("testclient: GET %s: %v", uri, err)
+		}
+		return res
+	}
+
+	first := get()
+	if first.StatusCode != http.StatusOK {
+		t.Fatalf("GET %s: expected status 200 OK, got %s", uri, first.Status)
+		return
+	}
+	etag, ok := parseETag(first.Header.Get("ETag"))
+	if !ok {
+		t.Fatalf("GET %s: expected an ETag, got %q", uri, first.Header.Get("ETag"))
+		return
+	}
+	body := bufferedBody(first).data
+
+	second := get()
+	again, _ := parseETag(second.Header.Get("ETag"))
+	switch same := bytes.Equal(bufferedBody(second).data, body); {
+	case same && again != etag:
+		t.Errorf("GET %s: the same content came with ETag %s, then %s; caches cannot revalidate it", uri, etag, second.Header.Get("ETag"))
+	case !same && again == etag && !etag.weak:
+		t.Errorf("GET %s: different content came under the same strong ETag %s", uri, etag)
+	}
+
+	weakForm := entityTag{weak: true, opaque: etag.opaque}
+	for _, inm := range []string{etag.String(), weakForm.String(), "*"} {
+		if res := get("If-None-Match", inm); res.StatusCode != http.StatusNotModified {
+			t.Errorf("GET %s with If-None-Match: %s: expected status 304 Not Modified, got %s", uri, inm, res.Status)
+		}
+	}
+	if res := get("If-None-Match", `"testclient-other"`); res.StatusCode != http.StatusOK {
+		t.Errorf(`GET %s with If-None-Match: "testclient-other": expected status 200 OK, got %s`, uri, res.Status)
+	}
+
+	if strings.EqualFold(first.Header.Get("Accept-Ranges"), "bytes") && len(body) > 1 {
+		res := get("Range", "bytes=0-0", "If-Range", etag.String())
+		switch {
+		case !etag.weak && res.StatusCode != http.StatusPartialContent:
+			t.Errorf("GET %s with If-Range: %s: expected status 206 Partial Content for the current strong ETag, got %s", uri, etag, res.Status)
+		case etag.weak && res.StatusCode != http.StatusOK:
+			t.Errorf("GET %s with If-Range: %s: expected status 200 OK, as a weak ETag never matches If-Range, got %s", uri, etag, res.Status)
+		}
+	}
+
+	if mutate == nil {
+		return
+	}
+	mutate()
+	changed := get()
+	if now, _ := parseETag(changed.Header.Get("ETag")); now == etag || now.opaque == etag.opaque {
+		t.Errorf("GET %s: the ETag %s stayed the same after the change", uri, etag)
+	}
+	if res := get("If-None-Match", etag.String()); res.StatusCode != http.StatusOK {
+		t.Errorf("GET %s with If-None-Match: %s after the change: expected status 200 OK with the new content, got %s", uri, etag, res.Status)
+	}
+}
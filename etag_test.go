@@ -0,0 +1,125 @@
+package testclient
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// document serves content with an ETag from etag, using ServeContent for
+// the conditional and range requests unless naive is set.
+type document struct {
+	content string
+	etag    func(content string) string
+	naive   bool
+}
+
+func (d *document) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	tag := d.etag(d.content)
+	w.Header().Set("ETag", tag)
+	if d.naive {
+		if r.Header.Get("If-None-Match") == tag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fmt.Fprint(w, d.content)
+		return
+	}
+	http.ServeContent(w, r, "", time.Time{}, strings.NewReader(d.content))
+}
+
+func contentTag(content string) string {
+	return fmt.Sprintf(`"%x"`, sha256.Sum256([]byte(content)))[:18] + `"`
+}
+
+func TestExpectETagConsistency(t *testing.T) {
+	doc := &document{content: "v1 of the page", etag: contentTag}
+	ft := &fakeT{TB: t}
+	ExpectETagConsistency(ft, New(doc), "/page", func() { doc.content = "v2 of the page" })
+	if len(ft.failures) != 0 {
+		t.Errorf("failures = %q", ft.failures)
+	}
+
+	weak := &document{content: "v1", etag: func(c string) string { return "W/" + contentTag(c) }}
+	ft = &fakeT{TB: t}
+	ExpectETagConsistency(ft, New(weak), "/page", nil)
+	if len(ft.failures) != 0 {
+		t.Errorf("failures with a weak ETag = %q", ft.failures)
+	}
+}
+
+func TestExpectETagConsistencyFailures(t *testing.T) {
+	n := 0
+	tests := []struct {
+		name   string
+		doc    *document
+		mutate func(d *document)
+		want   []string
+	}{
+		{name: "no ETag", doc: &document{content: "x", etag: func(string) string { return "" }}, want: []string{`GET /page: expected an ETag, got ""`}},
+		{name: "unstable", doc: &document{content: "x", etag: func(string) string { n++; return fmt.Sprintf(`"%d"`, n) }}, want: []string{
+			`GET /page: the same content came with ETag "1", then "2"`,
+			`GET /page with If-None-Match: "1": expected status 304 Not Modified, got 200 OK`,
+		}},
+		{name: "naive comparison", doc: &document{content: "x", etag: contentTag, naive: true}, want: []string{
+			`with If-None-Match: W/"`,
+			`with If-None-Match: *: expected status 304 Not Modified, got 200 OK`,
+		}},
+		{name: "stale after change", doc: &document{content: "x", etag: func(string) string { return `"v"` }}, mutate: func(d *document) { d.content = "y" }, want: []string{
+			`GET /page: the ETag "v" stayed the same after the change`,
+			`GET /page with If-None-Match: "v" after the change: expected status 200 OK with the new content, got 304 Not Modified`,
+		}},
+		{name: "weak If-Range", doc: &document{content: "xyz", etag: func(c string) string { return `W/"` + c + `"` }}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mutate func()
+			if tt.mutate != nil {
+				mutate = func() { tt.mutate(tt.doc) }
+			}
+			ft := &fakeT{TB: t}
+			ExpectETagConsistency(ft, New(tt.doc), "/page", mutate)
+			if len(ft.failures) < len(tt.want) {
+				t.Fatalf("failures = %q", ft.failures)
+			}
+			if tt.want == nil && len(ft.failures) > 0 {
+				t.Fatalf("failures = %q", ft.failures)
+			}
+			for _, want := range tt.want {
+				found := false
+				for _, f := range ft.failures {
+					found = found || strings.Contains(f, want)
+				}
+				if !found {
+					t.Errorf("no failure %q in %q", want, ft.failures)
+				}
+			}
+		})
+	}
+}
+
+func TestExpectETagConsistencyIfRange(t *testing.T) {
+	// a handler serving ranges but ignoring If-Range
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `W/"a"`)
+		r.Header.Del("If-Range")
+		http.ServeContent(w, r, "", time.Time{}, strings.NewReader("abcdef"))
+	})
+	ft := &fakeT{TB: t}
+	ExpectETagConsistency(ft, New(h), "/page", nil)
+	if len(ft.failures) != 1 || ft.failures[0] != `GET /page with If-Range: W/"a": expected status 200 OK, as a weak ETag never matches If-Range, got 206 Partial Content` {
+		t.Errorf("failures = %q", ft.failures)
+	}
+}
+
+func TestParseETag(t *testing.T) {
+	for in, want := range map[string]string{`"a"`: `"a"`, ` W/"a" `: `W/"a"`, `a`: "", `""`: `""`, `"a"b"`: "", `W/a`: ""} {
+		e, ok := parseETag(in)
+		if got := e.String(); ok != (want != "") || ok && got != want {
+			t.Errorf("parseETag(%q) = %q, %v", in, got, ok)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package testclient
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// headerValues returns the values of the header name in h. List-valued
+// headers are split at top-level commas, so "Vary: Accept, Origin" yields
+// two values; Set-Cookie, whose values contain commas, is not split.
+func headerValues(h http.Header, name string) []string {
+	raw := h.Values(name)
+	if http.CanonicalHeaderKey(name) == "Set-Cookie" {
+		return raw
+	}
+	var values []string
+	for _, v := range raw {
+		values = append(values, splitList(v)...)
+	}
+	return values
+}
+
+// splitList splits a comma-separated header value, ignoring commas inside
+// quoted strings and <URI-references>, as in Link.
+func splitList(v string) []string {
+	var parts []string
+	start, quoted, angle := 0, false, false
+	for i := 0; i < len(v); i++ {
+		switch c := v[i]; {
+		case c == '\\' && quoted:
+			i++
+		case c == '"':
+			quoted = !quoted
+		case c == '<' && !quoted:
+			angle = true
+		case c == '>' && !quoted:
+			angle = false
+		case c == ',' && !quoted && !angle:
+			if p := strings.TrimSpace(v[start:i]); p != "" {
+				parts = append(parts, p)
+			}
+			start = i + 1
+		}
+	}
+	if p := strings.TrimSpace(v[start:]); p != "" {
+		parts = append(parts, p)
+	}
+	return parts
+}
+
+// ExpectHeader fails the test unless one of the values of the header name
+// is want. Every value counts, including later header lines and list
+// elements, unlike Header.Get.
+func ExpectHeader(t testing.TB, res *http.Response, name, want string) {
+	t.Helper()
+	values := headerValues(res.Header, name)
+	for _, v := range values {
+		if v == want {
+			return
+		}
+	}
+	t.Fatalf("expected header %s: %q, got %q%s", name, want, values, trailNote(res))
+}
+
+// ExpectHeaderValues fails the test unless the values of the header name
+// are exactly want, in order.
+func ExpectHeaderValues(t testing.TB, res *http.Response, name string, want ...string) {
+	t.Helper()
+	values := headerValues(res.Header, name)
+	if len(values) == len(want) {
+		same := true
+		for i := range want {
+			same = same && values[i] == want[i]
+		}
+		if same {
+			return
+		}
+	}
+	t.Fatalf("expected header %s: %q, got %q%s", name, want, values, trailNote(res))
+}
+
+// ExpectHeaderMatch fails the test unless one of the values of the header
+// name matches the regular expression pattern.
+func ExpectHeaderMatch(t testing.TB, res *http.Response, name, pattern string) {
+	t.Helper()
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		t.Fatalf("bad header pattern: %v", err)
+	}
+	values := headerValues(res.Header, name)
+	for _, v := range values {
+		if re.MatchString(v) {
+			return
+		}
+	}
+	t.Fatalf("expected header %s matching %q, got %q%s", name, pattern, values, trailNote(res))
+}
+
+// ExpectHeaderAbsent fails the test if res has the header name.
+func ExpectHeaderAbsent(t testing.TB, res *http.Response, name string) {
+	t.Helper()
+	if values := res.Header.Values(name); len(values) > 0 {
+		t.Fatalf("expected no header %s, got %q%s", name, values, trailNote(res))
+	}
+}
+
+// maxDumpBody limits how much of the body a failed status assertion shows.
+const maxDumpBody = 4 << 10
+
+// ExpectStatus fails the test unless res has the status want. The failure
+// shows the response headers and body.
+func ExpectStatus(t testing.TB, res *http.Response, want int) {
+	t.Helper()
+	if res.StatusCode != want {
+		t.Fatalf("expected status %d %s, got %s", want, http.StatusText(want), dumpResponse(res))
+	}
+}
+
+// ExpectSuccess fails the test unless res has a 2xx status.
+func ExpectSuccess(t testing.TB, res *http.Response) {
+	t.Helper()
+	expectClass(t, res, 2)
+}
+
+// ExpectRedirect fails the test unless res has a 3xx status.
+func ExpectRedirect(t testing.TB, res *http.Response) {
+	t.Helper()
+	expectClass(t, res, 3)
+}
+
+// Expect4xx fails the test unless res has a 4xx status.
+func Expect4xx(t testing.TB, res *http.Response) {
+	t.Helper()
+	expectClass(t, res, 4)
+}
+
+// Expect5xx fails the test unless res has a 5xx status.
+func Expect5xx(t testing.TB, res *http.Response) {
+	t.Helper()
+	expectClass(t, res, 5)
+}
+
+func expectClass(t testing.TB, res *http.Response, class int) {
+	t.Helper()
+	if res.StatusCode/100 != class {
+		t.Fatalf("expected status %dxx, got %s", class, dumpResponse(res))
+	}
+}
+
+// ExpectMaxBodySize fails the test if the body of res is longer than max
+// bytes, as delivered: decoded, if the client decompresses.
+func ExpectMaxBodySize(t testing.TB, res *http.Response, max int) {
+	t.Helper()
+	if n := len(bufferedBody(res).data); n > max {
+		t.Errorf("expected a body of at most %d bytes, got %d%s", max, n, trailNote(res))
+	}
+}
+
+// dumpResponse describes res for a failure message: status line, the
+// request under WithRequestID, headers and the start of a buffered body. A
+// streamed body is not read.
+func dumpResponse(res *http.Response) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d %s\n", res.StatusCode, http.StatusText(res.StatusCode))
+	if rid, ok := requestIDOf(res.Request); ok {
+		fmt.Fprintf(&b, "(response to %s %s, %s %s)\n", res.Request.Method, res.Request.URL.RequestURI(), rid.header, rid.id)
+	}
+	b.WriteString(trailOf(res))
+	res.Header.Write(&b)
+	body, ok := res.Body.(*responseBody)
+	if !ok {
+		if res.Body != nil && res.Body != http.NoBody {
+			b.WriteString("\n(streamed body not shown)")
+		}
+		return b.String()
+	}
+	data := body.data
+	b.WriteString("\n")
+	if len(data) > maxDumpBody {
+		fmt.Fprintf(&b, "%s\n... (%d more bytes)", data[:maxDumpBody], len(data)-maxDumpBody)
+	} else {
+		b.Write(data)
+	}
+	return b.String()
+}
@@ -0,0 +1,93 @@
+package testclient
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func headerResponse() *http.Response {
+	return &http.Response{Header: http.Header{
+		"Vary":       {"Accept-Encoding, Origin", "Cookie"},
+		"Link":       {`<https://a.test/p?x=1,2>; rel="next", <https://a.test/p?x=0>; rel="prev"; title="a, b"`},
+		"Set-Cookie": {"a=1; Expires=Wed, 21 Oct 2030 07:28:00 GMT", "b=2"},
+	}}
+}
+
+func TestExpectHeader(t *testing.T) {
+	res := headerResponse()
+	ExpectHeader(t, res, "vary", "Cookie")
+	ExpectHeader(t, res, "Vary", "Origin")
+	ExpectHeaderValues(t, res, "Vary", "Accept-Encoding", "Origin", "Cookie")
+	ExpectHeaderValues(t, res, "Link", `<https://a.test/p?x=1,2>; rel="next"`, `<https://a.test/p?x=0>; rel="prev"; title="a, b"`)
+	ExpectHeaderValues(t, res, "Set-Cookie", "a=1; Expires=Wed, 21 Oct 2030 07:28:00 GMT", "b=2")
+	ExpectHeaderMatch(t, res, "Link", `rel="prev"`)
+	ExpectHeaderAbsent(t, res, "Cache-Control")
+}
+
+func TestExpectHeaderFailures(t *testing.T) {
+	res := headerResponse()
+	for name, check := range map[string]func(testing.TB){
+		`expected header Vary: "Accept", got ["Accept-Encoding" "Origin" "Cookie"]`: func(t testing.TB) { ExpectHeader(t, res, "Vary", "Accept") },
+		`expected header Vary: ["Cookie"]`:                                          func(t testing.TB) { ExpectHeaderValues(t, res, "Vary", "Cookie") },
+		`expected header Link matching "rel=.last."`:                                func(t testing.TB) { ExpectHeaderMatch(t, res, "Link", `rel=.last.`) },
+		`expected no header Set-Cookie`:                                             func(t testing.TB) { ExpectHeaderAbsent(t, res, "Set-Cookie") },
+	} {
+		ft := &fakeT{}
+		check(ft)
+		if len(ft.failures) != 1 || !strings.Contains(ft.failures[0], name) {
+			t.Errorf("failures = %q, want %q", ft.failures, name)
+		}
+	}
+}
+
+func TestExpectStatus(t *testing.T) {
+	s := NewStub()
+	s.On("GET", "/missing").ReplyJSON(http.StatusNotFound, map[string]string{"error": "no such user"})
+	s.On("GET", "/big").Reply(http.StatusInternalServerError, strings.Repeat("x", 5000))
+	c := New(s)
+	res, _ := c.Request(c.NewRequest(http.MethodGet, "/missing", nil))
+
+	ExpectStatus(t, res, http.StatusNotFound)
+	Expect4xx(t, res)
+
+	ft := &fakeT{}
+	ExpectStatus(ft, res, http.StatusOK)
+	ExpectSuccess(ft, res)
+	if len(ft.failures) != 2 {
+		t.Fatalf("failures = %q", ft.failures)
+	}
+	for _, want := range []string{"expected status 200 OK, got 404 Not Found", "Content-Type: application/json", `{"error":"no such user"}`} {
+		if !strings.Contains(ft.failures[0], want) {
+			t.Errorf("failure does not contain %q:\n%s", want, ft.failures[0])
+		}
+	}
+	if !strings.Contains(ft.failures[1], "expected status 2xx, got 404") {
+		t.Errorf("failure = %q", ft.failures[1])
+	}
+	if got := body(t, res); got != `{"error":"no such user"}` {
+		t.Errorf("body after the dump = %q", got)
+	}
+
+	res, _ = c.Request(c.NewRequest(http.MethodGet, "/big", nil))
+	ft = &fakeT{}
+	ExpectRedirect(ft, res)
+	if len(ft.failures) != 1 || !strings.Contains(ft.failures[0], "(904 more bytes)") {
+		t.Errorf("failures = %.200q", ft.failures)
+	}
+	Expect5xx(t, res)
+}
+
+func TestExpectMaxBodySize(t *testing.T) {
+	s := NewStub()
+	s.On("GET", "/page").Reply(http.StatusOK, strings.Repeat("x", 2048))
+	c := New(s)
+	res, _ := c.Request(c.NewRequest(http.MethodGet, "/page", nil))
+
+	ExpectMaxBodySize(t, res, 2048)
+	ft := &fakeT{}
+	ExpectMaxBodySize(ft, res, 1024)
+	if len(ft.failures) != 1 || !strings.Contains(ft.failures[0], "expected a body of at most 1024 bytes, got 2048") {
+		t.Errorf("failures = %q", ft.failures)
+	}
+}
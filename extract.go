@@ -0,0 +1,61 @@
+package testclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// ExtractJSON decodes the value at path in the JSON body of res into v,
+// for use in a later request. path is like "$.id" or "$.items[0].sku".
+// The body stays readable.
+func ExtractJSON(res *http.Response, path string, v any) error {
+	value, err := jsonAt(res, path)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("testclient: decoding %s: %w", path, err)
+	}
+	return nil
+}
+
+// ExtractHeader stores the value of header name of res in v. It fails if
+// the response has no such header.
+func ExtractHeader(res *http.Response, name string, v *string) error {
+	value := res.Header.Get(name)
+	if value == "" {
+		return fmt.Errorf("testclient: response has no %s header", name)
+	}
+	*v = value
+	return nil
+}
+
+// ExtractCookie stores the value of the cookie name set by res in v. It
+// fails if the response did not set it.
+func ExtractCookie(res *http.Response, name string, v *string) error {
+	for _, c := range res.Cookies() {
+		if c.Name == name {
+			*v = c.Value
+			return nil
+		}
+	}
+	return fmt.Errorf("testclient: response sets no %s cookie", name)
+}
+
+// jsonAt returns the value at path in the JSON body of res, with numbers
+// decoded as json.Number.
+func jsonAt(res *http.Response, path string) (any, error) {
+	var doc any
+	dec := json.NewDecoder(bytes.NewReader(bufferedBody(res).data))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("testclient: body is not JSON: %w", err)
+	}
+	return lookupJSON(doc, path)
+}
@@ -0,0 +1,81 @@
+package testclient
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/orders/42" {
+			if c, err := r.Cookie("session"); err != nil || c.Value != "s1" {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "s1"})
+		w.Header().Set("Location", "/orders/42")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"order": {"id": 42, "lines": [{"sku": "A-1", "qty": 2}]}}`)
+	})
+	c := New(h)
+	res, err := c.PostForm("/orders", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var id int
+	if err := ExtractJSON(res, "$.order.id", &id); err != nil || id != 42 {
+		t.Errorf("id = %d, %v", id, err)
+	}
+	var line struct {
+		SKU string
+		Qty int
+	}
+	if err := ExtractJSON(res, "$.order.lines[0]", &line); err != nil || line.SKU != "A-1" || line.Qty != 2 {
+		t.Errorf("line = %+v, %v", line, err)
+	}
+	var loc, session string
+	if err := ExtractHeader(res, "Location", &loc); err != nil {
+		t.Fatal(err)
+	}
+	if err := ExtractCookie(res, "session", &session); err != nil {
+		t.Fatal(err)
+	}
+	if got := body(t, res); !strings.Contains(got, `"id": 42`) {
+		t.Errorf("body after extracting = %q", got)
+	}
+
+	// a fresh client has no jar cookies, so only the extracted value counts
+	res, err = New(h).Get(loc, nil, Header("Cookie", "session="+session))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("chained request got %d", res.StatusCode)
+	}
+}
+
+func TestExtractErrors(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": "x"}`)
+	})
+	res, err := New(h).Get("/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	var s string
+	for name, err := range map[string]error{
+		"missing property": ExtractJSON(res, "$.name", &s),
+		"wrong type":       ExtractJSON(res, "$.id", &n),
+		"missing header":   ExtractHeader(res, "Location", &s),
+		"missing cookie":   ExtractCookie(res, "session", &s),
+	} {
+		if err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package testclient
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ErrConnectionReset is returned by Request when the handler aborted the
+// connection (by panicking with http.ErrAbortHandler) before writing a
+// response. It matches syscall.ECONNRESET with errors.Is.
+var ErrConnectionReset = fmt.Errorf("testclient: connection reset by handler: %w", syscall.ECONNRESET)
+
+// Faults describes failures to inject in front of a handler, to exercise the
+// retry and timeout behavior of callers.
+type Faults struct {
+	// Latency delays every request, or until the request context is done.
+	Latency time.Duration
+	// ErrorRate is the probability of answering ErrorStatus without calling
+	// the handler. ErrorStatus defaults to 503.
+	ErrorRate   float64
+	ErrorStatus int
+	// ResetRate is the probability of aborting the connection without a
+	// response.
+	ResetRate float64
+	// TruncateAfter, if positive, aborts the connection once the handler
+	// has written that many body bytes, leaving the client a short body.
+	TruncateAfter int
+	// Rand is the source of randomness; nil uses the source seeded by
+	// WithRandSeed or RandSeed, if any, and math/rand otherwise.
+	Rand *rand.Rand
+
+	mu sync.Mutex
+}
+
+// roll reports whether an event of probability p happens, drawing from
+// f.Rand, or else from seeded if it is set.
+func (f *Faults) roll(p float64, seeded *seededRand) bool {
+	switch {
+	case p <= 0:
+		return false
+	case p >= 1:
+		return true
+	}
+	if f.Rand == nil {
+		if seeded != nil {
+			return seeded.Float64() < p
+		}
+		return rand.Float64() < p
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.Rand.Float64() < p
+}
+
+// Wrap returns h with the faults injected. Connection resets and truncation
+// abort the handler with http.ErrAbortHandler, which a real http.Server
+// turns into a dropped connection and Client.Request into an error.
+func (f *Faults) Wrap(h http.Handler) http.Handler {
+	return f.wrap(h, nil)
+}
+
+// wrap is Wrap, rolling with seeded unless f has a Rand.
+func (f *Faults) wrap(h http.Handler, seeded *seededRand) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if f.Latency > 0 {
+			timer := time.NewTimer(f.Latency)
+			select {
+			case <-timer.C:
+			case <-r.Context().Done():
+				timer.Stop()
+				return
+			}
+		}
+		if f.roll(f.ResetRate, seeded) {
+			panic(http.ErrAbortHandler)
+		}
+		if f.roll(f.ErrorRate, seeded) {
+			status := f.ErrorStatus
+			if status == 0 {
+				status = http.StatusServiceUnavailable
+			}
+			http.Error(w, "testclient: injected fault", status)
+			return
+		}
+		if f.TruncateAfter <= 0 {
+			h.ServeHTTP(w, r)
+			return
+		}
+		tw := &truncatingWriter{ResponseWriter: w, remaining: f.TruncateAfter}
+		h.ServeHTTP(tw, r)
+		if tw.cut {
+			panic(http.ErrAbortHandler)
+		}
+	})
+}
+
+// WithFaults injects f in front of the handler for every request.
+func WithFaults(f *Faults) Option {
+	return func(c *Client) {
+		c.faults = f
+	}
+}
+
+// Inject injects f in front of the route.
+func (r *Route) Inject(f *Faults) *Route {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.faults = f
+	return r
+}
+
+type truncatingWriter struct {
+	http.ResponseWriter
+	remaining int
+	cut       bool
+}
+
+func (w *truncatingWriter) Write(b []byte) (int, error) {
+	if len(b) <= w.remaining {
+		n, err := w.ResponseWriter.Write(b)
+		w.remaining -= n
+		return n, err
+	}
+	w.ResponseWriter.Write(b[:w.remaining])
+	w.remaining = 0
+	w.cut = true
+	// pretend the write went out, as it would into a socket buffer
+	return len(b), nil
+}
+
+func (w *truncatingWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
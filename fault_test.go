@@ -0,0 +1,113 @@
+package testclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestFaultsLatency(t *testing.T) {
+	c := New(NewStub(), WithFaults(&Faults{Latency: 50 * time.Millisecond}))
+	start := time.Now()
+	if _, err := c.Request(c.NewRequest(http.MethodGet, "/", nil)); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d < 50*time.Millisecond {
+		t.Errorf("request took %v, want at least 50ms", d)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	c = New(NewStub(), WithFaults(&Faults{Latency: time.Hour}))
+	start = time.Now()
+	c.Request(c.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("request ignored its context for %v", d)
+	}
+}
+
+func TestFaultsErrorRate(t *testing.T) {
+	s := NewStub()
+	route := s.On("GET", "/flaky").Reply(http.StatusOK, "ok")
+	c := New(s, WithFaults(&Faults{ErrorRate: 1}))
+	res, err := c.Request(c.NewRequest(http.MethodGet, "/flaky", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", res.StatusCode)
+	}
+	if route.Hits() != 0 {
+		t.Errorf("handler served %d requests, want 0", route.Hits())
+	}
+
+	f := &Faults{ErrorRate: 0.5, ErrorStatus: http.StatusBadGateway, Rand: rand.New(rand.NewSource(1))}
+	route.Inject(f)
+	c = New(s)
+	var failed int
+	for i := 0; i < 100; i++ {
+		res, _ := c.Request(c.NewRequest(http.MethodGet, "/flaky", nil))
+		if res.StatusCode == http.StatusBadGateway {
+			failed++
+		}
+	}
+	if failed == 0 || failed == 100 {
+		t.Errorf("%d of 100 requests failed, want some", failed)
+	}
+}
+
+func TestFaultsZeroIsNoop(t *testing.T) {
+	s := NewStub()
+	s.On("GET", "/").Reply(http.StatusOK, "ok").Inject(&Faults{})
+	c := New(s, WithFaults(&Faults{}))
+	res, err := c.Request(c.NewRequest(http.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := body(t, res); got != "ok" {
+		t.Errorf("body = %q, want ok", got)
+	}
+}
+
+func TestFaultsReset(t *testing.T) {
+	s := NewStub()
+	s.On("GET", "/").Reply(http.StatusOK, "ok").Inject(&Faults{ResetRate: 1})
+	c := New(s)
+	res, err := c.Request(c.NewRequest(http.MethodGet, "/", nil))
+	if !errors.Is(err, ErrConnectionReset) || !errors.Is(err, syscall.ECONNRESET) {
+		t.Errorf("err = %v, want a connection reset", err)
+	}
+	if res != nil || c.Response() != nil {
+		t.Error("reset request returned a response")
+	}
+}
+
+func TestFaultsTruncate(t *testing.T) {
+	s := NewStub()
+	s.On("GET", "/").Reply(http.StatusOK, strings.Repeat("x", 100)).Inject(&Faults{TruncateAfter: 10})
+	c := New(s)
+	res, err := c.Request(c.NewRequest(http.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(res.Body)
+	if len(b) != 10 || err != io.ErrUnexpectedEOF {
+		t.Errorf("read %d bytes, %v; want 10 bytes, unexpected EOF", len(b), err)
+	}
+}
+
+func TestRequestAbortHandler(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	})
+	c := New(h)
+	if _, err := c.Request(c.NewRequest(http.MethodGet, "/", nil)); !errors.Is(err, ErrConnectionReset) {
+		t.Errorf("err = %v, want ErrConnectionReset", err)
+	}
+}
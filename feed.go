@@ -0,0 +1,312 @@
+package testclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Feed formats, as Feed.Format gives them.
+const (
+	FeedRSS  = "rss"
+	FeedAtom = "atom"
+	FeedJSON = "json"
+)
+
+// Feed is an RSS 2.0, Atom or JSON Feed document, reduced to what the
+// three formats share.
+type Feed struct {
+	// Format is FeedRSS, FeedAtom or FeedJSON.
+	Format string
+	Title  string
+	Link   string
+	Items  []FeedItem
+
+	problems []string
+}
+
+// FeedItem is an RSS item, an Atom entry or a JSON Feed item. ID is the
+// guid of an RSS item.
+type FeedItem struct {
+	ID        string
+	Title     string
+	Link      string
+	Published string
+}
+
+type rssDoc struct {
+	XMLName xml.Name
+	Version string `xml:"version,attr"`
+	Channel *struct {
+		Title       string `xml:"title"`
+		Link        string `xml:"link"`
+		Description string `xml:"description"`
+		Items       []struct {
+			Title       string `xml:"title"`
+			Link        string `xml:"link"`
+			Description string `xml:"description"`
+			GUID        string `xml:"guid"`
+			PubDate     string `xml:"pubDate"`
+		} `xml:"item"`
+	} `xml:"channel"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+}
+
+type atomDoc struct {
+	XMLName xml.Name
+	ID      string     `xml:"id"`
+	Title   string     `xml:"title"`
+	Updated string     `xml:"updated"`
+	Links   []atomLink `xml:"link"`
+	Entries []struct {
+		ID        string     `xml:"id"`
+		Title     string     `xml:"title"`
+		Updated   string     `xml:"updated"`
+		Published string     `xml:"published"`
+		Links     []atomLink `xml:"link"`
+	} `xml:"entry"`
+}
+
+type jsonFeedDoc struct {
+	Version     string `json:"version"`
+	Title       string `json:"title"`
+	HomePageURL string `json:"home_page_url"`
+	Items       *[]struct {
+		ID            any    `json:"id"`
+		URL           string `json:"url"`
+		Title         string `json:"title"`
+		DatePublished string `json:"date_published"`
+	} `json:"items"`
+}
+
+// ParseFeed parses the body of res as a feed, telling the format from the
+// document: JSON is a JSON Feed, an <rss> root RSS and a <feed> root
+// Atom. It fails only on a malformed document; ExpectFeed checks the
+// required fields.
+func ParseFeed(res *http.Response) (*Feed, error) {
+	body := bytes.TrimSpace(bufferedBody(res).data)
+	if bytes.HasPrefix(body, []byte("{")) {
+		return parseJSONFeed(body)
+	}
+	root, err := rootElement(body)
+	if err != nil {
+		return nil, fmt.Errorf("testclient: feed is not XML or JSON: %w", err)
+	}
+	switch root {
+	case "rss":
+		return parseRSS(body)
+	case "feed":
+		return parseAtom(body)
+	}
+	return nil, fmt.Errorf("testclient: <%s> is not the root of an RSS or Atom feed", root)
+}
+
+// rootElement returns the local name of the root element of an XML
+// document, reading no further.
+func rootElement(doc []byte) (string, error) {
+	d := xml.NewDecoder(bytes.NewReader(doc))
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return "", err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+func parseRSS(body []byte) (*Feed, error) {
+	var doc rssDoc
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("testclient: malformed RSS feed: %w", err)
+	}
+	f := &Feed{Format: FeedRSS}
+	if doc.Version != "2.0" {
+		f.problem("rss version is %q, not 2.0", doc.Version)
+	}
+	ch := doc.Channel
+	if ch == nil {
+		f.problem("no channel")
+		return f, nil
+	}
+	f.Title, f.Link = strings.TrimSpace(ch.Title), strings.TrimSpace(ch.Link)
+	f.require("channel", map[string]string{"title": ch.Title, "link": ch.Link, "description": ch.Description})
+	for i, it := range ch.Items {
+		item := FeedItem{ID: strings.TrimSpace(it.GUID), Title: strings.TrimSpace(it.Title), Link: strings.TrimSpace(it.Link), Published: strings.TrimSpace(it.PubDate)}
+		if item.Title == "" && strings.TrimSpace(it.Description) == "" {
+			f.problem("item %d has neither title nor description", i+1)
+		}
+		if item.Published != "" && !validTime(item.Published, time.RFC1123Z, time.RFC1123, time.RFC822Z, time.RFC822) {
+			f.problem("item %d pubDate %q is not an RFC 822 date", i+1, item.Published)
+		}
+		f.Items = append(f.Items, item)
+	}
+	return f, nil
+}
+
+func parseAtom(body []byte) (*Feed, error) {
+	var doc atomDoc
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("testclient: malformed Atom feed: %w", err)
+	}
+	f := &Feed{Format: FeedAtom, Title: strings.TrimSpace(doc.Title), Link: atomHref(doc.Links)}
+	if doc.XMLName.Space != "http://www.w3.org/2005/Atom" {
+		f.problem("feed is not in the Atom namespace")
+	}
+	f.require("feed", map[string]string{"id": doc.ID, "title": doc.Title, "updated": doc.Updated})
+	f.checkTime("feed updated", doc.Updated)
+	for i, e := range doc.Entries {
+		f.require(fmt.Sprintf("entry %d", i+1), map[string]string{"id": e.ID, "title": e.Title, "updated": e.Updated})
+		f.checkTime(fmt.Sprintf("entry %d updated", i+1), e.Updated)
+		f.checkTime(fmt.Sprintf("entry %d published", i+1), e.Published)
+		f.Items = append(f.Items, FeedItem{ID: strings.TrimSpace(e.ID), Title: strings.TrimSpace(e.Title), Link: atomHref(e.Links), Published: strings.TrimSpace(e.Published)})
+	}
+	return f, nil
+}
+
+func parseJSONFeed(body []byte) (*Feed, error) {
+	var doc jsonFeedDoc
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("testclient: malformed JSON Feed: %w", err)
+	}
+	f := &Feed{Format: FeedJSON, Title: doc.Title, Link: doc.HomePageURL}
+	if !strings.HasPrefix(doc.Version, "https://jsonfeed.org/version/") {
+		f.problem("version %q is not a JSON Feed version URL", doc.Version)
+	}
+	f.require("feed", map[string]string{"title": doc.Title})
+	if doc.Items == nil {
+		f.problem("feed has no items array")
+		return f, nil
+	}
+	for i, it := range *doc.Items {
+		id := ""
+		switch v := it.ID.(type) {
+		case string:
+			id = v
+		case nil:
+		default:
+			// version 1 allowed numbers; 1.1 requires strings
+			id = fmt.Sprint(v)
+		}
+		f.require(fmt.Sprintf("item %d", i+1), map[string]string{"id": id})
+		f.checkTime(fmt.Sprintf("item %d date_published", i+1), it.DatePublished)
+		f.Items = append(f.Items, FeedItem{ID: id, Title: it.Title, Link: it.URL, Published: it.DatePublished})
+	}
+	return f, nil
+}
+
+func atomHref(links []atomLink) string {
+	for _, l := range links {
+		if l.Rel == "" || l.Rel == "alternate" {
+			return l.Href
+		}
+	}
+	return ""
+}
+
+func (f *Feed) problem(format string, args ...any) {
+	f.problems = append(f.problems, fmt.Sprintf(format, args...))
+}
+
+// require records a problem for each field of what that is empty, in the
+// order of the format's spec.
+func (f *Feed) require(what string, fields map[string]string) {
+	for _, name := range []string{"id", "title", "link", "description", "updated"} {
+		if v, ok := fields[name]; ok && strings.TrimSpace(v) == "" {
+			f.problem("%s has no %s", what, name)
+		}
+	}
+}
+
+func (f *Feed) checkTime(what, value string) {
+	if value = strings.TrimSpace(value); value != "" && !validTime(value, time.RFC3339) {
+		f.problem("%s %q is not an RFC 3339 date", what, value)
+	}
+}
+
+func validTime(value string, layouts ...string) bool {
+	for _, layout := range layouts {
+		if _, err := time.Parse(layout, value); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// ExpectFeed fails the test unless the body of res is a well-formed feed
+// with the fields its format requires: for RSS 2.0, the title, link and
+// description of the channel and a title or description for each item;
+// for Atom, the id, title and updated of the feed and of each entry; for
+// JSON Feed, a version URL, a title and an id for each item. Dates must
+// parse and item IDs be unique. It returns the feed.
+func ExpectFeed(t testing.TB, res *http.Response) *Feed {
+	t.Helper()
+	f, err := ParseFeed(res)
+	if err != nil {
+		t.Fatalf("%v", err)
+		return nil
+	}
+	problems := append([]string(nil), f.problems...)
+	seen := map[string]int{}
+	for i, item := range f.Items {
+		if item.ID == "" {
+			continue
+		}
+		if j, ok := seen[item.ID]; ok {
+			problems = append(problems, fmt.Sprintf("items %d and %d share the ID %q", j+1, i+1, item.ID))
+		}
+		seen[item.ID] = i
+	}
+	if len(problems) > 0 {
+		t.Fatalf("invalid %s feed:\n  - %s", f.Format, strings.Join(problems, "\n  - "))
+	}
+	return f
+}
+
+// ExpectFeedItems fails the test unless feed has n items.
+func ExpectFeedItems(t testing.TB, feed *Feed, n int) {
+	t.Helper()
+	if len(feed.Items) != n {
+		t.Fatalf("expected %d feed items, got %d", n, len(feed.Items))
+	}
+}
+
+// ExpectStableFeedIDs fetches the feed at uri twice and fails the test
+// unless every item has an ID, an RSS guid, and the second fetch lists
+// the same IDs in the same order: feed readers take an item with a new ID
+// for a new item and show it again.
+func ExpectStableFeedIDs(t testing.TB, c *Client, uri string, opts ...RequestOption) {
+	t.Helper()
+	var ids [2][]string
+	for i := range ids {
+		res, err := c.Request(c.NewRequest(http.MethodGet, uri, nil), opts...)
+		if err != nil {
+			t.Fatalf("testclient: GET %s: %v", uri, err)
+			return
+		}
+		f := ExpectFeed(t, res)
+		if f == nil {
+			return
+		}
+		for j, item := range f.Items {
+			if item.ID == "" {
+				t.Fatalf("feed item %d of %s has no ID; readers cannot tell it from a new one", j+1, uri)
+				return
+			}
+			ids[i] = append(ids[i], item.ID)
+		}
+	}
+	if strings.Join(ids[0], "\n") != strings.Join(ids[1], "\n") {
+		t.Fatalf("feed IDs of %s changed between two requests:\nfirst  %q\nsecond %q", uri, ids[0], ids[1])
+	}
+}
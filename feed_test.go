@@ -0,0 +1,104 @@
+package testclient
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func feedResponse(body string) *http.Response {
+	return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: newResponseBody([]byte(body), nil)}
+}
+
+const rssFeed = `<?xml version="1.0"?>
+<rss version="2.0"><channel>
+  <title>Blog</title><link>https://example.com/</link><description>News</description>
+  <item><title>Hello</title><link>https://example.com/hello</link><guid>post-1</guid><pubDate>Mon, 02 Jan 2006 15:04:05 -0700</pubDate></item>
+  <item><description>Untitled note</description><guid>post-2</guid></item>
+</channel></rss>`
+
+func TestExpectFeed(t *testing.T) {
+	tests := []struct {
+		name, body, format string
+		items              int
+		want               string
+	}{
+		{name: "rss", body: rssFeed, format: FeedRSS, items: 2},
+		{name: "atom", format: FeedAtom, items: 1, body: `<feed xmlns="http://www.w3.org/2005/Atom"><id>urn:blog</id><title>Blog</title>
+			<updated>2024-05-01T10:00:00Z</updated><link href="https://example.com/"/>
+			<entry><id>urn:post:1</id><title>Hello</title><updated>2024-05-01T10:00:00Z</updated><link rel="alternate" href="https://example.com/hello"/></entry></feed>`},
+		{name: "json", format: FeedJSON, items: 2, body: `{"version": "https://jsonfeed.org/version/1.1", "title": "Blog",
+			"items": [{"id": "1", "url": "https://example.com/hello", "date_published": "2024-05-01T10:00:00Z"}, {"id": 2}]}`},
+		{name: "malformed", body: `<rss version="2.0"><channel>`, want: "testclient: malformed RSS feed"},
+		{name: "not a feed", body: `<html></html>`, want: "testclient: <html> is not the root of an RSS or Atom feed"},
+		{name: "rss problems", body: `<rss version="0.91"><channel><title>Blog</title><item><guid>a</guid><pubDate>yesterday</pubDate></item><item><title>b</title><guid>a</guid></item></channel></rss>`,
+			want: "invalid rss feed:\n  - rss version is \"0.91\", not 2.0\n  - channel has no link\n  - channel has no description\n  - item 1 has neither title nor description\n  - item 1 pubDate \"yesterday\" is not an RFC 822 date\n  - items 1 and 2 share the ID \"a\""},
+		{name: "atom problems", body: `<feed><title>Blog</title><updated>May 1</updated><entry><id>x</id></entry></feed>`,
+			want: "invalid atom feed:\n  - feed is not in the Atom namespace\n  - feed has no id\n  - feed updated \"May 1\" is not an RFC 3339 date\n  - entry 1 has no title\n  - entry 1 has no updated"},
+		{name: "json problems", body: `{"version": "1", "items": [{"title": "x"}]}`,
+			want: "invalid json feed:\n  - version \"1\" is not a JSON Feed version URL\n  - feed has no title\n  - item 1 has no id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ft := &fakeT{TB: t}
+			f := ExpectFeed(ft, feedResponse(tt.body))
+			if tt.want != "" {
+				if len(ft.failures) != 1 || !strings.HasPrefix(ft.failures[0], tt.want) {
+					t.Errorf("failures = %q, want %q", ft.failures, tt.want)
+				}
+				return
+			}
+			if len(ft.failures) != 0 {
+				t.Fatalf("failures = %q", ft.failures)
+			}
+			if f.Format != tt.format || f.Title != "Blog" || len(f.Items) != tt.items || f.Items[0].Link != "https://example.com/hello" {
+				t.Errorf("feed = %+v", f)
+			}
+		})
+	}
+}
+
+func TestExpectFeedItems(t *testing.T) {
+	f, err := ParseFeed(feedResponse(rssFeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ft := &fakeT{TB: t}
+	ExpectFeedItems(ft, f, 2)
+	ExpectFeedItems(ft, f, 3)
+	if len(ft.failures) != 1 || ft.failures[0] != "expected 3 feed items, got 2" {
+		t.Errorf("failures = %q", ft.failures)
+	}
+}
+
+func TestExpectStableFeedIDs(t *testing.T) {
+	n := 0
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n++
+		switch r.URL.Path {
+		case "/feed.xml":
+			fmt.Fprint(w, rssFeed)
+		case "/unstable.json":
+			fmt.Fprintf(w, `{"version": "https://jsonfeed.org/version/1.1", "title": "Blog", "items": [{"id": "%d"}]}`, n)
+		case "/noguid.xml":
+			fmt.Fprint(w, strings.Replace(rssFeed, "<guid>post-2</guid>", "", 1))
+		}
+	})
+	c := New(h)
+	ft := &fakeT{TB: t}
+	ExpectStableFeedIDs(ft, c, "/feed.xml")
+	if len(ft.failures) != 0 || n != 2 {
+		t.Errorf("failures = %q after %d requests", ft.failures, n)
+	}
+	ft = &fakeT{TB: t}
+	ExpectStableFeedIDs(ft, c, "/unstable.json")
+	if len(ft.failures) != 1 || ft.failures[0] != "feed IDs of /unstable.json changed between two requests:\nfirst  [\"3\"]\nsecond [\"4\"]" {
+		t.Errorf("failures = %q", ft.failures)
+	}
+	ft = &fakeT{TB: t}
+	ExpectStableFeedIDs(ft, c, "/noguid.xml")
+	if len(ft.failures) != 1 || !strings.HasPrefix(ft.failures[0], "feed item 2 of /noguid.xml has no ID") {
+		t.Errorf("failures = %q", ft.failures)
+	}
+}
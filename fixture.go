@@ -0,0 +1,60 @@
+package testclient
+
+import (
+	"bytes"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"text/template"
+)
+
+// TemplateData is available to fixture templates as their dot.
+type TemplateData struct {
+	Method string
+	Path   string
+	// Params holds the route's {name} path parameters.
+	Params map[string]string
+	Query  url.Values
+	Header http.Header
+}
+
+func newTemplateData(req *http.Request, params map[string]string) TemplateData {
+	return TemplateData{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Params: params,
+		Query:  req.URL.Query(),
+		Header: req.Header,
+	}
+}
+
+// ReplyFile replies with the contents of the file at path, typically under
+// testdata. The Content-Type is inferred from the file extension, falling
+// back to content sniffing. A file containing "{{" is executed as a
+// text/template with TemplateData, e.g. {"id": "{{.Params.id}}"}. ReplyFile
+// panics if the file cannot be read or parsed.
+func (r *Route) ReplyFile(status int, path string) *Route {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		panic("testclient: ReplyFile: " + err.Error())
+	}
+	var tmpl *template.Template
+	if bytes.Contains(b, []byte("{{")) {
+		tmpl, err = template.New(filepath.Base(path)).Option("missingkey=error").Parse(string(b))
+		if err != nil {
+			panic("testclient: ReplyFile: " + err.Error())
+		}
+	}
+	ct := mime.TypeByExtension(filepath.Ext(path))
+	if ct == "" {
+		ct = http.DetectContentType(b)
+	}
+
+	r.SetHeader("Content-Type", ct)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status, r.body, r.handler, r.tmpl = status, b, nil, tmpl
+	return r
+}
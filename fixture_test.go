@@ -0,0 +1,59 @@
+package testclient
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestReplyFile(t *testing.T) {
+	stub := NewStub()
+	stub.On(http.MethodGet, "/users").ReplyFile(http.StatusOK, "testdata/users.json")
+	stub.On(http.MethodGet, "/users/{id}").ReplyFile(http.StatusOK, "testdata/user.json")
+	stub.On(http.MethodGet, "/raw").ReplyFile(http.StatusTeapot, "testdata/fixture.unknownext")
+	c := New(stub)
+
+	tests := []struct {
+		path   string
+		status int
+		ctype  string
+		body   string
+	}{
+		{"/users", http.StatusOK, "application/json", `[{"id": 1, "name": "alice"}]` + "\n"},
+		{"/users/a%2Fb?expand=orders", http.StatusOK, "application/json", `{"id": "a/b", "expand": "orders"}` + "\n"},
+		{"/raw", http.StatusTeapot, "text/plain; charset=utf-8", "plain fixture\n"},
+	}
+	for _, tt := range tests {
+		c.Request(c.NewRequest(http.MethodGet, tt.path, nil))
+		res := c.Response()
+		if res.StatusCode != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.path, res.StatusCode, tt.status)
+		}
+		if ct := res.Header.Get("Content-Type"); ct != tt.ctype {
+			t.Errorf("%s: Content-Type = %q, want %q", tt.path, ct, tt.ctype)
+		}
+		if got := body(t, res); got != tt.body {
+			t.Errorf("%s: body = %q, want %q", tt.path, got, tt.body)
+		}
+	}
+}
+
+func TestReplyFileTemplateError(t *testing.T) {
+	stub := NewStub()
+	stub.On(http.MethodGet, "/users/{id}").ReplyFile(http.StatusOK, "testdata/user.json")
+	c := New(stub)
+	c.Request(c.NewRequest(http.MethodGet, "/users/1", nil))
+	res := c.Response()
+	if res.StatusCode != http.StatusInternalServerError || !strings.Contains(body(t, res), "rendering fixture") {
+		t.Errorf("missing query value: got %d", res.StatusCode)
+	}
+}
+
+func TestReplyFileMissing(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("ReplyFile did not panic on a missing file")
+		}
+	}()
+	NewStub().On(http.MethodGet, "/").ReplyFile(http.StatusOK, "testdata/missing.json")
+}
@@ -0,0 +1,77 @@
+package testclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// EncodeNestedForm encodes params the way Rails and PHP nest form
+// parameters: maps become user[name]=x and slices become tags[]=a&tags[]=b,
+// at any depth, so a slice of maps is sent as users[][name]=a. Map keys are
+// sorted and slice elements keep their order, so each element of a slice of
+// maps is sent as a group. Other values are formatted with fmt.Sprint; nil
+// is sent as an empty value.
+func EncodeNestedForm(params map[string]any) string {
+	var b strings.Builder
+	brackets := strings.NewReplacer("%5B", "[", "%5D", "]")
+	flattenForm("", reflect.ValueOf(params), func(k, v string) {
+		if b.Len() > 0 {
+			b.WriteByte('&')
+		}
+		b.WriteString(brackets.Replace(url.QueryEscape(k)))
+		b.WriteByte('=')
+		b.WriteString(url.QueryEscape(v))
+	})
+	return b.String()
+}
+
+func flattenForm(prefix string, v reflect.Value, add func(k, v string)) {
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			add(prefix, "")
+			return
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Map:
+		keys := make([]string, 0, v.Len())
+		values := map[string]reflect.Value{}
+		for _, k := range v.MapKeys() {
+			s := fmt.Sprint(k.Interface())
+			keys = append(keys, s)
+			values[s] = v.MapIndex(k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			name := k
+			if prefix != "" {
+				name = prefix + "[" + k + "]"
+			}
+			flattenForm(name, values[k], add)
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			add(prefix, string(v.Bytes()))
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			flattenForm(prefix+"[]", v.Index(i), add)
+		}
+	case reflect.Invalid:
+		add(prefix, "")
+	default:
+		add(prefix, fmt.Sprint(v.Interface()))
+	}
+}
+
+// PostNestedForm posts params encoded with EncodeNestedForm to uri.
+func (c *Client) PostNestedForm(uri string, params map[string]any, opts ...RequestOption) (*http.Response, error) {
+	req := c.NewRequest(http.MethodPost, uri, strings.NewReader(EncodeNestedForm(params)))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return c.Request(req, opts...)
+}
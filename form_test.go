@@ -0,0 +1,42 @@
+package testclient
+
+import (
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestEncodeNestedForm(t *testing.T) {
+	got := EncodeNestedForm(map[string]any{
+		"user": map[string]any{
+			"name":    "Ann Lee",
+			"age":     30,
+			"address": map[string]string{"city": "Tokyo"},
+		},
+		"tags":   []string{"a", "b&c"},
+		"agree":  true,
+		"note":   nil,
+		"people": []map[string]any{{"name": "x", "role": "admin"}, {"name": "y", "role": "dev"}},
+	})
+	want := "agree=true&note=" +
+		"&people[][name]=x&people[][role]=admin&people[][name]=y&people[][role]=dev" +
+		"&tags[]=a&tags[]=b%26c" +
+		"&user[address][city]=Tokyo&user[age]=30&user[name]=Ann+Lee"
+	if got != want {
+		t.Errorf("EncodeNestedForm =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestPostNestedForm(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		io.WriteString(w, r.Header.Get("Content-Type")+" "+r.PostForm.Get("user[name]")+" "+r.PostForm["tags[]"][1])
+	})
+	res, err := New(h).PostNestedForm("/users", map[string]any{"user": map[string]any{"name": "ann"}, "tags": []any{"a", "b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := body(t, res); got != "application/x-www-form-urlencoded ann b" {
+		t.Errorf("handler saw %q", got)
+	}
+}
@@ -0,0 +1,106 @@
+package testclient
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"net/url"
+	"runtime/debug"
+	"strings"
+	"testing"
+)
+
+// FuzzHandler makes h a fuzz target. Each input is turned into a request:
+// a method, a target, headers written as "Key: value" lines, and a body.
+// The test fails if h panics or answers 5xx with what looks like a Go stack
+// trace in the body. seeds are added to the corpus; inputs that cannot form
+// a request, such as an invalid method, are skipped.
+//
+//	func FuzzAPI(f *testing.F) {
+//		testclient.FuzzHandler(f, api, []testclient.RequestSpec{{Method: "POST", Target: "/items", Body: []byte(`{}`)}})
+//	}
+func FuzzHandler(f *testing.F, h http.Handler, seeds []RequestSpec) {
+	f.Helper()
+	for _, s := range seeds {
+		var header strings.Builder
+		for k, vv := range s.Header {
+			for _, v := range vv {
+				fmt.Fprintf(&header, "%s: %s\n", k, v)
+			}
+		}
+		f.Add(s.Method, s.Target, header.String(), s.Body)
+	}
+	f.Fuzz(func(t *testing.T, method, target, header string, body []byte) {
+		req, ok := fuzzRequest(method, target, header, body)
+		if !ok {
+			t.Skip("input does not form a request")
+		}
+		fuzzOne(t, h, req)
+	})
+}
+
+// fuzzRequest builds the request for one fuzz input.
+func fuzzRequest(method, target, header string, body []byte) (*http.Request, bool) {
+	if method == "" {
+		method = http.MethodGet
+	}
+	if !validToken(method) {
+		return nil, false
+	}
+	if !strings.HasPrefix(target, "/") {
+		target = "/" + target
+	}
+	if _, err := url.ParseRequestURI(target); err != nil || strings.ContainsAny(target, " \r\n") {
+		return nil, false
+	}
+	h, err := textproto.NewReader(bufio.NewReader(strings.NewReader(strings.TrimSpace(header) + "\r\n\r\n"))).ReadMIMEHeader()
+	if err != nil && strings.TrimSpace(header) != "" {
+		return nil, false
+	}
+	req := httptest.NewRequest(method, target, bytes.NewReader(body))
+	for k, vv := range h {
+		req.Header[k] = vv
+	}
+	return req, true
+}
+
+// fuzzOne serves req and fails t on a panic or a 5xx carrying a stack.
+func fuzzOne(t testing.TB, h http.Handler, req *http.Request) {
+	t.Helper()
+	desc := fmt.Sprintf("%s %s", req.Method, req.URL.RequestURI())
+	rec := httptest.NewRecorder()
+	panicked, stack := func() (p any, stack []byte) {
+		defer func() {
+			if p = recover(); p != nil {
+				stack = debug.Stack()
+			}
+		}()
+		h.ServeHTTP(rec, req)
+		return nil, nil
+	}()
+	if panicked != nil {
+		t.Fatalf("testclient: handler panicked on %s: %v\n%s", desc, panicked, stack)
+		return
+	}
+	if rec.Code >= 500 && looksLikeStack(rec.Body.Bytes()) {
+		t.Fatalf("testclient: %s answered %d with a stack trace:\n%s", desc, rec.Code, rec.Body.Bytes())
+	}
+}
+
+// looksLikeStack reports whether b contains a Go stack trace.
+func looksLikeStack(b []byte) bool {
+	return bytes.Contains(b, []byte("goroutine ")) && bytes.Contains(b, []byte(".go:"))
+}
+
+func validToken(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c <= ' ' || c >= 0x7f || strings.IndexByte("\"(),/:;<=>?@[\\]{}", c) >= 0 {
+			return false
+		}
+	}
+	return s != ""
+}
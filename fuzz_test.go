@@ -0,0 +1,64 @@
+package testclient
+
+import (
+	"io"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"testing"
+)
+
+func FuzzEchoHandler(f *testing.F) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(w, r.Body)
+	})
+	FuzzHandler(f, h, []RequestSpec{
+		{Method: http.MethodPost, Target: "/echo", Header: http.Header{"Content-Type": {"text/plain"}}, Body: []byte("hi")},
+		{Target: "/"},
+	})
+}
+
+func TestFuzzRequest(t *testing.T) {
+	req, ok := fuzzRequest("PUT", "items/1?x=1", "X-A: 1\nX-B: 2", []byte("b"))
+	if !ok {
+		t.Fatal("valid input rejected")
+	}
+	if req.Method != "PUT" || req.URL.Path != "/items/1" || req.Header.Get("X-B") != "2" {
+		t.Errorf("request = %s %s %v", req.Method, req.URL, req.Header)
+	}
+	for _, in := range [][2]string{{"BAD METHOD", "/"}, {"GET", "/a b"}, {"GET", "/%zz"}} {
+		if _, ok := fuzzRequest(in[0], in[1], "", nil); ok {
+			t.Errorf("fuzzRequest(%q, %q) accepted", in[0], in[1])
+		}
+	}
+}
+
+func TestFuzzOneFailures(t *testing.T) {
+	panics := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+	leaks := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, string(debug.Stack()), http.StatusInternalServerError)
+	})
+	plain := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	})
+	for name, tc := range map[string]struct {
+		h    http.Handler
+		fail string
+	}{
+		"panic":       {panics, "panicked on GET /x"},
+		"stack trace": {leaks, "answered 500 with a stack trace"},
+		"plain 5xx":   {plain, ""},
+	} {
+		ft := &fakeT{}
+		req, _ := fuzzRequest("GET", "/x", "", nil)
+		fuzzOne(ft, tc.h, req)
+		switch {
+		case tc.fail == "" && len(ft.failures) > 0:
+			t.Errorf("%s: unexpected failure %v", name, ft.failures)
+		case tc.fail != "" && (len(ft.failures) != 1 || !strings.Contains(ft.failures[0], tc.fail)):
+			t.Errorf("%s: failures = %v, want %q", name, ft.failures, tc.fail)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package testclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// GraphQLResponse is the decoded envelope of a GraphQL response.
+type GraphQLResponse struct {
+	Data       json.RawMessage `json:"data"`
+	Errors     []GraphQLError  `json:"errors"`
+	Extensions map[string]any  `json:"extensions"`
+}
+
+// GraphQLError is an entry of the errors array.
+type GraphQLError struct {
+	Message   string `json:"message"`
+	Path      []any  `json:"path"`
+	Locations []struct {
+		Line   int `json:"line"`
+		Column int `json:"column"`
+	} `json:"locations"`
+	Extensions map[string]any `json:"extensions"`
+}
+
+// Code returns extensions.code, the machine-readable error code most
+// servers set.
+func (e GraphQLError) Code() string {
+	code, _ := e.Extensions["code"].(string)
+	return code
+}
+
+// PathString returns the path joined with dots, such as "user.friends.0".
+func (e GraphQLError) PathString() string {
+	parts := make([]string, len(e.Path))
+	for i, p := range e.Path {
+		parts[i] = fmt.Sprint(p)
+	}
+	return strings.Join(parts, ".")
+}
+
+// GraphQL posts query and variables to path in the standard JSON envelope
+// and decodes the response envelope. The HTTP response stays available
+// through Response.
+func (c *Client) GraphQL(path, query string, variables map[string]any, opts ...RequestOption) (*GraphQLResponse, error) {
+	b, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		return nil, err
+	}
+	req := c.NewRequest(http.MethodPost, path, bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/graphql-response+json, application/json")
+	if _, err := c.Request(req, opts...); err != nil {
+		return nil, err
+	}
+	var gr GraphQLResponse
+	if err := json.Unmarshal(c.BodyBytes(), &gr); err != nil {
+		return nil, fmt.Errorf("testclient: decoding GraphQL response (status %d): %w", c.response.StatusCode, err)
+	}
+	return &gr, nil
+}
+
+// Decode decodes data into v.
+func (gr *GraphQLResponse) Decode(v any) error {
+	if len(gr.Data) == 0 || string(gr.Data) == "null" {
+		return fmt.Errorf("testclient: GraphQL response has no data")
+	}
+	return json.Unmarshal(gr.Data, v)
+}
+
+// ExpectNoGraphQLErrors fails the test if the response has errors.
+func ExpectNoGraphQLErrors(t testing.TB, gr *GraphQLResponse) {
+	t.Helper()
+	if len(gr.Errors) > 0 {
+		t.Fatalf("expected no GraphQL errors, got %s", formatGraphQLErrors(gr.Errors))
+	}
+}
+
+// ExpectGraphQLError fails the test unless the response has an error with
+// extensions.code code at path, written as by PathString; an empty path
+// matches any. It returns the error for further checks on its message.
+func ExpectGraphQLError(t testing.TB, gr *GraphQLResponse, code, path string) GraphQLError {
+	t.Helper()
+	for _, e := range gr.Errors {
+		if e.Code() == code && (path == "" || e.PathString() == path) {
+			return e
+		}
+	}
+	t.Fatalf("expected GraphQL error %s at %q, got %s", code, path, formatGraphQLErrors(gr.Errors))
+	return GraphQLError{}
+}
+
+func formatGraphQLErrors(errs []GraphQLError) string {
+	if len(errs) == 0 {
+		return "none"
+	}
+	var b strings.Builder
+	for _, e := range errs {
+		fmt.Fprintf(&b, "\n  %s at %q: %s", e.Code(), e.PathString(), e.Message)
+	}
+	return b.String()
+}
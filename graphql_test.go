@@ -0,0 +1,63 @@
+package testclient
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// graphQLServer answers the user query, failing for id 0.
+func graphQLServer(t *testing.T) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || !strings.Contains(req.Query, "user(") {
+			t.Errorf("bad GraphQL request %+v: %v", req, err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if req.Variables["id"] == "0" {
+			w.Write([]byte(`{"data":{"user":null},"errors":[{"message":"user not found","path":["user"],"extensions":{"code":"NOT_FOUND"}}]}`))
+			return
+		}
+		w.Write([]byte(`{"data":{"user":{"id":"1","friends":[{"name":"bob"}]}}}`))
+	})
+}
+
+func TestGraphQL(t *testing.T) {
+	c := New(graphQLServer(t))
+	const query = `query($id: ID!) { user(id: $id) { id friends { name } } }`
+	gr, err := c.GraphQL("/graphql", query, map[string]any{"id": "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ExpectNoGraphQLErrors(t, gr)
+	var data struct {
+		User struct {
+			ID      string
+			Friends []struct{ Name string }
+		}
+	}
+	if err := gr.Decode(&data); err != nil {
+		t.Fatal(err)
+	}
+	if data.User.ID != "1" || len(data.User.Friends) != 1 || data.User.Friends[0].Name != "bob" {
+		t.Errorf("decoded %+v", data)
+	}
+
+	gr, err = c.GraphQL("/graphql", query, map[string]any{"id": "0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := ExpectGraphQLError(t, gr, "NOT_FOUND", "user"); e.Message != "user not found" {
+		t.Errorf("message = %q", e.Message)
+	}
+	ft := &fakeT{}
+	ExpectNoGraphQLErrors(ft, gr)
+	ExpectGraphQLError(ft, gr, "FORBIDDEN", "")
+	if len(ft.failures) != 2 || !strings.Contains(ft.failures[1], `NOT_FOUND at "user": user not found`) {
+		t.Errorf("failures = %q", ft.failures)
+	}
+}
@@ -0,0 +1,59 @@
+package testclient
+
+import (
+	"encoding/base64"
+	"net/http"
+)
+
+// SetHeader sets a header sent with every subsequent request, unless the
+// request sets the header itself.
+func (c *Client) SetHeader(key, value string) {
+	if c.headers == nil {
+		c.headers = http.Header{}
+	}
+	c.headers.Set(key, value)
+}
+
+// DelHeader removes a header set with SetHeader.
+func (c *Client) DelHeader(key string) {
+	c.headers.Del(key)
+}
+
+// SetBasicAuth sends HTTP Basic credentials with every subsequent request.
+func (c *Client) SetBasicAuth(username, password string) {
+	c.SetHeader("Authorization", basicAuth(username, password))
+}
+
+// SetBearerToken sends a bearer token with every subsequent request.
+func (c *Client) SetBearerToken(token string) {
+	c.SetHeader("Authorization", "Bearer "+token)
+}
+
+// Header sets a header on a single request.
+func Header(key, value string) RequestOption {
+	return func(req *http.Request) {
+		req.Header.Set(key, value)
+	}
+}
+
+// BasicAuth sends HTTP Basic credentials with a single request.
+func BasicAuth(username, password string) RequestOption {
+	return Header("Authorization", basicAuth(username, password))
+}
+
+// BearerToken sends a bearer token with a single request.
+func BearerToken(token string) RequestOption {
+	return Header("Authorization", "Bearer "+token)
+}
+
+func basicAuth(username, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+}
+
+func (c *Client) applyHeaders(req *http.Request) {
+	for key, values := range c.headers {
+		if _, ok := req.Header[key]; !ok {
+			req.Header[key] = append([]string(nil), values...)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package testclient
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestAuthHelpers(t *testing.T) {
+	var got *http.Request
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+	})
+	c := New(h)
+
+	c.SetBasicAuth("alice", "s3cret")
+	c.PostForm("/", nil)
+	if user, pass, ok := got.BasicAuth(); !ok || user != "alice" || pass != "s3cret" {
+		t.Errorf("BasicAuth = %q, %q, %v", user, pass, ok)
+	}
+	c.PostForm("/", nil)
+	if _, _, ok := got.BasicAuth(); !ok {
+		t.Error("basic auth not sent on the second request")
+	}
+
+	c.SetBearerToken("tok")
+	c.PostForm("/", nil)
+	if a := got.Header.Get("Authorization"); a != "Bearer tok" {
+		t.Errorf("Authorization = %q, want Bearer tok", a)
+	}
+
+	c.PostForm("/", nil, BasicAuth("bob", "pw"))
+	if user, _, _ := got.BasicAuth(); user != "bob" {
+		t.Errorf("per-request BasicAuth user = %q, want bob", user)
+	}
+	c.PostForm("/", nil, BearerToken("other"))
+	if a := got.Header.Get("Authorization"); a != "Bearer other" {
+		t.Errorf("per-request Authorization = %q", a)
+	}
+
+	c.DelHeader("Authorization")
+	c.PostForm("/", nil)
+	if a := got.Header.Get("Authorization"); a != "" {
+		t.Errorf("Authorization = %q after DelHeader", a)
+	}
+}
+
+func TestSetHeader(t *testing.T) {
+	var got http.Header
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header
+	})
+	c := New(h)
+	c.SetHeader("X-Tenant", "acme")
+	c.PostForm("/", nil)
+	if v := got.Get("X-Tenant"); v != "acme" {
+		t.Errorf("X-Tenant = %q, want acme", v)
+	}
+	c.PostForm("/", nil, Header("X-Tenant", "other"))
+	if v := got.Values("X-Tenant"); len(v) != 1 || v[0] != "other" {
+		t.Errorf("X-Tenant = %q, want only the per-request value", v)
+	}
+}
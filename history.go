@@ -0,0 +1,83 @@
+package testclient
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Exchange is a request sent with Request and what came of it.
+type Exchange struct {
+	Request *http.Request
+	// Response is nil if Err is set.
+	Response *http.Response
+	Err      error
+	// RequestID is the ID WithRequestID sent, if any.
+	RequestID string
+	// Duration is how long the handler took to serve the request, or its
+	// last attempt with WithRetry.
+	Duration time.Duration
+}
+
+type exchange struct {
+	req  *http.Request
+	body []byte
+	res  *http.Response
+	err  error
+	took time.Duration
+}
+
+// WithHistory keeps every request sent with Request, and the helpers built
+// on it, along with its response, for History. Request bodies are buffered
+// so that they can be kept. With WithRetry, only the last attempt is kept.
+func WithHistory() Option {
+	return func(c *Client) {
+		c.keepHistory = true
+	}
+}
+
+// History returns the exchanges of the client under WithHistory, oldest
+// first. Each request and response has a fresh body reader.
+func (c *Client) History() []Exchange {
+	out := make([]Exchange, len(c.history))
+	for i, ex := range c.history {
+		out[i] = ex.open()
+	}
+	return out
+}
+
+// ClearHistory forgets the recorded exchanges.
+func (c *Client) ClearHistory() {
+	c.history = nil
+}
+
+// keepBody buffers the body of req for the history, leaving req readable.
+func keepBody(req *http.Request) ([]byte, error) {
+	body, err := bufferBody(req)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	return body, nil
+}
+
+func (ex exchange) open() Exchange {
+	req := ex.req.Clone(ex.req.Context())
+	if ex.body == nil {
+		req.Body = http.NoBody
+	} else {
+		req.Body = io.NopCloser(bytes.NewReader(ex.body))
+	}
+	rid, _ := requestIDOf(ex.req)
+	out := Exchange{Request: req, Err: ex.err, RequestID: rid.id, Duration: ex.took}
+	if ex.res != nil {
+		res := *ex.res
+		b := bufferedBody(ex.res)
+		res.Body = newResponseBody(b.data, b.err)
+		out.Response = &res
+	}
+	return out
+}
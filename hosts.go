@@ -0,0 +1,60 @@
+package testclient
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// WithHandlers registers a handler per host, so that a single client can
+// drive several in-process services and follow redirects between them.
+func WithHandlers(handlers map[string]http.Handler) Option {
+	return func(c *Client) {
+		for host, h := range handlers {
+			c.Handle(host, h)
+		}
+	}
+}
+
+// Handle registers h to serve requests addressed to host. Requests for hosts
+// without a handler of their own go to the handler passed to New.
+func (c *Client) Handle(host string, h http.Handler) {
+	if c.handlers == nil {
+		c.handlers = map[string]http.Handler{}
+	}
+	c.handlers[strings.ToLower(host)] = h
+}
+
+// Use wraps the handlers with middleware for every later request,
+// including redirect follow-ups, streams and WebSocket dials. The first
+// middleware is the outermost, as in most routers.
+func (c *Client) Use(mw ...func(http.Handler) http.Handler) {
+	c.middleware = append(c.middleware, mw...)
+}
+
+// handlerFor returns the handler serving req, wrapped in the middleware.
+func (c *Client) handlerFor(req *http.Request) http.Handler {
+	h := c.route(req)
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		h = c.middleware[i](h)
+	}
+	return h
+}
+
+func (c *Client) route(req *http.Request) http.Handler {
+	host := strings.ToLower(req.Host)
+	if h, ok := c.handlers[host]; ok {
+		return h
+	}
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		if h, ok := c.handlers[name]; ok {
+			return h
+		}
+	}
+	if c.server != nil {
+		return c.server
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "testclient: no handler for host "+r.Host, http.StatusBadGateway)
+	})
+}
@@ -0,0 +1,101 @@
+package testclient
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func named(name string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, name)
+	})
+}
+
+func body(t *testing.T, res *http.Response) string {
+	t.Helper()
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestHandlersByHost(t *testing.T) {
+	c := New(named("default"), WithHandlers(map[string]http.Handler{
+		"api.example.com":  named("api"),
+		"auth.example.com": named("auth"),
+	}))
+
+	tests := []struct {
+		host string
+		want string
+	}{
+		{"api.example.com", "api"},
+		{"AUTH.example.com", "auth"},
+		{"api.example.com:8080", "api"},
+		{"other.example.com", "default"},
+	}
+	for _, tt := range tests {
+		c.PostForm("/", nil, Host(tt.host))
+		if got := body(t, c.Response()); got != tt.want {
+			t.Errorf("host %s: served by %q, want %q", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestHandlersNoDefault(t *testing.T) {
+	c := New(nil)
+	c.Handle("api.example.com", named("api"))
+	c.PostForm("/", nil, Host("unknown.example.com"))
+	if got := c.Response().StatusCode; got != http.StatusBadGateway {
+		t.Errorf("status = %d, want %d", got, http.StatusBadGateway)
+	}
+}
+
+func TestCrossHostRedirect(t *testing.T) {
+	api := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://auth.example.com/login", http.StatusFound)
+	})
+	c := New(nil, WithHandlers(map[string]http.Handler{
+		"api.example.com":  api,
+		"auth.example.com": named("auth"),
+	}))
+	c.SetHost("api.example.com")
+	c.PostForm("/", nil)
+	if err := c.FollowRedirect(); err != nil {
+		t.Fatal(err)
+	}
+	if got := body(t, c.Response()); got != "auth" {
+		t.Errorf("redirect served by %q, want auth", got)
+	}
+}
+
+func TestUse(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/start" {
+			http.Redirect(w, r, "/end", http.StatusFound)
+			return
+		}
+		io.WriteString(w, strings.Join(r.Header.Values("X-Chain"), ","))
+	})
+	tag := func(name string) func(http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.Header.Add("X-Chain", name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+	c := New(h)
+	c.Use(tag("auth"), tag("tenant"))
+	c.Use(tag("request-id"))
+	c.Request(c.NewRequest(http.MethodGet, "/start", nil))
+	if err := c.FollowRedirect(); err != nil {
+		t.Fatal(err)
+	}
+	if got := body(t, c.Response()); got != "auth,tenant,request-id" {
+		t.Errorf("middleware order on the redirect = %q", got)
+	}
+}
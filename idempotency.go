@@ -0,0 +1,59 @@
+package testclient
+
+import (
+	"net/http"
+	"testing"
+)
+
+// IdempotencyKeyHeader is the header carrying the key of an idempotent
+// request.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// ExpectIdempotent sends req, then replays it with the same
+// Idempotency-Key, and fails the test unless the replay gets the status,
+// Content-Type and body of the first response (JSON bodies compared
+// property by property), and every route of effects, such as the stubbed
+// payment provider behind the handler, served exactly one of the two
+// requests. A key is generated unless req has one.
+func ExpectIdempotent(t testing.TB, c *Client, req *http.Request, effects ...*Route) {
+	t.Helper()
+	body, err := bufferBody(req)
+	if err != nil {
+		t.Fatalf("testclient: reading request body: %v", err)
+		return
+	}
+	if req.Header.Get(IdempotencyKeyHeader) == "" {
+		req.Header.Set(IdempotencyKeyHeader, randomHex(c.random(), 16))
+	}
+	hits := make([]int, len(effects))
+	for i, r := range effects {
+		hits[i] = r.Hits()
+	}
+
+	var res [2]*http.Response
+	for i := range res {
+		if res[i], err = c.Request(withBody(req, body)); err != nil {
+			t.Fatalf("testclient: %s %s: %v", req.Method, req.URL.RequestURI(), err)
+			return
+		}
+	}
+	key := req.Header.Get(IdempotencyKeyHeader)
+	if diff := Diff(replayed(res[0]), replayed(res[1]), DiffOptions{}); len(diff) > 0 {
+		t.Errorf("replaying %s %s with %s %s changed the response (first vs replay):\n%s", req.Method, req.URL.RequestURI(), IdempotencyKeyHeader, key, diff)
+	}
+	for i, r := range effects {
+		if n := r.Hits() - hits[i]; n != 1 {
+			t.Errorf("replaying %s %s with %s %s: expected %s %s to be hit once, got %d", req.Method, req.URL.RequestURI(), IdempotencyKeyHeader, key, r.method, r.path, n)
+		}
+	}
+}
+
+// replayed returns res with only the headers a replay has to repeat.
+func replayed(res *http.Response) *http.Response {
+	out := *res
+	out.Header = http.Header{}
+	if ct := res.Header.Values("Content-Type"); len(ct) > 0 {
+		out.Header["Content-Type"] = ct
+	}
+	return &out
+}
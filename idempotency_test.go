@@ -0,0 +1,71 @@
+package testclient
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// paymentsHandler charges through provider on every payment; with
+// remember, it answers a repeated Idempotency-Key from its cache instead.
+func paymentsHandler(provider http.Handler, remember bool) http.Handler {
+	var mu sync.Mutex
+	seen := map[string]string{}
+	charges := 0
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		key := r.Header.Get(IdempotencyKeyHeader)
+		w.Header().Set("Content-Type", "application/json")
+		if body, ok := seen[key]; ok && remember {
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(http.StatusCreated)
+			io.WriteString(w, body)
+			return
+		}
+		provider.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/charges", r.Body))
+		charges++
+		body := fmt.Sprintf(`{"charge": %d, "amount": 100}`, charges)
+		seen[key] = body
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, body)
+	})
+}
+
+func TestExpectIdempotent(t *testing.T) {
+	provider := NewStub()
+	charge := provider.On("POST", "/charges")
+	c := New(paymentsHandler(provider, true))
+	ExpectIdempotent(t, c, c.NewRequest(http.MethodPost, "/payments", strings.NewReader(`{"amount": 100}`)), charge)
+	if charge.Hits() != 1 {
+		t.Errorf("provider hit %d times", charge.Hits())
+	}
+
+	req := c.NewRequest(http.MethodPost, "/payments", strings.NewReader(`{"amount": 100}`))
+	req.Header.Set(IdempotencyKeyHeader, "order-7")
+	ExpectIdempotent(t, c, req, charge)
+	if c.request.Header.Get(IdempotencyKeyHeader) != "order-7" {
+		t.Errorf("sent key %q", c.request.Header.Get(IdempotencyKeyHeader))
+	}
+}
+
+func TestExpectIdempotentFailure(t *testing.T) {
+	provider := NewStub()
+	charge := provider.On("POST", "/charges")
+	c := New(paymentsHandler(provider, false))
+	ft := &fakeT{}
+	ExpectIdempotent(ft, c, c.NewRequest(http.MethodPost, "/payments", strings.NewReader(`{"amount": 100}`)), charge)
+	if len(ft.failures) != 2 {
+		t.Fatalf("failures = %q", ft.failures)
+	}
+	if !strings.Contains(ft.failures[0], "changed the response (first vs replay):\n$.charge: 1 vs 2") {
+		t.Errorf("failures[0] = %q", ft.failures[0])
+	}
+	if !strings.Contains(ft.failures[1], "expected POST /charges to be hit once, got 2") {
+		t.Errorf("failures[1] = %q", ft.failures[1])
+	}
+}
@@ -0,0 +1,50 @@
+// Code generated by testclient.GenerateRoutes; DO NOT EDIT.
+
+package routesexample
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/raksul/go-testclient"
+)
+
+// Routes sends requests to the routes of the application.
+type Routes struct {
+	*testclient.Client
+}
+
+// GetFilesByPath sends GET /files/{path...}.
+func (r Routes) GetFilesByPath(path any, opts ...testclient.RequestOption) (*http.Response, error) {
+	return r.Do("GET", "/files/{path...}", testclient.Params{"path": path}, nil, opts...)
+}
+
+// DeleteTypesByType sends DELETE /types/{type...}.
+func (r Routes) DeleteTypesByType(typeParam any, opts ...testclient.RequestOption) (*http.Response, error) {
+	return r.Do("DELETE", "/types/{type...}", testclient.Params{"type": typeParam}, nil, opts...)
+}
+
+// GetUsers sends GET /users.
+func (r Routes) GetUsers(opts ...testclient.RequestOption) (*http.Response, error) {
+	return r.Do("GET", "/users", nil, nil, opts...)
+}
+
+// UsersCreate sends POST /users.
+func (r Routes) UsersCreate(body io.Reader, opts ...testclient.RequestOption) (*http.Response, error) {
+	return r.Do("POST", "/users", nil, body, opts...)
+}
+
+// GetUsersByIDOrdersByOrderID sends GET /users/{id}/orders/{order_id}.
+func (r Routes) GetUsersByIDOrdersByOrderID(id any, orderID any, opts ...testclient.RequestOption) (*http.Response, error) {
+	return r.Do("GET", "/users/{id}/orders/{order_id}", testclient.Params{"id": id, "order_id": orderID}, nil, opts...)
+}
+
+// PatchUsersByIDProfile sends PATCH /users/{id}/profile.
+func (r Routes) PatchUsersByIDProfile(id any, body io.Reader, opts ...testclient.RequestOption) (*http.Response, error) {
+	return r.Do("PATCH", "/users/{id}/profile", testclient.Params{"id": id}, body, opts...)
+}
+
+// UsersShow sends GET /users/{id}.
+func (r Routes) UsersShow(id any, opts ...testclient.RequestOption) (*http.Response, error) {
+	return r.Do("GET", "/users/{id}", testclient.Params{"id": id}, nil, opts...)
+}
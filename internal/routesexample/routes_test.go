@@ -0,0 +1,40 @@
+package routesexample
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/raksul/go-testclient"
+)
+
+func TestRoutes(t *testing.T) {
+	echo := func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		io.WriteString(w, r.Method+" "+r.URL.EscapedPath()+" "+string(b))
+	}
+	stub := testclient.NewStub()
+	stub.On("*", "/users/{id}/orders/{order}").ReplyFunc(echo)
+	stub.On("*", "/users/{id}/profile").ReplyFunc(echo)
+	stub.On("*", "/files/{path...}").ReplyFunc(echo)
+	r := Routes{testclient.New(stub)}
+
+	tests := []struct {
+		call func() (*http.Response, error)
+		want string
+	}{
+		{func() (*http.Response, error) { return r.GetUsersByIDOrdersByOrderID(7, "A/1") }, "GET /users/7/orders/A%2F1 "},
+		{func() (*http.Response, error) { return r.GetFilesByPath("docs/read me.md") }, "GET /files/docs/read%20me.md "},
+		{func() (*http.Response, error) { return r.PatchUsersByIDProfile(7, strings.NewReader("name=x")) }, "PATCH /users/7/profile name=x"},
+	}
+	for _, tt := range tests {
+		res, err := tt.call()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b, _ := io.ReadAll(res.Body); string(b) != tt.want {
+			t.Errorf("served %q, want %q", b, tt.want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// Code generated by testclient.GenerateTest from a recorded session.
+
+package testgenexample
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/raksul/go-testclient"
+)
+
+func TestRecordedSession(t *testing.T) {
+	c := testclient.New(NewServer())
+	steps := []struct {
+		method string
+		target string
+		header http.Header
+		body   string
+		status int
+		want   string
+	}{
+		{
+			method: "POST",
+			target: "/signup",
+			header: http.Header{
+				"Content-Type": {"application/x-www-form-urlencoded"},
+			},
+			body:   "name=alice",
+			status: 201,
+			want:   `{"user": "alice"}`,
+		},
+		{
+			method: "GET",
+			target: "/cart",
+			status: 200,
+			want: `cart of alice
+
+`,
+		},
+		{
+			method: "GET",
+			target: "/cart?sku=A-1",
+			header: http.Header{
+				"X-Trace": {"t1"},
+			},
+			status: 200,
+			want: `cart of alice
+A-1
+`,
+		},
+	}
+	for i, step := range steps {
+		req := c.NewRequest(step.method, step.target, strings.NewReader(step.body))
+		for k, vv := range step.header {
+			req.Header[k] = vv
+		}
+		res, err := c.Request(req)
+		if err != nil {
+			t.Fatalf("step %d: %s %s: %v", i+1, step.method, step.target, err)
+		}
+		if res.StatusCode != step.status {
+			t.Fatalf("step %d: %s %s: expected status %d, got %d", i+1, step.method, step.target, step.status, res.StatusCode)
+		}
+		if got := string(c.BodyBytes()); step.want != "" && got != step.want {
+			t.Errorf("step %d: %s %s: expected body %q, got %q", i+1, step.method, step.target, step.want, got)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+// Package testgenexample holds a test generated by testclient.GenerateTest
+// from a session against the server it declares.
+package testgenexample
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// NewServer returns a small shop: sign up, then add an item to the cart of
+// the signed-up user.
+func NewServer() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/signup", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		name := r.FormValue("name")
+		http.SetCookie(w, &http.Cookie{Name: "user", Value: name, Path: "/"})
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"user": %q}`, name)
+	})
+	mux.HandleFunc("/cart", func(w http.ResponseWriter, r *http.Request) {
+		user, err := r.Cookie("user")
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintf(w, "cart of %s\n%s\n", user.Value, r.URL.Query().Get("sku"))
+	})
+	return mux
+}
@@ -0,0 +1,207 @@
+package testclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ClearCookies empties the cookie jar, as if the browser were restarted
+// without saved cookies.
+func (c *Client) ClearCookies() {
+	c.jar.clear()
+}
+
+// DeleteCookie removes the cookies called name from the jar, for every
+// domain and path.
+func (c *Client) DeleteCookie(name string) {
+	c.jar.delete(name)
+}
+
+// Logout posts to the logout endpoint at path and checks that it
+// invalidated the session: each cookie in names, or every cookie the request
+// carried if names is empty, must have been expired or cleared by the
+// response. It returns the response, and an error naming the cookies still
+// in the jar.
+func (c *Client) Logout(path string, names ...string) (*http.Response, error) {
+	req := c.NewRequest(http.MethodPost, path, nil)
+	res, err := c.Request(req)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		for _, cookie := range req.Cookies() {
+			names = append(names, cookie.Name)
+		}
+	}
+	remaining := map[string]bool{}
+	for _, cookie := range c.jar.Cookies(requestURL(req)) {
+		remaining[cookie.Name] = true
+	}
+	var kept []string
+	for _, name := range names {
+		if remaining[name] {
+			kept = append(kept, name)
+		}
+	}
+	if len(kept) > 0 {
+		return res, fmt.Errorf("testclient: logout %s left cookies set: %s", path, strings.Join(kept, ", "))
+	}
+	return res, nil
+}
+
+// clockJar is the client's cookie jar. net/http/cookiejar evaluates expiry
+// against real time, so clockJar hands it session cookies only and expires
+// them itself against the client clock. It also remembers where each cookie
+// came from, which cookiejar does not expose, so cookies can be deleted by
+// name.
+type clockJar struct {
+	jar   *cookiejar.Jar
+	clock func() time.Time
+
+	mu      sync.Mutex
+	cookies map[jarKey]jarEntry
+}
+
+type jarKey struct {
+	domain, path, name string
+}
+
+type jarEntry struct {
+	value    string
+	hostOnly bool
+	expires  time.Time
+	origin   *url.URL
+}
+
+func newClockJar(clock func() time.Time) *clockJar {
+	jar, _ := cookiejar.New(nil) // never fails with nil options
+	return &clockJar{jar: jar, clock: clock, cookies: map[jarKey]jarEntry{}}
+}
+
+func (j *clockJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	now := j.clock()
+	pass := make([]*http.Cookie, 0, len(cookies))
+	j.mu.Lock()
+	for _, c := range cookies {
+		c := *c
+		key := jarKey{domain: strings.TrimPrefix(strings.ToLower(c.Domain), "."), path: c.Path, name: c.Name}
+		hostOnly := key.domain == ""
+		if hostOnly {
+			key.domain = strings.ToLower(u.Hostname())
+		}
+		if key.path == "" || key.path[0] != '/' {
+			key.path = defaultCookiePath(u.Path)
+		}
+
+		var expires time.Time
+		switch {
+		case c.MaxAge < 0:
+			expires = now
+		case c.MaxAge > 0:
+			expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+		case !c.Expires.IsZero():
+			expires = c.Expires
+		}
+		delete(j.cookies, key)
+		if !expires.IsZero() && !expires.After(now) {
+			c.MaxAge, c.Expires = -1, time.Time{}
+		} else {
+			c.MaxAge, c.Expires, c.RawExpires = 0, time.Time{}, ""
+			j.cookies[key] = jarEntry{value: c.Value, hostOnly: hostOnly, expires: expires, origin: u}
+		}
+		pass = append(pass, &c)
+	}
+	j.mu.Unlock()
+	j.jar.SetCookies(u, pass)
+}
+
+func (j *clockJar) Cookies(u *url.URL) []*http.Cookie {
+	cookies := j.jar.Cookies(u)
+	now := j.clock()
+	host := strings.ToLower(u.Hostname())
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+
+	j.mu.Lock()
+	expired := map[jarKey]jarEntry{}
+	kept := cookies[:0]
+	for _, c := range cookies {
+		drop := false
+		for key, e := range j.cookies {
+			if !e.expires.IsZero() && !e.expires.After(now) && key.name == c.Name && e.value == c.Value && e.matches(key, host, path) {
+				drop = true
+				expired[key] = e
+				delete(j.cookies, key)
+			}
+		}
+		if !drop {
+			kept = append(kept, c)
+		}
+	}
+	j.mu.Unlock()
+
+	for key, e := range expired {
+		j.remove(key, e)
+	}
+	return kept
+}
+
+// remove deletes the cookie stored under key from the underlying jar.
+func (j *clockJar) remove(key jarKey, e jarEntry) {
+	del := &http.Cookie{Name: key.name, Path: key.path, MaxAge: -1}
+	if !e.hostOnly {
+		del.Domain = key.domain
+	}
+	j.jar.SetCookies(e.origin, []*http.Cookie{del})
+}
+
+// delete removes every cookie called name, whatever its domain and path.
+func (j *clockJar) delete(name string) {
+	j.mu.Lock()
+	found := map[jarKey]jarEntry{}
+	for key, e := range j.cookies {
+		if key.name == name {
+			found[key] = e
+			delete(j.cookies, key)
+		}
+	}
+	j.mu.Unlock()
+	for key, e := range found {
+		j.remove(key, e)
+	}
+}
+
+// clear removes all cookies.
+func (j *clockJar) clear() {
+	jar, _ := cookiejar.New(nil)
+	j.mu.Lock()
+	j.jar, j.cookies = jar, map[jarKey]jarEntry{}
+	j.mu.Unlock()
+}
+
+func (e jarEntry) matches(key jarKey, host, path string) bool {
+	if e.hostOnly {
+		if host != key.domain {
+			return false
+		}
+	} else if host != key.domain && !strings.HasSuffix(host, "."+key.domain) {
+		return false
+	}
+	return path == key.path || strings.HasPrefix(path, strings.TrimSuffix(key.path, "/")+"/")
+}
+
+// defaultCookiePath is the default-path of RFC 6265 section 5.1.4.
+func defaultCookiePath(p string) string {
+	i := strings.LastIndex(p, "/")
+	if p == "" || p[0] != '/' || i == 0 {
+		return "/"
+	}
+	return p[:i]
+}
@@ -0,0 +1,71 @@
+package testclient
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// sessionApp sets cookies on /login, clears them on /logout if asked to,
+// and echoes the cookies it receives.
+func sessionApp(clearOnLogout bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "s", Path: "/"})
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "api", Path: "/api", Domain: "example.com"})
+			http.SetCookie(w, &http.Cookie{Name: "theme", Value: "dark", Path: "/"})
+		case "/logout":
+			if clearOnLogout {
+				http.SetCookie(w, &http.Cookie{Name: "session", Path: "/", MaxAge: -1})
+			}
+		}
+		var names []string
+		for _, c := range r.Cookies() {
+			names = append(names, c.Name+"="+c.Value)
+		}
+		w.Write([]byte(strings.Join(names, ";")))
+	})
+}
+
+func cookiesAt(t *testing.T, c *Client, path string) string {
+	t.Helper()
+	res, err := c.Request(c.NewRequest(http.MethodGet, path, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body(t, res)
+}
+
+func TestDeleteCookie(t *testing.T) {
+	c := New(sessionApp(false))
+	cookiesAt(t, c, "/login")
+	if got := cookiesAt(t, c, "/api/x"); got != "session=api;session=s;theme=dark" {
+		t.Fatalf("cookies = %q", got)
+	}
+	c.DeleteCookie("session")
+	if got := cookiesAt(t, c, "/api/x"); got != "theme=dark" {
+		t.Errorf("cookies after DeleteCookie = %q, want theme only", got)
+	}
+	c.ClearCookies()
+	if got := cookiesAt(t, c, "/api/x"); got != "" {
+		t.Errorf("cookies after ClearCookies = %q, want none", got)
+	}
+}
+
+func TestLogout(t *testing.T) {
+	c := New(sessionApp(true))
+	cookiesAt(t, c, "/login")
+	if _, err := c.Logout("/logout", "session"); err != nil {
+		t.Errorf("Logout: %v", err)
+	}
+	if got := cookiesAt(t, c, "/"); got != "theme=dark" {
+		t.Errorf("cookies after logout = %q", got)
+	}
+
+	c = New(sessionApp(false))
+	cookiesAt(t, c, "/login")
+	if _, err := c.Logout("/logout"); err == nil || !strings.Contains(err.Error(), "session, theme") {
+		t.Errorf("Logout err = %v, want session and theme reported", err)
+	}
+}
@@ -0,0 +1,20 @@
+package testclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+)
+
+// PostJSON sends payload encoded as JSON to uri.
+func (c *Client) PostJSON(uri string, payload any, opts ...RequestOption) error {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req := c.NewRequest(http.MethodPost, uri, bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+
+	_, err = c.Request(req, opts...)
+	return err
+}
@@ -0,0 +1,50 @@
+package testclient
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestPostJSON(t *testing.T) {
+	var got map[string]any
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+	})
+	if err := New(h).PostJSON("/", map[string]any{"name": "alice"}); err != nil {
+		t.Fatal(err)
+	}
+	if got["name"] != "alice" {
+		t.Errorf("handler decoded %v", got)
+	}
+	if err := New(h).PostJSON("/", make(chan int)); err == nil {
+		t.Error("PostJSON accepted an unencodable payload")
+	}
+}
+
+func TestGzipRequest(t *testing.T) {
+	var got map[string]any
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ce := r.Header.Get("Content-Encoding"); ce != "gzip" {
+			t.Errorf("Content-Encoding = %q, want gzip", ce)
+		}
+		if r.ContentLength <= 0 {
+			t.Errorf("ContentLength = %d", r.ContentLength)
+		}
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Fatalf("body is not gzip: %v", err)
+		}
+		json.NewDecoder(zr).Decode(&got)
+	})
+	if err := New(h).PostJSON("/", map[string]any{"name": "alice"}, Gzip()); err != nil {
+		t.Fatal(err)
+	}
+	if got["name"] != "alice" {
+		t.Errorf("handler decoded %v", got)
+	}
+}
@@ -0,0 +1,164 @@
+package testclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// JSONAPIMediaType is the media type of JSON:API documents.
+const JSONAPIMediaType = "application/vnd.api+json"
+
+// Resource is a JSON:API resource object.
+type Resource struct {
+	Type          string                  `json:"type"`
+	ID            string                  `json:"id,omitempty"`
+	Attributes    map[string]any          `json:"attributes,omitempty"`
+	Relationships map[string]Relationship `json:"relationships,omitempty"`
+	Links         map[string]any          `json:"links,omitempty"`
+	Meta          map[string]any          `json:"meta,omitempty"`
+}
+
+// ResourceID identifies a resource in a relationship.
+type ResourceID struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// Relationship is a relationship object. Data is a ResourceID, a
+// []ResourceID or nil for an empty to-one relationship.
+type Relationship struct {
+	Data  any            `json:"data"`
+	Links map[string]any `json:"links,omitempty"`
+}
+
+// ToOne returns a to-one relationship to the resource typ/id.
+func ToOne(typ, id string) Relationship {
+	return Relationship{Data: ResourceID{Type: typ, ID: id}}
+}
+
+// ToMany returns a to-many relationship to resources of typ.
+func ToMany(typ string, ids ...string) Relationship {
+	data := make([]ResourceID, len(ids))
+	for i, id := range ids {
+		data[i] = ResourceID{Type: typ, ID: id}
+	}
+	return Relationship{Data: data}
+}
+
+// JSONAPIError is an entry of the errors array.
+type JSONAPIError struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	Code   string `json:"code"`
+	Title  string `json:"title"`
+	Detail string `json:"detail"`
+	Source struct {
+		Pointer   string `json:"pointer"`
+		Parameter string `json:"parameter"`
+	} `json:"source"`
+}
+
+// JSONAPIDocument is a decoded JSON:API response document.
+type JSONAPIDocument struct {
+	Data     json.RawMessage `json:"data"`
+	Included []Resource      `json:"included"`
+	Links    map[string]any  `json:"links"`
+	Errors   []JSONAPIError  `json:"errors"`
+	Meta     map[string]any  `json:"meta"`
+}
+
+// Resource decodes Data as a single resource.
+func (d *JSONAPIDocument) Resource() (Resource, error) {
+	var r Resource
+	err := json.Unmarshal(d.Data, &r)
+	return r, err
+}
+
+// Resources decodes Data as a collection.
+func (d *JSONAPIDocument) Resources() ([]Resource, error) {
+	var rs []Resource
+	err := json.Unmarshal(d.Data, &rs)
+	return rs, err
+}
+
+// JSONAPI sends r, if not nil, as the primary data of a JSON:API document
+// with the JSON:API Content-Type and Accept headers, and decodes the
+// response document. The HTTP response stays available through Response.
+func (c *Client) JSONAPI(method, uri string, r *Resource, opts ...RequestOption) (*JSONAPIDocument, error) {
+	var body io.Reader
+	if r != nil {
+		b, err := json.Marshal(map[string]any{"data": r})
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(b)
+	}
+	req := c.NewRequest(method, uri, body)
+	if r != nil {
+		req.Header.Set("Content-Type", JSONAPIMediaType)
+	}
+	req.Header.Set("Accept", JSONAPIMediaType)
+	if _, err := c.Request(req, opts...); err != nil {
+		return nil, err
+	}
+	if len(c.BodyBytes()) == 0 {
+		// 204 No Content and friends
+		return &JSONAPIDocument{}, nil
+	}
+	var doc JSONAPIDocument
+	if err := json.Unmarshal(c.BodyBytes(), &doc); err != nil {
+		return nil, fmt.Errorf("testclient: decoding JSON:API document (status %d): %w", c.response.StatusCode, err)
+	}
+	return &doc, nil
+}
+
+// ExpectIncluded fails the test unless the document includes the resource
+// typ/id, and returns it.
+func ExpectIncluded(t testing.TB, doc *JSONAPIDocument, typ, id string) Resource {
+	t.Helper()
+	var got []string
+	for _, r := range doc.Included {
+		if r.Type == typ && r.ID == id {
+			return r
+		}
+		got = append(got, r.Type+"/"+r.ID)
+	}
+	t.Fatalf("expected included resource %s/%s, got %v", typ, id, got)
+	return Resource{}
+}
+
+// ExpectLink fails the test unless the document has the top-level link
+// name, and returns its href. Links may be strings or link objects.
+func ExpectLink(t testing.TB, doc *JSONAPIDocument, name string) string {
+	t.Helper()
+	switch l := doc.Links[name].(type) {
+	case string:
+		return l
+	case map[string]any:
+		if href, ok := l["href"].(string); ok {
+			return href
+		}
+	}
+	t.Fatalf("expected link %s, got %v", name, doc.Links)
+	return ""
+}
+
+// ExpectJSONAPIError fails the test unless the document has an error with
+// the given status and code; an empty code matches any. It returns the
+// error for further checks.
+func ExpectJSONAPIError(t testing.TB, doc *JSONAPIDocument, status int, code string) JSONAPIError {
+	t.Helper()
+	var got []string
+	for _, e := range doc.Errors {
+		if e.Status == fmt.Sprint(status) && (code == "" || e.Code == code) {
+			return e
+		}
+		got = append(got, fmt.Sprintf("%s %s: %s", e.Status, e.Code, e.Detail))
+	}
+	t.Fatalf("expected JSON:API error %d %s, got [%s]", status, code, strings.Join(got, "; "))
+	return JSONAPIError{}
+}
@@ -0,0 +1,70 @@
+package testclient
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestJSONAPI(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != JSONAPIMediaType {
+			t.Errorf("Accept = %q", r.Header.Get("Accept"))
+		}
+		w.Header().Set("Content-Type", JSONAPIMediaType)
+		if r.Method == http.MethodGet {
+			w.Write([]byte(`{"data":[{"type":"articles","id":"1","attributes":{"title":"Hi"},"relationships":{"author":{"data":{"type":"people","id":"9"}}}}],
+				"included":[{"type":"people","id":"9","attributes":{"name":"Dan"}}],
+				"links":{"self":"/articles","next":{"href":"/articles?page=2"}}}`))
+			return
+		}
+		var in struct{ Data Resource }
+		json.NewDecoder(r.Body).Decode(&in)
+		if r.Header.Get("Content-Type") != JSONAPIMediaType || in.Data.Type != "articles" {
+			t.Errorf("request = %q %+v", r.Header.Get("Content-Type"), in)
+		}
+		if ids, _ := in.Data.Relationships["tags"].Data.([]any); len(ids) != 2 {
+			t.Errorf("tags relationship = %v", in.Data.Relationships["tags"])
+		}
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"errors":[{"status":"422","code":"blank","detail":"title can't be blank","source":{"pointer":"/data/attributes/title"}}]}`))
+	})
+	c := New(h)
+
+	doc, err := c.JSONAPI(http.MethodGet, "/articles", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	articles, err := doc.Resources()
+	if err != nil || len(articles) != 1 || articles[0].Attributes["title"] != "Hi" {
+		t.Fatalf("Resources = %+v, %v", articles, err)
+	}
+	author := ExpectIncluded(t, doc, "people", "9")
+	if author.Attributes["name"] != "Dan" {
+		t.Errorf("author = %+v", author)
+	}
+	if next := ExpectLink(t, doc, "next"); next != "/articles?page=2" {
+		t.Errorf("next = %q", next)
+	}
+
+	doc, err = c.JSONAPI(http.MethodPost, "/articles", &Resource{
+		Type:          "articles",
+		Attributes:    map[string]any{"title": ""},
+		Relationships: map[string]Relationship{"author": ToOne("people", "9"), "tags": ToMany("tags", "a", "b")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := ExpectJSONAPIError(t, doc, 422, "blank"); e.Source.Pointer != "/data/attributes/title" {
+		t.Errorf("error = %+v", e)
+	}
+
+	ft := &fakeT{}
+	ExpectJSONAPIError(ft, doc, 409, "")
+	ExpectIncluded(ft, doc, "people", "1")
+	ExpectLink(ft, doc, "self")
+	if len(ft.failures) != 3 || !strings.Contains(ft.failures[0], "422 blank: title can't be blank") {
+		t.Errorf("failures = %q", ft.failures)
+	}
+}
@@ -0,0 +1,180 @@
+package testclient
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"time"
+)
+
+// JWT describes a test token. Zero fields are left out of the claims, except
+// that IssuedAt defaults to now and ExpiresIn to one hour.
+type JWT struct {
+	Sub string
+	Iss string
+	Aud string
+	// Claims holds additional claims; they override the fields above.
+	Claims map[string]any
+
+	IssuedAt  time.Time
+	ExpiresIn time.Duration
+
+	// Key is a []byte for HS*, an *rsa.PrivateKey for RS* and an
+	// *ecdsa.PrivateKey for ES*.
+	Key any
+	// Alg defaults to HS256, RS256 or ES256 depending on Key.
+	Alg   string
+	KeyID string
+	// Rand is the source of the jti claim; nil uses crypto/rand. ES*
+	// signatures draw from crypto/rand regardless.
+	Rand io.Reader
+}
+
+// Sign encodes and signs the token.
+func (j JWT) Sign() (string, error) {
+	alg := j.Alg
+	if alg == "" {
+		switch j.Key.(type) {
+		case *rsa.PrivateKey:
+			alg = "RS256"
+		case *ecdsa.PrivateKey:
+			alg = "ES256"
+		default:
+			alg = "HS256"
+		}
+	}
+
+	header := map[string]any{"alg": alg, "typ": "JWT"}
+	if j.KeyID != "" {
+		header["kid"] = j.KeyID
+	}
+	iat := j.IssuedAt
+	if iat.IsZero() {
+		iat = time.Now()
+	}
+	exp := j.ExpiresIn
+	if exp == 0 {
+		exp = time.Hour
+	}
+	var jti [16]byte
+	r := j.Rand
+	if r == nil {
+		r = rand.Reader
+	}
+	if _, err := io.ReadFull(r, jti[:]); err != nil {
+		return "", err
+	}
+	claims := map[string]any{
+		"iat": iat.Unix(),
+		"exp": iat.Add(exp).Unix(),
+		"jti": hex.EncodeToString(jti[:]),
+	}
+	for k, v := range map[string]string{"sub": j.Sub, "iss": j.Iss, "aud": j.Aud} {
+		if v != "" {
+			claims[k] = v
+		}
+	}
+	for k, v := range j.Claims {
+		claims[k] = v
+	}
+
+	h, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	p, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	input := b64(h) + "." + b64(p)
+	sig, err := signJWT(alg, j.Key, []byte(input))
+	if err != nil {
+		return "", err
+	}
+	return input + "." + b64(sig), nil
+}
+
+func signJWT(alg string, key any, input []byte) ([]byte, error) {
+	if alg == "none" {
+		return nil, nil
+	}
+	if len(alg) != 5 {
+		return nil, fmt.Errorf("jwt: unsupported alg %q", alg)
+	}
+	var newHash func() hash.Hash
+	var ch crypto.Hash
+	switch alg[2:] {
+	case "256":
+		newHash, ch = sha256.New, crypto.SHA256
+	case "384":
+		newHash, ch = sha512.New384, crypto.SHA384
+	case "512":
+		newHash, ch = sha512.New, crypto.SHA512
+	default:
+		return nil, fmt.Errorf("jwt: unsupported alg %q", alg)
+	}
+
+	switch alg[:2] {
+	case "HS":
+		k, ok := key.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("jwt: %s needs a []byte key, got %T", alg, key)
+		}
+		mac := hmac.New(newHash, k)
+		mac.Write(input)
+		return mac.Sum(nil), nil
+	case "RS":
+		k, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("jwt: %s needs an *rsa.PrivateKey, got %T", alg, key)
+		}
+		h := newHash()
+		h.Write(input)
+		return rsa.SignPKCS1v15(rand.Reader, k, ch, h.Sum(nil))
+	case "ES":
+		k, ok := key.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("jwt: %s needs an *ecdsa.PrivateKey, got %T", alg, key)
+		}
+		h := newHash()
+		h.Write(input)
+		r, s, err := ecdsa.Sign(rand.Reader, k, h.Sum(nil))
+		if err != nil {
+			return nil, err
+		}
+		size := (k.Curve.Params().BitSize + 7) / 8
+		sig := make([]byte, 2*size)
+		r.FillBytes(sig[:size])
+		s.FillBytes(sig[size:])
+		return sig, nil
+	}
+	return nil, fmt.Errorf("jwt: unsupported alg %q", alg)
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// UseJWT signs j and sends it as the bearer token of every subsequent
+// request.
+func (c *Client) UseJWT(j JWT) error {
+	if j.Rand == nil && c.rand != nil {
+		j.Rand = c.rand
+	}
+	token, err := j.Sign()
+	if err != nil {
+		return err
+	}
+	c.SetBearerToken(token)
+	return nil
+}
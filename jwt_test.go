@@ -0,0 +1,124 @@
+package testclient
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func decodeJWT(t *testing.T, token string) (header, claims map[string]any, input, sig []byte) {
+	t.Helper()
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("token has %d parts", len(parts))
+	}
+	for i, v := range []*map[string]any{&header, &claims} {
+		b, err := base64.RawURLEncoding.DecodeString(parts[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(b, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return header, claims, []byte(parts[0] + "." + parts[1]), sig
+}
+
+func TestJWTHS256(t *testing.T) {
+	key := []byte("secret")
+	iat := time.Unix(1700000000, 0)
+	token, err := JWT{Sub: "user-1", Iss: "test", Key: key, IssuedAt: iat, ExpiresIn: time.Minute,
+		Claims: map[string]any{"role": "admin"}}.Sign()
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, claims, input, sig := decodeJWT(t, token)
+	if header["alg"] != "HS256" {
+		t.Errorf("alg = %v", header["alg"])
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(input)
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		t.Error("bad HS256 signature")
+	}
+	if claims["sub"] != "user-1" || claims["iss"] != "test" || claims["role"] != "admin" {
+		t.Errorf("claims = %v", claims)
+	}
+	if claims["iat"] != float64(iat.Unix()) || claims["exp"] != float64(iat.Unix()+60) {
+		t.Errorf("iat/exp = %v/%v", claims["iat"], claims["exp"])
+	}
+	if claims["jti"] == "" {
+		t.Error("no jti")
+	}
+}
+
+func TestJWTAsymmetric(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	token, err := JWT{Sub: "u", Key: rsaKey, KeyID: "k1"}.Sign()
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, _, input, sig := decodeJWT(t, token)
+	if header["alg"] != "RS256" || header["kid"] != "k1" {
+		t.Errorf("header = %v", header)
+	}
+	sum := sha256.Sum256(input)
+	if err := rsa.VerifyPKCS1v15(&rsaKey.PublicKey, crypto.SHA256, sum[:], sig); err != nil {
+		t.Errorf("bad RS256 signature: %v", err)
+	}
+
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	token, err = JWT{Sub: "u", Key: ecKey}.Sign()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, input, sig = decodeJWT(t, token)
+	sum = sha256.Sum256(input)
+	r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+	if len(sig) != 64 || !ecdsa.Verify(&ecKey.PublicKey, sum[:], r, s) {
+		t.Error("bad ES256 signature")
+	}
+}
+
+func TestJWTBadKey(t *testing.T) {
+	if _, err := (JWT{Alg: "RS256", Key: []byte("x")}).Sign(); err == nil {
+		t.Error("RS256 accepted a []byte key")
+	}
+	if _, err := (JWT{Alg: "XX1", Key: []byte("x")}).Sign(); err == nil {
+		t.Error("unknown alg accepted")
+	}
+}
+
+func TestUseJWT(t *testing.T) {
+	var auth string
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+	})
+	c := New(h)
+	if err := c.UseJWT(JWT{Sub: "user-1", Key: []byte("k")}); err != nil {
+		t.Fatal(err)
+	}
+	c.PostForm("/", nil)
+	token, ok := strings.CutPrefix(auth, "Bearer ")
+	if !ok {
+		t.Fatalf("Authorization = %q", auth)
+	}
+	if _, claims, _, _ := decodeJWT(t, token); claims["sub"] != "user-1" {
+		t.Errorf("sub = %v", claims["sub"])
+	}
+}
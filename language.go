@@ -0,0 +1,48 @@
+package testclient
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// SetLanguage sends lang as the Accept-Language of every subsequent
+// request. lang is a single tag such as "ja-JP" or a full weighted list.
+func (c *Client) SetLanguage(lang string) {
+	c.SetHeader("Accept-Language", lang)
+}
+
+// Language sets the Accept-Language of a single request.
+func Language(lang string) RequestOption {
+	return Header("Accept-Language", lang)
+}
+
+// ExpectLanguage fails the test unless the Content-Language of res lists
+// lang. Tags compare case-insensitively, as BCP 47 tags do.
+func ExpectLanguage(t testing.TB, res *http.Response, lang string) {
+	t.Helper()
+	got := headerValues(res.Header, "Content-Language")
+	if !containsFold(got, lang) {
+		t.Errorf("expected Content-Language %s, got %q", lang, strings.Join(got, ", "))
+	}
+}
+
+// ExpectLanguageGolden checks the Content-Language of res like
+// ExpectLanguage and compares its body with the golden file for lang in
+// dir, named after the tag, e.g. testdata/welcome/ja-JP.golden.
+func ExpectLanguageGolden(t testing.TB, res *http.Response, lang, dir string) {
+	t.Helper()
+	ExpectLanguage(t, res, lang)
+	path := filepath.Join(dir, lang+".golden")
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("testclient: reading golden file: %v", err)
+		return
+	}
+	if got := bufferedBody(res).data; !bytes.Equal(got, want) {
+		t.Errorf("body does not match %s:\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
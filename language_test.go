@@ -0,0 +1,42 @@
+package testclient
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func welcomeHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.Header.Get("Accept-Language"), "ja") {
+			w.Header().Set("Content-Language", "ja-JP")
+			w.Write([]byte("ようこそ\n"))
+			return
+		}
+		w.Header().Set("Content-Language", "en-US")
+		w.Write([]byte("Welcome\n"))
+	})
+}
+
+func TestSetLanguage(t *testing.T) {
+	c := New(welcomeHandler())
+	c.SetLanguage("ja-JP")
+	res, err := c.Request(c.NewRequest(http.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ExpectLanguageGolden(t, res, "ja-JP", "testdata/welcome")
+
+	res, err = c.Request(c.NewRequest(http.MethodGet, "/", nil), Language("en-US,en;q=0.8"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ExpectLanguageGolden(t, res, "en-US", "testdata/welcome")
+
+	ft := &fakeT{TB: t}
+	ExpectLanguage(ft, res, "ja-JP")
+	ExpectLanguageGolden(ft, res, "fr-FR", "testdata/welcome")
+	if len(ft.failures) != 3 || !strings.Contains(ft.failures[0], `expected Content-Language ja-JP, got "en-US"`) || !strings.Contains(ft.failures[2], "golden file") {
+		t.Errorf("failures = %q", ft.failures)
+	}
+}
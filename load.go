@@ -0,0 +1,137 @@
+package testclient
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Load describes a load run. Requests builds the i-th request, so a mix of
+// endpoints can be chosen by i. Concurrency workers, each with its own
+// session, send requests for Duration of real time, together at most Rate
+// requests per second; a zero Rate sends as fast as the handler allows.
+type Load struct {
+	Requests    func(i int) *http.Request
+	Duration    time.Duration
+	Concurrency int
+	Rate        float64
+}
+
+// LoadReport is the outcome of a load run. Latencies are the time the
+// handler took to serve a request. Responses with a 5xx status and requests
+// that failed count as errors.
+type LoadReport struct {
+	Requests   int
+	Errors     int
+	Elapsed    time.Duration
+	Throughput float64 // requests per second
+	ErrorRate  float64
+	P50        time.Duration
+	P95        time.Duration
+	P99        time.Duration
+	Max        time.Duration
+}
+
+func (r LoadReport) String() string {
+	return fmt.Sprintf("%d requests in %v (%.1f/s), %.2f%% errors, latency p50 %v p95 %v p99 %v max %v",
+		r.Requests, r.Elapsed.Round(time.Millisecond), r.Throughput, 100*r.ErrorRate, r.P50, r.P95, r.P99, r.Max)
+}
+
+// Load runs l against the client's handler and reports throughput, error
+// rate and latency percentiles.
+func (c *Client) Load(l Load) LoadReport {
+	workers := l.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	var tokens chan struct{}
+	stop := make(chan struct{})
+	if l.Rate > 0 {
+		tokens = make(chan struct{})
+		go func() {
+			ticker := time.NewTicker(time.Duration(float64(time.Second) / l.Rate))
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					select {
+					case tokens <- struct{}{}:
+					case <-stop:
+						return
+					}
+				case <-stop:
+					return
+				}
+			}
+		}()
+	}
+
+	var (
+		next   int64 = -1
+		failed int64
+		mu     sync.Mutex
+		lats   []time.Duration
+		wg     sync.WaitGroup
+	)
+	start := time.Now()
+	deadline := start.Add(l.Duration)
+	timer := time.AfterFunc(l.Duration, func() { close(stop) })
+	defer timer.Stop()
+	for w := 0; w < workers; w++ {
+		s := c.session()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var mine []time.Duration
+			defer func() {
+				mu.Lock()
+				lats = append(lats, mine...)
+				mu.Unlock()
+			}()
+			for time.Now().Before(deadline) {
+				if tokens != nil {
+					select {
+					case <-tokens:
+					case <-stop:
+						return
+					}
+				}
+				req := l.Requests(int(atomic.AddInt64(&next, 1)))
+				res, err := s.Request(req)
+				mine = append(mine, s.LastDuration())
+				if err != nil || res.StatusCode >= 500 {
+					atomic.AddInt64(&failed, 1)
+				}
+				if err == nil {
+					io.Copy(io.Discard, res.Body)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	r := LoadReport{Requests: len(lats), Errors: int(failed), Elapsed: time.Since(start)}
+	if r.Requests == 0 {
+		return r
+	}
+	sort.Slice(lats, func(i, j int) bool { return lats[i] < lats[j] })
+	r.Throughput = float64(r.Requests) / r.Elapsed.Seconds()
+	r.ErrorRate = float64(r.Errors) / float64(r.Requests)
+	r.P50, r.P95, r.P99 = percentile(lats, 0.50), percentile(lats, 0.95), percentile(lats, 0.99)
+	r.Max = lats[len(lats)-1]
+	return r
+}
+
+// percentile returns the nearest-rank percentile p of sorted.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
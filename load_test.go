@@ -0,0 +1,47 @@
+package testclient
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	s := NewStub()
+	s.On("GET", "/ok").Reply(http.StatusOK, "ok")
+	s.On("GET", "/fail").Reply(http.StatusInternalServerError, "")
+	c := New(s)
+	paths := []string{"/ok", "/ok", "/ok", "/fail"}
+	r := c.Load(Load{
+		Requests: func(i int) *http.Request {
+			return c.NewRequest(http.MethodGet, paths[i%len(paths)], nil)
+		},
+		Duration:    50 * time.Millisecond,
+		Concurrency: 4,
+	})
+	if r.Requests < 8 {
+		t.Fatalf("only %d requests", r.Requests)
+	}
+	if r.ErrorRate < 0.15 || r.ErrorRate > 0.35 {
+		t.Errorf("error rate = %v, want about 0.25", r.ErrorRate)
+	}
+	if r.Throughput <= 0 || r.P50 > r.P95 || r.P95 > r.P99 || r.P99 > r.Max {
+		t.Errorf("inconsistent report: %v", r)
+	}
+	if !strings.Contains(r.String(), "errors") {
+		t.Errorf("String() = %q", r.String())
+	}
+}
+
+func TestLoadRate(t *testing.T) {
+	c := New(NewStub())
+	r := c.Load(Load{
+		Requests: func(int) *http.Request { return c.NewRequest(http.MethodGet, "/", nil) },
+		Duration: 100 * time.Millisecond,
+		Rate:     100,
+	})
+	if r.Requests < 3 || r.Requests > 11 {
+		t.Errorf("%d requests in 100ms at 100/s, want about 10", r.Requests)
+	}
+}
@@ -0,0 +1,149 @@
+package testclient
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// DefaultLatencyBuckets are the upper bounds of the latency histogram of
+// Metrics, those the Prometheus client uses by default.
+var DefaultLatencyBuckets = []time.Duration{
+	5 * time.Millisecond, 10 * time.Millisecond, 25 * time.Millisecond, 50 * time.Millisecond,
+	100 * time.Millisecond, 250 * time.Millisecond, 500 * time.Millisecond,
+	time.Second, 2500 * time.Millisecond, 5 * time.Second, 10 * time.Second,
+}
+
+// RequestKey groups requests by method and response status.
+type RequestKey struct {
+	Method string
+	Status int
+}
+
+// Histogram is a latency histogram.
+type Histogram struct {
+	// Bounds are the upper bounds of the buckets, in increasing order.
+	Bounds []time.Duration
+	// Counts are the observations up to each bound, cumulative as in
+	// Prometheus; those above the last bound are only in Count.
+	Counts []int
+	Count  int
+	Sum    time.Duration
+	Max    time.Duration
+}
+
+func (h *Histogram) observe(d time.Duration) {
+	h.Count++
+	h.Sum += d
+	if d > h.Max {
+		h.Max = d
+	}
+	for i, b := range h.Bounds {
+		if d <= b {
+			h.Counts[i]++
+		}
+	}
+}
+
+// Metrics is a snapshot of the requests served for a client.
+type Metrics struct {
+	// Requests counts the requests served by method and status, each retry
+	// attempt and redirect hop on its own. Status 0 counts those that got
+	// no response, as the handler panicked or dropped the connection.
+	Requests map[RequestKey]int
+	// Latency is the histogram of how long the handler took.
+	Latency Histogram
+}
+
+// Total returns the number of requests served.
+func (m Metrics) Total() int {
+	return m.Latency.Count
+}
+
+// Count returns the number of requests of method, or of any method if it
+// is empty, answered with status.
+func (m Metrics) Count(method string, status int) int {
+	n := 0
+	for k, v := range m.Requests {
+		if (method == "" || k.Method == method) && k.Status == status {
+			n += v
+		}
+	}
+	return n
+}
+
+// metrics accumulates Metrics, shared by a client and its burst sessions.
+type metrics struct {
+	mu       sync.Mutex
+	requests map[RequestKey]int
+	latency  Histogram
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		requests: map[RequestKey]int{},
+		latency:  Histogram{Bounds: DefaultLatencyBuckets, Counts: make([]int, len(DefaultLatencyBuckets))},
+	}
+}
+
+func (m *metrics) observe(method string, status int, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests[RequestKey{method, status}]++
+	m.latency.observe(d)
+}
+
+// Metrics returns a snapshot of the requests served so far, including
+// those of Burst and Load sessions.
+func (c *Client) Metrics() Metrics {
+	c.metrics.mu.Lock()
+	defer c.metrics.mu.Unlock()
+	snap := Metrics{Requests: make(map[RequestKey]int, len(c.metrics.requests)), Latency: c.metrics.latency}
+	for k, v := range c.metrics.requests {
+		snap.Requests[k] = v
+	}
+	snap.Latency.Counts = append([]int(nil), c.metrics.latency.Counts...)
+	return snap
+}
+
+// ResetMetrics starts Metrics over.
+func (c *Client) ResetMetrics() {
+	fresh := newMetrics()
+	c.metrics.mu.Lock()
+	defer c.metrics.mu.Unlock()
+	c.metrics.requests, c.metrics.latency = fresh.requests, fresh.latency
+}
+
+// ExpectNo5xx asserts that no request of m was answered with a 5xx status.
+func ExpectNo5xx(t testing.TB, m Metrics) {
+	t.Helper()
+	var bad []string
+	for k, v := range m.Requests {
+		if k.Status >= 500 && k.Status <= 599 {
+			bad = append(bad, fmt.Sprintf("%d %s %d", v, k.Method, k.Status))
+		}
+	}
+	if len(bad) > 0 {
+		sort.Strings(bad)
+		t.Errorf("expected no 5xx responses, got %s", strings.Join(bad, ", "))
+	}
+}
+
+// ExpectMaxLatency asserts that the handler served every request of m
+// within max.
+func ExpectMaxLatency(t testing.TB, m Metrics, max time.Duration) {
+	t.Helper()
+	if m.Latency.Max > max {
+		slow := m.Latency.Count
+		for i, b := range m.Latency.Bounds {
+			if b >= max {
+				slow = m.Latency.Count - m.Latency.Counts[i]
+				break
+			}
+		}
+		t.Errorf("expected every request within %v, got a maximum of %v (about %d of %d requests slower)", max, m.Latency.Max, slow, m.Latency.Count)
+	}
+}
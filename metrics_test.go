@@ -0,0 +1,67 @@
+package testclient
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ms, _ := strconv.Atoi(r.URL.Query().Get("ms"))
+		clock.Advance(time.Duration(ms) * time.Millisecond)
+		if code, _ := strconv.Atoi(r.URL.Query().Get("code")); code != 0 {
+			w.WriteHeader(code)
+		}
+		if r.URL.Query().Has("panic") {
+			panic("boom")
+		}
+	})
+	c := New(h, WithClock(clock))
+	c.Get("/?ms=3", nil)
+	c.Get("/?ms=30", nil)
+	c.PostForm("/?ms=70&code=503", nil)
+	c.Get("/?panic", nil)
+
+	m := c.Metrics()
+	if m.Total() != 4 {
+		t.Errorf("Total() = %d, want 4", m.Total())
+	}
+	if m.Count(http.MethodGet, 200) != 2 || m.Count("", 503) != 1 || m.Count(http.MethodGet, 0) != 1 {
+		t.Errorf("Requests = %v", m.Requests)
+	}
+	h5 := m.Latency
+	if h5.Max != 70*time.Millisecond || h5.Sum != 103*time.Millisecond {
+		t.Errorf("Latency = %+v", h5)
+	}
+	// buckets 5ms, 10ms, 25ms, 50ms, 100ms, ... are cumulative
+	if h5.Counts[0] != 2 || h5.Counts[2] != 2 || h5.Counts[3] != 3 || h5.Counts[4] != 4 {
+		t.Errorf("Latency.Counts = %v", h5.Counts)
+	}
+
+	ft := &fakeT{}
+	ExpectNo5xx(ft, m)
+	ExpectMaxLatency(ft, m, 50*time.Millisecond)
+	if len(ft.failures) != 2 {
+		t.Errorf("failures = %q", ft.failures)
+	}
+
+	c.ResetMetrics()
+	c.Get("/", nil)
+	m = c.Metrics()
+	if m.Total() != 1 {
+		t.Errorf("Total() after ResetMetrics = %d", m.Total())
+	}
+	ExpectNo5xx(t, m)
+	ExpectMaxLatency(t, m, 50*time.Millisecond)
+}
+
+func TestMetricsBurst(t *testing.T) {
+	c := New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	c.Burst(20, func(int) *http.Request { return c.NewRequest(http.MethodGet, "/", nil) })
+	if got := c.Metrics().Count(http.MethodGet, 200); got != 20 {
+		t.Errorf("count after Burst = %d, want 20", got)
+	}
+}
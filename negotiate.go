@@ -0,0 +1,80 @@
+package testclient
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"testing"
+	"text/tabwriter"
+)
+
+// Negotiation is one row of a content negotiation matrix: the Accept
+// header to send and the answer expected for it.
+type Negotiation struct {
+	Accept string
+	// ContentType is the expected media type, compared without parameters.
+	// It is not checked if empty.
+	ContentType string
+	// Status is the expected status; zero means 200.
+	Status int
+}
+
+// ExpectNegotiation sends req once per case with its Accept header and
+// fails the test unless every answer has the expected status and media
+// type and lists Accept in Vary. On failure it reports the whole matrix as
+// a table.
+func ExpectNegotiation(t testing.TB, c *Client, req *http.Request, cases ...Negotiation) {
+	t.Helper()
+	body, err := bufferBody(req)
+	if err != nil {
+		t.Fatalf("testclient: reading request body: %v", err)
+		return
+	}
+	var table strings.Builder
+	tw := tabwriter.NewWriter(&table, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "Accept\tStatus\tContent-Type\tVary\t")
+	failed := false
+	for _, tc := range cases {
+		try := withBody(req, body)
+		try.Header.Set("Accept", tc.Accept)
+		res, err := c.Request(try)
+		if err != nil {
+			fmt.Fprintf(tw, "%s\t%v\t\t\tFAIL\n", tc.Accept, err)
+			failed = true
+			continue
+		}
+		problem := negotiationProblem(res, tc)
+		if problem != "" {
+			failed = true
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\n", tc.Accept, res.StatusCode, res.Header.Get("Content-Type"), strings.Join(res.Header.Values("Vary"), ", "), problem)
+	}
+	tw.Flush()
+	if failed {
+		t.Errorf("content negotiation for %s %s failed:\n%s", req.Method, req.URL, table.String())
+	}
+}
+
+func negotiationProblem(res *http.Response, tc Negotiation) string {
+	var problems []string
+	want := tc.Status
+	if want == 0 {
+		want = http.StatusOK
+	}
+	if res.StatusCode != want {
+		problems = append(problems, fmt.Sprintf("want status %d", want))
+	}
+	if tc.ContentType != "" {
+		if got, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type")); !strings.EqualFold(got, tc.ContentType) {
+			problems = append(problems, "want "+tc.ContentType)
+		}
+	}
+	if vary := headerValues(res.Header, "Vary"); !containsFold(vary, "Accept") && !contains(vary, "*") {
+		problems = append(problems, "Vary lacks Accept")
+	}
+	if len(problems) == 0 {
+		return ""
+	}
+	return "FAIL: " + strings.Join(problems, "; ")
+}
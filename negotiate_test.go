@@ -0,0 +1,64 @@
+package testclient
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// negotiatingHandler serves JSON, HTML or XML, picking the offer with the
+// highest quality, JSON for */*, and 406 when none is acceptable.
+func negotiatingHandler(vary bool) http.Handler {
+	offers := []string{"application/json", "text/html", "application/xml"}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if vary {
+			w.Header().Set("Vary", "Accept")
+		}
+		best, bestQ := "", 0.0
+		for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+			media, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+			q := 1.0
+			if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+				q, _ = strconv.ParseFloat(v, 64)
+			}
+			for _, offer := range offers {
+				if (media == offer || media == "*/*") && q > bestQ {
+					best, bestQ = offer, q
+					break
+				}
+			}
+		}
+		if best == "" {
+			w.WriteHeader(http.StatusNotAcceptable)
+			return
+		}
+		w.Header().Set("Content-Type", best+"; charset=utf-8")
+	})
+}
+
+func TestExpectNegotiation(t *testing.T) {
+	cases := []Negotiation{
+		{Accept: "application/json", ContentType: "application/json"},
+		{Accept: "text/html", ContentType: "text/html"},
+		{Accept: "application/xml", ContentType: "application/xml"},
+		{Accept: "*/*", ContentType: "application/json"},
+		{Accept: "application/json;q=0.5, text/html", ContentType: "text/html"},
+		{Accept: "image/png", Status: http.StatusNotAcceptable},
+	}
+	c := New(negotiatingHandler(true))
+	ExpectNegotiation(t, c, c.NewRequest(http.MethodGet, "/doc", nil), cases...)
+
+	ft := &fakeT{TB: t}
+	c = New(negotiatingHandler(false))
+	ExpectNegotiation(ft, c, c.NewRequest(http.MethodGet, "/doc", nil), append(cases, Negotiation{Accept: "text/html", ContentType: "application/json"})...)
+	if len(ft.failures) != 1 {
+		t.Fatalf("failures = %q", ft.failures)
+	}
+	report := ft.failures[0]
+	for _, want := range []string{"Accept", "Status", "image/png", "406", "Vary lacks Accept", "want application/json"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report lacks %q:\n%s", want, report)
+		}
+	}
+}
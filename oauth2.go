@@ -0,0 +1,185 @@
+package testclient
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// OAuth2Flow configures an OAuth2/OIDC authorization-code flow driven by
+// Client.OAuth2.
+type OAuth2Flow struct {
+	AuthorizeURL string
+	TokenURL     string
+	ClientID     string
+	// ClientSecret, if set, authenticates the token request with HTTP Basic.
+	ClientSecret string
+	RedirectURI  string
+	Scopes       []string
+	// State is generated when empty.
+	State string
+	// PKCE sends an S256 code challenge and the matching verifier.
+	PKCE bool
+	// Interact is called when the authorization server answers with a page
+	// instead of a redirect, e.g. a login or consent form. It must drive the
+	// client until its last response is a redirect again.
+	Interact func(c *Client) error
+	// MaxRedirects defaults to 10.
+	MaxRedirects int
+}
+
+// OAuth2Token is the token endpoint's response.
+type OAuth2Token struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+	IDToken      string `json:"id_token"`
+	ExpiresIn    int    `json:"expires_in"`
+	Scope        string `json:"scope"`
+}
+
+// OAuth2 runs the authorization-code flow against the in-process handlers:
+// it opens the authorize endpoint, follows redirects until one targets
+// RedirectURI, checks the state, exchanges the code for tokens and installs
+// the access token as the client's bearer token.
+func (c *Client) OAuth2(flow OAuth2Flow) (*OAuth2Token, error) {
+	state := flow.State
+	if state == "" {
+		state = randomString(c.random())
+	}
+	q := url.Values{
+		"response_type": {"code"},
+		"client_id":     {flow.ClientID},
+		"redirect_uri":  {flow.RedirectURI},
+		"state":         {state},
+	}
+	if len(flow.Scopes) > 0 {
+		q.Set("scope", strings.Join(flow.Scopes, " "))
+	}
+	var verifier string
+	if flow.PKCE {
+		verifier = randomString(c.random())
+		sum := sha256.Sum256([]byte(verifier))
+		q.Set("code_challenge", base64.RawURLEncoding.EncodeToString(sum[:]))
+		q.Set("code_challenge_method", "S256")
+	}
+	authorize, err := url.Parse(flow.AuthorizeURL)
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: bad AuthorizeURL: %w", err)
+	}
+	for k, vv := range authorize.Query() {
+		q[k] = vv
+	}
+	authorize.RawQuery = q.Encode()
+	redirect, err := url.Parse(flow.RedirectURI)
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: bad RedirectURI: %w", err)
+	}
+
+	if _, err := c.Request(c.NewRequest(http.MethodGet, authorize.String(), nil)); err != nil {
+		return nil, err
+	}
+	code, err := c.awaitOAuth2Code(flow, redirect, state)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {code},
+		"redirect_uri": {flow.RedirectURI},
+	}
+	if flow.ClientSecret == "" {
+		form.Set("client_id", flow.ClientID)
+	}
+	if verifier != "" {
+		form.Set("code_verifier", verifier)
+	}
+	req := c.NewRequest(http.MethodPost, flow.TokenURL, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if flow.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(flow.ClientID), url.QueryEscape(flow.ClientSecret))
+	}
+	res, err := c.Request(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("oauth2: token endpoint returned %d", res.StatusCode)
+	}
+	var token OAuth2Token
+	if err := json.NewDecoder(res.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("oauth2: decoding token response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("oauth2: token response has no access_token")
+	}
+	c.SetBearerToken(token.AccessToken)
+	return &token, nil
+}
+
+// awaitOAuth2Code follows redirects from the current response until one
+// targets redirect, and returns the authorization code it carries.
+func (c *Client) awaitOAuth2Code(flow OAuth2Flow, redirect *url.URL, state string) (string, error) {
+	max := flow.MaxRedirects
+	if max == 0 {
+		max = 10
+	}
+	interacted := false
+	for hops := 0; ; {
+		res := c.response
+		if res == nil {
+			return "", fmt.Errorf("oauth2: no response")
+		}
+		if res.StatusCode < 300 || res.StatusCode >= 400 {
+			if flow.Interact == nil || interacted {
+				return "", fmt.Errorf("oauth2: expected a redirect, got %d from %s", res.StatusCode, requestURL(c.request))
+			}
+			interacted = true
+			if err := flow.Interact(c); err != nil {
+				return "", fmt.Errorf("oauth2: interact: %w", err)
+			}
+			continue
+		}
+
+		loc, err := requestURL(c.request).Parse(res.Header.Get("Location"))
+		if err != nil {
+			return "", fmt.Errorf("oauth2: bad Location header: %w", err)
+		}
+		if loc.Scheme == redirect.Scheme && strings.EqualFold(loc.Host, redirect.Host) && loc.Path == redirect.Path {
+			q := loc.Query()
+			if e := q.Get("error"); e != "" {
+				return "", fmt.Errorf("oauth2: authorization failed: %s: %s", e, q.Get("error_description"))
+			}
+			if q.Get("state") != state {
+				return "", fmt.Errorf("oauth2: state mismatch: got %q, want %q", q.Get("state"), state)
+			}
+			if q.Get("code") == "" {
+				return "", fmt.Errorf("oauth2: redirect has no code")
+			}
+			return q.Get("code"), nil
+		}
+
+		if hops++; hops > max {
+			return "", fmt.Errorf("oauth2: stopped after %d redirects", max)
+		}
+		if err := c.FollowRedirect(); err != nil {
+			return "", err
+		}
+	}
+}
+
+func randomString(r io.Reader) string {
+	var b [24]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b[:])
+}
@@ -0,0 +1,122 @@
+package testclient
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+type fakeAuthServer struct {
+	challenge string
+}
+
+func (s *fakeAuthServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/authorize":
+		if _, err := r.Cookie("login"); err != nil {
+			w.Write([]byte(`<form method="post" action="/login">`))
+			return
+		}
+		q := r.URL.Query()
+		s.challenge = q.Get("code_challenge")
+		target := q.Get("redirect_uri") + "?code=the-code&state=" + url.QueryEscape(q.Get("state"))
+		http.Redirect(w, r, target, http.StatusFound)
+	case "/login":
+		http.SetCookie(w, &http.Cookie{Name: "login", Value: "1", Path: "/"})
+		http.Redirect(w, r, r.FormValue("return"), http.StatusFound)
+	case "/token":
+		id, secret, _ := r.BasicAuth()
+		if id != "app" || secret != "shh" || r.FormValue("code") != "the-code" {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		sum := sha256.Sum256([]byte(r.FormValue("code_verifier")))
+		if base64.RawURLEncoding.EncodeToString(sum[:]) != s.challenge {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "at-1", "token_type": "Bearer", "expires_in": 3600})
+	}
+}
+
+func TestOAuth2(t *testing.T) {
+	auth := &fakeAuthServer{}
+	var apiAuth string
+	api := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiAuth = r.Header.Get("Authorization")
+	})
+	c := New(api, WithHandlers(map[string]http.Handler{"auth.example.com": auth}))
+
+	var authorizeURL string
+	token, err := c.OAuth2(OAuth2Flow{
+		AuthorizeURL: "http://auth.example.com/authorize",
+		TokenURL:     "http://auth.example.com/token",
+		ClientID:     "app",
+		ClientSecret: "shh",
+		RedirectURI:  "http://app.example.com/callback",
+		Scopes:       []string{"openid", "profile"},
+		PKCE:         true,
+		Interact: func(c *Client) error {
+			authorizeURL = requestURL(c.request).String()
+			c.PostForm("http://auth.example.com/login", url.Values{"return": {authorizeURL}})
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "at-1" || token.ExpiresIn != 3600 {
+		t.Errorf("token = %+v", token)
+	}
+	if !strings.Contains(authorizeURL, "scope=openid+profile") {
+		t.Errorf("authorize URL %s has no scope", authorizeURL)
+	}
+
+	c.PostForm("/api", nil)
+	if apiAuth != "Bearer at-1" {
+		t.Errorf("Authorization = %q after the flow", apiAuth)
+	}
+}
+
+func TestOAuth2Errors(t *testing.T) {
+	badState := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://app.example.com/callback?code=x&state=forged", http.StatusFound)
+	})
+	denied := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://app.example.com/callback?error=access_denied", http.StatusFound)
+	})
+	page := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("login"))
+	})
+	loop := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/authorize", http.StatusFound)
+	})
+
+	tests := []struct {
+		name    string
+		handler http.Handler
+		want    string
+	}{
+		{"state", badState, "state mismatch"},
+		{"denied", denied, "access_denied"},
+		{"no redirect", page, "expected a redirect"},
+		{"loop", loop, "redirects"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.handler).OAuth2(OAuth2Flow{
+				AuthorizeURL: "http://auth.example.com/authorize",
+				TokenURL:     "http://auth.example.com/token",
+				ClientID:     "app",
+				RedirectURI:  "http://app.example.com/callback",
+			})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package testclient
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// MethodOverrideHeader is the header tunneling the method of a POST.
+const MethodOverrideHeader = "X-HTTP-Method-Override"
+
+// MethodOverride tunnels method, such as DELETE, through a POST carrying it
+// in the X-HTTP-Method-Override header, for handlers behind a method
+// override middleware.
+func MethodOverride(method string) RequestOption {
+	return func(req *http.Request) {
+		req.Header.Set(MethodOverrideHeader, strings.ToUpper(method))
+		req.Method = http.MethodPost
+	}
+}
+
+// FormMethodOverride tunnels method through a POST carrying it as the
+// _method parameter, the way Rails forms do: appended to a form
+// URL-encoded body, or to the query of a request with any other body.
+func FormMethodOverride(method string) RequestOption {
+	return func(req *http.Request) {
+		method = strings.ToUpper(method)
+		req.Method = http.MethodPost
+		mt, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+		if mt != "application/x-www-form-urlencoded" {
+			q := req.URL.Query()
+			q.Set("_method", method)
+			req.URL.RawQuery = q.Encode()
+			return
+		}
+		var body []byte
+		if req.Body != nil {
+			body, _ = io.ReadAll(req.Body)
+			req.Body.Close()
+		}
+		form := "_method=" + url.QueryEscape(method)
+		if len(body) > 0 {
+			form = string(body) + "&" + form
+		}
+		req.Body = io.NopCloser(strings.NewReader(form))
+		req.ContentLength = int64(len(form))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader([]byte(form))), nil
+		}
+	}
+}
+
+// ExpectMethod fails the test unless the last request captured by inner,
+// which wraps the handler behind the method override middleware, had the
+// method want: the tunneled method rather than POST.
+func ExpectMethod(t testing.TB, inner *Captured, want string) {
+	t.Helper()
+	req := inner.Last()
+	if req == nil {
+		t.Fatalf("expected a %s request, got none", want)
+		return
+	}
+	if req.Method != want {
+		t.Fatalf("expected the handler to see %s %s, got %s", want, req.URL.Path, req.Method)
+	}
+}
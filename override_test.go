@@ -0,0 +1,60 @@
+package testclient
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// overrideMiddleware turns a POST into the method it tunnels, like
+// Rack::MethodOverride.
+func overrideMiddleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			if m := r.Header.Get(MethodOverrideHeader); m != "" {
+				r.Method = m
+			} else if m := r.FormValue("_method"); m != "" {
+				r.Method = m
+			}
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+func TestMethodOverride(t *testing.T) {
+	inner := Capture(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.FormValue("name")))
+	}))
+	outer := Capture(overrideMiddleware(inner))
+	c := New(outer)
+
+	c.Request(c.NewRequest(http.MethodDelete, "/users/1", nil), MethodOverride("delete"))
+	ExpectMethod(t, inner, http.MethodDelete)
+	if sent := outer.Last(); sent.Method != http.MethodPost || sent.Header.Get(MethodOverrideHeader) != "DELETE" {
+		t.Errorf("sent %s with override %q", sent.Method, sent.Header.Get(MethodOverrideHeader))
+	}
+
+	c.PostForm("/users/1", url.Values{"name": {"alice"}}, FormMethodOverride(http.MethodPatch))
+	ExpectMethod(t, inner, http.MethodPatch)
+	if got := string(c.BodyBytes()); got != "alice" {
+		t.Errorf("handler read name %q", got)
+	}
+	if b := outer.Bodies()[1]; string(b) != "name=alice&_method=PATCH" {
+		t.Errorf("body = %q", b)
+	}
+
+	c.Request(c.NewRequest(http.MethodPut, "/users/1", strings.NewReader(`{"name": "bob"}`)), Header("Content-Type", "application/json"), FormMethodOverride("PUT"))
+	ExpectMethod(t, inner, http.MethodPut)
+	if q := inner.Last().URL.RawQuery; q != "_method=PUT" {
+		t.Errorf("query = %q", q)
+	}
+
+	ft := &fakeT{}
+	c.Request(c.NewRequest(http.MethodDelete, "/users/1", nil))
+	ExpectMethod(ft, inner, http.MethodPost)
+	ExpectMethod(ft, Capture(http.NotFoundHandler()), http.MethodPost)
+	if len(ft.failures) != 2 || !strings.Contains(ft.failures[0], "expected the handler to see POST /users/1, got DELETE") || !strings.Contains(ft.failures[1], "expected a POST request, got none") {
+		t.Errorf("failures = %q", ft.failures)
+	}
+}
@@ -0,0 +1,188 @@
+package testclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// maxPagination is how many pages Paginate fetches before giving up on
+// reaching the last one.
+const maxPagination = 1000
+
+// NextPage returns the URL of the page after res, relative to that of res
+// or absolute, or false if res is the last page.
+type NextPage func(res *http.Response) (string, bool)
+
+// LinkNext follows the rel="next" link of the Link header, as GitHub and
+// RFC 8288 paginate.
+func LinkNext(res *http.Response) (string, bool) {
+	for _, link := range headerValues(res.Header, "Link") {
+		target, params, ok := strings.Cut(link, ";")
+		target = strings.TrimSpace(target)
+		if !ok || !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+			continue
+		}
+		for _, p := range strings.Split(params, ";") {
+			name, value, _ := strings.Cut(strings.TrimSpace(p), "=")
+			if !strings.EqualFold(name, "rel") {
+				continue
+			}
+			for _, rel := range strings.Fields(strings.Trim(value, `"`)) {
+				if strings.EqualFold(rel, "next") {
+					return target[1 : len(target)-1], true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// CursorNext follows a cursor at path in the JSON body, such as
+// "$.meta.next_cursor", sending it as the query parameter param of the
+// same URL. A missing, null or empty cursor ends the walk.
+func CursorNext(path, param string) NextPage {
+	return func(res *http.Response) (string, bool) {
+		v, err := jsonAt(res, path)
+		if err != nil || v == nil {
+			return "", false
+		}
+		cursor := fmt.Sprint(v)
+		if cursor == "" {
+			return "", false
+		}
+		u := *res.Request.URL
+		q := u.Query()
+		q.Set(param, cursor)
+		u.RawQuery = q.Encode()
+		return u.String(), true
+	}
+}
+
+// PageNext counts up the query parameter param, starting from 1, until a
+// page whose array at itemsPath, such as "$.items", is empty.
+func PageNext(param, itemsPath string) NextPage {
+	return func(res *http.Response) (string, bool) {
+		if items, err := jsonAt(res, itemsPath); err != nil {
+			return "", false
+		} else if a, ok := items.([]any); !ok || len(a) == 0 {
+			return "", false
+		}
+		u := *res.Request.URL
+		q := u.Query()
+		page, err := strconv.Atoi(q.Get(param))
+		if err != nil {
+			page = 1
+		}
+		q.Set(param, strconv.Itoa(page+1))
+		u.RawQuery = q.Encode()
+		return u.String(), true
+	}
+}
+
+// Pages are the responses of a paginated collection, in order.
+type Pages struct {
+	Responses []*http.Response
+}
+
+// Items returns the elements of the arrays at path, such as "$.items", of
+// every page, in order, with numbers as json.Number.
+func (p *Pages) Items(path string) ([]any, error) {
+	var items []any
+	for i, res := range p.Responses {
+		page, err := pageItems(res, path)
+		if err != nil {
+			return nil, fmt.Errorf("testclient: page %d: %w", i+1, err)
+		}
+		items = append(items, page...)
+	}
+	return items, nil
+}
+
+func pageItems(res *http.Response, path string) ([]any, error) {
+	v, err := jsonAt(res, path)
+	if err != nil {
+		return nil, err
+	}
+	items, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an array", path)
+	}
+	return items, nil
+}
+
+// Paginate fetches first and then the page next returns for each page,
+// until it reports the last one. It fails on a request error, a page not
+// answered 2xx, a page linking to an earlier one, which would loop
+// forever, and a walk of more than 1000 pages, for lack of a last page.
+// The pages fetched so far are returned with the error.
+func (c *Client) Paginate(first string, next NextPage, opts ...RequestOption) (*Pages, error) {
+	pages := &Pages{}
+	seen := map[string]int{}
+	target := first
+	for {
+		req := c.NewRequest(http.MethodGet, target, nil)
+		res, err := c.Request(req, opts...)
+		if err != nil {
+			return pages, fmt.Errorf("testclient: page %d (%s): %w", len(pages.Responses)+1, target, err)
+		}
+		pages.Responses = append(pages.Responses, res)
+		n := len(pages.Responses)
+		if res.StatusCode < 200 || res.StatusCode >= 300 {
+			return pages, fmt.Errorf("testclient: page %d (%s): status %d", n, target, res.StatusCode)
+		}
+		seen[requestURL(res.Request).String()] = n
+		ref, more := next(res)
+		if !more {
+			return pages, nil
+		}
+		u, err := requestURL(res.Request).Parse(ref)
+		if err != nil {
+			return pages, fmt.Errorf("testclient: page %d links to a bad next page %q: %w", n, ref, err)
+		}
+		if m, ok := seen[u.String()]; ok {
+			return pages, fmt.Errorf("testclient: pagination loop: page %d links to %s, which was page %d", n, u, m)
+		}
+		if n == maxPagination {
+			return pages, fmt.Errorf("testclient: no last page after %d pages", maxPagination)
+		}
+		target = u.String()
+	}
+}
+
+// ExpectPaginated walks the pages of a collection as Paginate does and
+// returns their items, the arrays at itemsPath, failing the test if the
+// walk fails, if a page other than the last is empty, or if an item shows
+// up on two pages, as when an offset skips or repeats rows.
+func ExpectPaginated(t testing.TB, c *Client, first string, next NextPage, itemsPath string, opts ...RequestOption) []any {
+	t.Helper()
+	pages, err := c.Paginate(first, next, opts...)
+	if err != nil {
+		t.Fatalf("%v", err)
+		return nil
+	}
+	var items []any
+	onPage := map[string]int{}
+	for i, res := range pages.Responses {
+		page, err := pageItems(res, itemsPath)
+		if err != nil {
+			t.Fatalf("testclient: page %d (%s): %v", i+1, res.Request.URL.RequestURI(), err)
+			return nil
+		}
+		if len(page) == 0 && i < len(pages.Responses)-1 {
+			t.Errorf("page %d (%s) is empty but links to a next page", i+1, res.Request.URL.RequestURI())
+		}
+		for _, item := range page {
+			b, _ := json.Marshal(item)
+			if p, ok := onPage[string(b)]; ok && p != i+1 {
+				t.Errorf("item %s is on page %d and page %d", b, p, i+1)
+			}
+			onPage[string(b)] = i + 1
+		}
+		items = append(items, page...)
+	}
+	return items
+}
@@ -0,0 +1,143 @@
+package testclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// collection serves items 1 to 7, three a page, paginated by Link header,
+// cursor field and page parameter; offset shifts the start of every page
+// after the first, to emulate offset bugs.
+func collection(offset int) http.Handler {
+	const size, total = 3, 7
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if c := r.URL.Query().Get("cursor"); c != "" {
+			page, _ = strconv.Atoi(c)
+		}
+		if page == 0 {
+			page = 1
+		}
+		start := (page - 1) * size
+		if page > 1 {
+			start += offset
+		}
+		items := []int{}
+		for i := start + 1; i <= start+size && i <= total; i++ {
+			items = append(items, i)
+		}
+		body := map[string]any{"items": items, "next_cursor": nil}
+		if start+size < total {
+			w.Header().Add("Link", fmt.Sprintf(`</items?page=%d>; rel="next", </items?page=1>; rel="first"`, page+1))
+			body["next_cursor"] = strconv.Itoa(page + 1)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(body)
+	})
+}
+
+func TestPaginate(t *testing.T) {
+	tests := []struct {
+		name  string
+		next  NextPage
+		pages int
+	}{
+		{"Link", LinkNext, 3},
+		{"cursor", CursorNext("$.next_cursor", "cursor"), 3},
+		{"page param", PageNext("page", "$.items"), 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(collection(0))
+			pages, err := c.Paginate("/items", tt.next)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(pages.Responses) != tt.pages {
+				t.Errorf("%d pages", len(pages.Responses))
+			}
+			items, err := pages.Items("$.items")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprint(items) != "[1 2 3 4 5 6 7]" {
+				t.Errorf("items = %v", items)
+			}
+			ft := &fakeT{TB: t}
+			if got := ExpectPaginated(ft, c, "/items", tt.next, "$.items"); len(got) != 7 || len(ft.failures) != 0 {
+				t.Errorf("ExpectPaginated = %v, failures %q", got, ft.failures)
+			}
+		})
+	}
+}
+
+func TestPaginateErrors(t *testing.T) {
+	loop := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next := map[string]string{"/a": "/b", "/b": "/a?x=1", "/a?x=1": "b"}[r.URL.RequestURI()]
+		w.Header().Set("Link", "<"+next+`>; rel="next"`)
+	})
+	_, err := New(loop).Paginate("/a", LinkNext)
+	if err == nil || err.Error() != "testclient: pagination loop: page 3 links to http://example.com/b, which was page 2" {
+		t.Errorf("err = %v", err)
+	}
+
+	endless := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(r.URL.Query().Get("n"))
+		w.Header().Set("Link", fmt.Sprintf(`</?n=%d>; rel="next"`, n+1))
+	})
+	pages, err := New(endless).Paginate("/", LinkNext)
+	if err == nil || err.Error() != "testclient: no last page after 1000 pages" || len(pages.Responses) != 1000 {
+		t.Errorf("err = %v", err)
+	}
+
+	broken := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Link", `</?page=2>; rel="next"`)
+	})
+	ft := &fakeT{TB: t}
+	ExpectPaginated(ft, New(broken), "/", LinkNext, "$.items")
+	if len(ft.failures) != 1 || ft.failures[0] != "testclient: page 2 (http://example.com/?page=2): status 500" {
+		t.Errorf("failures = %q", ft.failures)
+	}
+}
+
+func TestExpectPaginatedOffsetBugs(t *testing.T) {
+	ft := &fakeT{TB: t}
+	ExpectPaginated(ft, New(collection(-1)), "/items", LinkNext, "$.items")
+	if strings.Join(ft.failures, "\n") != "item 3 is on page 1 and page 2" {
+		t.Errorf("failures = %q", ft.failures)
+	}
+
+	empty := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "" {
+			w.Header().Set("Link", `</?page=2>; rel="next"`)
+		} else if r.URL.Query().Get("page") == "2" {
+			w.Header().Set("Link", `</?page=3>; rel="next"`)
+			fmt.Fprint(w, `{"items": []}`)
+			return
+		}
+		fmt.Fprint(w, `{"items": [1]}`)
+	})
+	ft = &fakeT{TB: t}
+	ExpectPaginated(ft, New(empty), "/", LinkNext, "$.items")
+	if len(ft.failures) != 2 || ft.failures[0] != "page 2 (/?page=2) is empty but links to a next page" {
+		t.Errorf("failures = %q", ft.failures)
+	}
+}
+
+func TestLinkNext(t *testing.T) {
+	res := &http.Response{Header: http.Header{"Link": {`<https://api.example.com/a?page=1>; rel="prev first"`, `<https://api.example.com/a?p=3,4>; title="x; y"; rel="next last"`}}}
+	if got, ok := LinkNext(res); !ok || got != "https://api.example.com/a?p=3,4" {
+		t.Errorf("LinkNext = %q, %v", got, ok)
+	}
+	if _, ok := LinkNext(&http.Response{Header: http.Header{}}); ok {
+		t.Error("LinkNext found a next page without a Link")
+	}
+}
@@ -0,0 +1,50 @@
+package testclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+)
+
+// PanicError is returned when the handler panics while serving a request.
+// Its message names the request and carries the stack of the panicking
+// goroutine, so t.Fatal(err) reports where the handler failed rather than a
+// dump from inside ServeHTTP.
+type PanicError struct {
+	Method string
+	URL    string
+	Value  any
+	Stack  []byte
+}
+
+func newPanicError(p any, req *http.Request) *PanicError {
+	return &PanicError{Method: req.Method, URL: requestURL(req).String(), Value: p, Stack: debug.Stack()}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("testclient: handler panicked serving %s %s: %v\n%s", e.Method, e.URL, e.Value, e.Stack)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// serveRecovering serves req and recovers a panic in the handler. It
+// reports whether the handler aborted with http.ErrAbortHandler, which is
+// how net/http drops a connection, and returns any other panic as an error.
+func serveRecovering(h http.Handler, w http.ResponseWriter, req *http.Request) (aborted bool, perr *PanicError) {
+	defer func() {
+		if p := recover(); p != nil {
+			if err, ok := p.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				aborted = true
+				return
+			}
+			perr = newPanicError(p, req)
+		}
+	}()
+	h.ServeHTTP(w, req)
+	return false, nil
+}
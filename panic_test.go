@@ -0,0 +1,63 @@
+package testclient
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+var errBroken = errors.New("broken")
+
+func panickingHandler(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("err") != "" {
+		panic(errBroken)
+	}
+	panic("nil map")
+}
+
+func TestRequestPanic(t *testing.T) {
+	c := New(http.HandlerFunc(panickingHandler))
+	_, err := c.Request(c.NewRequest(http.MethodPost, "/orders", nil))
+	var perr *PanicError
+	if !errors.As(err, &perr) {
+		t.Fatalf("err = %v, want a *PanicError", err)
+	}
+	if perr.Value != "nil map" || perr.Method != http.MethodPost || perr.URL != "http://example.com/orders" {
+		t.Errorf("PanicError = %+v", perr)
+	}
+	msg := err.Error()
+	if !strings.Contains(msg, "POST http://example.com/orders: nil map") || !strings.Contains(msg, "panickingHandler") {
+		t.Errorf("message does not name the request and the panicking function:\n%s", msg)
+	}
+	if c.Response() != nil {
+		t.Error("Response after a panic is not nil")
+	}
+
+	if _, err := c.Request(c.NewRequest(http.MethodGet, "/?err=1", nil)); !errors.Is(err, errBroken) {
+		t.Errorf("err = %v, want to unwrap to the panic value", err)
+	}
+}
+
+func TestStreamPanic(t *testing.T) {
+	c := New(http.HandlerFunc(panickingHandler))
+	var perr *PanicError
+	if _, err := c.Stream(c.NewRequest(http.MethodGet, "/", nil)); !errors.As(err, &perr) {
+		t.Errorf("Stream err = %v, want a *PanicError", err)
+	}
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "partial")
+		w.(http.Flusher).Flush()
+		panic("late")
+	})
+	c = New(h)
+	res, err := c.Stream(c.NewRequest(http.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadAll(res.Body); !errors.As(err, &perr) || perr.Value != "late" {
+		t.Errorf("body error = %v, want the panic", err)
+	}
+}
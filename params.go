@@ -0,0 +1,110 @@
+package testclient
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Params are the values of the {name} placeholders of a path template.
+// Values are formatted with fmt.Sprint.
+type Params map[string]any
+
+// ExpandPath substitutes params into the {name} placeholders of tmpl,
+// escaping each value so that it stays within its path segment or query
+// value. A {name...} placeholder in the path, as a catch-all route has,
+// takes the rest of the path: its value may span segments, each escaped on
+// its own. It fails on a placeholder without a value, a value that is empty
+// and would collapse the path, and a param that tmpl does not use.
+func ExpandPath(tmpl string, params Params) (string, error) {
+	path, query, hasQuery := strings.Cut(tmpl, "?")
+	used := map[string]bool{}
+	path, err := expand(path, params, used, url.PathEscape, escapeSegments)
+	if err != nil {
+		return "", err
+	}
+	if hasQuery {
+		if query, err = expand(query, params, used, url.QueryEscape, nil); err != nil {
+			return "", err
+		}
+		path += "?" + query
+	}
+	var unused []string
+	for name := range params {
+		if !used[name] {
+			unused = append(unused, name)
+		}
+	}
+	if len(unused) > 0 {
+		sort.Strings(unused)
+		return "", fmt.Errorf("testclient: %s has no placeholder for %s", tmpl, strings.Join(unused, ", "))
+	}
+	return path, nil
+}
+
+// expand fills the placeholders of s, escaping values with escape and
+// those of {name...} placeholders with escapeRest, which is nil where they
+// are not allowed.
+func expand(s string, params Params, used map[string]bool, escape, escapeRest func(string) string) (string, error) {
+	var b strings.Builder
+	for {
+		open := strings.IndexByte(s, '{')
+		if open < 0 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+		end := strings.IndexByte(s[open:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("testclient: unclosed placeholder in %q", s)
+		}
+		name := s[open+1 : open+end]
+		esc := escape
+		if rest, ok := strings.CutSuffix(name, "..."); ok {
+			if escapeRest == nil {
+				return "", fmt.Errorf("testclient: {%s} is only allowed in the path", name)
+			}
+			name, esc = rest, escapeRest
+		}
+		v, ok := params[name]
+		if !ok {
+			return "", fmt.Errorf("testclient: no value for {%s}", name)
+		}
+		value := fmt.Sprint(v)
+		if value == "" {
+			return "", fmt.Errorf("testclient: empty value for {%s}", name)
+		}
+		used[name] = true
+		b.WriteString(s[:open])
+		b.WriteString(esc(value))
+		s = s[open+end+1:]
+	}
+}
+
+// escapeSegments escapes each segment of a path on its own, keeping the
+// slashes between them.
+func escapeSegments(path string) string {
+	segs := strings.Split(path, "/")
+	for i, seg := range segs {
+		segs[i] = url.PathEscape(seg)
+	}
+	return strings.Join(segs, "/")
+}
+
+// Get sends a GET request to path, a template whose {name} placeholders
+// are filled from params as by ExpandPath.
+func (c *Client) Get(path string, params Params, opts ...RequestOption) (*http.Response, error) {
+	return c.Do(http.MethodGet, path, params, nil, opts...)
+}
+
+// Do sends a method request to path, a template whose {name} placeholders
+// are filled from params as by ExpandPath.
+func (c *Client) Do(method, path string, params Params, body io.Reader, opts ...RequestOption) (*http.Response, error) {
+	target, err := ExpandPath(path, params)
+	if err != nil {
+		return nil, err
+	}
+	return c.Request(c.NewRequest(method, target, body), opts...)
+}
@@ -0,0 +1,57 @@
+package testclient
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestExpandPath(t *testing.T) {
+	tests := []struct {
+		tmpl   string
+		params Params
+		want   string
+		err    string
+	}{
+		{"/users/{id}/orders/{order}", Params{"id": 7, "order": "A-1"}, "/users/7/orders/A-1", ""},
+		{"/files/{name}", Params{"name": "a/b c?"}, "/files/a%2Fb%20c%3F", ""},
+		{"/search/{kind}?q={q}", Params{"kind": "users", "q": "a&b=c"}, "/search/users?q=a%26b%3Dc", ""},
+		{"/files/{path...}", Params{"path": "docs/a b?.md"}, "/files/docs/a%20b%3F.md", ""},
+		{"/search?q={q...}", Params{"q": "a/b"}, "", "only allowed in the path"},
+		{"/static", nil, "/static", ""},
+		{"/users/{id}", Params{}, "", "no value for {id}"},
+		{"/users/{id}/orders", Params{"id": ""}, "", "empty value for {id}"},
+		{"/users/{id}", Params{"id": 1, "order": 2}, "", "no placeholder for order"},
+		{"/users/{id", Params{"id": 1}, "", "unclosed placeholder"},
+	}
+	for _, tt := range tests {
+		got, err := ExpandPath(tt.tmpl, tt.params)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: err = %v, want %q", tt.tmpl, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%s = %q, %v; want %q", tt.tmpl, got, err, tt.want)
+		}
+	}
+}
+
+func TestGet(t *testing.T) {
+	stub := NewStub()
+	stub.On(http.MethodGet, "/users/{id}/orders/{order}").ReplyFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.EscapedPath()))
+	})
+	c := New(stub)
+	res, err := c.Get("/users/{id}/orders/{order}", Params{"id": 7, "order": "A/1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := body(t, res); got != "/users/7/orders/A%2F1" {
+		t.Errorf("served %q", got)
+	}
+	if _, err := c.Get("/users/{id}/orders/{order}", Params{"id": 7}); err == nil {
+		t.Error("missing param: no error")
+	}
+}
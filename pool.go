@@ -0,0 +1,69 @@
+package testclient
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+)
+
+// recorders holds timelineRecorders for reuse across requests. Only the
+// recorder, its header map and its body buffer are reused; everything a
+// response exposes is copied out before the recorder goes back.
+var recorders = sync.Pool{
+	New: func() any {
+		return &timelineRecorder{ResponseRecorder: &httptest.ResponseRecorder{
+			HeaderMap: http.Header{},
+			Body:      new(bytes.Buffer),
+		}}
+	},
+}
+
+// maxPooledBody keeps unusually large bodies from pinning memory in the pool.
+const maxPooledBody = 1 << 20
+
+func getRecorder(c *Client) *timelineRecorder {
+	rec := recorders.Get().(*timelineRecorder)
+	header, body := rec.HeaderMap, rec.Body
+	for k := range header {
+		delete(header, k)
+	}
+	body.Reset()
+	*rec.ResponseRecorder = httptest.ResponseRecorder{HeaderMap: header, Body: body, Code: http.StatusOK}
+	rec.timeline, rec.written, rec.wroteHeader, rec.now = nil, 0, false, c.clock.Now
+	rec.committed, rec.superfluous = false, rec.superfluous[:0]
+	return rec
+}
+
+func putRecorder(rec *timelineRecorder) {
+	if rec.Body.Cap() > maxPooledBody {
+		return
+	}
+	rec.timeline, rec.now = nil, nil
+	recorders.Put(rec)
+}
+
+// result returns the response recorded by rec. Its body is the one copy
+// of the bytes out of the recorder, which can then be reused; err, if not
+// nil, ends the body in place of io.EOF.
+func (rec *timelineRecorder) result(err error) *http.Response {
+	res := rec.Result()
+	body := make([]byte, rec.Body.Len())
+	copy(body, rec.Body.Bytes())
+	res.Body = newResponseBody(body, err)
+	return res
+}
+
+// snapshot copies rec for Recorder, with body as the body if it is the
+// copy result made.
+func (rec *timelineRecorder) snapshot(body []byte) *httptest.ResponseRecorder {
+	if body == nil {
+		body = append([]byte(nil), rec.Body.Bytes()...)
+	}
+	return &httptest.ResponseRecorder{
+		Code:      rec.Code,
+		HeaderMap: rec.HeaderMap.Clone(),
+		Body:      bytes.NewBuffer(body),
+		Flushed:   rec.Flushed,
+	}
+}
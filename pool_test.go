@@ -0,0 +1,45 @@
+package testclient
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRecorderReuseKeepsResponses(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Path", r.URL.Path)
+		io.WriteString(w, strings.Repeat(r.URL.Path, 100))
+	})
+	c := New(h)
+	var responses []*http.Response
+	for _, p := range []string{"/a", "/b", "/c"} {
+		res, err := c.Request(c.NewRequest(http.MethodGet, p, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		responses = append(responses, res)
+	}
+	for i, p := range []string{"/a", "/b", "/c"} {
+		if got := responses[i].Header.Get("X-Path"); got != p {
+			t.Errorf("response %d header = %q, want %q", i, got, p)
+		}
+		if got := body(t, responses[i]); got != strings.Repeat(p, 100) {
+			t.Errorf("response %d body was overwritten: %.10q...", i, got)
+		}
+	}
+}
+
+func BenchmarkRequest(b *testing.B) {
+	payload := strings.Repeat("x", 16<<10)
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, payload)
+	})
+	c := New(h)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		res, _ := c.Request(c.NewRequest(http.MethodGet, "/", nil))
+		io.Copy(io.Discard, res.Body)
+	}
+}
@@ -0,0 +1,73 @@
+package testclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"testing"
+)
+
+// ProblemDetails is an RFC 7807 problem document. Members other than the
+// standard ones are kept in Extensions.
+type ProblemDetails struct {
+	Type       string
+	Title      string
+	Status     int
+	Detail     string
+	Instance   string
+	Extensions map[string]any
+}
+
+// DecodeProblem decodes the problem document in the body of res. It fails
+// unless the Content-Type is application/problem+json. A missing type is
+// reported as "about:blank", as the RFC specifies.
+func DecodeProblem(res *http.Response) (ProblemDetails, error) {
+	var p ProblemDetails
+	if mt, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type")); mt != "application/problem+json" {
+		return p, fmt.Errorf("testclient: Content-Type %q is not application/problem+json", res.Header.Get("Content-Type"))
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(bufferedBody(res).data, &doc); err != nil {
+		return p, fmt.Errorf("testclient: decoding problem document: %w", err)
+	}
+	p.Type, p.Title, p.Detail, p.Instance = "about:blank", str(doc["title"]), str(doc["detail"]), str(doc["instance"])
+	if t := str(doc["type"]); t != "" {
+		p.Type = t
+	}
+	if status, ok := doc["status"].(float64); ok {
+		p.Status = int(status)
+	}
+	for _, k := range []string{"type", "title", "status", "detail", "instance"} {
+		delete(doc, k)
+	}
+	if len(doc) > 0 {
+		p.Extensions = doc
+	}
+	return p, nil
+}
+
+func str(v any) string {
+	s, _ := v.(string)
+	return s
+}
+
+// ExpectProblem fails the test unless res is a problem document with the
+// HTTP status status and the problem type typeURI, and returns it. The
+// status member, if present, must agree with the HTTP status.
+func ExpectProblem(t testing.TB, res *http.Response, status int, typeURI string) ProblemDetails {
+	t.Helper()
+	p, err := DecodeProblem(res)
+	if err != nil {
+		t.Fatalf("expected problem %d %s: %v\n%s", status, typeURI, err, dumpResponse(res))
+		return p
+	}
+	if res.StatusCode != status || p.Type != typeURI {
+		t.Fatalf("expected problem %d %s, got %d %s: %s", status, typeURI, res.StatusCode, p.Type, p.Detail)
+		return p
+	}
+	if p.Status != 0 && p.Status != res.StatusCode {
+		t.Errorf("problem status member %d disagrees with HTTP status %d", p.Status, res.StatusCode)
+	}
+	return p
+}
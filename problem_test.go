@@ -0,0 +1,36 @@
+package testclient
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestExpectProblem(t *testing.T) {
+	s := NewStub()
+	s.On("POST", "/transfer").Reply(http.StatusForbidden, `{"type":"https://example.com/probs/out-of-credit","title":"You do not have enough credit.","status":403,"detail":"Your balance is 30.","balance":30}`).
+		SetHeader("Content-Type", "application/problem+json")
+	s.On("GET", "/blank").Reply(http.StatusNotFound, `{"title":"Not Found","status":400}`).
+		SetHeader("Content-Type", "application/problem+json; charset=utf-8")
+	s.On("GET", "/html").Reply(http.StatusInternalServerError, "<h1>oops</h1>").SetHeader("Content-Type", "text/html")
+	c := New(s)
+
+	res, _ := c.Request(c.NewRequest(http.MethodPost, "/transfer", nil))
+	p := ExpectProblem(t, res, http.StatusForbidden, "https://example.com/probs/out-of-credit")
+	if p.Title != "You do not have enough credit." || p.Extensions["balance"] != float64(30) {
+		t.Errorf("problem = %+v", p)
+	}
+
+	ft := &fakeT{}
+	ExpectProblem(ft, res, http.StatusForbidden, "about:blank")
+	res, _ = c.Request(c.NewRequest(http.MethodGet, "/blank", nil))
+	ExpectProblem(ft, res, http.StatusNotFound, "about:blank")
+	res, _ = c.Request(c.NewRequest(http.MethodGet, "/html", nil))
+	ExpectProblem(ft, res, http.StatusInternalServerError, "about:blank")
+	if len(ft.failures) != 3 ||
+		!strings.Contains(ft.failures[0], "got 403 https://example.com/probs/out-of-credit: Your balance is 30.") ||
+		!strings.Contains(ft.failures[1], "status member 400 disagrees with HTTP status 404") ||
+		!strings.Contains(ft.failures[2], `Content-Type "text/html" is not application/problem+json`) {
+		t.Errorf("failures = %q", ft.failures)
+	}
+}
@@ -0,0 +1,58 @@
+package testclient
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// SetQuery sets a query parameter sent with every subsequent request, such
+// as api_version=2, unless the request has the parameter itself.
+func (c *Client) SetQuery(key, value string) {
+	if c.query == nil {
+		c.query = url.Values{}
+	}
+	c.query.Set(key, value)
+}
+
+// DelQuery removes a query parameter set with SetQuery.
+func (c *Client) DelQuery(key string) {
+	c.query.Del(key)
+}
+
+// Query sets a query parameter on a single request, replacing any value it
+// has, including one set with SetQuery.
+func Query(key, value string) RequestOption {
+	return func(req *http.Request) {
+		q := req.URL.Query()
+		q.Set(key, value)
+		req.URL.RawQuery = q.Encode()
+	}
+}
+
+func (c *Client) applyQuery(req *http.Request) {
+	if len(c.query) == 0 {
+		return
+	}
+	q := req.URL.Query()
+	added := false
+	for key, values := range c.query {
+		if _, ok := q[key]; !ok {
+			q[key] = append([]string(nil), values...)
+			added = true
+		}
+	}
+	if added {
+		req.URL.RawQuery = q.Encode()
+	}
+}
+
+func cloneValues(v url.Values) url.Values {
+	if v == nil {
+		return nil
+	}
+	out := make(url.Values, len(v))
+	for k, vv := range v {
+		out[k] = append([]string(nil), vv...)
+	}
+	return out
+}
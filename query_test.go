@@ -0,0 +1,40 @@
+package testclient
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestSetQuery(t *testing.T) {
+	var got []string
+	c := New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.URL.RawQuery)
+	}))
+	c.SetQuery("api_version", "2")
+	c.SetQuery("locale", "ja")
+
+	c.Get("/users", nil)
+	c.Get("/users?page={page}", Params{"page": 3})
+	c.Get("/users?locale=en", nil)
+	c.Get("/users", nil, Query("api_version", "3"))
+	c.Burst(1, func(int) *http.Request { return c.NewRequest(http.MethodGet, "/burst", nil) })
+	c.DelQuery("locale")
+	c.Get("/users", nil)
+
+	want := []string{
+		"api_version=2&locale=ja",
+		"api_version=2&locale=ja&page=3",
+		"api_version=2&locale=en",
+		"api_version=3&locale=ja",
+		"api_version=2&locale=ja",
+		"api_version=2",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("queries = %q", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("request %d query = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package testclient
+
+import (
+	crand "crypto/rand"
+	"encoding/hex"
+	"io"
+	"math/rand"
+	"sync"
+)
+
+// WithRandSeed makes the client draw the values it generates, such as
+// trace, request and idempotency IDs, OAuth2 states and PKCE verifiers,
+// JWT IDs, WebSocket keys and masks, and the rolls of faults without a Rand
+// of their own, from a source seeded with seed, so that a failing test can
+// be reproduced byte for byte. Without it they come from crypto/rand.
+func WithRandSeed(seed int64) Option {
+	return func(c *Client) {
+		c.rand = newSeededRand(seed)
+	}
+}
+
+// RandSeed makes the stub roll the faults injected into its routes that
+// have no Rand of their own with a source seeded with seed.
+func RandSeed(seed int64) StubOption {
+	return func(s *Stub) {
+		s.seed, s.rand = seed, newSeededRand(seed)
+	}
+}
+
+// seededRand is a seeded math/rand source safe for concurrent use.
+type seededRand struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+func newSeededRand(seed int64) *seededRand {
+	return &seededRand{r: rand.New(rand.NewSource(seed))}
+}
+
+func (s *seededRand) Read(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.r.Read(p)
+}
+
+func (s *seededRand) Float64() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.r.Float64()
+}
+
+// random returns the source of the random bytes c generates.
+func (c *Client) random() io.Reader {
+	if c.rand == nil {
+		return crand.Reader
+	}
+	return c.rand
+}
+
+// randomHex returns n bytes read from r, hex-encoded.
+func randomHex(r io.Reader, n int) string {
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
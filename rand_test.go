@@ -0,0 +1,95 @@
+package testclient
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// generated sends a request under seed and returns the values the client
+// generated for it.
+func generated(t *testing.T, seed int64) string {
+	var got []string
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get("X-Request-ID"), r.Header.Get("traceparent"), r.Header.Get(IdempotencyKeyHeader))
+		if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			payload, _ := base64.RawURLEncoding.DecodeString(strings.Split(token, ".")[1])
+			var claims struct{ JTI string }
+			json.Unmarshal(payload, &claims)
+			got = append(got, claims.JTI)
+		}
+	})
+	c := New(h, WithRandSeed(seed), WithRequestID(""), WithTraceContext("", ""))
+	if err := c.UseJWT(JWT{Key: []byte("secret"), Sub: "alice"}); err != nil {
+		t.Fatal(err)
+	}
+	ExpectIdempotent(t, c, c.NewRequest(http.MethodPost, "/payments", nil))
+	return fmt.Sprint(c.TraceID(), got)
+}
+
+func TestWithRandSeed(t *testing.T) {
+	a, b := generated(t, 42), generated(t, 42)
+	if a != b {
+		t.Errorf("same seed generated\n%s\n%s", a, b)
+	}
+	if c := generated(t, 43); c == a {
+		t.Errorf("seeds 42 and 43 generated the same values %s", a)
+	}
+	if len(strings.Fields(a)) != 8 {
+		t.Errorf("missing generated values: %s", a)
+	}
+}
+
+func TestRandSeedFaults(t *testing.T) {
+	statuses := func(seed int64) string {
+		stub := NewStub(RandSeed(seed))
+		stub.On(http.MethodGet, "/flaky").Inject(&Faults{ErrorRate: 0.5})
+		c := New(stub)
+		var got []int
+		for i := 0; i < 20; i++ {
+			res, _ := c.Request(c.NewRequest(http.MethodGet, "/flaky", nil))
+			got = append(got, res.StatusCode)
+		}
+		return fmt.Sprint(got)
+	}
+	if a, b := statuses(7), statuses(7); a != b || !strings.Contains(a, "503") || !strings.Contains(a, "200") {
+		t.Errorf("same seed rolled\n%s\n%s", a, b)
+	}
+
+	rolls := func(seed int64) string {
+		c := New(http.NotFoundHandler(), WithRandSeed(seed), WithFaults(&Faults{ErrorRate: 0.5}))
+		var got []int
+		for i := 0; i < 20; i++ {
+			res, _ := c.Request(c.NewRequest(http.MethodGet, "/", nil))
+			got = append(got, res.StatusCode)
+		}
+		return fmt.Sprint(got)
+	}
+	if a, b := rolls(7), rolls(7); a != b {
+		t.Errorf("same client seed rolled\n%s\n%s", a, b)
+	}
+}
+
+func TestWithRandSeedWebSocketKey(t *testing.T) {
+	key := func(seed int64) string {
+		var key string
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key = r.Header.Get("Sec-WebSocket-Key")
+			if conn, _ := wsAccept(t, w, r); conn != nil {
+				conn.Close()
+			}
+		})
+		ws, err := New(h, WithRandSeed(seed)).Dial("/ws")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ws.Close()
+		return key
+	}
+	if a, b := key(1), key(1); a == "" || a != b {
+		t.Errorf("same seed sent keys %q and %q", a, b)
+	}
+}
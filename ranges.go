@@ -0,0 +1,159 @@
+package testclient
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// ByteRange is an inclusive range of byte offsets. A negative End runs to
+// the end of the resource.
+type ByteRange struct {
+	Start, End int64
+}
+
+func (r ByteRange) String() string {
+	if r.End < 0 {
+		return fmt.Sprintf("%d-", r.Start)
+	}
+	return fmt.Sprintf("%d-%d", r.Start, r.End)
+}
+
+// resolve fills in an open End for a resource of size bytes.
+func (r ByteRange) resolve(size int64) ByteRange {
+	if r.End < 0 || r.End >= size {
+		r.End = size - 1
+	}
+	return r
+}
+
+// Ranges sets the Range header of a single request to ranges.
+func Ranges(ranges ...ByteRange) RequestOption {
+	return func(req *http.Request) {
+		specs := make([]string, len(ranges))
+		for i, r := range ranges {
+			specs[i] = r.String()
+		}
+		req.Header.Set("Range", "bytes="+strings.Join(specs, ","))
+	}
+}
+
+// GetRange requests bytes start through end of path, inclusive. A negative
+// end asks for everything from start on.
+func (c *Client) GetRange(path string, start, end int64, opts ...RequestOption) (*http.Response, error) {
+	opts = append([]RequestOption{Ranges(ByteRange{start, end})}, opts...)
+	return c.Request(c.NewRequest(http.MethodGet, path, nil), opts...)
+}
+
+// RangePart is one range of a 206 Partial Content response.
+type RangePart struct {
+	ByteRange
+	// Size is the length of the whole resource, or -1 if the server sent
+	// "*".
+	Size int64
+	Body []byte
+}
+
+// ParseRanges returns the parts of a 206 response, whether it carries a
+// single Content-Range or a multipart/byteranges body.
+func ParseRanges(res *http.Response) ([]RangePart, error) {
+	if res.StatusCode != http.StatusPartialContent {
+		return nil, fmt.Errorf("testclient: expected status 206, got %d", res.StatusCode)
+	}
+	body := bufferedBody(res).data
+	mediaType, params, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if mediaType != "multipart/byteranges" {
+		part, err := parseContentRange(res.Header.Get("Content-Range"))
+		if err != nil {
+			return nil, err
+		}
+		part.Body = body
+		return []RangePart{part}, nil
+	}
+	if res.Header.Get("Content-Range") != "" {
+		return nil, fmt.Errorf("testclient: multipart/byteranges response has a top-level Content-Range")
+	}
+	var parts []RangePart
+	mr := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("testclient: reading multipart/byteranges: %w", err)
+		}
+		part, err := parseContentRange(p.Header.Get("Content-Range"))
+		if err != nil {
+			return nil, fmt.Errorf("testclient: part %d: %w", len(parts), err)
+		}
+		if part.Body, err = io.ReadAll(p); err != nil {
+			return nil, fmt.Errorf("testclient: reading part %d: %w", len(parts), err)
+		}
+		parts = append(parts, part)
+	}
+	return parts, nil
+}
+
+// parseContentRange parses a "bytes first-last/size" Content-Range.
+func parseContentRange(v string) (RangePart, error) {
+	spec, ok := strings.CutPrefix(v, "bytes ")
+	rng, size, ok2 := strings.Cut(spec, "/")
+	first, last, ok3 := strings.Cut(rng, "-")
+	if !ok || !ok2 || !ok3 {
+		return RangePart{}, fmt.Errorf("testclient: malformed Content-Range %q", v)
+	}
+	part := RangePart{Size: -1}
+	var err error
+	if part.Start, err = strconv.ParseInt(first, 10, 64); err != nil {
+		return RangePart{}, fmt.Errorf("testclient: malformed Content-Range %q", v)
+	}
+	if part.End, err = strconv.ParseInt(last, 10, 64); err != nil || part.End < part.Start {
+		return RangePart{}, fmt.Errorf("testclient: malformed Content-Range %q", v)
+	}
+	if size != "*" {
+		if part.Size, err = strconv.ParseInt(size, 10, 64); err != nil || part.End >= part.Size {
+			return RangePart{}, fmt.Errorf("testclient: malformed Content-Range %q", v)
+		}
+	}
+	return part, nil
+}
+
+// ExpectPartialContent fails the test unless res is a 206 answer serving
+// want out of full: one part per range, in order, each with a Content-Range
+// naming the range and the size of full and carrying exactly those bytes.
+// It returns the parts.
+func ExpectPartialContent(t testing.TB, res *http.Response, full []byte, want ...ByteRange) []RangePart {
+	t.Helper()
+	if res.StatusCode != http.StatusPartialContent {
+		t.Fatalf("expected status 206 Partial Content, got %s", dumpResponse(res))
+		return nil
+	}
+	parts, err := ParseRanges(res)
+	if err != nil {
+		t.Fatalf("%v", err)
+		return nil
+	}
+	if len(parts) != len(want) {
+		t.Fatalf("expected %d ranges, got %d", len(want), len(parts))
+		return parts
+	}
+	size := int64(len(full))
+	for i, part := range parts {
+		w := want[i].resolve(size)
+		if part.ByteRange != w || part.Size != size {
+			t.Errorf("range %d: expected Content-Range bytes %s/%d, got bytes %s/%d", i, w, size, part.ByteRange, part.Size)
+			continue
+		}
+		if !bytes.Equal(part.Body, full[w.Start:w.End+1]) {
+			t.Errorf("range %d (%s): body %q does not match the resource (%q)", i, w, part.Body, full[w.Start:w.End+1])
+		}
+	}
+	return parts
+}
@@ -0,0 +1,72 @@
+package testclient
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetRange(t *testing.T) {
+	const doc = "0123456789abcdefghij"
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "doc.txt", time.Time{}, strings.NewReader(doc))
+	})
+	c := New(h)
+
+	res, err := c.GetRange("/doc", 5, 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := ExpectPartialContent(t, res, []byte(doc), ByteRange{5, 9})
+	if len(parts) != 1 || string(parts[0].Body) != "56789" {
+		t.Errorf("parts = %+v", parts)
+	}
+
+	res, err = c.GetRange("/doc", 15, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ExpectPartialContent(t, res, []byte(doc), ByteRange{15, -1})
+
+	ranges := []ByteRange{{0, 2}, {10, 12}, {18, -1}}
+	res, err = c.Request(c.NewRequest(http.MethodGet, "/doc", nil), Ranges(ranges...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(res.Header.Get("Content-Type"), "multipart/byteranges") {
+		t.Fatalf("Content-Type = %q", res.Header.Get("Content-Type"))
+	}
+	ExpectPartialContent(t, res, []byte(doc), ranges...)
+}
+
+func TestExpectPartialContentFailures(t *testing.T) {
+	stub := NewStub()
+	stub.On(http.MethodGet, "/wrong").Reply(http.StatusPartialContent, "xyz").SetHeader("Content-Range", "bytes 0-2/10")
+	stub.On(http.MethodGet, "/full").Reply(http.StatusOK, "0123456789")
+	stub.On(http.MethodGet, "/bad").Reply(http.StatusPartialContent, "012").SetHeader("Content-Range", "bytes 2-0/10")
+	c := New(stub)
+	full := []byte("0123456789")
+
+	tests := []struct {
+		path string
+		want ByteRange
+		msg  string
+	}{
+		{"/wrong", ByteRange{0, 2}, "does not match"},
+		{"/wrong", ByteRange{0, 3}, "expected Content-Range bytes 0-3/10"},
+		{"/full", ByteRange{0, 2}, "expected status 206"},
+		{"/bad", ByteRange{0, 2}, "malformed Content-Range"},
+	}
+	for _, tt := range tests {
+		res, err := c.GetRange(tt.path, tt.want.Start, tt.want.End)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ft := &fakeT{TB: t}
+		ExpectPartialContent(ft, res, full, tt.want)
+		if len(ft.failures) != 1 || !strings.Contains(ft.failures[0], tt.msg) {
+			t.Errorf("%s %v: failures = %q, want %q", tt.path, tt.want, ft.failures, tt.msg)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package testclient
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// RateLimit is the rate limit state a response advertises.
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	// Reset is the number of the reset header as sent: seconds until the
+	// window resets for the RateLimit headers, often a Unix time for
+	// X-RateLimit-Reset.
+	Reset int64
+	// Header names the headers it was read from: "X-RateLimit",
+	// "RateLimit" for RateLimit-Limit and the like, or "RateLimit" for
+	// the single RateLimit header of later drafts.
+	Header string
+}
+
+// ParseRateLimit reads the rate limit headers of res: X-RateLimit-Limit,
+// -Remaining and -Reset; the RateLimit-Limit, -Remaining and -Reset of
+// the IETF draft; or a single RateLimit header, either
+// "limit=100, remaining=42, reset=30" or `"default";r=42;t=30` with the
+// quota in the q parameter of RateLimit-Policy. It reports false if res
+// has none of them.
+func ParseRateLimit(res *http.Response) (RateLimit, bool) {
+	for _, prefix := range []string{"X-RateLimit", "RateLimit"} {
+		limit, ok := headerInt(res.Header, prefix+"-Limit")
+		remaining, ok2 := headerInt(res.Header, prefix+"-Remaining")
+		if ok && ok2 {
+			reset, _ := headerInt(res.Header, prefix+"-Reset")
+			return RateLimit{Limit: int(limit), Remaining: int(remaining), Reset: reset, Header: prefix}, true
+		}
+	}
+	params := rateLimitParams(res.Header.Get("RateLimit"))
+	if len(params) == 0 {
+		return RateLimit{}, false
+	}
+	rl := RateLimit{Header: "RateLimit", Limit: -1, Remaining: -1}
+	for key, value := range params {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			continue
+		}
+		switch key {
+		case "limit":
+			rl.Limit = int(n)
+		case "remaining", "r":
+			rl.Remaining = int(n)
+		case "reset", "t":
+			rl.Reset = n
+		}
+	}
+	if q, ok := rateLimitParams(res.Header.Get("RateLimit-Policy"))["q"]; ok && rl.Limit < 0 {
+		if n, err := strconv.Atoi(q); err == nil {
+			rl.Limit = n
+		}
+	}
+	return rl, rl.Remaining >= 0
+}
+
+// rateLimitParams returns the key=value pairs of a RateLimit header,
+// separated by commas or semicolons, of its first item, or none for a
+// header of blank items.
+func rateLimitParams(v string) map[string]string {
+	params := map[string]string{}
+	first := splitList(v)
+	if len(first) == 0 {
+		return params
+	}
+	if strings.Contains(first[0], ";") {
+		v = first[0]
+	}
+	for _, p := range strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ';' }) {
+		if key, value, ok := strings.Cut(strings.TrimSpace(p), "="); ok {
+			params[strings.ToLower(key)] = strings.Trim(value, `"`)
+		}
+	}
+	return params
+}
+
+func headerInt(h http.Header, name string) (int64, bool) {
+	n, err := strconv.ParseInt(strings.TrimSpace(h.Get(name)), 10, 64)
+	return n, err == nil
+}
+
+// ExpectRateLimit fails the test unless res advertises the rate limit
+// limit with remaining requests left, and returns it.
+func ExpectRateLimit(t testing.TB, res *http.Response, limit, remaining int) RateLimit {
+	t.Helper()
+	rl, ok := ParseRateLimit(res)
+	if !ok {
+		t.Fatalf("expected rate limit headers, got none%s", trailNote(res))
+		return rl
+	}
+	if rl.Limit != limit || rl.Remaining != remaining {
+		t.Fatalf("expected rate limit %d with %d remaining, got %d with %d remaining (%s headers)", limit, remaining, rl.Limit, rl.Remaining, rl.Header)
+	}
+	return rl
+}
+
+// ExpectRateLimitCountdown sends req n times and fails the test unless
+// each response advertises the same limit with one request fewer
+// remaining than the one before, never below zero, and, for the RateLimit
+// headers, a reset that does not move away. Give the client a fake clock
+// so that no window resets during the countdown.
+func ExpectRateLimitCountdown(t testing.TB, c *Client, req *http.Request, n int) {
+	t.Helper()
+	body, err := bufferBody(req)
+	if err != nil {
+		t.Fatalf("testclient: reading request body: %v", err)
+		return
+	}
+	var prev RateLimit
+	for i := 1; i <= n; i++ {
+		res, err := c.Request(withBody(req, body))
+		if err != nil {
+			t.Fatalf("testclient: request %d, %s %s: %v", i, req.Method, req.URL.RequestURI(), err)
+			return
+		}
+		rl, ok := ParseRateLimit(res)
+		if !ok {
+			t.Fatalf("request %d: expected rate limit headers, got none (status %d)", i, res.StatusCode)
+			return
+		}
+		if rl.Remaining < 0 {
+			t.Errorf("request %d: negative remaining %d", i, rl.Remaining)
+		}
+		if i > 1 {
+			if rl.Limit != prev.Limit {
+				t.Errorf("request %d: limit changed from %d to %d", i, prev.Limit, rl.Limit)
+			}
+			if rl.Remaining != prev.Remaining-1 && !(prev.Remaining == 0 && rl.Remaining == 0) {
+				t.Errorf("request %d: expected %d remaining after %d, got %d", i, prev.Remaining-1, prev.Remaining, rl.Remaining)
+			}
+			if rl.Header == "RateLimit" && rl.Reset > prev.Reset {
+				t.Errorf("request %d: reset went from %d to %d seconds; the window moved", i, prev.Reset, rl.Reset)
+			}
+		}
+		prev = rl
+	}
+}
+
+// ProbeRateLimit sends req until it is answered 429 Too Many Requests, at
+// most max times, and returns how many requests got through before, along
+// with the 429 response. It fails if none came within max requests.
+func ProbeRateLimit(c *Client, req *http.Request, max int) (int, *http.Response, error) {
+	body, err := bufferBody(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	for i := 0; i < max; i++ {
+		res, err := c.Request(withBody(req, body))
+		if err != nil {
+			return i, nil, err
+		}
+		if res.StatusCode == http.StatusTooManyRequests {
+			return i, res, nil
+		}
+	}
+	return max, nil, fmt.Errorf("testclient: %s %s: no 429 Too Many Requests after %d requests", req.Method, req.URL.RequestURI(), max)
+}
+
+// ExpectRateLimitThreshold sends req until the limiter answers 429 and
+// fails the test unless exactly limit requests got through, and the 429
+// tells the client when to come back with Retry-After and advertises no
+// requests remaining, if it has rate limit headers.
+func ExpectRateLimitThreshold(t testing.TB, c *Client, req *http.Request, limit int) {
+	t.Helper()
+	n, res, err := ProbeRateLimit(c, req, limit+1)
+	if err != nil {
+		t.Fatalf("expected a 429 after %d requests: %v", limit, err)
+		return
+	}
+	if n != limit {
+		t.Errorf("expected the limiter to allow %d requests, it allowed %d", limit, n)
+	}
+	if res.Header.Get("Retry-After") == "" {
+		t.Errorf("429 response has no Retry-After")
+	}
+	if rl, ok := ParseRateLimit(res); ok && rl.Remaining != 0 {
+		t.Errorf("429 response advertises %d requests remaining", rl.Remaining)
+	}
+}
@@ -0,0 +1,112 @@
+package testclient
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// limiter allows limit requests, advertising them with headers in style.
+type limiter struct {
+	limit, used int
+	style       string
+	// skip makes the countdown jump by one after the first request.
+	skip       bool
+	retryAfter bool
+}
+
+func (l *limiter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	limited := l.used == l.limit
+	if !limited {
+		l.used++
+		if l.skip && l.used == 2 {
+			l.used++
+		}
+	}
+	if limited && l.retryAfter {
+		w.Header().Set("Retry-After", "30")
+	}
+	remaining := strconv.Itoa(l.limit - l.used)
+	switch l.style {
+	case "x":
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(l.limit))
+		w.Header().Set("X-RateLimit-Remaining", remaining)
+		w.Header().Set("X-RateLimit-Reset", "1700000000")
+	case "ietf":
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(l.limit))
+		w.Header().Set("RateLimit-Remaining", remaining)
+		w.Header().Set("RateLimit-Reset", "30")
+	case "combined":
+		w.Header().Set("RateLimit", "limit="+strconv.Itoa(l.limit)+", remaining="+remaining+", reset=30")
+	case "structured":
+		w.Header().Set("RateLimit-Policy", `"default";q=`+strconv.Itoa(l.limit)+";w=60")
+		w.Header().Set("RateLimit", `"default";r=`+remaining+";t=30")
+	}
+	if limited {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}
+}
+
+func TestParseRateLimit(t *testing.T) {
+	for _, style := range []string{"x", "ietf", "combined", "structured"} {
+		c := New(&limiter{limit: 5, style: style})
+		res, _ := c.Get("/", nil)
+		rl := ExpectRateLimit(t, res, 5, 4)
+		if style == "x" && (rl.Header != "X-RateLimit" || rl.Reset != 1700000000) || style != "x" && (rl.Header != "RateLimit" || rl.Reset != 30) {
+			t.Errorf("%s: rate limit = %+v", style, rl)
+		}
+	}
+	if _, ok := ParseRateLimit(&http.Response{Header: http.Header{}}); ok {
+		t.Error("parsed a rate limit without headers")
+	}
+	for _, v := range []string{",", " , ,", ";"} {
+		if rl, ok := ParseRateLimit(&http.Response{Header: http.Header{"Ratelimit": {v}}}); ok {
+			t.Errorf("RateLimit: %q parsed as %+v", v, rl)
+		}
+	}
+	ft := &fakeT{TB: t}
+	ExpectRateLimit(ft, &http.Response{Header: http.Header{"X-Ratelimit-Limit": {"5"}, "X-Ratelimit-Remaining": {"1"}}}, 5, 2)
+	if len(ft.failures) != 1 || ft.failures[0] != "expected rate limit 5 with 2 remaining, got 5 with 1 remaining (X-RateLimit headers)" {
+		t.Errorf("failures = %q", ft.failures)
+	}
+}
+
+func TestExpectRateLimitCountdown(t *testing.T) {
+	ft := &fakeT{TB: t}
+	c := New(&limiter{limit: 3, style: "ietf"})
+	ExpectRateLimitCountdown(ft, c, c.NewRequest(http.MethodPost, "/", strings.NewReader("x")), 4)
+	if len(ft.failures) != 0 {
+		t.Errorf("failures = %q", ft.failures)
+	}
+
+	ft = &fakeT{TB: t}
+	c = New(&limiter{limit: 5, style: "x", skip: true})
+	ExpectRateLimitCountdown(ft, c, c.NewRequest(http.MethodGet, "/", nil), 3)
+	if len(ft.failures) != 1 || ft.failures[0] != "request 2: expected 3 remaining after 4, got 2" {
+		t.Errorf("failures = %q", ft.failures)
+	}
+}
+
+func TestExpectRateLimitThreshold(t *testing.T) {
+	ft := &fakeT{TB: t}
+	c := New(&limiter{limit: 3, style: "x", retryAfter: true})
+	ExpectRateLimitThreshold(ft, c, c.NewRequest(http.MethodGet, "/", nil), 3)
+	if len(ft.failures) != 0 {
+		t.Errorf("failures = %q", ft.failures)
+	}
+
+	ft = &fakeT{TB: t}
+	c = New(&limiter{limit: 4, style: "x"})
+	ExpectRateLimitThreshold(ft, c, c.NewRequest(http.MethodGet, "/", nil), 3)
+	if len(ft.failures) != 1 || !strings.HasPrefix(ft.failures[0], "expected a 429 after 3 requests: testclient: GET /: no 429 Too Many Requests after 4 requests") {
+		t.Errorf("failures = %q", ft.failures)
+	}
+
+	ft = &fakeT{TB: t}
+	c = New(&limiter{limit: 2, style: "x"})
+	ExpectRateLimitThreshold(ft, c, c.NewRequest(http.MethodGet, "/", nil), 3)
+	if strings.Join(ft.failures, "\n") != "expected the limiter to allow 3 requests, it allowed 2\n429 response has no Retry-After" {
+		t.Errorf("failures = %q", ft.failures)
+	}
+}
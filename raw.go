@@ -0,0 +1,100 @@
+package testclient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// rawTimeout bounds how long RawRequest waits for the server to answer.
+const rawTimeout = 5 * time.Second
+
+// RawRequest writes raw to a real http.Server serving the client's handlers
+// over an in-memory connection and returns the first response it sends.
+// It lets malformed requests reach net/http's parser, which
+// httptest.NewRequest refuses to build: bad header folding, invalid chunk
+// sizes, oversized header lines. The client's headers, cookies, TLS and
+// signer are not applied; the bytes are sent as given. FollowRedirect
+// follows a redirect from the request as the server parsed it.
+func (c *Client) RawRequest(raw []byte) (*http.Response, error) {
+	client, server := newConnPair()
+	l := &oneConnListener{conn: server, closed: make(chan struct{})}
+	// parsed is the request as the server read it, kept for FollowRedirect
+	var mu sync.Mutex
+	var parsed *http.Request
+	srv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			parsed = r.Clone(context.Background())
+			parsed.Body = http.NoBody
+			mu.Unlock()
+			c.handlerFor(r).ServeHTTP(w, r)
+		}),
+		ErrorLog: log.New(io.Discard, "", 0),
+	}
+	served := make(chan struct{})
+	go func() {
+		srv.Serve(l)
+		close(served)
+	}()
+	defer func() {
+		client.Close()
+		srv.Close()
+		<-served
+	}()
+
+	if _, err := client.Write(raw); err != nil {
+		return nil, err
+	}
+	client.SetReadDeadline(time.Now().Add(rawTimeout))
+	method := http.MethodGet
+	if i := bytes.IndexByte(raw, ' '); i > 0 {
+		method = string(raw[:i])
+	}
+	res, err := http.ReadResponse(bufio.NewReader(client), &http.Request{Method: method})
+	if err != nil {
+		return nil, fmt.Errorf("testclient: reading raw response: %w", err)
+	}
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	res.Body = newResponseBody(body, err)
+	mu.Lock()
+	c.request, c.response = parsed, res
+	mu.Unlock()
+	return res, nil
+}
+
+// oneConnListener hands out a single connection and then blocks until it is
+// closed.
+type oneConnListener struct {
+	mu     sync.Mutex
+	conn   net.Conn
+	once   sync.Once
+	closed chan struct{}
+}
+
+func (l *oneConnListener) Accept() (net.Conn, error) {
+	l.mu.Lock()
+	conn := l.conn
+	l.conn = nil
+	l.mu.Unlock()
+	if conn != nil {
+		return conn, nil
+	}
+	<-l.closed
+	return nil, net.ErrClosed
+}
+
+func (l *oneConnListener) Close() error {
+	l.once.Do(func() { close(l.closed) })
+	return nil
+}
+
+func (l *oneConnListener) Addr() net.Addr { return memAddr{} }
@@ -0,0 +1,106 @@
+package testclient
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRawRequest(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		io.WriteString(w, r.Method+" "+r.Host+" "+string(b))
+	})
+	c := New(h)
+	res, err := c.RawRequest([]byte("POST /echo HTTP/1.1\r\nHost: api.test\r\nTransfer-Encoding: chunked\r\n\r\n3\r\nabc\r\n0\r\n\r\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := body(t, res); got != "POST api.test abc" {
+		t.Errorf("body = %q", got)
+	}
+	if c.Response() != res {
+		t.Error("Response is not the raw response")
+	}
+}
+
+func TestRawRequestMalformed(t *testing.T) {
+	called := false
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+	c := New(h)
+	for name, raw := range map[string]string{
+		"header name": "GET / HTTP/1.1\r\nHost: a\r\nBad Header: 1\r\n\r\n",
+		"long line":   "GET / HTTP/1.1\r\nHost: a\r\nX-Big: " + strings.Repeat("x", 1<<20+8192) + "\r\n\r\n",
+		"no host":     "GET / HTTP/1.1\r\n\r\n",
+	} {
+		res, err := c.RawRequest([]byte(raw))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if res.StatusCode/100 != 4 {
+			t.Errorf("%s: status = %d, want 4xx", name, res.StatusCode)
+		}
+	}
+	if called {
+		t.Error("handler called for a request the server rejected")
+	}
+}
+
+func TestRawRequestBadChunk(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+	})
+	res, err := New(h).RawRequest([]byte("POST / HTTP/1.1\r\nHost: a\r\nTransfer-Encoding: chunked\r\n\r\nzz\r\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusBadRequest || !strings.Contains(body(t, res), "chunk") {
+		t.Errorf("response = %d %q, want the handler's 400", res.StatusCode, body(t, res))
+	}
+}
+
+func TestRawRequestHead(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "10")
+	})
+	res, err := New(h).RawRequest([]byte("HEAD / HTTP/1.1\r\nHost: a\r\n\r\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.ContentLength != 10 || body(t, res) != "" {
+		t.Errorf("HEAD response = %d bytes declared, body %q", res.ContentLength, body(t, res))
+	}
+}
+
+func TestRawRequestFollowRedirect(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/docs/old" {
+			http.Redirect(w, r, "new?x=1", http.StatusFound)
+			return
+		}
+		fmt.Fprintf(w, "%s %s", r.Host, r.URL.RequestURI())
+	})
+	c := New(h)
+	if _, err := c.RawRequest([]byte("GET /docs/old HTTP/1.1\r\nHost: api.test\r\n\r\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.FollowRedirect(); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(bufferedBody(c.Response()).data); got != "api.test /docs/new?x=1" {
+		t.Errorf("followed to %q", got)
+	}
+
+	// a request the server rejected was never parsed
+	c.RawRequest([]byte("GET / HTTP/1.1\r\n\r\n"))
+	if err := c.FollowRedirect(); err == nil {
+		t.Error("FollowRedirect succeeded after a rejected raw request")
+	}
+}
@@ -0,0 +1,72 @@
+package testclient
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// WithRedirectAudit checks the Location of every redirect, whether the
+// test follows it or not, as AuditRedirect does, and reports each problem
+// with t.Errorf. allow lists the hosts besides that of the request that
+// redirects may go to, as for AuditRedirect.
+func WithRedirectAudit(t testing.TB, allow ...string) Option {
+	return func(c *Client) {
+		c.redirectAudit = &redirectAudit{tb: t, allow: allow}
+	}
+}
+
+type redirectAudit struct {
+	tb    testing.TB
+	allow []string
+}
+
+// AuditRedirect returns the problems with the Location of res, a redirect
+// answering req: a target that is not http or https, a downgrade from
+// https to http, or a host other than that of req that allow does not
+// list. An entry of allow is a host, "*." and a domain for its subdomains,
+// or either followed by a path prefix, as in "accounts.example.com/login",
+// to allow only the paths under it. A redirect back to the host of req is
+// always allowed, which makes the audit catch open redirects in
+// return_to parameters without listing the application itself.
+func AuditRedirect(req *http.Request, res *http.Response, allow []string) []string {
+	location := res.Header.Get("Location")
+	if res.StatusCode < 300 || res.StatusCode >= 400 || location == "" {
+		return nil
+	}
+	origin := requestURL(req)
+	target, err := origin.Parse(location)
+	if err != nil {
+		return []string{fmt.Sprintf("redirect to an invalid Location %q", location)}
+	}
+	switch {
+	case target.Scheme != "http" && target.Scheme != "https":
+		return []string{fmt.Sprintf("redirect to %s, which is not http or https", target)}
+	case origin.Scheme == "https" && target.Scheme == "http":
+		return []string{fmt.Sprintf("redirect from https to %s downgrades to plain http", target)}
+	case sameHost(origin, target) || allowedTarget(target.Hostname(), target.Path, allow):
+		return nil
+	}
+	return []string{fmt.Sprintf("redirect to %s, a host not in the allowlist; an open redirect sends users to other sites", target)}
+}
+
+func allowedTarget(host, path string, allow []string) bool {
+	for _, entry := range allow {
+		pattern, prefix, _ := strings.Cut(entry, "/")
+		hostMatches := strings.EqualFold(host, pattern)
+		if domain, ok := strings.CutPrefix(pattern, "*."); ok {
+			hostMatches = len(host) > len(domain) && strings.HasSuffix(strings.ToLower(host), "."+strings.ToLower(domain))
+		}
+		if hostMatches && underPath(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// underPath reports whether path is "/"+prefix or below it.
+func underPath(path, prefix string) bool {
+	prefix = "/" + strings.TrimSuffix(prefix, "/")
+	return prefix == "/" || path == prefix || strings.HasPrefix(path, prefix+"/")
+}
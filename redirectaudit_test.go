@@ -0,0 +1,69 @@
+package testclient
+
+import (
+	"crypto/tls"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestAuditRedirect(t *testing.T) {
+	allow := []string{"accounts.example.com/login", "*.cdn.example.net"}
+	tests := []struct {
+		name     string
+		https    bool
+		status   int
+		location string
+		want     string
+	}{
+		{name: "same host", location: "/dashboard"},
+		{name: "same host other port", location: "http://app.example.com:8443/x"},
+		{name: "not a redirect", status: http.StatusOK, location: "https://evil.example"},
+		{name: "external", location: "https://evil.example/phish", want: "redirect to https://evil.example/phish, a host not in the allowlist"},
+		{name: "protocol-relative", location: "//evil.example", want: "a host not in the allowlist"},
+		{name: "allowed path", location: "https://accounts.example.com/login/callback"},
+		{name: "allowed path exactly", location: "https://accounts.example.com/login"},
+		{name: "outside allowed path", location: "https://accounts.example.com/logout", want: "not in the allowlist"},
+		{name: "path prefix is per segment", location: "https://accounts.example.com/login-evil", want: "not in the allowlist"},
+		{name: "subdomain", location: "https://eu.cdn.example.net/a.js"},
+		{name: "bare domain of wildcard", location: "https://cdn.example.net/a.js", want: "not in the allowlist"},
+		{name: "downgrade", https: true, location: "http://app.example.com/", want: "downgrades to plain http"},
+		{name: "upgrade", location: "https://app.example.com/"},
+		{name: "javascript", location: "javascript:alert(1)", want: "which is not http or https"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "http://app.example.com/login?return_to=x", nil)
+			if tt.https {
+				req.URL.Scheme = "https"
+				req.TLS = &tls.ConnectionState{}
+			}
+			status := tt.status
+			if status == 0 {
+				status = http.StatusFound
+			}
+			res := &http.Response{StatusCode: status, Header: http.Header{"Location": {tt.location}}}
+			issues := AuditRedirect(req, res, allow)
+			switch {
+			case tt.want == "" && len(issues) > 0:
+				t.Errorf("unexpected issues %q", issues)
+			case tt.want != "" && (len(issues) != 1 || !strings.Contains(issues[0], tt.want)):
+				t.Errorf("issues = %q, want one containing %q", issues, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithRedirectAudit(t *testing.T) {
+	stub := NewStub()
+	stub.On(http.MethodGet, "/login").ReplyFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, r.URL.Query().Get("return_to"), http.StatusFound)
+	})
+	ft := &fakeT{TB: t}
+	c := New(stub, WithRedirectAudit(ft))
+	c.Get("/login?return_to=/cart", nil)
+	c.Get("/login?return_to=https://evil.example/", nil)
+	if len(ft.failures) != 1 || !strings.HasPrefix(ft.failures[0], "testclient: GET http://example.com/login?return_to=https://evil.example/: redirect to https://evil.example/") {
+		t.Errorf("failures = %q", ft.failures)
+	}
+}
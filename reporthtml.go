@@ -0,0 +1,191 @@
+package testclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+)
+
+// WithHTMLArtifacts is WithArtifacts writing the Report as a self-contained
+// HTML page, for people rather than tools: a timeline of the requests with
+// expandable headers and bodies, the redirects that led to each request and
+// the cookies after each response.
+func WithHTMLArtifacts(dir string, when ArtifactPolicy) Option {
+	return func(c *Client) {
+		c.keepHistory = true
+		c.artifacts = append(c.artifacts, artifactPolicy{dir: dir, when: when, html: true})
+	}
+}
+
+// WriteHTMLReport writes the Report of the client to w as an HTML page.
+func (c *Client) WriteHTMLReport(w io.Writer) error {
+	return c.Report().WriteHTML(w)
+}
+
+// WriteHTML writes r to w as a self-contained HTML page.
+func (r Report) WriteHTML(w io.Writer) error {
+	return reportPage.Execute(w, r.page())
+}
+
+type htmlPage struct {
+	Report
+	Steps []htmlStep
+}
+
+type htmlStep struct {
+	ReportExchange
+	N int
+	// RedirectFrom is the number of the step whose redirect this request
+	// followed, or 0.
+	RedirectFrom   int
+	Class          string
+	RequestBody    string
+	ResponseBody   string
+	CookieChanges  []string
+	Cookies        []string
+	FailuresBefore []ReportFailure
+}
+
+func (r Report) page() htmlPage {
+	p := htmlPage{Report: r}
+	jar := map[string]string{}
+	failures := r.Failures
+	for i, ex := range r.Exchanges {
+		s := htmlStep{
+			ReportExchange: ex,
+			N:              i + 1,
+			Class:          statusClass(ex),
+			RequestBody:    displayBody(ex.RequestBody, ex.RequestBodyBase64),
+			ResponseBody:   displayBody(ex.ResponseBody, ex.ResponseBodyBase64),
+		}
+		for len(failures) > 0 && failures[0].AfterExchanges <= i {
+			s.FailuresBefore = append(s.FailuresBefore, failures[0])
+			failures = failures[1:]
+		}
+		if i > 0 && redirectsTo(r.Exchanges[i-1], ex.URL) {
+			s.RedirectFrom = i
+		}
+		s.CookieChanges = applyCookies(jar, ex.ResponseHeader)
+		s.Cookies = jarState(jar)
+		p.Steps = append(p.Steps, s)
+	}
+	p.Failures = failures
+	return p
+}
+
+func statusClass(ex ReportExchange) string {
+	switch {
+	case ex.Error != "" || ex.Status >= 500:
+		return "error"
+	case ex.Status >= 400:
+		return "fail"
+	case ex.Status >= 300:
+		return "redirect"
+	}
+	return "ok"
+}
+
+// redirectsTo reports whether the response of prev redirected to target.
+func redirectsTo(prev ReportExchange, target string) bool {
+	location := prev.ResponseHeader.Get("Location")
+	if prev.Status < 300 || prev.Status >= 400 || location == "" {
+		return false
+	}
+	base, err := url.Parse(prev.URL)
+	if err != nil {
+		return false
+	}
+	u, err := base.Parse(location)
+	return err == nil && u.String() == target
+}
+
+// applyCookies updates jar, cookie values by name, with the Set-Cookie
+// headers of h and describes the changes.
+func applyCookies(jar map[string]string, h http.Header) []string {
+	var changes []string
+	for _, cookie := range (&http.Response{Header: h}).Cookies() {
+		old, had := jar[cookie.Name]
+		switch {
+		case cookie.MaxAge < 0 || !cookie.Expires.IsZero() && cookie.Expires.Before(time.Now()):
+			if had {
+				delete(jar, cookie.Name)
+				changes = append(changes, "− "+cookie.Name)
+			}
+		case !had:
+			jar[cookie.Name] = cookie.Value
+			changes = append(changes, "+ "+cookie.Name+"="+cookie.Value)
+		case old != cookie.Value:
+			jar[cookie.Name] = cookie.Value
+			changes = append(changes, "~ "+cookie.Name+"="+cookie.Value)
+		}
+	}
+	return changes
+}
+
+func jarState(jar map[string]string) []string {
+	out := make([]string, 0, len(jar))
+	for name, value := range jar {
+		out = append(out, name+"="+value)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// displayBody returns a body for reading: JSON indented, binary data
+// summarized.
+func displayBody(text string, binary []byte) string {
+	if binary != nil {
+		return fmt.Sprintf("(%d bytes of binary data)", len(binary))
+	}
+	var b bytes.Buffer
+	if json.Indent(&b, []byte(text), "", "  ") == nil {
+		return b.String()
+	}
+	return text
+}
+
+var reportPage = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{with .Test}}{{.}}{{else}}Session{{end}} — testclient report</title>
+<style>
+body { font: 14px/1.4 system-ui, sans-serif; margin: 2em; color: #222; }
+h1 .failed { color: #b00; } h1 .passed { color: #070; }
+details { border-left: 4px solid #ccc; margin: .4em 0; padding: .2em .8em; }
+details.ok { border-color: #3a3; } details.redirect { border-color: #39c; }
+details.fail { border-color: #e90; } details.error { border-color: #c00; }
+summary { cursor: pointer; font-family: ui-monospace, monospace; }
+.meta { color: #777; font-size: 90%; }
+.failure { background: #fee; border: 1px solid #c00; padding: .4em .8em; white-space: pre-wrap; font-family: ui-monospace, monospace; }
+pre { background: #f6f6f6; padding: .5em; overflow-x: auto; }
+table { border-collapse: collapse; } td { padding: 0 .8em 0 0; vertical-align: top; font-family: ui-monospace, monospace; }
+</style>
+</head>
+<body>
+<h1>{{with .Test}}{{.}}{{else}}Session{{end}} {{if .Failed}}<span class="failed">failed</span>{{else}}<span class="passed">passed</span>{{end}}</h1>
+<p class="meta">{{len .Steps}} requests</p>
+{{range .Steps}}
+{{range .FailuresBefore}}<div class="failure">{{.Message}}</div>{{end}}
+<details class="{{.Class}}">
+<summary>#{{.N}} {{.Method}} {{.URL}} → {{if .Error}}error: {{.Error}}{{else}}{{.Status}}{{end}} <span class="meta">{{printf "%.3f" .DurationMS}} ms{{with .RedirectFrom}}, redirected from #{{.}}{{end}}{{with .RequestID}}, request ID {{.}}{{end}}</span>{{range .CookieChanges}} <span class="meta">{{.}}</span>{{end}}</summary>
+<h3>Request</h3>
+<table>{{range $k, $vv := .RequestHeader}}{{range $vv}}<tr><td>{{$k}}</td><td>{{.}}</td></tr>{{end}}{{end}}</table>
+{{with .RequestBody}}<pre>{{.}}</pre>{{end}}
+{{if not .Error}}<h3>Response</h3>
+<table>{{range $k, $vv := .ResponseHeader}}{{range $vv}}<tr><td>{{$k}}</td><td>{{.}}</td></tr>{{end}}{{end}}</table>
+{{with .ResponseBody}}<pre>{{.}}</pre>{{end}}{{end}}
+<h3>Cookies after</h3>
+{{if .Cookies}}<table>{{range .Cookies}}<tr><td>{{.}}</td></tr>{{end}}</table>{{else}}<p class="meta">none</p>{{end}}
+</details>
+{{end}}
+{{range .Failures}}<div class="failure">{{.Message}}</div>{{end}}
+</body>
+</html>
+`))
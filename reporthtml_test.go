@@ -0,0 +1,67 @@
+package testclient
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHTMLReport(t *testing.T) {
+	s := NewStub()
+	s.On(http.MethodPost, "/login").ReplyFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc"})
+		http.Redirect(w, r, "/home", http.StatusFound)
+	})
+	s.On(http.MethodGet, "/home").ReplyJSON(http.StatusOK, map[string]string{"greeting": "<hi>"})
+	s.On(http.MethodPost, "/logout").ReplyFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", MaxAge: -1})
+	})
+	dir := t.TempDir()
+
+	ft := newFailingT(t)
+	c := NewT(ft, s, WithHTMLArtifacts(dir, ArtifactsOnFailure))
+	c.PostForm("/login", url.Values{"user": {"alice"}})
+	c.FollowRedirect()
+	res, _ := c.Get("/home", nil)
+	ExpectHeader(c.T(), res, "Cache-Control", "no-store")
+	c.PostForm("/logout", nil)
+	ft.run()
+
+	data, err := os.ReadFile(filepath.Join(dir, artifactName(t.Name())+".html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := string(data)
+	for _, want := range []string{
+		`<span class="failed">failed</span>`,
+		`#1 POST http://example.com/login → 302`,
+		`<span class="meta">&#43; session=abc</span>`,
+		`redirected from #1`,
+		`<details class="ok">`,
+		"{\n  &#34;greeting&#34;: &#34;\\u003chi\\u003e&#34;\n}",
+		`<div class="failure">expected header Cache-Control: &#34;no-store&#34;`,
+		`<span class="meta">− session</span>`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("report lacks %q", want)
+		}
+	}
+	if i, j := strings.Index(page, "#3 GET"), strings.Index(page, `class="failure"`); j < i || strings.Index(page, "#4 POST") < j {
+		t.Error("failure not placed between the requests it came between")
+	}
+	if strings.Count(page, "redirected from") != 1 {
+		t.Error("the repeated GET /home is not a redirect")
+	}
+}
+
+func TestDisplayBody(t *testing.T) {
+	if got := displayBody("", []byte{0xff, 0}); got != "(2 bytes of binary data)" {
+		t.Errorf("binary body = %q", got)
+	}
+	if got := displayBody("a=1", nil); got != "a=1" {
+		t.Errorf("text body = %q", got)
+	}
+}
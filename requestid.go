@@ -0,0 +1,90 @@
+package testclient
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+// DefaultRequestIDHeader is the header WithRequestID uses by default.
+const DefaultRequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// requestID is the ID of a request and the header it travels in.
+type requestID struct {
+	header, id string
+}
+
+// WithRequestID attaches a generated ID to every request in header, or
+// X-Request-ID if it is empty, unless the request carries one already. The
+// ID shows in History and in the failure messages about the response, so
+// that a failing request can be found in the handler's logs.
+func WithRequestID(header string) Option {
+	return func(c *Client) {
+		if header == "" {
+			header = DefaultRequestIDHeader
+		}
+		c.requestIDHeader = http.CanonicalHeaderKey(header)
+	}
+}
+
+func (c *Client) applyRequestID(req *http.Request) {
+	if c.requestIDHeader == "" {
+		return
+	}
+	id := req.Header.Get(c.requestIDHeader)
+	if id == "" {
+		id = randomHex(c.random(), 16)
+		req.Header.Set(c.requestIDHeader, id)
+	}
+	*req = *req.WithContext(context.WithValue(req.Context(), requestIDKey{}, requestID{c.requestIDHeader, id}))
+}
+
+func requestIDOf(req *http.Request) (requestID, bool) {
+	if req == nil {
+		return requestID{}, false
+	}
+	rid, ok := req.Context().Value(requestIDKey{}).(requestID)
+	return rid, ok
+}
+
+// RequestID returns the ID of the last request under WithRequestID, or "".
+func (c *Client) RequestID() string {
+	rid, _ := requestIDOf(c.request)
+	return rid.id
+}
+
+// ExpectRequestIDEchoed asserts that the handler answered with the ID of
+// the request in the same header. res must come from a client with
+// WithRequestID.
+func ExpectRequestIDEchoed(t testing.TB, res *http.Response) {
+	t.Helper()
+	rid, ok := requestIDOf(res.Request)
+	if !ok {
+		t.Fatalf("expected a response to a request with an ID; use WithRequestID")
+		return
+	}
+	if got := res.Header.Get(rid.header); got != rid.id {
+		t.Errorf("expected %s %s echoed, got %q", rid.header, rid.id, got)
+	}
+}
+
+// ExpectRequestIDPropagated asserts that downstream, such as a request the
+// handler made to a fake third party, carries the ID of the request res
+// answers, in the same header.
+func ExpectRequestIDPropagated(t testing.TB, res *http.Response, downstream *http.Request) {
+	t.Helper()
+	rid, ok := requestIDOf(res.Request)
+	if !ok {
+		t.Fatalf("expected a response to a request with an ID; use WithRequestID")
+		return
+	}
+	if downstream == nil {
+		t.Errorf("expected a downstream request carrying %s %s, got none", rid.header, rid.id)
+		return
+	}
+	if got := downstream.Header.Get(rid.header); got != rid.id {
+		t.Errorf("expected downstream %s %s to carry %s %s, got %q", downstream.Method, downstream.URL, rid.header, rid.id, got)
+	}
+}
@@ -0,0 +1,61 @@
+package testclient
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestWithRequestID(t *testing.T) {
+	downstream := Capture(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	third := New(downstream)
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Correlation-ID")
+		if r.URL.Path == "/echo" {
+			w.Header().Set("X-Correlation-ID", id)
+		}
+		if r.URL.Path == "/call" {
+			third.Get("/hook", nil, Header("X-Correlation-ID", id))
+		}
+		w.WriteHeader(http.StatusTeapot)
+	})
+	c := New(h, WithRequestID("x-correlation-id"), WithHistory())
+
+	res, _ := c.Get("/echo", nil)
+	first := c.RequestID()
+	if len(first) != 32 {
+		t.Fatalf("RequestID() = %q", first)
+	}
+	ExpectRequestIDEchoed(t, res)
+	res, _ = c.Get("/call", nil)
+	if c.RequestID() == first {
+		t.Error("request ID reused")
+	}
+	ExpectRequestIDPropagated(t, res, downstream.Last())
+
+	c.Get("/echo", nil, Header("X-Correlation-ID", "mine"))
+	if c.RequestID() != "mine" {
+		t.Errorf("RequestID() = %q, want the explicit one", c.RequestID())
+	}
+	if got := c.History()[0].RequestID; got != first {
+		t.Errorf("History()[0].RequestID = %q, want %q", got, first)
+	}
+
+	ft := &fakeT{}
+	ExpectRequestIDEchoed(ft, res)
+	ExpectRequestIDPropagated(ft, res, nil)
+	ExpectStatus(ft, res, http.StatusOK)
+	if len(ft.failures) != 3 {
+		t.Fatalf("failures = %q", ft.failures)
+	}
+	if want := "(response to GET /call, X-Correlation-Id " + c.History()[1].RequestID + ")"; !strings.Contains(ft.failures[2], want) {
+		t.Errorf("status failure does not name the request ID:\n%s", ft.failures[2])
+	}
+
+	ft = &fakeT{}
+	plain, _ := New(h).Get("/echo", nil)
+	ExpectRequestIDEchoed(ft, plain)
+	if len(ft.failures) != 1 || !strings.Contains(ft.failures[0], "WithRequestID") {
+		t.Errorf("failures without WithRequestID = %q", ft.failures)
+	}
+}
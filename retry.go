@@ -0,0 +1,159 @@
+package testclient
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Backoff returns how long to wait before retry number n, counting from 1.
+type Backoff func(n int) time.Duration
+
+// ConstantBackoff waits d before every retry.
+func ConstantBackoff(d time.Duration) Backoff {
+	return func(int) time.Duration { return d }
+}
+
+// ExponentialBackoff waits base before the first retry and doubles the wait
+// for each further one, up to max.
+func ExponentialBackoff(base, max time.Duration) Backoff {
+	return func(n int) time.Duration {
+		d := base
+		for i := 1; i < n && d < max; i++ {
+			d *= 2
+		}
+		if d > max {
+			d = max
+		}
+		return d
+	}
+}
+
+// RetryCondition reports whether an attempt should be retried.
+type RetryCondition func(res *http.Response, err error) bool
+
+// RetryOn retries attempts that failed with an error, such as
+// ErrConnectionReset, or that were answered with one of statuses.
+func RetryOn(statuses ...int) RetryCondition {
+	return func(res *http.Response, err error) bool {
+		if err != nil {
+			return true
+		}
+		for _, s := range statuses {
+			if res.StatusCode == s {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Attempt is one try of a request.
+type Attempt struct {
+	Request  *http.Request
+	Response *http.Response
+	Err      error
+	Start    time.Time
+	Duration time.Duration
+}
+
+type retryPolicy struct {
+	retries int
+	backoff Backoff
+	retryOn RetryCondition
+}
+
+// WithRetry repeats a Request up to retries more times while retryOn holds,
+// waiting as backoff says in between. A nil backoff retries immediately. A
+// 429 or 503 response with a Retry-After header is waited out instead. The
+// request body is buffered so every attempt sends it again.
+func WithRetry(retries int, backoff Backoff, retryOn RetryCondition) Option {
+	return func(c *Client) {
+		if backoff == nil {
+			backoff = ConstantBackoff(0)
+		}
+		c.retry = &retryPolicy{retries: retries, backoff: backoff, retryOn: retryOn}
+	}
+}
+
+// Attempts returns the attempts made by the last Request, in order; the last
+// one is what Request returned.
+func (c *Client) Attempts() []Attempt {
+	return c.attempts
+}
+
+func (p *retryPolicy) do(c *Client, req *http.Request) (*http.Response, error) {
+	body, err := bufferBody(req)
+	if err != nil {
+		c.request, c.response, c.attempts = req, nil, nil
+		return nil, err
+	}
+
+	c.attempts = nil
+	for n := 0; ; n++ {
+		if n > 0 {
+			last := c.attempts[len(c.attempts)-1]
+			wait := p.backoff(n)
+			if d, ok := retryAfter(last.Response, c.clock.Now()); ok {
+				wait = d
+			}
+			if c.clock.Sleep(req.Context(), wait) != nil {
+				return last.Response, last.Err
+			}
+		}
+		try := withBody(req, body)
+		start := c.clock.Now()
+		res, err := c.serve(try)
+		c.attempts = append(c.attempts, Attempt{Request: try, Response: res, Err: err, Start: start, Duration: c.LastDuration()})
+		if n == p.retries || p.retryOn == nil || !p.retryOn(res, err) {
+			return res, err
+		}
+	}
+}
+
+// retryAfter returns the wait asked for by the Retry-After header of a 429
+// or 503 response, given either in seconds or as an HTTP date.
+func retryAfter(res *http.Response, now time.Time) (time.Duration, bool) {
+	if res == nil || (res.StatusCode != http.StatusTooManyRequests && res.StatusCode != http.StatusServiceUnavailable) {
+		return 0, false
+	}
+	v := strings.TrimSpace(res.Header.Get("Retry-After"))
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	when, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+	if d := when.Sub(now); d > 0 {
+		return d, true
+	}
+	return 0, true
+}
+
+// bufferBody reads and closes the body of req so it can be sent again.
+func bufferBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	defer req.Body.Close()
+	return io.ReadAll(req.Body)
+}
+
+// withBody returns a copy of req carrying body.
+func withBody(req *http.Request, body []byte) *http.Request {
+	try := req.Clone(req.Context())
+	if body != nil {
+		try.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	return try
+}
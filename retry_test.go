@@ -0,0 +1,133 @@
+package testclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetryUntilSuccess(t *testing.T) {
+	var bodies []string
+	calls := 0
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		io.WriteString(w, "ok")
+	})
+	c := New(h, WithRetry(3, ConstantBackoff(time.Millisecond), RetryOn(502, 503)))
+	res, err := c.Request(c.NewRequest(http.MethodPost, "/", strings.NewReader("payload")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := body(t, res); got != "ok" {
+		t.Errorf("body = %q, want ok", got)
+	}
+	attempts := c.Attempts()
+	if len(attempts) != 3 {
+		t.Fatalf("%d attempts, want 3", len(attempts))
+	}
+	for i, want := range []int{502, 502, 200} {
+		if got := attempts[i].Response.StatusCode; got != want {
+			t.Errorf("attempt %d status = %d, want %d", i, got, want)
+		}
+	}
+	for i, b := range bodies {
+		if b != "payload" {
+			t.Errorf("attempt %d body = %q, want payload", i, b)
+		}
+	}
+	if c.Response() != res {
+		t.Error("Response is not the last attempt")
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	s := NewStub()
+	route := s.On("GET", "/").Reply(http.StatusServiceUnavailable, "")
+	c := New(s, WithRetry(2, nil, RetryOn(503)))
+	res, err := c.Request(c.NewRequest(http.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusServiceUnavailable || route.Hits() != 3 || len(c.Attempts()) != 3 {
+		t.Errorf("status %d after %d hits and %d attempts, want 503 after 3", res.StatusCode, route.Hits(), len(c.Attempts()))
+	}
+}
+
+func TestRetryOnReset(t *testing.T) {
+	s := NewStub()
+	s.On("GET", "/").Reply(http.StatusOK, "ok").Inject(&Faults{ResetRate: 1})
+	c := New(s, WithRetry(1, nil, RetryOn()))
+	_, err := c.Request(c.NewRequest(http.MethodGet, "/", nil))
+	if !errors.Is(err, ErrConnectionReset) {
+		t.Errorf("err = %v, want ErrConnectionReset", err)
+	}
+	if n := len(c.Attempts()); n != 2 {
+		t.Errorf("%d attempts, want 2", n)
+	}
+}
+
+func TestRetryStopsOnContext(t *testing.T) {
+	s := NewStub()
+	s.On("GET", "/").Reply(http.StatusBadGateway, "")
+	c := New(s, WithRetry(5, ConstantBackoff(time.Hour), RetryOn(502)))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	res, _ := c.Request(c.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+	if res == nil || res.StatusCode != http.StatusBadGateway || len(c.Attempts()) != 1 {
+		t.Errorf("got %v after %d attempts, want the first 502", res, len(c.Attempts()))
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	b := ExponentialBackoff(10*time.Millisecond, 50*time.Millisecond)
+	for n, want := range map[int]time.Duration{1: 10 * time.Millisecond, 2: 20 * time.Millisecond, 3: 40 * time.Millisecond, 4: 50 * time.Millisecond, 10: 50 * time.Millisecond} {
+		if got := b(n); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", n, got, want)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	calls := 0
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch calls {
+		case 1:
+			w.Header().Set("Retry-After", "120")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.Header().Set("Retry-After", clock.Now().Add(30*time.Second).Format(http.TimeFormat))
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 3:
+			w.Header().Set("Retry-After", "soon")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	})
+	c := New(h, WithClock(clock), WithRetry(3, ConstantBackoff(time.Second), RetryOn(429, 503)))
+	start := time.Now()
+	res, err := c.Request(c.NewRequest(http.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want 200", res.StatusCode)
+	}
+	want := []time.Duration{2 * time.Minute, 30 * time.Second, time.Second}
+	if got := clock.Sleeps(); len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("waits = %v, want %v", got, want)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("retries took %v of real time", d)
+	}
+}
@@ -0,0 +1,159 @@
+package testclient
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// Robots is a parsed robots.txt.
+type Robots struct {
+	groups []robotsGroup
+	// Sitemaps are the URLs of the Sitemap lines.
+	Sitemaps []string
+}
+
+type robotsGroup struct {
+	agents []string
+	rules  []robotsRule
+}
+
+type robotsRule struct {
+	allow   bool
+	pattern string
+	re      *regexp.Regexp
+}
+
+// ParseRobots parses the content of a robots.txt.
+func ParseRobots(content string) *Robots {
+	r := &Robots{}
+	var group *robotsGroup
+	inAgents := false
+	sc := bufio.NewScanner(strings.NewReader(content))
+	for sc.Scan() {
+		line, _, _ := strings.Cut(sc.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+		switch key {
+		case "user-agent":
+			if !inAgents {
+				r.groups = append(r.groups, robotsGroup{})
+				group = &r.groups[len(r.groups)-1]
+			}
+			group.agents = append(group.agents, strings.ToLower(value))
+			inAgents = true
+		case "allow", "disallow":
+			inAgents = false
+			if group == nil || value == "" {
+				continue
+			}
+			group.rules = append(group.rules, robotsRule{allow: key == "allow", pattern: value, re: robotsPattern(value)})
+		case "sitemap":
+			r.Sitemaps = append(r.Sitemaps, value)
+		}
+	}
+	return r
+}
+
+// robotsPattern compiles a path pattern, where * matches any run of
+// characters and a final $ anchors the end.
+func robotsPattern(pattern string) *regexp.Regexp {
+	anchored := strings.HasSuffix(pattern, "$")
+	parts := strings.Split(strings.TrimSuffix(pattern, "$"), "*")
+	for i, p := range parts {
+		parts[i] = regexp.QuoteMeta(p)
+	}
+	expr := "^" + strings.Join(parts, ".*")
+	if anchored {
+		expr += "$"
+	}
+	return regexp.MustCompile(expr)
+}
+
+// Allowed reports whether agent, such as "Googlebot", may fetch path, a
+// path with its query: the group of the longest agent token agent
+// starts with applies, or else that of "*"; of its rules, the longest
+// pattern matching path wins, Allow on a tie.
+func (r *Robots) Allowed(agent, path string) bool {
+	group := r.group(strings.ToLower(agent))
+	if group == nil {
+		return true
+	}
+	allowed, longest := true, -1
+	for _, rule := range group.rules {
+		if !rule.re.MatchString(path) {
+			continue
+		}
+		if n := len(rule.pattern); n > longest || n == longest && rule.allow {
+			allowed, longest = rule.allow, n
+		}
+	}
+	return allowed
+}
+
+func (r *Robots) group(agent string) *robotsGroup {
+	var best *robotsGroup
+	bestLen := -1
+	for i := range r.groups {
+		for _, a := range r.groups[i].agents {
+			n := len(a)
+			if a == "*" {
+				n = 0
+			} else if !strings.HasPrefix(agent, a) {
+				continue
+			}
+			if n > bestLen {
+				best, bestLen = &r.groups[i], n
+			}
+		}
+	}
+	return best
+}
+
+// Robots fetches and parses /robots.txt. A missing robots.txt, answered
+// 404, allows everything.
+func (c *Client) Robots(opts ...RequestOption) (*Robots, error) {
+	res, err := c.Request(c.NewRequest(http.MethodGet, "/robots.txt", nil), opts...)
+	if err != nil {
+		return nil, err
+	}
+	switch res.StatusCode {
+	case http.StatusOK:
+		return ParseRobots(string(bufferedBody(res).data)), nil
+	case http.StatusNotFound:
+		return &Robots{}, nil
+	}
+	return nil, fmt.Errorf("testclient: GET /robots.txt: status %d", res.StatusCode)
+}
+
+// ExpectNoDisallowedLinks fetches robots.txt and then each of pages,
+// public pages that crawlers see, and fails the test for every link or GET
+// form of theirs to the same host that robots.txt disallows for all
+// crawlers ("*"): crawlers find them and report the pages as blocked.
+func ExpectNoDisallowedLinks(t testing.TB, c *Client, pages ...string) {
+	t.Helper()
+	robots, err := c.Robots()
+	if err != nil {
+		t.Fatalf("%v", err)
+		return
+	}
+	for _, page := range pages {
+		res, err := c.Request(c.NewRequest(http.MethodGet, page, nil))
+		if err != nil {
+			t.Fatalf("testclient: GET %s: %v", page, err)
+			return
+		}
+		base := requestURL(res.Request)
+		for _, link := range pageLinks(base, res) {
+			if sameHost(base, link) && !robots.Allowed("*", link.RequestURI()) {
+				t.Errorf("%s links to %s, which robots.txt disallows", page, link.RequestURI())
+			}
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package testclient
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRobotsAllowed(t *testing.T) {
+	robots := ParseRobots(`# shop
+User-agent: *
+Disallow: /cart
+Disallow: /*.pdf$
+Allow: /cart/share
+Disallow: /search?
+Disallow:
+
+User-agent: Googlebot
+User-agent: bingbot
+Disallow: /drafts
+
+Sitemap: https://www.example.com/sitemap.xml
+`)
+	tests := []struct {
+		agent, path string
+		want        bool
+	}{
+		{"*", "/", true},
+		{"*", "/cart", false},
+		{"*", "/cart/items", false},
+		{"*", "/cart/share/1", true},
+		{"*", "/docs/terms.pdf", false},
+		{"*", "/docs/terms.pdf?v=2", true},
+		{"*", "/search", true},
+		{"*", "/search?q=a", false},
+		{"Googlebot/2.1", "/cart", true},
+		{"googlebot", "/drafts/x", false},
+		{"Bingbot", "/drafts", false},
+		{"DuckDuckBot", "/drafts", true},
+	}
+	for _, tt := range tests {
+		if got := robots.Allowed(tt.agent, tt.path); got != tt.want {
+			t.Errorf("Allowed(%q, %q) = %v, want %v", tt.agent, tt.path, got, tt.want)
+		}
+	}
+	if len(robots.Sitemaps) != 1 || robots.Sitemaps[0] != "https://www.example.com/sitemap.xml" {
+		t.Errorf("sitemaps = %q", robots.Sitemaps)
+	}
+	if !ParseRobots("").Allowed("*", "/anything") {
+		t.Error("an empty robots.txt disallows")
+	}
+}
+
+func TestExpectNoDisallowedLinks(t *testing.T) {
+	stub := NewStub()
+	stub.On(http.MethodGet, "/robots.txt").Reply(http.StatusOK, "User-agent: *\nDisallow: /admin\nDisallow: /search\n")
+	stub.On(http.MethodGet, "/").Reply(http.StatusOK, `<a href="/products">Products</a> <a href="/admin/login">Staff</a>
+		<a href="https://partner.example/admin">Partner</a> <form action="/search"><input name="q"></form>`).SetHeader("Content-Type", "text/html")
+	c := New(stub)
+	ft := &fakeT{TB: t}
+	ExpectNoDisallowedLinks(ft, c, "/")
+	want := []string{"/ links to /admin/login, which robots.txt disallows", "/ links to /search?q=, which robots.txt disallows"}
+	if strings.Join(ft.failures, "\n") != strings.Join(want, "\n") {
+		t.Errorf("failures = %q", ft.failures)
+	}
+
+	ft = &fakeT{TB: t}
+	ExpectNoDisallowedLinks(ft, New(http.NotFoundHandler()), "/")
+	if len(ft.failures) != 0 {
+		t.Errorf("failures without robots.txt = %q", ft.failures)
+	}
+}
@@ -0,0 +1,183 @@
+package testclient
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// RouteSpec is a route of the application under test, as listed by its
+// router: chi.Walk, gin's Routes or echo's Routes.
+type RouteSpec struct {
+	Method string
+	// Path is the route pattern. Parameters may be written {id},
+	// {id:[0-9]+}, {path...}, :id or *path.
+	Path string
+	// Name is the Go name of the generated method; empty derives one from
+	// the method and path, e.g. GetUsersByID.
+	Name string
+}
+
+// GenerateRoutes writes the source of package pkg declaring a Routes type
+// with one method per route, taking its path parameters as arguments:
+//
+//	func (r Routes) UsersShow(id any, opts ...testclient.RequestOption) (*http.Response, error)
+//
+// Methods that carry a body (POST, PUT, PATCH) also take an io.Reader.
+// Regenerating from the router after a route is renamed or loses a
+// parameter turns stale calls into compile errors. Routes are sorted by
+// path and method so the output is stable.
+func GenerateRoutes(w io.Writer, pkg string, routes []RouteSpec) error {
+	routes = append([]RouteSpec(nil), routes...)
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by testclient.GenerateRoutes; DO NOT EDIT.\n\npackage %s\n\n", pkg)
+	fmt.Fprintf(&b, "import (\n")
+	if hasBodyRoute(routes) {
+		fmt.Fprintf(&b, "\t\"io\"\n")
+	}
+	fmt.Fprintf(&b, "\t\"net/http\"\n\n\t\"github.com/raksul/go-testclient\"\n)\n\n")
+	fmt.Fprintf(&b, "// Routes sends requests to the routes of the application.\ntype Routes struct {\n\t*testclient.Client\n}\n")
+
+	seen := map[string]RouteSpec{}
+	for _, r := range routes {
+		method := strings.ToUpper(r.Method)
+		path, params := normalizeRoute(r.Path)
+		name := r.Name
+		if name == "" {
+			name = routeName(method, path)
+		}
+		if !token.IsIdentifier(name) || !token.IsExported(name) {
+			return fmt.Errorf("testclient: route %s %s: %q is not an exported Go name", method, r.Path, name)
+		}
+		if prev, ok := seen[name]; ok {
+			return fmt.Errorf("testclient: routes %s %s and %s %s are both named %s", prev.Method, prev.Path, method, r.Path, name)
+		}
+		seen[name] = RouteSpec{Method: method, Path: r.Path}
+
+		var args, values []string
+		for _, p := range params {
+			arg := paramIdent(p)
+			args = append(args, arg+" any")
+			values = append(values, fmt.Sprintf("%q: %s", p, arg))
+		}
+		body := "nil"
+		if methodHasBody(method) {
+			args = append(args, "body io.Reader")
+			body = "body"
+		}
+		args = append(args, "opts ...testclient.RequestOption")
+		paramsExpr := "nil"
+		if len(values) > 0 {
+			paramsExpr = "testclient.Params{" + strings.Join(values, ", ") + "}"
+		}
+		fmt.Fprintf(&b, "\n// %s sends %s %s.\n", name, method, path)
+		fmt.Fprintf(&b, "func (r Routes) %s(%s) (*http.Response, error) {\n", name, strings.Join(args, ", "))
+		fmt.Fprintf(&b, "\treturn r.Do(%q, %q, %s, %s, opts...)\n}\n", method, path, paramsExpr, body)
+	}
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return fmt.Errorf("testclient: formatting generated routes: %w", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+// normalizeRoute rewrites the parameters of a router pattern as {name}
+// placeholders, and catch-alls as {name...} ones taking the rest of the
+// path, and returns their names in order.
+func normalizeRoute(pattern string) (string, []string) {
+	segs := strings.Split(pattern, "/")
+	var params []string
+	for i, seg := range segs {
+		var name, rest string
+		switch {
+		case strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}"):
+			name, _, _ = strings.Cut(seg[1:len(seg)-1], ":")
+			if n, ok := strings.CutSuffix(name, "..."); ok {
+				name, rest = n, "..."
+			}
+		case strings.HasPrefix(seg, ":"):
+			name = seg[1:]
+		case strings.HasPrefix(seg, "*") && len(seg) > 1:
+			name, rest = seg[1:], "..."
+		default:
+			continue
+		}
+		segs[i] = "{" + name + rest + "}"
+		params = append(params, name)
+	}
+	return strings.Join(segs, "/"), params
+}
+
+// routeName derives a method name such as GetUsersByIDOrders from a route.
+func routeName(method, path string) string {
+	name := exportedName(strings.ToLower(method))
+	for _, seg := range strings.Split(path, "/") {
+		if strings.HasPrefix(seg, "{") {
+			name += "By" + exportedName(seg[1:len(seg)-1])
+			continue
+		}
+		name += exportedName(seg)
+	}
+	return name
+}
+
+// exportedName camel-cases s, splitting at anything but letters and
+// digits, with the initialism ID kept upper case.
+func exportedName(s string) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if strings.EqualFold(word, "id") {
+			b.WriteString("ID")
+			continue
+		}
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return b.String()
+}
+
+// paramIdent returns a Go identifier for a path parameter that does not
+// collide with keywords or the other arguments.
+func paramIdent(p string) string {
+	name := exportedName(p)
+	if name == "" {
+		return "param"
+	}
+	ident := strings.ToLower(name[:1]) + name[1:]
+	if name == "ID" {
+		ident = "id"
+	}
+	if !token.IsIdentifier(ident) || ident == "body" || ident == "opts" || ident == "r" {
+		ident += "Param"
+	}
+	return ident
+}
+
+func methodHasBody(method string) bool {
+	return method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch
+}
+
+func hasBodyRoute(routes []RouteSpec) bool {
+	for _, r := range routes {
+		if methodHasBody(strings.ToUpper(r.Method)) {
+			return true
+		}
+	}
+	return false
+}
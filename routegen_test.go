@@ -0,0 +1,51 @@
+package testclient
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+// exampleRoutes is a route table in the dialects of several routers; the
+// code generated from it lives in internal/routesexample.
+var exampleRoutes = []RouteSpec{
+	{Method: "GET", Path: "/users"},
+	{Method: "POST", Path: "/users", Name: "UsersCreate"},
+	{Method: "GET", Path: "/users/{id}", Name: "UsersShow"},
+	{Method: "get", Path: "/users/:id/orders/:order_id"},
+	{Method: "PATCH", Path: "/users/{id:[0-9]+}/profile"},
+	{Method: "GET", Path: "/files/{path...}"},
+	{Method: "DELETE", Path: "/types/*type"},
+}
+
+func TestGenerateRoutes(t *testing.T) {
+	var b bytes.Buffer
+	if err := GenerateRoutes(&b, "routesexample", exampleRoutes); err != nil {
+		t.Fatal(err)
+	}
+	const path = "internal/routesexample/routes_gen.go"
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != string(want) {
+		t.Errorf("generated routes differ from %s:\n%s", path, b.String())
+	}
+}
+
+func TestGenerateRoutesErrors(t *testing.T) {
+	tests := []struct {
+		routes []RouteSpec
+		err    string
+	}{
+		{[]RouteSpec{{Method: "GET", Path: "/a", Name: "Show"}, {Method: "GET", Path: "/b", Name: "Show"}}, "are both named Show"},
+		{[]RouteSpec{{Method: "GET", Path: "/a", Name: "show"}}, "not an exported Go name"},
+	}
+	for _, tt := range tests {
+		var b bytes.Buffer
+		if err := GenerateRoutes(&b, "api", tt.routes); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%v: err = %v, want %q", tt.routes, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package testclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Vars are the variables of a scenario. A step refers to one as {{name}}
+// in its target, header values and body.
+type Vars map[string]string
+
+// Scenario is a multi-step flow, such as sign-up followed by checkout, run
+// in order by RunScenario.
+type Scenario struct {
+	Name string
+	// Vars are the initial variables.
+	Vars  Vars
+	Steps []Step
+}
+
+// Step is one request of a scenario and what to expect of its response.
+type Step struct {
+	Name    string
+	Request RequestSpec
+	// Status is the expected status; zero accepts any 2xx.
+	Status int
+	// Extract stores values of the response in variables for later steps.
+	// A source is "status", "header:Name", "cookie:Name" or a JSON path into
+	// the body such as "$.order.id" or "$.items[0].sku".
+	Extract map[string]string
+	// Check, if set, makes further assertions on the response.
+	Check func(res *http.Response, vars Vars) error
+}
+
+// ScenarioError reports the step at which a scenario failed, along with
+// the steps that ran before it.
+type ScenarioError struct {
+	Scenario string
+	// Step is the index of the failed step.
+	Step int
+	Err  error
+	// Response is the response of the failed step, if it got one.
+	Response *http.Response
+
+	transcript []string
+	steps      int
+}
+
+func (e *ScenarioError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "scenario %q failed at step %d/%d: %v\n", e.Scenario, e.Step+1, e.steps, e.Err)
+	for i, line := range e.transcript {
+		marker := "   "
+		if i == e.Step {
+			marker = ">  "
+		}
+		fmt.Fprintf(&b, "%s%d. %s\n", marker, i+1, line)
+	}
+	if e.Response != nil {
+		fmt.Fprintf(&b, "response of step %d:\n%s", e.Step+1, dumpResponse(e.Response))
+	}
+	return b.String()
+}
+
+func (e *ScenarioError) Unwrap() error {
+	return e.Err
+}
+
+// RunScenario runs the steps of s in order, stopping at the first whose
+// request fails, whose status is unexpected, or whose Check or Extract
+// fails, and returns the variables at that point. The error is a
+// *ScenarioError listing the steps that ran.
+func (c *Client) RunScenario(s Scenario) (Vars, error) {
+	vars := Vars{}
+	for k, v := range s.Vars {
+		vars[k] = v
+	}
+	var transcript []string
+	for i, step := range s.Steps {
+		name := step.Name
+		if name == "" {
+			name = fmt.Sprintf("step %d", i+1)
+		}
+		req, res, err := c.runStep(step, vars)
+		line := name
+		if req != nil {
+			line += "  " + req.Method + " " + req.URL.RequestURI()
+		}
+		if res != nil {
+			line += fmt.Sprintf(" → %d", res.StatusCode)
+		}
+		transcript = append(transcript, line)
+		if err != nil {
+			return vars, &ScenarioError{Scenario: s.Name, Step: i, Err: err, Response: res, transcript: transcript, steps: len(s.Steps)}
+		}
+	}
+	return vars, nil
+}
+
+func (c *Client) runStep(step Step, vars Vars) (*http.Request, *http.Response, error) {
+	spec := step.Request
+	target, err := substitute(spec.Target, vars)
+	if err != nil {
+		return nil, nil, err
+	}
+	if target == "" {
+		target = "/"
+	}
+	body, err := substitute(string(spec.Body), vars)
+	if err != nil {
+		return nil, nil, err
+	}
+	method := spec.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	var req *http.Request
+	if body == "" {
+		req = c.NewRequest(method, target, nil)
+	} else {
+		req = c.NewRequest(method, target, strings.NewReader(body))
+	}
+	for k, vv := range spec.Header {
+		for _, v := range vv {
+			v, err := substitute(v, vars)
+			if err != nil {
+				return nil, nil, err
+			}
+			req.Header.Add(k, v)
+		}
+	}
+	res, err := c.Request(req)
+	if err != nil {
+		return req, nil, err
+	}
+
+	if step.Status != 0 && res.StatusCode != step.Status {
+		return req, res, fmt.Errorf("expected status %d, got %d", step.Status, res.StatusCode)
+	}
+	if step.Status == 0 && (res.StatusCode < 200 || res.StatusCode > 299) {
+		return req, res, fmt.Errorf("expected a 2xx status, got %d", res.StatusCode)
+	}
+	for name, source := range step.Extract {
+		v, err := extract(res, source)
+		if err != nil {
+			return req, res, fmt.Errorf("extracting %s from %s: %w", name, source, err)
+		}
+		vars[name] = v
+	}
+	if step.Check != nil {
+		if err := step.Check(res, vars); err != nil {
+			return req, res, err
+		}
+	}
+	return req, res, nil
+}
+
+// substitute replaces the {{name}} references in s with their variables.
+func substitute(s string, vars Vars) (string, error) {
+	var b strings.Builder
+	for {
+		open := strings.Index(s, "{{")
+		if open < 0 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+		end := strings.Index(s[open:], "}}")
+		if end < 0 {
+			return "", fmt.Errorf("testclient: unclosed {{ in %q", s)
+		}
+		name := strings.TrimSpace(s[open+2 : open+end])
+		v, ok := vars[name]
+		if !ok {
+			return "", fmt.Errorf("testclient: undefined variable %s", name)
+		}
+		b.WriteString(s[:open])
+		b.WriteString(v)
+		s = s[open+end+2:]
+	}
+}
+
+// extract returns the value source names in res.
+func extract(res *http.Response, source string) (string, error) {
+	if source == "status" {
+		return strconv.Itoa(res.StatusCode), nil
+	}
+	var v string
+	if name, ok := strings.CutPrefix(source, "header:"); ok {
+		err := ExtractHeader(res, name, &v)
+		return v, err
+	}
+	if name, ok := strings.CutPrefix(source, "cookie:"); ok {
+		err := ExtractCookie(res, name, &v)
+		return v, err
+	}
+	if !strings.HasPrefix(source, "$") {
+		return "", fmt.Errorf("unknown source")
+	}
+	value, err := jsonAt(res, source)
+	if err != nil {
+		return "", err
+	}
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case nil:
+		return "", fmt.Errorf("%s is null", source)
+	}
+	b, _ := json.Marshal(value)
+	return string(b), nil
+}
+
+// lookupJSON follows a path such as $.items[0].sku into a decoded JSON
+// document.
+func lookupJSON(doc any, path string) (any, error) {
+	rest := strings.TrimPrefix(path, "$")
+	v := doc
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			key := rest[1 : end+1]
+			obj, ok := v.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("%s: not an object at .%s", path, key)
+			}
+			if v, ok = obj[key]; !ok {
+				return nil, fmt.Errorf("%s: no property %s", path, key)
+			}
+			rest = rest[end+1:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("%s: unclosed [", path)
+			}
+			i, err := strconv.Atoi(rest[1:end])
+			if err != nil {
+				return nil, fmt.Errorf("%s: bad index %s", path, rest[1:end])
+			}
+			arr, ok := v.([]any)
+			if !ok || i < 0 || i >= len(arr) {
+				return nil, fmt.Errorf("%s: no element %d", path, i)
+			}
+			v = arr[i]
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("%s: unexpected %q", path, rest[0])
+		}
+	}
+	return v, nil
+}
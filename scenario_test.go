@@ -0,0 +1,93 @@
+package testclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func checkoutHandler() http.Handler {
+	stub := NewStub()
+	stub.On(http.MethodPost, "/signup").ReplyFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "/users/u1")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"user": {"id": "u1", "token": "t-%s"}}`, r.FormValue("name"))
+	})
+	stub.On(http.MethodPost, "/carts").ReplyFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer t-alice" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"cart": {"id": 42, "items": [{"sku": "A-1"}]}}`)
+	})
+	stub.On(http.MethodPost, "/carts/{id}/pay").ReplyFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusPaymentRequired)
+		fmt.Fprint(w, `{"error": "card declined"}`)
+	})
+	return stub
+}
+
+func TestRunScenario(t *testing.T) {
+	form := http.Header{"Content-Type": {"application/x-www-form-urlencoded"}}
+	auth := http.Header{"Authorization": {"Bearer {{token}}"}}
+	steps := []Step{
+		{
+			Name:    "sign up",
+			Request: RequestSpec{Method: http.MethodPost, Target: "/signup", Header: form, Body: []byte("name={{name}}")},
+			Status:  http.StatusCreated,
+			Extract: map[string]string{"token": "$.user.token", "home": "header:Location"},
+		},
+		{
+			Name:    "create cart",
+			Request: RequestSpec{Method: http.MethodPost, Target: "/carts", Header: auth},
+			Extract: map[string]string{"cart": "$.cart.id", "sku": "$.cart.items[0].sku"},
+			Check: func(res *http.Response, vars Vars) error {
+				if vars["sku"] != "A-1" {
+					return errors.New("wrong sku")
+				}
+				return nil
+			},
+		},
+	}
+	c := New(checkoutHandler())
+	vars, err := c.RunScenario(Scenario{Name: "checkout", Vars: Vars{"name": "alice"}, Steps: steps})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vars["token"] != "t-alice" || vars["home"] != "/users/u1" || vars["cart"] != "42" {
+		t.Errorf("vars = %v", vars)
+	}
+
+	pay := Step{Name: "pay", Request: RequestSpec{Method: http.MethodPost, Target: "/carts/{{cart}}/pay", Header: auth}}
+	_, err = New(checkoutHandler()).RunScenario(Scenario{Name: "checkout", Vars: Vars{"name": "alice"}, Steps: append(steps, pay, steps[0])})
+	var serr *ScenarioError
+	if !errors.As(err, &serr) || serr.Step != 2 || serr.Response.StatusCode != http.StatusPaymentRequired {
+		t.Fatalf("err = %v", err)
+	}
+	report := err.Error()
+	for _, want := range []string{
+		`scenario "checkout" failed at step 3/4: expected a 2xx status, got 402`,
+		"   1. sign up  POST /signup → 201",
+		"   2. create cart  POST /carts → 200",
+		">  3. pay  POST /carts/42/pay → 402",
+		"card declined",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report lacks %q:\n%s", want, report)
+		}
+	}
+
+	_, err = New(checkoutHandler()).RunScenario(Scenario{Steps: steps[1:]})
+	if err == nil || !strings.Contains(err.Error(), "undefined variable token") {
+		t.Errorf("err = %v", err)
+	}
+	_, err = New(checkoutHandler()).RunScenario(Scenario{Vars: Vars{"name": "alice"}, Steps: []Step{{
+		Request: RequestSpec{Method: http.MethodPost, Target: "/signup", Header: form, Body: []byte("name=x")},
+		Extract: map[string]string{"id": "$.user.missing"},
+	}}})
+	if err == nil || !strings.Contains(err.Error(), "extracting id from $.user.missing: $.user.missing: no property missing") {
+		t.Errorf("err = %v", err)
+	}
+}
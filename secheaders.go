@@ -0,0 +1,152 @@
+package testclient
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// minHSTSMaxAge is the shortest Strict-Transport-Security max-age the
+// audit accepts: 180 days.
+const minHSTSMaxAge = 180 * 24 * 60 * 60
+
+// SecurityHeaderPolicy exempts routes from the security header audit.
+type SecurityHeaderPolicy struct {
+	// Exempt maps path patterns, as path.Match takes them, to the headers
+	// not required on their responses, such as X-Frame-Options on a page
+	// meant to be embedded; a pattern with no headers exempts the route
+	// from the audit.
+	Exempt map[string][]string
+}
+
+// WithSecurityHeaderAudit checks the security headers of every HTML
+// response, as AuditSecurityHeaders does, and reports each problem with
+// t.Errorf.
+func WithSecurityHeaderAudit(t testing.TB, policy SecurityHeaderPolicy) Option {
+	return func(c *Client) {
+		c.securityAudit = &securityAudit{tb: t, policy: policy}
+	}
+}
+
+type securityAudit struct {
+	tb     testing.TB
+	policy SecurityHeaderPolicy
+}
+
+// AuditSecurityHeaders returns the problems with the security headers of
+// res, an answer to req, if it is an HTML page other than a redirect:
+//   - Content-Security-Policy missing, not restricting scripts, or letting
+//     any inline script or any host run,
+//   - X-Content-Type-Options other than nosniff,
+//   - X-Frame-Options other than DENY or SAMEORIGIN, unless the policy
+//     sets frame-ancestors,
+//   - over https, Strict-Transport-Security missing or with a max-age
+//     under 180 days.
+//
+// Headers that policy exempts for the path of req are not checked.
+func AuditSecurityHeaders(req *http.Request, res *http.Response, policy SecurityHeaderPolicy) []string {
+	mt, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if (mt != "text/html" && mt != "application/xhtml+xml") || (res.StatusCode >= 300 && res.StatusCode < 400) {
+		return nil
+	}
+	exempt := map[string]bool{}
+	for pattern, headers := range policy.Exempt {
+		if ok, _ := path.Match(pattern, req.URL.Path); !ok {
+			continue
+		}
+		if len(headers) == 0 {
+			return nil
+		}
+		for _, h := range headers {
+			exempt[http.CanonicalHeaderKey(h)] = true
+		}
+	}
+
+	var issues []string
+	csp := parseCSP(res.Header.Values("Content-Security-Policy"))
+	if !exempt["Content-Security-Policy"] {
+		issues = append(issues, cspIssues(csp, res.Header.Get("Content-Security-Policy") != "")...)
+	}
+	if got := res.Header.Get("X-Content-Type-Options"); !exempt["X-Content-Type-Options"] && !strings.EqualFold(got, "nosniff") {
+		issues = append(issues, fmt.Sprintf("X-Content-Type-Options is %q, not nosniff; browsers may sniff the content as another type", got))
+	}
+	if _, ancestors := csp["frame-ancestors"]; !exempt["X-Frame-Options"] && !ancestors {
+		switch got := strings.ToUpper(res.Header.Get("X-Frame-Options")); got {
+		case "DENY", "SAMEORIGIN":
+		case "":
+			issues = append(issues, "no X-Frame-Options or CSP frame-ancestors; other sites can frame the page for clickjacking")
+		default:
+			issues = append(issues, fmt.Sprintf("X-Frame-Options %s is not DENY or SAMEORIGIN; browsers ignore it", got))
+		}
+	}
+	if req.TLS != nil && !exempt["Strict-Transport-Security"] {
+		if hsts := res.Header.Get("Strict-Transport-Security"); hsts == "" {
+			issues = append(issues, "no Strict-Transport-Security on an https response")
+		} else if maxAge := hstsMaxAge(hsts); maxAge < minHSTSMaxAge {
+			issues = append(issues, fmt.Sprintf("Strict-Transport-Security max-age=%d is under 180 days", maxAge))
+		}
+	}
+	return issues
+}
+
+// parseCSP returns the directives of a Content-Security-Policy and their
+// sources. Of repeated directives, the first counts.
+func parseCSP(values []string) map[string][]string {
+	csp := map[string][]string{}
+	for _, v := range values {
+		for _, d := range strings.Split(v, ";") {
+			fields := strings.Fields(d)
+			if len(fields) == 0 {
+				continue
+			}
+			name := strings.ToLower(fields[0])
+			if _, ok := csp[name]; !ok {
+				csp[name] = fields[1:]
+			}
+		}
+	}
+	return csp
+}
+
+func cspIssues(csp map[string][]string, present bool) []string {
+	if !present {
+		return []string{"no Content-Security-Policy"}
+	}
+	scripts, ok := csp["script-src"]
+	if !ok {
+		if scripts, ok = csp["default-src"]; !ok {
+			return []string{"Content-Security-Policy sets neither script-src nor default-src; scripts are not restricted"}
+		}
+	}
+	var issues []string
+	nonced := false
+	for _, src := range scripts {
+		if strings.HasPrefix(src, "'nonce-") || strings.HasPrefix(src, "'sha") {
+			nonced = true
+		}
+	}
+	for _, src := range scripts {
+		switch {
+		case src == "*":
+			issues = append(issues, "Content-Security-Policy lets scripts load from any host")
+		case strings.EqualFold(src, "'unsafe-inline'") && !nonced:
+			issues = append(issues, "Content-Security-Policy allows 'unsafe-inline' scripts without a nonce or hash")
+		}
+	}
+	return issues
+}
+
+func hstsMaxAge(hsts string) int {
+	for _, d := range strings.Split(hsts, ";") {
+		name, value, _ := strings.Cut(strings.TrimSpace(d), "=")
+		if strings.EqualFold(name, "max-age") {
+			n, _ := strconv.Atoi(strings.Trim(value, `"`))
+			return n
+		}
+	}
+	return 0
+}
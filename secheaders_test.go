@@ -0,0 +1,92 @@
+package testclient
+
+import (
+	"crypto/tls"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestAuditSecurityHeaders(t *testing.T) {
+	secure := func(over ...string) http.Header {
+		h := http.Header{
+			"Content-Type":              {"text/html; charset=utf-8"},
+			"Content-Security-Policy":   {"default-src 'self'"},
+			"X-Content-Type-Options":    {"nosniff"},
+			"X-Frame-Options":           {"DENY"},
+			"Strict-Transport-Security": {"max-age=31536000; includeSubDomains"},
+		}
+		for i := 0; i < len(over); i += 2 {
+			if over[i+1] == "" {
+				h.Del(over[i])
+			} else {
+				h.Set(over[i], over[i+1])
+			}
+		}
+		return h
+	}
+	tests := []struct {
+		name   string
+		path   string
+		https  bool
+		status int
+		header http.Header
+		policy SecurityHeaderPolicy
+		want   string
+	}{
+		{name: "secure", https: true, header: secure()},
+		{name: "not HTML", header: http.Header{"Content-Type": {"application/json"}}},
+		{name: "redirect", status: http.StatusFound, header: http.Header{"Content-Type": {"text/html"}}},
+		{name: "no CSP", header: secure("Content-Security-Policy", ""), want: "no Content-Security-Policy"},
+		{name: "CSP without scripts", header: secure("Content-Security-Policy", "img-src 'self'"), want: "neither script-src nor default-src"},
+		{name: "unsafe-inline", header: secure("Content-Security-Policy", "script-src 'self' 'unsafe-inline'"), want: "allows 'unsafe-inline' scripts"},
+		{name: "unsafe-inline with nonce", header: secure("Content-Security-Policy", "script-src 'nonce-abc' 'unsafe-inline'")},
+		{name: "any host", header: secure("Content-Security-Policy", "default-src *"), want: "from any host"},
+		{name: "sniffing", header: secure("X-Content-Type-Options", ""), want: `X-Content-Type-Options is "", not nosniff`},
+		{name: "no frame options", header: secure("X-Frame-Options", ""), want: "no X-Frame-Options or CSP frame-ancestors"},
+		{name: "frame-ancestors", header: secure("X-Frame-Options", "", "Content-Security-Policy", "default-src 'self'; frame-ancestors 'none'")},
+		{name: "ALLOW-FROM", header: secure("X-Frame-Options", "ALLOW-FROM https://a.example"), want: "is not DENY or SAMEORIGIN"},
+		{name: "no HSTS over https", https: true, header: secure("Strict-Transport-Security", ""), want: "no Strict-Transport-Security"},
+		{name: "no HSTS over http", header: secure("Strict-Transport-Security", "")},
+		{name: "short HSTS", https: true, header: secure("Strict-Transport-Security", "max-age=3600"), want: "max-age=3600 is under 180 days"},
+		{name: "exempt header", path: "/embed/video", header: secure("X-Frame-Options", ""), policy: SecurityHeaderPolicy{Exempt: map[string][]string{"/embed/*": {"x-frame-options"}}}},
+		{name: "exempt header elsewhere", path: "/video", header: secure("X-Frame-Options", ""), policy: SecurityHeaderPolicy{Exempt: map[string][]string{"/embed/*": {"X-Frame-Options"}}}, want: "no X-Frame-Options"},
+		{name: "exempt route", path: "/legacy", header: http.Header{"Content-Type": {"text/html"}}, policy: SecurityHeaderPolicy{Exempt: map[string][]string{"/legacy": nil}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := tt.path
+			if path == "" {
+				path = "/"
+			}
+			req, _ := http.NewRequest(http.MethodGet, "http://example.com"+path, nil)
+			if tt.https {
+				req.TLS = &tls.ConnectionState{}
+			}
+			status := tt.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			issues := AuditSecurityHeaders(req, &http.Response{StatusCode: status, Header: tt.header}, tt.policy)
+			switch {
+			case tt.want == "" && len(issues) > 0:
+				t.Errorf("unexpected issues %q", issues)
+			case tt.want != "" && (len(issues) != 1 || !strings.Contains(issues[0], tt.want)):
+				t.Errorf("issues = %q, want one containing %q", issues, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithSecurityHeaderAudit(t *testing.T) {
+	stub := NewStub()
+	stub.On(http.MethodGet, "/").Reply(http.StatusOK, "<p>hi</p>").SetHeader("Content-Type", "text/html")
+	stub.On(http.MethodGet, "/api").ReplyJSON(http.StatusOK, map[string]string{})
+	ft := &fakeT{TB: t}
+	c := New(stub, WithSecurityHeaderAudit(ft, SecurityHeaderPolicy{}))
+	c.Get("/api", nil)
+	c.Get("/", nil)
+	if len(ft.failures) != 3 || !strings.HasPrefix(ft.failures[0], "testclient: GET http://example.com/: no Content-Security-Policy") {
+		t.Errorf("failures = %q", ft.failures)
+	}
+}
@@ -0,0 +1,93 @@
+package testclient
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type shadowPolicy struct {
+	handler     http.Handler
+	t           testing.TB
+	opts        DiffOptions
+	divergences []Divergence
+}
+
+// Divergence is a request to which the shadow handler answered otherwise
+// than the handler under test.
+type Divergence struct {
+	Request *http.Request
+	Diff    ResponseDiff
+}
+
+func (d Divergence) String() string {
+	return fmt.Sprintf("%s %s diverges from the shadow:\n\t%s", d.Request.Method, requestURL(d.Request), strings.ReplaceAll(d.Diff.String(), "\n", "\n\t"))
+}
+
+// WithShadow sends every request, after the handler under test has served
+// it, to shadow as well, such as the legacy implementation the handler
+// replaces, and compares the responses with Diff under opts. A failure of
+// only one of them to respond counts as a difference at "error". Each
+// divergence is kept for Divergences and, unless t is nil, reported on t
+// as it happens. The shadow gets the same prepared request, with the
+// client's cookies and headers, through the same middleware, whatever its
+// host; faults are not injected into it and its cookies are not kept.
+// Request bodies are buffered to be sent twice.
+func WithShadow(shadow http.Handler, t testing.TB, opts DiffOptions) Option {
+	return func(c *Client) {
+		c.shadow = &shadowPolicy{handler: shadow, t: t, opts: opts}
+	}
+}
+
+// Divergences returns the divergences found under WithShadow, in order.
+func (c *Client) Divergences() []Divergence {
+	if c.shadow == nil {
+		return nil
+	}
+	return append([]Divergence(nil), c.shadow.divergences...)
+}
+
+// ExpectNoDivergence asserts that the shadow of c answered every request
+// as the handler under test did.
+func ExpectNoDivergence(t testing.TB, c *Client) {
+	t.Helper()
+	for _, d := range c.Divergences() {
+		t.Errorf("%s", d)
+	}
+}
+
+// compareShadow serves req, carrying body, on the shadow handler and
+// compares the outcome with that of the handler under test.
+func (c *Client) compareShadow(req *http.Request, body []byte, res *http.Response, err error) {
+	s := c.session()
+	s.server, s.handlers = c.shadow.handler, nil
+	s.faults, s.cacheAudit, s.cookieAudit, s.securityAudit, s.redirectAudit, s.strictWrites = nil, nil, nil, nil, nil, nil
+	s.metrics = newMetrics()
+	shadowRes, shadowErr := s.serve(withBody(req, body))
+
+	var diff ResponseDiff
+	switch {
+	case err != nil || shadowErr != nil:
+		if errText(err) != errText(shadowErr) {
+			diff = ResponseDiff{{"error", errText(err), errText(shadowErr)}}
+		}
+	default:
+		diff = Diff(res, shadowRes, c.shadow.opts)
+	}
+	if len(diff) == 0 {
+		return
+	}
+	d := Divergence{Request: req, Diff: diff}
+	c.shadow.divergences = append(c.shadow.divergences, d)
+	if c.shadow.t != nil {
+		c.shadow.t.Errorf("testclient: %s", d)
+	}
+}
+
+func errText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return fmt.Sprintf("%q", err.Error())
+}
@@ -0,0 +1,73 @@
+package testclient
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestWithShadow(t *testing.T) {
+	legacy := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Powered-By", "legacy")
+		switch r.URL.Path {
+		case "/panic":
+			panic("legacy bug")
+		case "/users":
+			fmt.Fprintf(w, `{"name": %q, "version": 1}`, b)
+		default:
+			fmt.Fprint(w, `{"ok": true}`)
+		}
+	})
+	rewrite := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/users":
+			fmt.Fprintf(w, `{"name": %q, "version": 2}`, strings.ToUpper(string(b)))
+		default:
+			fmt.Fprint(w, `{"ok": true}`)
+		}
+	})
+
+	ft := &fakeT{}
+	c := New(rewrite, WithShadow(legacy, ft, DiffOptions{IgnoreHeaders: []string{"X-Powered-By"}, IgnorePaths: []string{"$.version"}}))
+	c.Get("/", nil)
+	res, err := c.Request(c.NewRequest(http.MethodPost, "/users", strings.NewReader("ann")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := body(t, res); !strings.Contains(got, "ANN") {
+		t.Errorf("response of the handler under test = %s", got)
+	}
+	c.Get("/panic", nil)
+
+	divs := c.Divergences()
+	if len(divs) != 2 {
+		t.Fatalf("Divergences = %v", divs)
+	}
+	if got := divs[0].Diff.String(); got != `$.name: "ANN" vs "ann"` {
+		t.Errorf("diff = %s", got)
+	}
+	if divs[1].Diff[0].Where != "error" || divs[1].Request.URL.Path != "/panic" {
+		t.Errorf("divergence = %s", divs[1])
+	}
+	if len(ft.failures) != 2 || !strings.Contains(ft.failures[0], "POST http://example.com/users diverges") {
+		t.Errorf("failures = %q", ft.failures)
+	}
+	if m := c.Metrics(); m.Total() != 3 {
+		t.Errorf("metrics count shadow requests: %d", m.Total())
+	}
+
+	ft = &fakeT{}
+	ExpectNoDivergence(ft, c)
+	if len(ft.failures) != 2 {
+		t.Errorf("ExpectNoDivergence failures = %q", ft.failures)
+	}
+	same := New(rewrite, WithShadow(rewrite, nil, DiffOptions{}))
+	same.Get("/users", nil)
+	ExpectNoDivergence(t, same)
+}
@@ -0,0 +1,61 @@
+package testclient
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+)
+
+// Signer signs an outgoing request, typically by adding a header. It runs
+// last, after the client has applied its headers and cookies, for every
+// request including redirect follow-ups.
+type Signer interface {
+	Sign(*http.Request) error
+}
+
+// SignerFunc adapts a function to the Signer interface.
+type SignerFunc func(*http.Request) error
+
+func (f SignerFunc) Sign(req *http.Request) error {
+	return f(req)
+}
+
+// WithSigner signs every request with s.
+func WithSigner(s Signer) Option {
+	return func(c *Client) {
+		c.signer = s
+	}
+}
+
+// SetSigner replaces the signer; nil disables signing.
+func (c *Client) SetSigner(s Signer) {
+	c.signer = s
+}
+
+// HMACSigner signs the request body with HMAC-SHA256 and puts the hex digest
+// in Header, the scheme used by most webhook providers.
+type HMACSigner struct {
+	Key    []byte
+	Header string
+	// Prefix is prepended to the digest, e.g. "sha256=".
+	Prefix string
+}
+
+func (s HMACSigner) Sign(req *http.Request) error {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	mac := hmac.New(sha256.New, s.Key)
+	mac.Write(body)
+	req.Header.Set(s.Header, s.Prefix+hex.EncodeToString(mac.Sum(nil)))
+	return nil
+}
@@ -0,0 +1,76 @@
+package testclient
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSignerOnEveryRequest(t *testing.T) {
+	var signed []string
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signed = append(signed, r.URL.Path+":"+r.Header.Get("X-Sig"))
+		if r.URL.Path == "/start" {
+			http.Redirect(w, r, "/next", http.StatusFound)
+		}
+	})
+	n := 0
+	sig := SignerFunc(func(req *http.Request) error {
+		n++
+		req.Header.Set("X-Sig", strings.Repeat("s", n))
+		return nil
+	})
+
+	c := New(h, WithSigner(sig))
+	c.PostForm("/start", nil)
+	if err := c.FollowRedirect(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"/start:s", "/next:ss"}
+	if strings.Join(signed, ",") != strings.Join(want, ",") {
+		t.Errorf("handler saw %v, want %v", signed, want)
+	}
+}
+
+func TestSignerError(t *testing.T) {
+	called := false
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true })
+	boom := errors.New("no key")
+	c := New(h, WithSigner(SignerFunc(func(*http.Request) error { return boom })))
+
+	res, err := c.Request(c.NewRequest(http.MethodGet, "/", nil))
+	if !errors.Is(err, boom) || res != nil {
+		t.Errorf("Request = %v, %v; want the signer error", res, err)
+	}
+	if called {
+		t.Error("handler called for a request that failed to sign")
+	}
+	if err := c.PostJSON("/", 1); !errors.Is(err, boom) {
+		t.Errorf("PostJSON error = %v, want the signer error", err)
+	}
+}
+
+func TestHMACSigner(t *testing.T) {
+	key := []byte("webhook-secret")
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mac := hmac.New(sha256.New, key)
+		mac.Write(body)
+		want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+		if got := r.Header.Get("X-Hub-Signature-256"); got != want {
+			t.Errorf("signature = %q, want %q", got, want)
+		}
+		if string(body) != `{"event":"push"}` {
+			t.Errorf("body = %q after signing", body)
+		}
+	})
+	c := New(h, WithSigner(HMACSigner{Key: key, Header: "X-Hub-Signature-256", Prefix: "sha256="}))
+	if err := c.PostJSON("/hook", map[string]string{"event": "push"}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,96 @@
+package testclient
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// maxSitemaps limits how many sitemaps a sitemap index may lead to.
+const maxSitemaps = 100
+
+type sitemapXML struct {
+	URLs []struct {
+		Loc string `xml:"loc"`
+	} `xml:"url"`
+	Sitemaps []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+}
+
+// Sitemap fetches /sitemap.xml and returns the URLs it lists, following a
+// sitemap index to the sitemaps it lists.
+func (c *Client) Sitemap(opts ...RequestOption) ([]string, error) {
+	var urls []string
+	queue, fetched := []string{"/sitemap.xml"}, 0
+	for len(queue) > 0 {
+		if fetched == maxSitemaps {
+			return nil, fmt.Errorf("testclient: more than %d sitemaps", maxSitemaps)
+		}
+		loc := queue[0]
+		queue = queue[1:]
+		fetched++
+		res, err := c.Request(c.NewRequest(http.MethodGet, loc, nil), opts...)
+		if err != nil {
+			return nil, err
+		}
+		if res.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("testclient: GET %s: status %d", loc, res.StatusCode)
+		}
+		var doc sitemapXML
+		if err := xml.Unmarshal(bufferedBody(res).data, &doc); err != nil {
+			return nil, fmt.Errorf("testclient: parsing %s: %w", loc, err)
+		}
+		for _, u := range doc.URLs {
+			urls = append(urls, strings.TrimSpace(u.Loc))
+		}
+		for _, s := range doc.Sitemaps {
+			queue = append(queue, strings.TrimSpace(s.Loc))
+		}
+	}
+	return urls, nil
+}
+
+// ExpectSitemap fetches the sitemap and fails the test for every URL of it
+// that is not answered 200, unless redirects, URLs of the sitemap to
+// their intended Location, lists it and it redirects there. URLs
+// robots.txt disallows for all crawlers fail too: they cannot be indexed.
+func ExpectSitemap(t testing.TB, c *Client, redirects map[string]string) {
+	t.Helper()
+	urls, err := c.Sitemap()
+	if err != nil {
+		t.Fatalf("%v", err)
+		return
+	}
+	if len(urls) == 0 {
+		t.Errorf("the sitemap lists no URLs")
+	}
+	robots, err := c.Robots()
+	if err != nil {
+		t.Fatalf("%v", err)
+		return
+	}
+	for _, u := range urls {
+		res, err := c.Request(c.NewRequest(http.MethodGet, u, nil))
+		if err != nil {
+			t.Errorf("sitemap URL %s: %v", u, err)
+			continue
+		}
+		if !robots.Allowed("*", res.Request.URL.RequestURI()) {
+			t.Errorf("sitemap URL %s is disallowed by robots.txt", u)
+		}
+		want, redirect := redirects[u]
+		switch {
+		case redirect && res.StatusCode >= 300 && res.StatusCode < 400:
+			if target, err := res.Request.URL.Parse(res.Header.Get("Location")); err != nil || target.String() != want {
+				t.Errorf("sitemap URL %s redirects to %q, expected %s", u, res.Header.Get("Location"), want)
+			}
+		case redirect:
+			t.Errorf("sitemap URL %s: expected a redirect to %s, got %s", u, want, res.Status)
+		case res.StatusCode != http.StatusOK:
+			t.Errorf("sitemap URL %s: expected 200 OK, got %s", u, res.Status)
+		}
+	}
+}
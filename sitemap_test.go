@@ -0,0 +1,72 @@
+package testclient
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSitemap(t *testing.T) {
+	stub := NewStub()
+	stub.On(http.MethodGet, "/sitemap.xml").Reply(http.StatusOK, `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>https://www.example.com/sitemap-products.xml</loc></sitemap>
+</sitemapindex>`)
+	stub.On(http.MethodGet, "/sitemap-products.xml").Reply(http.StatusOK, `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>https://www.example.com/</loc></url>
+  <url><loc> https://www.example.com/products/1 </loc></url>
+  <url><loc>https://www.example.com/old</loc></url>
+  <url><loc>https://www.example.com/moved</loc></url>
+  <url><loc>https://www.example.com/gone</loc></url>
+  <url><loc>https://www.example.com/private/x</loc></url>
+</urlset>`)
+	stub.On(http.MethodGet, "/robots.txt").Reply(http.StatusOK, "User-agent: *\nDisallow: /private\n")
+	stub.On(http.MethodGet, "/").Reply(http.StatusOK, "home")
+	stub.On(http.MethodGet, "/products/1").Reply(http.StatusOK, "product")
+	stub.On(http.MethodGet, "/old").ReplyFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/products/1", http.StatusMovedPermanently)
+	})
+	stub.On(http.MethodGet, "/moved").ReplyFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/", http.StatusFound)
+	})
+	stub.On(http.MethodGet, "/private/x").Reply(http.StatusOK, "secret")
+	c := New(stub)
+
+	urls, err := c.Sitemap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(urls) != 6 || urls[1] != "https://www.example.com/products/1" {
+		t.Fatalf("urls = %q", urls)
+	}
+
+	ft := &fakeT{TB: t}
+	ExpectSitemap(ft, c, map[string]string{"https://www.example.com/old": "https://www.example.com/products/1"})
+	want := []string{
+		"sitemap URL https://www.example.com/moved: expected 200 OK, got 302 Found",
+		"sitemap URL https://www.example.com/gone: expected 200 OK, got 404 Not Found",
+		"sitemap URL https://www.example.com/private/x is disallowed by robots.txt",
+	}
+	if strings.Join(ft.failures, "\n") != strings.Join(want, "\n") {
+		t.Errorf("failures = %q", ft.failures)
+	}
+
+	ft = &fakeT{TB: t}
+	ExpectSitemap(ft, c, map[string]string{"https://www.example.com/old": "https://www.example.com/", "https://www.example.com/": "https://www.example.com/home"})
+	if len(ft.failures) < 2 || ft.failures[0] != "sitemap URL https://www.example.com/: expected a redirect to https://www.example.com/home, got 200 OK" ||
+		ft.failures[1] != `sitemap URL https://www.example.com/old redirects to "/products/1", expected https://www.example.com/` {
+		t.Errorf("failures = %q", ft.failures)
+	}
+}
+
+func TestSitemapErrors(t *testing.T) {
+	c := New(http.NotFoundHandler())
+	if _, err := c.Sitemap(); err == nil || err.Error() != "testclient: GET /sitemap.xml: status 404" {
+		t.Errorf("err = %v", err)
+	}
+	stub := NewStub()
+	stub.On(http.MethodGet, "/sitemap.xml").Reply(http.StatusOK, "<urlset><url>")
+	if _, err := New(stub).Sitemap(); err == nil || !strings.HasPrefix(err.Error(), "testclient: parsing /sitemap.xml:") {
+		t.Errorf("err = %v", err)
+	}
+}
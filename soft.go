@@ -0,0 +1,75 @@
+package testclient
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// ExpectAll runs checks, a group of assertions on res, with a testing.TB
+// on which failures do not stop the test, not even those reported with
+// Fatal, and then fails t once with all of them and a dump of res. One run
+// thus shows every field of a large response that is off:
+//
+//	testclient.ExpectAll(t, res, func(t testing.TB) {
+//		testclient.ExpectStatus(t, res, http.StatusOK)
+//		testclient.ExpectHeader(t, res, "Cache-Control", "no-store")
+//		testclient.ExpectHeaderAbsent(t, res, "Set-Cookie")
+//	})
+//
+// As the checks go on after a failure, one of them may panic on what an
+// earlier one rejected; the panic is reported as a failure too.
+func ExpectAll(t testing.TB, res *http.Response, checks func(t testing.TB)) {
+	t.Helper()
+	soft := &softT{TB: t}
+	func() {
+		defer func() {
+			if p := recover(); p != nil {
+				soft.Errorf("panic: %v", p)
+			}
+		}()
+		checks(soft)
+	}()
+	if !soft.failed {
+		return
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d expectations failed:\n", len(soft.failures))
+	for _, f := range soft.failures {
+		fmt.Fprintf(&b, "  - %s\n", strings.ReplaceAll(f, "\n", "\n    "))
+	}
+	b.WriteString("response:\n")
+	b.WriteString(dumpResponse(res))
+	t.Errorf("%s", b.String())
+}
+
+// softT collects failures instead of reporting them; Fatal records a
+// failure and returns.
+type softT struct {
+	testing.TB
+	failures []string
+	failed   bool
+}
+
+func (s *softT) Helper() {}
+
+func (s *softT) Errorf(format string, args ...any) {
+	s.failures = append(s.failures, fmt.Sprintf(format, args...))
+	s.failed = true
+}
+
+func (s *softT) Error(args ...any) {
+	s.failures = append(s.failures, strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+	s.failed = true
+}
+
+func (s *softT) Fatalf(format string, args ...any) { s.Errorf(format, args...) }
+
+func (s *softT) Fatal(args ...any) { s.Error(args...) }
+
+func (s *softT) Fail() { s.failed = true }
+
+func (s *softT) FailNow() { s.failed = true }
+
+func (s *softT) Failed() bool { return s.failed || s.TB.Failed() }
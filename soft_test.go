@@ -0,0 +1,55 @@
+package testclient
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestExpectAll(t *testing.T) {
+	s := NewStub()
+	s.On("GET", "/user").SetHeader("Cache-Control", "public").ReplyJSON(http.StatusOK, map[string]string{"name": "bob"})
+	c := New(s)
+	res, _ := c.Request(c.NewRequest(http.MethodGet, "/user", nil))
+
+	ExpectAll(t, res, func(t testing.TB) {
+		ExpectStatus(t, res, http.StatusOK)
+		ExpectHeader(t, res, "Cache-Control", "public")
+	})
+
+	ft := &fakeT{TB: t}
+	var ran []string
+	ExpectAll(ft, res, func(t testing.TB) {
+		ExpectStatus(t, res, http.StatusCreated)
+		ran = append(ran, "status")
+		ExpectHeader(t, res, "Cache-Control", "no-store")
+		ran = append(ran, "header")
+		ExpectHeaderAbsent(t, res, "Content-Type")
+		ran = append(ran, "absent")
+		if !t.Failed() {
+			t.Error("Failed is false after failures")
+		}
+		var missing *http.Cookie
+		_ = missing.Name
+	})
+	if len(ran) != 3 {
+		t.Errorf("checks stopped after %v", ran)
+	}
+	if len(ft.failures) != 1 {
+		t.Fatalf("failures = %q, want one report", ft.failures)
+	}
+	report := ft.failures[0]
+	for _, want := range []string{
+		"4 expectations failed:",
+		"  - expected status 201 Created, got 200 OK",
+		`  - expected header Cache-Control: "no-store", got ["public"]`,
+		`  - expected no header Content-Type, got ["application/json"]`,
+		"  - panic: runtime error: invalid memory address",
+		"response:\n200 OK\nCache-Control: public",
+		`{"name":"bob"}`,
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report does not contain %q:\n%s", want, report)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package testclient
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event is a single Server-Sent Event.
+type Event struct {
+	ID    string
+	Event string
+	Data  string
+	Retry time.Duration
+}
+
+// EventStream delivers the events of a text/event-stream response as the
+// handler writes them.
+type EventStream struct {
+	// C receives the events in order. It is closed when the stream ends or
+	// the context is cancelled.
+	C <-chan Event
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+}
+
+// Err returns the error that ended the stream, if any, once C is closed.
+func (s *EventStream) Err() error {
+	<-s.done
+	return s.err
+}
+
+// Close cancels the request and waits for the stream to stop.
+func (s *EventStream) Close() {
+	s.cancel()
+	<-s.done
+}
+
+// EventStream issues a GET for path and parses the response incrementally as
+// Server-Sent Events. Cancelling ctx cancels the handler's request context.
+func (c *Client) EventStream(ctx context.Context, path string, opts ...RequestOption) (*EventStream, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	req := c.NewRequest(http.MethodGet, path, nil).WithContext(ctx)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+	if err := c.prepare(req, opts); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	res, err := c.stream(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		cancel()
+		return nil, fmt.Errorf("event stream: bad status: %d", res.StatusCode)
+	}
+	if mt, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type")); mt != "text/event-stream" {
+		cancel()
+		return nil, fmt.Errorf("event stream: bad Content-Type: %q", res.Header.Get("Content-Type"))
+	}
+
+	events := make(chan Event)
+	s := &EventStream{C: events, cancel: cancel, done: make(chan struct{})}
+	go func() {
+		defer close(s.done)
+		defer close(events)
+		defer res.Body.Close()
+		s.err = parseEvents(ctx, bufio.NewReader(res.Body), events)
+	}()
+	return s, nil
+}
+
+// parseEvents implements the event stream interpretation of the HTML
+// Living Standard, section 9.2.6.
+func parseEvents(ctx context.Context, r *bufio.Reader, events chan<- Event) error {
+	var ev Event
+	var data strings.Builder
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if line == "" {
+				return ignoreEOF(err)
+			}
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+		if line == "" {
+			if data.Len() > 0 {
+				ev.Data = strings.TrimSuffix(data.String(), "\n")
+				if ev.Event == "" {
+					ev.Event = "message"
+				}
+				select {
+				case events <- ev:
+				case <-ctx.Done():
+					return nil
+				}
+			}
+			ev = Event{ID: ev.ID}
+			data.Reset()
+		} else if !strings.HasPrefix(line, ":") {
+			field, value, _ := strings.Cut(line, ":")
+			value = strings.TrimPrefix(value, " ")
+			switch field {
+			case "data":
+				data.WriteString(value)
+				data.WriteByte('\n')
+			case "event":
+				ev.Event = value
+			case "id":
+				if !strings.Contains(value, "\x00") {
+					ev.ID = value
+				}
+			case "retry":
+				if ms, err := strconv.Atoi(value); err == nil {
+					ev.Retry = time.Duration(ms) * time.Millisecond
+				}
+			}
+		}
+
+		if err != nil {
+			return ignoreEOF(err)
+		}
+	}
+}
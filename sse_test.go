@@ -0,0 +1,128 @@
+package testclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventStream(t *testing.T) {
+	next := make(chan struct{})
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ": hello\n\nid: 1\nevent: greet\ndata: hi\n\n")
+		w.(http.Flusher).Flush()
+		<-next
+		fmt.Fprint(w, "data: line1\ndata: line2\nretry: 1500\n\n")
+	})
+
+	s, err := New(h).EventStream(context.Background(), "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	// The first event must arrive before the handler writes the second.
+	want := Event{ID: "1", Event: "greet", Data: "hi"}
+	if got := <-s.C; got != want {
+		t.Errorf("first event = %+v, want %+v", got, want)
+	}
+	close(next)
+	want = Event{ID: "1", Event: "message", Data: "line1\nline2", Retry: 1500 * time.Millisecond}
+	if got := <-s.C; got != want {
+		t.Errorf("second event = %+v, want %+v", got, want)
+	}
+	if _, ok := <-s.C; ok {
+		t.Error("stream not closed after the handler returned")
+	}
+	if err := s.Err(); err != nil {
+		t.Errorf("Err = %v", err)
+	}
+}
+
+func TestEventStreamCancel(t *testing.T) {
+	cancelled := make(chan struct{})
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+		close(cancelled)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s, err := New(h).EventStream(ctx, "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("handler context not cancelled")
+	}
+	if _, ok := <-s.C; ok {
+		t.Error("stream not closed after cancel")
+	}
+}
+
+func TestEventStreamErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		dialErr string
+		err     string
+	}{
+		{
+			name: "status",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "nope", http.StatusForbidden)
+			},
+			dialErr: "bad status",
+		},
+		{
+			name: "content type",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("{}"))
+			},
+			dialErr: "bad Content-Type",
+		},
+		{
+			name: "panic before header",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				panic("early")
+			},
+			dialErr: "early",
+		},
+		{
+			name: "panic mid-stream",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				w.(http.Flusher).Flush()
+				panic("late")
+			},
+			err: "late",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := New(tt.handler).EventStream(context.Background(), "/events")
+			if tt.dialErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.dialErr) {
+					t.Fatalf("EventStream error = %v, want %q", err, tt.dialErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for range s.C {
+			}
+			if err := s.Err(); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Err = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package testclient
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// streamWriter is a ResponseWriter that hands the body to the reader as the
+// handler writes it, instead of buffering it like httptest.ResponseRecorder.
+type streamWriter struct {
+	header http.Header
+	pr     *io.PipeReader
+	pw     *io.PipeWriter
+	res    *http.Response
+	err    error
+
+	declared []string
+	ready    chan struct{}
+	once     sync.Once
+
+	// done is closed when the handler returns, after panicErr is set to
+	// its panic, if any; released tells whether the caller closed the body
+	done     chan struct{}
+	panicErr *PanicError
+	mu       sync.Mutex
+	released bool
+}
+
+func newStreamWriter() *streamWriter {
+	pr, pw := io.Pipe()
+	return &streamWriter{
+		header: http.Header{},
+		pr:     pr,
+		pw:     pw,
+		ready:  make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+func (w *streamWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *streamWriter) WriteHeader(code int) {
+	w.once.Do(func() {
+		header := w.header.Clone()
+		w.declared = declaredTrailers(header)
+		stripTrailerHeaders(header)
+		w.res = &http.Response{
+			Status:        fmt.Sprintf("%03d %s", code, http.StatusText(code)),
+			StatusCode:    code,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          streamBody{w},
+			ContentLength: -1,
+		}
+		if len(w.declared) > 0 {
+			w.res.Trailer = http.Header{}
+			for _, k := range w.declared {
+				w.res.Trailer[k] = nil
+			}
+		}
+		close(w.ready)
+	})
+}
+
+// streamBody is the body of a streamed response.
+type streamBody struct {
+	w *streamWriter
+}
+
+func (b streamBody) Read(p []byte) (int, error) { return b.w.pr.Read(p) }
+
+func (b streamBody) Close() error {
+	b.w.mu.Lock()
+	b.w.released = true
+	b.w.mu.Unlock()
+	return b.w.pr.Close()
+}
+
+// closeUnreleased closes the body unless the caller did already, waits for
+// the handler to return and returns its panic, if any.
+func (w *streamWriter) closeUnreleased() error {
+	w.mu.Lock()
+	released := w.released
+	w.mu.Unlock()
+	if released {
+		return nil
+	}
+	w.pr.Close()
+	select {
+	case <-w.done:
+	case <-time.After(wsTimeout):
+		return fmt.Errorf("testclient: stream handler did not return within %v of closing the body", wsTimeout)
+	}
+	if w.panicErr != nil {
+		return w.panicErr
+	}
+	return nil
+}
+
+// fail reports err to the reader: as the result of stream if the header has
+// not been committed yet, and as the error ending the body otherwise.
+func (w *streamWriter) fail(err error) {
+	w.once.Do(func() {
+		w.err = err
+		close(w.ready)
+	})
+	w.pw.CloseWithError(err)
+}
+
+func (w *streamWriter) Write(b []byte) (int, error) {
+	if w.header.Get("Content-Type") == "" {
+		w.header.Set("Content-Type", http.DetectContentType(b))
+	}
+	w.WriteHeader(http.StatusOK)
+	return w.pw.Write(b)
+}
+
+// Flush commits the header; written bytes are already with the reader.
+func (w *streamWriter) Flush() {
+	w.WriteHeader(http.StatusOK)
+}
+
+// stream serves req on a new goroutine and returns as soon as the handler
+// commits its header. The response body yields bytes as they are written.
+func (c *Client) stream(req *http.Request) (*http.Response, error) {
+	w := newStreamWriter()
+	handler := c.handlerFor(req)
+	go func() {
+		finished := make(chan struct{})
+		defer close(w.done)
+		defer func() {
+			close(finished)
+			if p := recover(); p != nil {
+				w.panicErr = newPanicError(p, req)
+				w.fail(w.panicErr)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			// like http.Transport, trailers are filled in before the body
+			// reports EOF
+			if t := finalTrailers(w.header, w.declared); t != nil {
+				if w.res.Trailer == nil {
+					w.res.Trailer = http.Header{}
+				}
+				for k, vv := range t {
+					w.res.Trailer[k] = vv
+				}
+			}
+			w.pw.Close()
+		}()
+		go func() {
+			select {
+			case <-req.Context().Done():
+				w.pr.CloseWithError(req.Context().Err())
+			case <-finished:
+			}
+		}()
+		handler.ServeHTTP(w, req)
+	}()
+
+	<-w.ready
+	if w.res == nil {
+		return nil, w.err
+	}
+	if c.decompress {
+		decodeBody(w.res)
+	}
+	c.track(closerFunc(w.closeUnreleased))
+	c.record(req, w.res)
+	return w.res, nil
+}
+
+func ignoreEOF(err error) error {
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
+// Stream sends req like Request but without buffering the response: it
+// returns once the handler commits its header, and the body delivers bytes as
+// the handler writes them. The caller must close the body; doing so makes the
+// handler's further writes fail with io.ErrClosedPipe.
+func (c *Client) Stream(req *http.Request, opts ...RequestOption) (*http.Response, error) {
+	if err := c.prepare(req, opts); err != nil {
+		return nil, err
+	}
+	return c.stream(req)
+}
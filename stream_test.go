@@ -0,0 +1,90 @@
+package testclient
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStreamIncremental(t *testing.T) {
+	next := make(chan struct{})
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Mode", "stream")
+		io.WriteString(w, "first\n")
+		<-next
+		io.WriteString(w, "second\n")
+	})
+
+	c := New(h)
+	res, err := c.Stream(c.NewRequest(http.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK || res.Header.Get("X-Mode") != "stream" {
+		t.Errorf("got %d %v, want 200 with X-Mode", res.StatusCode, res.Header)
+	}
+
+	br := bufio.NewReader(res.Body)
+	if line, _ := br.ReadString('\n'); line != "first\n" {
+		t.Errorf("first line = %q", line)
+	}
+	close(next)
+	rest, err := io.ReadAll(br)
+	if err != nil || string(rest) != "second\n" {
+		t.Errorf("rest = %q, %v", rest, err)
+	}
+}
+
+func TestStreamLargeBody(t *testing.T) {
+	const chunks = 1024
+	chunk := strings.Repeat("x", 64*1024)
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < chunks; i++ {
+			io.WriteString(w, chunk)
+		}
+	})
+
+	c := New(h)
+	res, err := c.Stream(c.NewRequest(http.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	n, err := io.Copy(io.Discard, res.Body)
+	if err != nil || n != chunks*int64(len(chunk)) {
+		t.Errorf("read %d bytes, %v; want %d", n, err, chunks*len(chunk))
+	}
+}
+
+func TestStreamCloseStopsHandler(t *testing.T) {
+	writeErr := make(chan error, 1)
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.(http.Flusher).Flush()
+		for {
+			if _, err := io.WriteString(w, "tick"); err != nil {
+				writeErr <- err
+				return
+			}
+		}
+	})
+
+	c := New(h)
+	res, err := c.Stream(c.NewRequest(http.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	select {
+	case err := <-writeErr:
+		if !errors.Is(err, io.ErrClosedPipe) {
+			t.Errorf("write error = %v, want io.ErrClosedPipe", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("handler kept writing after the body was closed")
+	}
+}
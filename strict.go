@@ -0,0 +1,59 @@
+package testclient
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// WithStrictWrites fails t whenever a handler serving a Request misuses
+// its ResponseWriter in ways a real server only logs or hides: calling
+// WriteHeader again after the header was written, changing headers after
+// that (other than trailers), or declaring a Content-Length other than
+// the number of body bytes written.
+func WithStrictWrites(t testing.TB) Option {
+	return func(c *Client) {
+		c.strictWrites = t
+	}
+}
+
+// writeProblems describes the misuses of rec by the handler that wrote
+// res, before trailers are moved out of its header.
+func writeProblems(req *http.Request, rec *timelineRecorder, res *http.Response) []string {
+	var out []string
+	for _, code := range rec.superfluous {
+		out = append(out, fmt.Sprintf("superfluous WriteHeader(%d) after the header was written with %d", code, res.StatusCode))
+	}
+
+	trailers := map[string]bool{}
+	for _, k := range declaredTrailers(res.Header) {
+		trailers[http.CanonicalHeaderKey(k)] = true
+	}
+	var changed []string
+	for _, h := range []http.Header{rec.HeaderMap, res.Header} {
+		for k := range h {
+			if trailers[k] || strings.HasPrefix(k, http.TrailerPrefix) || contains(changed, k) {
+				continue
+			}
+			if strings.Join(rec.HeaderMap[k], "\x00") != strings.Join(res.Header[k], "\x00") {
+				changed = append(changed, k)
+			}
+		}
+	}
+	sort.Strings(changed)
+	for _, k := range changed {
+		out = append(out, fmt.Sprintf("header %s changed after the header was written", k))
+	}
+
+	if cl := res.Header.Get("Content-Length"); cl != "" && req.Method != http.MethodHead &&
+		res.StatusCode != http.StatusNoContent && res.StatusCode != http.StatusNotModified {
+		n, err := strconv.ParseInt(cl, 10, 64)
+		if written := int64(rec.Body.Len()); err != nil || n != written {
+			out = append(out, fmt.Sprintf("Content-Length %s does not match the %d body bytes written", cl, written))
+		}
+	}
+	return out
+}
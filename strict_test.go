@@ -0,0 +1,58 @@
+package testclient
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestWithStrictWrites(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/twice":
+			w.WriteHeader(http.StatusCreated)
+			w.WriteHeader(http.StatusInternalServerError)
+		case "/implicit":
+			io.WriteString(w, "body")
+			w.WriteHeader(http.StatusNotFound)
+		case "/late-header":
+			io.WriteString(w, "body")
+			w.Header().Set("X-Request-Time", "3ms")
+		case "/length":
+			w.Header().Set("Content-Length", "10")
+			io.WriteString(w, "short")
+		case "/fine":
+			w.Header().Set("Trailer", "X-Checksum")
+			w.Header().Set("Content-Length", "4")
+			w.WriteHeader(http.StatusEarlyHints)
+			io.WriteString(w, "body")
+			w.Header().Set("X-Checksum", "abc")
+			w.Header().Set(http.TrailerPrefix+"X-Extra", "1")
+		}
+	})
+	ft := &fakeT{}
+	c := New(h, WithStrictWrites(ft))
+	for _, p := range []string{"/fine", "/twice", "/implicit", "/late-header", "/length"} {
+		c.Get(p, nil)
+	}
+	c.Request(c.NewRequest(http.MethodHead, "/length", nil))
+
+	want := []string{
+		"GET http://example.com/twice: superfluous WriteHeader(500) after the header was written with 201",
+		"GET http://example.com/implicit: superfluous WriteHeader(404) after the header was written with 200",
+		"GET http://example.com/late-header: header X-Request-Time changed after the header was written",
+		"GET http://example.com/length: Content-Length 10 does not match the 5 body bytes written",
+	}
+	if len(ft.failures) != len(want) {
+		t.Fatalf("failures = %q", ft.failures)
+	}
+	for i, w := range want {
+		if !strings.HasSuffix(ft.failures[i], w) {
+			t.Errorf("failure %d = %q, want %q", i, ft.failures[i], w)
+		}
+	}
+	if res, _ := c.Get("/fine", nil); res.Trailer.Get("X-Checksum") != "abc" {
+		t.Errorf("trailers = %v", res.Trailer)
+	}
+}
@@ -0,0 +1,493 @@
+package testclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"text/template"
+)
+
+// Stub is an http.Handler serving canned responses from registered routes.
+// Use it as the handler of a Client or as a fake upstream of the handler
+// under test.
+type Stub struct {
+	mu        sync.Mutex
+	routes    []*Route
+	strict    testing.TB
+	fallback  http.Handler
+	forwarded []exchange
+	states    map[string]string
+
+	// base is the stub an InstanceFor instance shares the routes of, and
+	// hits counts the requests the instance served by route
+	base *Stub
+	hits map[*Route]int
+	tb   testing.TB
+
+	// seed and rand are those of RandSeed
+	seed int64
+	rand *seededRand
+}
+
+// StubOption configures a Stub.
+type StubOption func(*Stub)
+
+// Strict makes the stub fail t on any request that matches no route,
+// reporting the offending method, URL and body, instead of answering 404.
+func Strict(t testing.TB) StubOption {
+	return func(s *Stub) {
+		s.strict = t
+	}
+}
+
+// Fallback makes the stub forward a request that matches no route to h,
+// such as the real router or an httputil.ReverseProxy to a recorded
+// upstream, so that only some routes need stubbing. Forwarded requests and
+// their responses are kept for Forwarded; the responses are buffered, so h
+// cannot stream or hijack. Fallback takes precedence over Strict.
+func Fallback(h http.Handler) StubOption {
+	return func(s *Stub) {
+		s.fallback = h
+	}
+}
+
+// NewStub returns a stub with no routes.
+func NewStub(opts ...StubOption) *Stub {
+	s := &Stub{}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// InstanceFor returns a view of the stub for the test t, to serve it in
+// one of several parallel subtests sharing the routes of s. The instance
+// counts hits per route for Hits, runs reply sequences and scenario states
+// of its own, starting afresh, and reports unmatched requests under Strict
+// and exhausted sequences to t. Routes registered on the instance take
+// precedence over the shared ones and serve it alone.
+func (s *Stub) InstanceFor(t testing.TB) *Stub {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	inst := &Stub{base: s, hits: map[*Route]int{}, tb: t, fallback: s.fallback}
+	if s.strict != nil {
+		inst.strict = t
+	}
+	if s.rand != nil {
+		inst.seed, inst.rand = s.seed, newSeededRand(s.seed)
+	}
+	return inst
+}
+
+// Hits returns how many requests r served through s. For the stub the
+// route was registered on, this is r.Hits(); an instance counts its own.
+func (s *Stub) Hits(r *Route) int {
+	if s.base == nil {
+		return r.Hits()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.hits[r]
+}
+
+// hit counts a request to r and returns its index in the reply sequence.
+func (s *Stub) hit(r *Route) int {
+	r.mu.Lock()
+	r.hits++
+	n := r.hits - r.seqStart - 1
+	r.mu.Unlock()
+	if s.base == nil {
+		return n
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hits[r]++
+	return s.hits[r] - 1
+}
+
+// Route is a registered stub route and its canned response.
+type Route struct {
+	method string
+	path   string
+
+	mu      sync.Mutex
+	status  int
+	header  http.Header
+	body    []byte
+	handler http.HandlerFunc
+	tmpl    *template.Template
+	faults  *Faults
+	hits    int
+
+	// sequence holds the statuses of ReplySequence, from hit seqStart on
+	sequence []int
+	seqStart int
+	seqFail  testing.TB
+
+	// when and then are the states of WhenState and SetsState
+	when, then stateOf
+	// conditions are those of WhenQuery, WhenHeader and the body matchers
+	conditions []condition
+}
+
+// stateOf is a state of a stub scenario; the zero value is none.
+type stateOf struct {
+	scenario, state string
+}
+
+// StateStarted is the state every stub scenario starts in.
+const StateStarted = "Started"
+
+// On registers a route for method and path. An empty method or "*" matches
+// any method; a path segment written as {name} matches any segment. The
+// route replies 200 with an empty body until told otherwise.
+func (s *Stub) On(method, path string) *Route {
+	r := &Route{
+		method: strings.ToUpper(method),
+		path:   path,
+		status: http.StatusOK,
+		header: http.Header{},
+	}
+	s.mu.Lock()
+	s.routes = append(s.routes, r)
+	s.mu.Unlock()
+	return r
+}
+
+// Reply sets the status and body of the response.
+func (r *Route) Reply(status int, body string) *Route {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status, r.body, r.handler, r.tmpl = status, []byte(body), nil, nil
+	return r
+}
+
+// ReplyJSON replies with v encoded as JSON.
+func (r *Route) ReplyJSON(status int, v any) *Route {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic("testclient: ReplyJSON: " + err.Error())
+	}
+	r.SetHeader("Content-Type", "application/json")
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status, r.body, r.handler, r.tmpl = status, b, nil, nil
+	return r
+}
+
+// ReplyFunc serves the route with h.
+func (r *Route) ReplyFunc(h http.HandlerFunc) *Route {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handler = h
+	return r
+}
+
+// ReplySequence replies with the statuses in turn on successive requests,
+// such as 503, 503, 200 for a client that should retry, and then keeps
+// repeating the last unless FailAfterSequence is set. The body and headers
+// are those set with Reply, ReplyJSON or SetHeader.
+func (r *Route) ReplySequence(statuses ...int) *Route {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sequence, r.seqStart, r.handler = statuses, r.hits, nil
+	return r
+}
+
+// FailAfterSequence makes a request past the end of the ReplySequence fail
+// t, reporting how many requests were expected, and get a 501.
+func (r *Route) FailAfterSequence(t testing.TB) *Route {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seqFail = t
+	return r
+}
+
+// WhenState makes the route match only while the stub scenario named
+// scenario is in state, so that routes of the same path can model a
+// stateful API, such as a job that is pending and later done.
+func (r *Route) WhenState(scenario, state string) *Route {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.when = stateOf{scenario, state}
+	return r
+}
+
+// SetsState moves the stub scenario named scenario to state when the
+// route matches a request.
+func (r *Route) SetsState(scenario, state string) *Route {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.then = stateOf{scenario, state}
+	return r
+}
+
+// State returns the state of the stub scenario named scenario.
+func (s *Stub) State(scenario string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state(scenario)
+}
+
+// ResetStates moves every stub scenario back to StateStarted.
+func (s *Stub) ResetStates() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states = nil
+}
+
+// state returns the state of scenario; s.mu is held.
+func (s *Stub) state(scenario string) string {
+	if st, ok := s.states[scenario]; ok {
+		return st
+	}
+	return StateStarted
+}
+
+// SetHeader adds a response header.
+func (r *Route) SetHeader(key, value string) *Route {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.header.Set(key, value)
+	return r
+}
+
+// Hits returns how many requests the route has served.
+func (r *Route) Hits() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.hits
+}
+
+// matches reports whether the route serves req, whose body body returns,
+// and returns the values of its {name} path parameters.
+func (r *Route) matches(req *http.Request, body func() []byte) (map[string]string, bool) {
+	if r.method != "" && r.method != "*" && r.method != req.Method {
+		return nil, false
+	}
+	params, ok := matchPath(r.path, req.URL)
+	if !ok {
+		return nil, false
+	}
+	r.mu.Lock()
+	conditions := r.conditions
+	r.mu.Unlock()
+	for _, cond := range conditions {
+		if !cond(req, body) {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// matchPath matches the path of u against pattern, where a segment written
+// as {name} matches any single non-empty segment and a last one written as
+// {name...} the rest of the path. Segments are compared unescaped, so an
+// encoded slash stays inside its segment.
+func matchPath(pattern string, u *url.URL) (map[string]string, bool) {
+	if !strings.Contains(pattern, "{") {
+		return nil, pattern == u.Path
+	}
+	want := strings.Split(pattern, "/")
+	got := strings.Split(u.EscapedPath(), "/")
+	params := map[string]string{}
+	if last := want[len(want)-1]; strings.HasPrefix(last, "{") && strings.HasSuffix(last, "...}") {
+		n := len(want) - 1
+		if len(got) <= n {
+			return nil, false
+		}
+		rest, err := url.PathUnescape(strings.Join(got[n:], "/"))
+		if err != nil {
+			return nil, false
+		}
+		params[last[1:len(last)-4]] = rest
+		want, got = want[:n], got[:n]
+	}
+	if len(want) != len(got) {
+		return nil, false
+	}
+	for i, seg := range want {
+		v, err := url.PathUnescape(got[i])
+		if err != nil {
+			return nil, false
+		}
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			if v == "" {
+				return nil, false
+			}
+			params[seg[1:len(seg)-1]] = v
+			continue
+		}
+		if seg != v {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// serve serves req, the (n+1)th request to the route in its sequence.
+func (r *Route) serve(w http.ResponseWriter, req *http.Request, params map[string]string, n int, tb testing.TB) {
+	r.mu.Lock()
+	status, body, handler, tmpl := r.status, r.body, r.handler, r.tmpl
+	if len(r.sequence) > 0 {
+		switch {
+		case n < len(r.sequence):
+			status = r.sequence[n]
+		case r.seqFail != nil:
+			t, want := r.seqFail, len(r.sequence)
+			if tb != nil {
+				t = tb
+			}
+			r.mu.Unlock()
+			t.Errorf("testclient: stub route %s %s got request %d, past its sequence of %d", req.Method, r.path, n+1, want)
+			http.Error(w, "testclient: reply sequence exhausted", http.StatusNotImplemented)
+			return
+		default:
+			status = r.sequence[len(r.sequence)-1]
+		}
+	}
+	for k, vv := range r.header {
+		w.Header()[k] = append([]string(nil), vv...)
+	}
+	r.mu.Unlock()
+
+	if handler != nil {
+		handler(w, req.WithContext(context.WithValue(req.Context(), pathParamsKey{}, params)))
+		return
+	}
+	if tmpl != nil {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, newTemplateData(req, params)); err != nil {
+			http.Error(w, "testclient: rendering fixture: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		body = buf.Bytes()
+	}
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+func (s *Stub) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if r, params := s.match(req); r != nil {
+		r.mu.Lock()
+		faults := r.faults
+		r.mu.Unlock()
+		if faults == nil {
+			r.serve(w, req, params, s.hit(r), s.tb)
+			return
+		}
+		faults.wrap(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			r.serve(w, req, params, s.hit(r), s.tb)
+		}), s.rand).ServeHTTP(w, req)
+		return
+	}
+	s.unmatched(w, req)
+}
+
+func (s *Stub) match(req *http.Request) (*Route, map[string]string) {
+	var body []byte
+	read := false
+	readBody := func() []byte {
+		if !read {
+			body, _ = keepBody(req)
+			read = true
+		}
+		return body
+	}
+	// conditions run unlocked, so that a When predicate may call the stub
+	s.mu.Lock()
+	routes := append([]*Route(nil), s.routes...)
+	s.mu.Unlock()
+	if s.base != nil {
+		s.base.mu.Lock()
+		routes = append(routes, s.base.routes...)
+		s.base.mu.Unlock()
+	}
+	for _, r := range routes {
+		params, ok := r.matches(req, readBody)
+		if ok && s.enter(r) {
+			return r, params
+		}
+	}
+	return nil, nil
+}
+
+// enter reports whether the scenario state of s lets r serve a request
+// and, if so, moves the scenario on as r says.
+func (s *Stub) enter(r *Route) bool {
+	r.mu.Lock()
+	when, then := r.when, r.then
+	r.mu.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if when.scenario != "" && s.state(when.scenario) != when.state {
+		return false
+	}
+	if then.scenario != "" {
+		if s.states == nil {
+			s.states = map[string]string{}
+		}
+		s.states[then.scenario] = then.state
+	}
+	return true
+}
+
+func (s *Stub) unmatched(w http.ResponseWriter, req *http.Request) {
+	if s.fallback != nil {
+		s.forward(w, req)
+		return
+	}
+	if s.strict == nil {
+		http.NotFound(w, req)
+		return
+	}
+	var body []byte
+	if req.Body != nil {
+		body, _ = io.ReadAll(req.Body)
+	}
+	s.strict.Errorf("testclient: stub received unexpected request %s %s\n%s", req.Method, req.URL.RequestURI(), body)
+	http.Error(w, "testclient: unexpected request", http.StatusNotImplemented)
+}
+
+func (s *Stub) forward(w http.ResponseWriter, req *http.Request) {
+	body, err := keepBody(req)
+	if err != nil {
+		http.Error(w, "testclient: reading request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	kept := req.Clone(req.Context())
+	rec := httptest.NewRecorder()
+	s.fallback.ServeHTTP(rec, req)
+
+	res := rec.Result()
+	res.Body = newResponseBody(rec.Body.Bytes(), nil)
+	s.mu.Lock()
+	s.forwarded = append(s.forwarded, exchange{req: kept, body: body, res: res})
+	s.mu.Unlock()
+
+	for k, vv := range rec.Header() {
+		w.Header()[k] = vv
+	}
+	w.WriteHeader(rec.Code)
+	w.Write(rec.Body.Bytes())
+}
+
+// Forwarded returns the requests the stub forwarded to its Fallback, with
+// the responses they got, oldest first. Passed to GenerateTest they make a
+// test pinning the behavior of the real handler.
+func (s *Stub) Forwarded() []Exchange {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Exchange, len(s.forwarded))
+	for i, ex := range s.forwarded {
+		out[i] = ex.open()
+	}
+	return out
+}
@@ -0,0 +1,272 @@
+package testclient
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// fakeT records failures instead of failing the enclosing test.
+type fakeT struct {
+	testing.TB
+	failures []string
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Errorf(format string, args ...any) {
+	f.failures = append(f.failures, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeT) Fatalf(format string, args ...any) {
+	f.Errorf(format, args...)
+}
+
+func TestStubRoutes(t *testing.T) {
+	stub := NewStub()
+	users := stub.On(http.MethodGet, "/users").ReplyJSON(http.StatusOK, []string{"alice"})
+	stub.On("*", "/any").Reply(http.StatusAccepted, "ok").SetHeader("X-Stub", "1")
+	stub.On(http.MethodPost, "/echo").ReplyFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.FormValue("v")))
+	})
+	c := New(stub)
+
+	c.Request(c.NewRequest(http.MethodGet, "/users", nil))
+	res := c.Response()
+	if res.StatusCode != http.StatusOK || res.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("GET /users = %d %v", res.StatusCode, res.Header)
+	}
+	if got := body(t, res); got != `["alice"]` {
+		t.Errorf("GET /users body = %q", got)
+	}
+	if users.Hits() != 1 {
+		t.Errorf("Hits = %d, want 1", users.Hits())
+	}
+
+	c.Request(c.NewRequest(http.MethodDelete, "/any", nil))
+	if res := c.Response(); res.StatusCode != http.StatusAccepted || res.Header.Get("X-Stub") != "1" {
+		t.Errorf("DELETE /any = %d %v", res.StatusCode, res.Header)
+	}
+
+	c.PostForm("/echo", url.Values{"v": {"hi"}})
+	if got := body(t, c.Response()); got != "hi" {
+		t.Errorf("POST /echo = %q", got)
+	}
+
+	c.PostForm("/users", nil)
+	if res := c.Response(); res.StatusCode != http.StatusNotFound {
+		t.Errorf("POST /users = %d, want 404 from a non-strict stub", res.StatusCode)
+	}
+}
+
+func TestStubFallback(t *testing.T) {
+	router := http.NewServeMux()
+	router.HandleFunc("/users/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"path": %q}`, r.URL.Path)
+	})
+	router.HandleFunc("/echo", func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(w, r.Body)
+	})
+	ft := &fakeT{}
+	stub := NewStub(Fallback(router), Strict(ft))
+	stub.On(http.MethodGet, "/payments/{id}").Reply(http.StatusServiceUnavailable, "flaky")
+	c := New(stub)
+
+	res, _ := c.Request(c.NewRequest(http.MethodGet, "/payments/1", nil))
+	ExpectStatus(t, res, http.StatusServiceUnavailable)
+	res, _ = c.Request(c.NewRequest(http.MethodGet, "/users/7", nil))
+	ExpectStatus(t, res, http.StatusOK)
+	if got := body(t, res); got != `{"path": "/users/7"}` {
+		t.Errorf("forwarded request got %q", got)
+	}
+	c.Request(c.NewRequest(http.MethodPost, "/echo", strings.NewReader("ping")))
+	if got := string(c.BodyBytes()); got != "ping" {
+		t.Errorf("echo = %q", got)
+	}
+	if len(ft.failures) != 0 {
+		t.Errorf("strict stub failed forwarded requests: %q", ft.failures)
+	}
+
+	forwarded := stub.Forwarded()
+	if len(forwarded) != 2 || forwarded[0].Request.URL.Path != "/users/7" || forwarded[1].Response.StatusCode != http.StatusOK {
+		t.Fatalf("forwarded = %+v", forwarded)
+	}
+	if b, _ := io.ReadAll(forwarded[1].Request.Body); string(b) != "ping" {
+		t.Errorf("kept request body %q", b)
+	}
+	if b, _ := io.ReadAll(forwarded[1].Response.Body); string(b) != "ping" {
+		t.Errorf("kept response body %q", b)
+	}
+
+	var test strings.Builder
+	if err := GenerateTest(&test, "api", "TestRouter", "newRouter()", forwarded); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(test.String(), `"/users/7"`) {
+		t.Errorf("generated test does not replay the forwarded request:\n%s", test.String())
+	}
+}
+
+func TestStubReplySequence(t *testing.T) {
+	stub := NewStub()
+	rates := stub.On(http.MethodGet, "/rates").Reply(http.StatusOK, "rates").ReplySequence(503, 503, 200)
+	c := New(stub)
+	var got []int
+	for i := 0; i < 5; i++ {
+		res, _ := c.Request(c.NewRequest(http.MethodGet, "/rates", nil))
+		got = append(got, res.StatusCode)
+	}
+	if fmt.Sprint(got) != "[503 503 200 200 200]" {
+		t.Errorf("statuses = %v", got)
+	}
+	if string(c.BodyBytes()) != "rates" {
+		t.Errorf("body = %q", c.BodyBytes())
+	}
+
+	// a sequence set later starts with the next request
+	rates.ReplySequence(500, 200)
+	retrying := New(stub, WithRetry(3, ConstantBackoff(0), RetryOn(500)))
+	res, _ := retrying.Request(retrying.NewRequest(http.MethodGet, "/rates", nil))
+	ExpectStatus(t, res, http.StatusOK)
+	if rates.Hits() != 7 {
+		t.Errorf("hits = %d, want 7", rates.Hits())
+	}
+}
+
+func TestStubFailAfterSequence(t *testing.T) {
+	ft := &fakeT{}
+	stub := NewStub()
+	stub.On(http.MethodGet, "/rates").ReplySequence(503, 200).FailAfterSequence(ft)
+	c := New(stub)
+	for i := 0; i < 2; i++ {
+		c.Request(c.NewRequest(http.MethodGet, "/rates", nil))
+	}
+	if len(ft.failures) != 0 {
+		t.Fatalf("failures = %q", ft.failures)
+	}
+	res, _ := c.Request(c.NewRequest(http.MethodGet, "/rates", nil))
+	ExpectStatus(t, res, http.StatusNotImplemented)
+	if len(ft.failures) != 1 || !strings.Contains(ft.failures[0], "stub route GET /rates got request 3, past its sequence of 2") {
+		t.Errorf("failures = %q", ft.failures)
+	}
+}
+
+func TestStubStates(t *testing.T) {
+	stub := NewStub()
+	stub.On(http.MethodGet, "/jobs/{id}").WhenState("export", StateStarted).Reply(http.StatusNotFound, "no job")
+	stub.On(http.MethodPost, "/jobs").SetsState("export", "pending").Reply(http.StatusAccepted, "created")
+	stub.On(http.MethodGet, "/jobs/{id}").WhenState("export", "pending").SetsState("export", "done").Reply(http.StatusOK, "pending")
+	stub.On(http.MethodGet, "/jobs/{id}").WhenState("export", "done").Reply(http.StatusOK, "done")
+	c := New(stub)
+
+	var got []string
+	for _, method := range []string{"GET", "POST", "GET", "GET", "GET"} {
+		res, _ := c.Request(c.NewRequest(method, map[string]string{"GET": "/jobs/1", "POST": "/jobs"}[method], nil))
+		got = append(got, fmt.Sprintf("%d %s", res.StatusCode, body(t, res)))
+	}
+	if want := "[404 no job 202 created 200 pending 200 done 200 done]"; fmt.Sprint(got) != want {
+		t.Errorf("responses = %v, want %v", got, want)
+	}
+	if st := stub.State("export"); st != "done" {
+		t.Errorf("State = %q", st)
+	}
+
+	stub.ResetStates()
+	if st := stub.State("export"); st != StateStarted {
+		t.Errorf("State after ResetStates = %q", st)
+	}
+	res, _ := c.Request(c.NewRequest(http.MethodGet, "/jobs/1", nil))
+	ExpectStatus(t, res, http.StatusNotFound)
+}
+
+func TestStubStrict(t *testing.T) {
+	ft := &fakeT{}
+	stub := NewStub(Strict(ft))
+	stub.On(http.MethodGet, "/ok")
+	c := New(stub)
+
+	c.Request(c.NewRequest(http.MethodGet, "/ok", nil))
+	if len(ft.failures) != 0 {
+		t.Fatalf("registered route failed the test: %v", ft.failures)
+	}
+	c.PostForm("/nope?x=1", url.Values{"secret": {"42"}})
+	if len(ft.failures) != 1 {
+		t.Fatalf("failures = %v, want one", ft.failures)
+	}
+	for _, want := range []string{"POST", "/nope?x=1", "secret=42"} {
+		if !strings.Contains(ft.failures[0], want) {
+			t.Errorf("failure %q does not mention %q", ft.failures[0], want)
+		}
+	}
+	if c.Response().StatusCode != http.StatusNotImplemented {
+		t.Errorf("status = %d", c.Response().StatusCode)
+	}
+}
+
+func TestStubInstanceFor(t *testing.T) {
+	shared := NewStub(Strict(t))
+	rates := shared.On(http.MethodGet, "/rates").Reply(http.StatusOK, "rates").ReplySequence(503, 200)
+	shared.On(http.MethodPost, "/jobs").SetsState("job", "pending")
+
+	for i := 0; i < 8; i++ {
+		i := i
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			t.Parallel()
+			stub := shared.InstanceFor(t)
+			if i%2 == 0 {
+				stub.On(http.MethodGet, "/rates").Reply(http.StatusTeapot, "own")
+			}
+			c := New(stub)
+			var got []int
+			for j := 0; j < 3; j++ {
+				res, err := c.Request(c.NewRequest(http.MethodGet, "/rates", nil))
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, res.StatusCode)
+			}
+			want := "[503 200 200]"
+			if i%2 == 0 {
+				want = "[418 418 418]"
+			}
+			if fmt.Sprint(got) != want {
+				t.Errorf("statuses = %v, want %v", got, want)
+			}
+			if i%2 == 1 && stub.Hits(rates) != 3 {
+				t.Errorf("instance hits = %d, want 3", stub.Hits(rates))
+			}
+			if st := stub.State("job"); st != StateStarted {
+				t.Errorf("state = %q before the instance posted a job", st)
+			}
+			c.Request(c.NewRequest(http.MethodPost, "/jobs", nil))
+			if st := stub.State("job"); st != "pending" {
+				t.Errorf("state = %q", st)
+			}
+		})
+	}
+	t.Cleanup(func() {
+		if rates.Hits() != 12 || shared.Hits(rates) != 12 {
+			t.Errorf("shared hits = %d, want 12", rates.Hits())
+		}
+		if st := shared.State("job"); st != StateStarted {
+			t.Errorf("shared state = %q", st)
+		}
+	})
+}
+
+func TestStubInstanceStrict(t *testing.T) {
+	shared := NewStub(Strict(t))
+	shared.On(http.MethodGet, "/ok")
+	ft := &fakeT{}
+	stub := shared.InstanceFor(ft)
+	c := New(stub)
+	c.Request(c.NewRequest(http.MethodGet, "/ok", nil))
+	c.Request(c.NewRequest(http.MethodGet, "/missing", nil))
+	if len(ft.failures) != 1 || !strings.Contains(ft.failures[0], "unexpected request GET /missing") {
+		t.Errorf("failures = %q", ft.failures)
+	}
+}
@@ -0,0 +1,107 @@
+package testclient
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+)
+
+// condition is a further requirement of a route on the request it serves.
+// body returns the request body, read on first use.
+type condition func(req *http.Request, body func() []byte) bool
+
+func (r *Route) addCondition(cond condition) *Route {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.conditions = append(r.conditions, cond)
+	return r
+}
+
+// WhenQuery makes the route match only requests whose query has the
+// parameter key with value among its values.
+func (r *Route) WhenQuery(key, value string) *Route {
+	return r.addCondition(func(req *http.Request, _ func() []byte) bool {
+		return contains(req.URL.Query()[key], value)
+	})
+}
+
+// WhenHeader makes the route match only requests with the header key set
+// to value, or with it present at all if value is "*".
+func (r *Route) WhenHeader(key, value string) *Route {
+	return r.addCondition(func(req *http.Request, _ func() []byte) bool {
+		values := req.Header.Values(key)
+		return value == "*" && len(values) > 0 || contains(values, value)
+	})
+}
+
+// WhenBodyJSON makes the route match only requests with a JSON body
+// containing partial: its objects may have further properties, while
+// arrays and other values must be equal. partial is JSON text, as a string
+// or []byte, or a value to encode, such as a map.
+func (r *Route) WhenBodyJSON(partial any) *Route {
+	var want any
+	var err error
+	switch p := partial.(type) {
+	case string:
+		err = json.Unmarshal([]byte(p), &want)
+	case []byte:
+		err = json.Unmarshal(p, &want)
+	default:
+		var b []byte
+		if b, err = json.Marshal(p); err == nil {
+			err = json.Unmarshal(b, &want)
+		}
+	}
+	if err != nil {
+		panic("testclient: WhenBodyJSON: " + err.Error())
+	}
+	return r.addCondition(func(req *http.Request, body func() []byte) bool {
+		var got any
+		return json.Unmarshal(body(), &got) == nil && jsonSubset(want, got)
+	})
+}
+
+// WhenBodyMatches makes the route match only requests whose body matches
+// the regular expression pattern. It panics if pattern does not compile.
+func (r *Route) WhenBodyMatches(pattern string) *Route {
+	re := regexp.MustCompile(pattern)
+	return r.addCondition(func(req *http.Request, body func() []byte) bool {
+		return re.Match(body())
+	})
+}
+
+// When makes the route match only requests for which match holds. match
+// runs without holding the stub, so it may call its methods, such as State.
+func (r *Route) When(match func(*http.Request) bool) *Route {
+	return r.addCondition(func(req *http.Request, _ func() []byte) bool {
+		return match(req)
+	})
+}
+
+// jsonSubset reports whether the decoded JSON got contains want.
+func jsonSubset(want, got any) bool {
+	w, ok := want.(map[string]any)
+	if !ok {
+		return reflect.DeepEqual(want, got)
+	}
+	g, ok := got.(map[string]any)
+	if !ok {
+		return false
+	}
+	for k, v := range w {
+		if gv, ok := g[k]; !ok || !jsonSubset(v, gv) {
+			return false
+		}
+	}
+	return true
+}
+
+type pathParamsKey struct{}
+
+// PathParam returns the value of the {name} path parameter of the stub
+// route serving req, in a function given to ReplyFunc.
+func PathParam(req *http.Request, name string) string {
+	params, _ := req.Context().Value(pathParamsKey{}).(map[string]string)
+	return params[name]
+}
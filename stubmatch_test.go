@@ -0,0 +1,92 @@
+package testclient
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestStubConditions(t *testing.T) {
+	stub := NewStub()
+	stub.On(http.MethodGet, "/search").WhenQuery("q", "go").Reply(http.StatusOK, "query")
+	stub.On(http.MethodGet, "/search").WhenHeader("Authorization", "*").Reply(http.StatusOK, "authorized")
+	stub.On(http.MethodGet, "/search").WhenHeader("Accept", "text/csv").Reply(http.StatusOK, "csv")
+	stub.On(http.MethodPost, "/orders").WhenBodyJSON(`{"customer": {"tier": "gold"}}`).Reply(http.StatusCreated, "gold")
+	stub.On(http.MethodPost, "/orders").WhenBodyJSON(map[string]any{"items": []string{"a"}}).Reply(http.StatusCreated, "single")
+	stub.On(http.MethodPost, "/orders").WhenBodyMatches(`"sku":\s*"B-`).Reply(http.StatusCreated, "b-series")
+	stub.On(http.MethodPost, "/orders").When(func(r *http.Request) bool { return r.ContentLength == 0 }).Reply(http.StatusBadRequest, "empty")
+	stub.On(http.MethodPost, "/orders").ReplyFunc(func(w http.ResponseWriter, r *http.Request) {
+		b := make([]byte, 64)
+		n, _ := r.Body.Read(b)
+		fmt.Fprintf(w, "other %s", b[:n])
+	})
+	c := New(stub)
+
+	for _, tt := range []struct {
+		method, target, header, body string
+		want                         string
+	}{
+		{"GET", "/search?q=rust&q=go", "", "", "query"},
+		{"GET", "/search", "Authorization: Bearer x", "", "authorized"},
+		{"GET", "/search", "Accept: text/csv", "", "csv"},
+		{"GET", "/search", "Accept: text/html", "", "404 page not found\n"},
+		{"POST", "/orders", "", `{"id": 1, "customer": {"name": "A", "tier": "gold"}}`, "gold"},
+		{"POST", "/orders", "", `{"items": ["a"], "id": 2}`, "single"},
+		{"POST", "/orders", "", `{"items": ["a", "b"], "sku": "B-7"}`, "b-series"},
+		{"POST", "/orders", "", ``, "empty"},
+		{"POST", "/orders", "", `{"items": []}`, `other {"items": []}`},
+	} {
+		req := c.NewRequest(tt.method, tt.target, nil)
+		if tt.body != "" {
+			req = c.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+		}
+		if name, value, ok := strings.Cut(tt.header, ": "); ok {
+			req.Header.Set(name, value)
+		}
+		res, err := c.Request(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := body(t, res); got != tt.want {
+			t.Errorf("%s %s %s %s = %q, want %q", tt.method, tt.target, tt.header, tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestStubPathParams(t *testing.T) {
+	stub := NewStub()
+	stub.On(http.MethodGet, "/users/{id}/files/{path...}").ReplyFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s:%s", PathParam(r, "id"), PathParam(r, "path"))
+	})
+	stub.On(http.MethodGet, "/static/{rest...}").ReplyFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "[%s]", PathParam(r, "rest"))
+	})
+	c := New(stub)
+	for target, want := range map[string]string{
+		"/users/7/files/a/b%2Fc.txt": "7:a/b/c.txt",
+		"/users/7/files/":            "7:",
+		"/static/":                   "[]",
+		"/static/css/app.css":        "[css/app.css]",
+		"/users/7/files":             "404 page not found\n",
+	} {
+		res, _ := c.Request(c.NewRequest(http.MethodGet, target, nil))
+		if got := body(t, res); got != want {
+			t.Errorf("GET %s = %q, want %q", target, got, want)
+		}
+	}
+}
+
+func TestStubWhenCallsStub(t *testing.T) {
+	stub := NewStub()
+	stub.On(http.MethodPost, "/login").SetsState("auth", "in").Reply(http.StatusNoContent, "")
+	stub.On(http.MethodGet, "/me").When(func(*http.Request) bool { return stub.State("auth") == "in" }).Reply(http.StatusOK, "alice")
+	c := New(stub)
+	if res, _ := c.Get("/me", nil); res.StatusCode != http.StatusNotFound {
+		t.Errorf("before login: status %d", res.StatusCode)
+	}
+	c.PostForm("/login", nil)
+	if res, _ := c.Get("/me", nil); res.StatusCode != http.StatusOK {
+		t.Errorf("after login: status %d", res.StatusCode)
+	}
+}
@@ -0,0 +1,102 @@
+// Package testcharset decodes response bodies in the charset their
+// Content-Type declares, such as Shift_JIS, EUC-JP or ISO-8859-1, into
+// UTF-8, for endpoints that predate UTF-8. The encodings come from
+// golang.org/x/text, which tests of UTF-8 services have no need for.
+package testcharset
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	testclient "github.com/raksul/go-testclient"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/ianaindex"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// Charset returns the charset parameter of the Content-Type of res, as
+// written, or "" if there is none.
+func Charset(res *http.Response) string {
+	_, params, err := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if err != nil {
+		return ""
+	}
+	return params["charset"]
+}
+
+// Decode returns body, the body of res, decoded from the charset of res
+// into UTF-8. Without a charset the body must be valid UTF-8 already.
+func Decode(res *http.Response, body []byte) (string, error) {
+	charset := Charset(res)
+	enc, err := lookup(charset)
+	if err != nil {
+		return "", err
+	}
+	if enc == nil {
+		if !utf8.Valid(body) {
+			return "", fmt.Errorf("testcharset: body without a charset is not valid UTF-8")
+		}
+		return string(body), nil
+	}
+	b, err := enc.NewDecoder().Bytes(body)
+	if err != nil {
+		return "", fmt.Errorf("testcharset: decoding %s body: %w", charset, err)
+	}
+	return string(b), nil
+}
+
+// Text returns the body of the last response of c decoded into UTF-8,
+// while c.BodyBytes() keeps returning it as sent.
+func Text(c *testclient.Client) (string, error) {
+	res := c.Response()
+	if res == nil {
+		return "", fmt.Errorf("testcharset: no response to decode")
+	}
+	return Decode(res, c.BodyBytes())
+}
+
+// ExpectText asserts that the body of the last response of c, decoded
+// into UTF-8, is want.
+func ExpectText(t testing.TB, c *testclient.Client, want string) {
+	t.Helper()
+	got, err := Text(c)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if got != want {
+		t.Fatalf("expected body %q, got %q", want, got)
+	}
+}
+
+// ExpectTextContains asserts that the body of the last response of c,
+// decoded into UTF-8, contains substr.
+func ExpectTextContains(t testing.TB, c *testclient.Client, substr string) {
+	t.Helper()
+	got, err := Text(c)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if !strings.Contains(got, substr) {
+		t.Fatalf("expected body containing %q, got %q", substr, got)
+	}
+}
+
+// lookup returns the encoding named charset, or nil for UTF-8 and no
+// charset at all.
+func lookup(charset string) (encoding.Encoding, error) {
+	if charset == "" {
+		return nil, nil
+	}
+	enc, err := ianaindex.IANA.Encoding(charset)
+	if err != nil || enc == nil {
+		return nil, fmt.Errorf("testcharset: unsupported charset %q", charset)
+	}
+	if enc == unicode.UTF8 {
+		return nil, nil
+	}
+	return enc, nil
+}
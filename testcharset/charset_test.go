@@ -0,0 +1,120 @@
+package testcharset
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	testclient "github.com/raksul/go-testclient"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+)
+
+const greeting = "こんにちは、世界"
+
+// legacyClient returns a client whose handler replies with text encoded
+// in enc, declared as charset.
+func legacyClient(t *testing.T, charset string, enc encoding.Encoding, text string) *testclient.Client {
+	body, err := enc.NewEncoder().Bytes([]byte(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := testclient.New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset="+charset)
+		w.Write(body)
+	}))
+	if _, err := c.Get("/", nil); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestText(t *testing.T) {
+	for _, tt := range []struct {
+		charset string
+		enc     encoding.Encoding
+		text    string
+	}{
+		{"Shift_JIS", japanese.ShiftJIS, greeting},
+		{"shift_jis", japanese.ShiftJIS, greeting},
+		{"EUC-JP", japanese.EUCJP, greeting},
+		{"ISO-8859-1", charmap.ISO8859_1, "Grüße, café"},
+	} {
+		c := legacyClient(t, tt.charset, tt.enc, tt.text)
+		if string(c.BodyBytes()) == tt.text {
+			t.Fatalf("%s: body sent as UTF-8", tt.charset)
+		}
+		got, err := Text(c)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.charset, err)
+		}
+		if got != tt.text {
+			t.Errorf("%s: Text = %q, want %q", tt.charset, got, tt.text)
+		}
+		if cs := Charset(c.Response()); cs != tt.charset {
+			t.Errorf("Charset = %q, want %q", cs, tt.charset)
+		}
+	}
+}
+
+func TestTextUTF8(t *testing.T) {
+	c := testclient.New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/declared" {
+			w.Header().Set("Content-Type", "text/plain; charset=UTF-8")
+		}
+		w.Write([]byte(greeting))
+	}))
+	for _, path := range []string{"/declared", "/undeclared"} {
+		c.Get(path, nil)
+		ExpectText(t, c, greeting)
+	}
+}
+
+func TestTextErrors(t *testing.T) {
+	if _, err := Text(testclient.New(http.NotFoundHandler())); err == nil || !strings.Contains(err.Error(), "no response") {
+		t.Errorf("err = %v", err)
+	}
+
+	c := legacyClient(t, "x-unknown", charmap.ISO8859_1, "abc")
+	if _, err := Text(c); err == nil || !strings.Contains(err.Error(), `unsupported charset "x-unknown"`) {
+		t.Errorf("err = %v", err)
+	}
+
+	c = testclient.New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte{0x82, 0xa0})
+	}))
+	c.Get("/", nil)
+	if _, err := Text(c); err == nil || !strings.Contains(err.Error(), "not valid UTF-8") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestExpectText(t *testing.T) {
+	c := legacyClient(t, "Shift_JIS", japanese.ShiftJIS, greeting)
+	ExpectText(t, c, greeting)
+	ExpectTextContains(t, c, "世界")
+
+	ft := &fakeT{TB: t}
+	ExpectText(ft, c, "hello")
+	ExpectTextContains(ft, c, "さようなら")
+	if len(ft.failures) != 2 || !strings.Contains(ft.failures[0], `expected body "hello", got "こんにちは、世界"`) || !strings.Contains(ft.failures[1], `expected body containing "さようなら"`) {
+		t.Errorf("failures = %q", ft.failures)
+	}
+}
+
+type fakeT struct {
+	testing.TB
+	failures []string
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Errorf(format string, args ...any) {
+	f.failures = append(f.failures, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeT) Fatalf(format string, args ...any) {
+	f.Errorf(format, args...)
+}
@@ -0,0 +1,134 @@
+// Package testcmp compares JSON response bodies with go-cmp, for tests
+// that need to ignore volatile fields such as timestamps and UUIDs, treat
+// arrays as sets, or read a diff of a large payload. Keeping it out of the
+// core package keeps go-cmp out of the dependencies of other tests.
+package testcmp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	testclient "github.com/raksul/go-testclient"
+)
+
+// ExpectJSONEqual asserts that the JSON body of the last response of c
+// equals expected under opts, reporting a diff (-expected +got) otherwise.
+//
+// expected is either JSON text, as a string, []byte or json.RawMessage,
+// compared with the body decoded into generic values, or a Go value such
+// as a struct, in which case the body is decoded into a value of its type
+// and options like cmpopts.IgnoreFields apply.
+func ExpectJSONEqual(t testing.TB, c *testclient.Client, expected any, opts ...cmp.Option) {
+	t.Helper()
+	if c.Response() == nil {
+		t.Fatalf("expected a JSON body, got no response")
+		return
+	}
+	want, got, err := decodePair(expected, c.BodyBytes())
+	if err != nil {
+		t.Fatalf("%v", err)
+		return
+	}
+	if diff := cmp.Diff(want, got, opts...); diff != "" {
+		t.Errorf("JSON body mismatch (-expected +got):\n%s", diff)
+	}
+}
+
+func decodePair(expected any, body []byte) (want, got any, err error) {
+	var text []byte
+	switch e := expected.(type) {
+	case string:
+		text = []byte(e)
+	case []byte:
+		text = e
+	case json.RawMessage:
+		text = e
+	}
+	if text != nil {
+		if err := json.Unmarshal(text, &want); err != nil {
+			return nil, nil, fmt.Errorf("bad expected JSON: %v", err)
+		}
+		if err := json.Unmarshal(body, &got); err != nil {
+			return nil, nil, fmt.Errorf("expected a JSON body, got %q", truncate(body))
+		}
+		return want, got, nil
+	}
+	ptr := reflect.New(reflect.TypeOf(expected))
+	if err := json.Unmarshal(body, ptr.Interface()); err != nil {
+		return nil, nil, fmt.Errorf("decoding body into %T: %v", expected, err)
+	}
+	return expected, ptr.Elem().Interface(), nil
+}
+
+func truncate(b []byte) []byte {
+	if len(b) > 200 {
+		return b[:200]
+	}
+	return b
+}
+
+// IgnorePaths ignores the values at JSON paths such as "$.created_at" or
+// "$.items[*].id", where [*] stands for any index, in bodies compared as
+// JSON text.
+func IgnorePaths(paths ...string) cmp.Option {
+	ignore := map[string]bool{}
+	for _, p := range paths {
+		ignore[p] = true
+	}
+	return cmp.FilterPath(func(p cmp.Path) bool {
+		return ignore[jsonPath(p, false)] || ignore[jsonPath(p, true)]
+	}, cmp.Ignore())
+}
+
+// jsonPath renders the path of generic JSON values p as $.a[0].b, or with
+// indexes as [*] if wildcard is set.
+func jsonPath(p cmp.Path, wildcard bool) string {
+	var b strings.Builder
+	b.WriteString("$")
+	for _, step := range p {
+		switch s := step.(type) {
+		case cmp.MapIndex:
+			b.WriteString("." + fmt.Sprint(s.Key().Interface()))
+		case cmp.SliceIndex:
+			if wildcard {
+				b.WriteString("[*]")
+			} else {
+				b.WriteString("[" + strconv.Itoa(s.Key()) + "]")
+			}
+		}
+	}
+	return b.String()
+}
+
+// UnorderedArrays compares every JSON array in bodies compared as JSON text
+// as a multiset, regardless of element order.
+func UnorderedArrays() cmp.Option {
+	return cmp.Transformer("testcmp.UnorderedArrays", func(in []any) []any {
+		out := append([]any(nil), in...)
+		keys := make([][]byte, len(out))
+		for i, v := range out {
+			keys[i], _ = json.Marshal(v)
+		}
+		sort.Sort(byKey{out, keys})
+		return out
+	})
+}
+
+type byKey struct {
+	values []any
+	keys   [][]byte
+}
+
+func (s byKey) Len() int           { return len(s.values) }
+func (s byKey) Less(i, j int) bool { return bytes.Compare(s.keys[i], s.keys[j]) < 0 }
+func (s byKey) Swap(i, j int) {
+	s.values[i], s.values[j] = s.values[j], s.values[i]
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+}
@@ -0,0 +1,94 @@
+package testcmp
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp/cmpopts"
+	testclient "github.com/raksul/go-testclient"
+)
+
+const order = `{
+	"id": "7c9e6679-7425-40de-944b-e07fc1f90ae7",
+	"created_at": "2024-05-01T10:00:00Z",
+	"items": [{"sku": "B-2", "id": 2}, {"sku": "A-1", "id": 1}],
+	"tags": ["new", "gift"]
+}`
+
+func orderClient(t *testing.T) *testclient.Client {
+	c := testclient.New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(order))
+	}))
+	if _, err := c.Get("/orders/1", nil); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestExpectJSONEqualText(t *testing.T) {
+	c := orderClient(t)
+	ExpectJSONEqual(t, c, `{
+		"id": "other",
+		"created_at": "now",
+		"items": [{"sku": "A-1", "id": 10}, {"sku": "B-2", "id": 20}],
+		"tags": ["gift", "new"]
+	}`, IgnorePaths("$.id", "$.created_at", "$.items[*].id"), UnorderedArrays())
+
+	ft := &fakeT{TB: t}
+	ExpectJSONEqual(ft, c, `{"id": "x", "created_at": "", "items": [], "tags": ["new", "gift"]}`, IgnorePaths("$.id", "$.created_at"))
+	if len(ft.failures) != 1 || !strings.Contains(ft.failures[0], "-expected +got") || !strings.Contains(ft.failures[0], "A-1") {
+		t.Errorf("failures = %q", ft.failures)
+	}
+}
+
+func TestExpectJSONEqualStruct(t *testing.T) {
+	type item struct {
+		SKU string
+		ID  int
+	}
+	type orderDoc struct {
+		ID        string
+		CreatedAt time.Time `json:"created_at"`
+		Items     []item
+		Tags      []string
+	}
+	c := orderClient(t)
+	ExpectJSONEqual(t, c, orderDoc{
+		Items: []item{{"A-1", 1}, {"B-2", 2}},
+		Tags:  []string{"gift", "new"},
+	},
+		cmpopts.IgnoreFields(orderDoc{}, "ID", "CreatedAt"),
+		cmpopts.SortSlices(func(a, b item) bool { return a.SKU < b.SKU }),
+		cmpopts.SortSlices(func(a, b string) bool { return a < b }),
+	)
+}
+
+func TestExpectJSONEqualErrors(t *testing.T) {
+	ft := &fakeT{TB: t}
+	ExpectJSONEqual(ft, testclient.New(http.NotFoundHandler()), `{}`)
+	c := orderClient(t)
+	ExpectJSONEqual(ft, c, `{`)
+	ExpectJSONEqual(ft, c, 42)
+	if len(ft.failures) != 3 {
+		t.Errorf("failures = %q", ft.failures)
+	}
+}
+
+type fakeT struct {
+	testing.TB
+	failures []string
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Errorf(format string, args ...any) {
+	f.failures = append(f.failures, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeT) Fatalf(format string, args ...any) {
+	f.Errorf(format, args...)
+}
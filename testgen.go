@@ -0,0 +1,143 @@
+package testclient
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// skippedTestHeaders are request headers the client of the generated test
+// sets by itself.
+var skippedTestHeaders = map[string]bool{
+	"Accept-Encoding": true,
+	"Content-Length":  true,
+	"Cookie":          true,
+}
+
+// GenerateTest writes the source of a test file of package pkg replaying a
+// recorded session, such as the History of a client, as a table-driven
+// test named name. handler is the Go expression of the handler under test,
+// e.g. "newServer()". Each step sends the recorded method, target, headers
+// and body and expects the recorded status and, for text and JSON
+// responses, the recorded body. Requests that failed are left out, as are
+// the Accept-Encoding, Content-Length and Cookie headers, which the client
+// sets itself.
+//
+// The output is a starting point for a regression test: values that vary
+// from run to run, such as timestamps and IDs, need to be loosened by hand.
+func GenerateTest(w io.Writer, pkg, name, handler string, session []Exchange) error {
+	if !token.IsIdentifier(name) || !strings.HasPrefix(name, "Test") {
+		return fmt.Errorf("testclient: %q is not a Go test name", name)
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by testclient.GenerateTest from a recorded session.\n\npackage %s\n\n", pkg)
+	fmt.Fprintf(&b, "import (\n\t\"net/http\"\n\t\"strings\"\n\t\"testing\"\n\n\t\"github.com/raksul/go-testclient\"\n)\n\n")
+	fmt.Fprintf(&b, "func %s(t *testing.T) {\n\tc := testclient.New(%s)\n", name, handler)
+	fmt.Fprintf(&b, "\tsteps := []struct {\n\t\tmethod string\n\t\ttarget string\n\t\theader http.Header\n\t\tbody   string\n\t\tstatus int\n\t\twant   string\n\t}{\n")
+	for _, ex := range session {
+		if ex.Err != nil || ex.Response == nil {
+			continue
+		}
+		if err := writeTestStep(&b, ex); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(&b, "\t}\n")
+	fmt.Fprintf(&b, `	for i, step := range steps {
+		req := c.NewRequest(step.method, step.target, strings.NewReader(step.body))
+		for k, vv := range step.header {
+			req.Header[k] = vv
+		}
+		res, err := c.Request(req)
+		if err != nil {
+			t.Fatalf("step %%d: %%s %%s: %%v", i+1, step.method, step.target, err)
+		}
+		if res.StatusCode != step.status {
+			t.Fatalf("step %%d: %%s %%s: expected status %%d, got %%d", i+1, step.method, step.target, step.status, res.StatusCode)
+		}
+		if got := string(c.BodyBytes()); step.want != "" && got != step.want {
+			t.Errorf("step %%d: %%s %%s: expected body %%q, got %%q", i+1, step.method, step.target, step.want, got)
+		}
+	}
+}
+`)
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return fmt.Errorf("testclient: formatting generated test: %w", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+func writeTestStep(b *bytes.Buffer, ex Exchange) error {
+	req, res := ex.Request, ex.Response
+	reqBody, err := io.ReadAll(req.Body)
+	if err != nil {
+		return fmt.Errorf("testclient: reading recorded request body: %w", err)
+	}
+	target := req.URL.RequestURI()
+	if req.Host != "" && req.Host != "example.com" {
+		target = requestURL(req).String()
+	}
+
+	fmt.Fprintf(b, "\t\t{\n\t\t\tmethod: %q,\n\t\t\ttarget: %q,\n", req.Method, target)
+	var keys []string
+	for k := range req.Header {
+		if !skippedTestHeaders[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	if len(keys) > 0 {
+		fmt.Fprintf(b, "\t\t\theader: http.Header{\n")
+		for _, k := range keys {
+			values := make([]string, len(req.Header[k]))
+			for i, v := range req.Header[k] {
+				values[i] = goString(v)
+			}
+			fmt.Fprintf(b, "\t\t\t\t%q: {%s},\n", k, strings.Join(values, ", "))
+		}
+		fmt.Fprintf(b, "\t\t\t},\n")
+	}
+	if len(reqBody) > 0 {
+		fmt.Fprintf(b, "\t\t\tbody: %s,\n", goString(string(reqBody)))
+	}
+	fmt.Fprintf(b, "\t\t\tstatus: %d,\n", res.StatusCode)
+	if data := bufferedBody(res).data; len(data) > 0 && isTextual(res.Header.Get("Content-Type")) && utf8.Valid(data) {
+		fmt.Fprintf(b, "\t\t\twant: %s,\n", goString(string(data)))
+	}
+	fmt.Fprintf(b, "\t\t},\n")
+	return nil
+}
+
+// isTextual reports whether a body of contentType can be written into a
+// test as a string.
+func isTextual(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return strings.HasPrefix(mediaType, "text/") || mediaType == "application/json" ||
+		strings.HasSuffix(mediaType, "+json") || mediaType == "application/xml" ||
+		strings.HasSuffix(mediaType, "+xml") || mediaType == "application/x-www-form-urlencoded"
+}
+
+// goString returns s as a Go string literal, raw where that reads better.
+func goString(s string) string {
+	if !strings.ContainsAny(s, "\n\"\\") || strings.Contains(s, "`") {
+		return strconv.Quote(s)
+	}
+	for _, r := range s {
+		if r != '\n' && r != '\t' && !unicode.IsPrint(r) {
+			return strconv.Quote(s)
+		}
+	}
+	return "`" + s + "`"
+}
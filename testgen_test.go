@@ -0,0 +1,83 @@
+package testclient
+
+import (
+	"bytes"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/raksul/go-testclient/internal/testgenexample"
+)
+
+// TestGenerateTest records a session against the example server; the test
+// generated from it lives in internal/testgenexample and runs there.
+func TestGenerateTest(t *testing.T) {
+	c := New(testgenexample.NewServer(), WithHistory())
+	c.PostForm("/signup", url.Values{"name": {"alice"}})
+	c.Get("/cart", nil)
+	c.Get("/cart?sku=A-1", nil, Header("X-Trace", "t1"))
+
+	var b bytes.Buffer
+	if err := GenerateTest(&b, "testgenexample", "TestRecordedSession", "NewServer()", c.History()); err != nil {
+		t.Fatal(err)
+	}
+	const path = "internal/testgenexample/recorded_test.go"
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != string(want) {
+		t.Errorf("generated test differs from %s:\n%s", path, b.String())
+	}
+}
+
+func TestGenerateTestName(t *testing.T) {
+	var b bytes.Buffer
+	if err := GenerateTest(&b, "api", "Recorded", "h", nil); err == nil || !strings.Contains(err.Error(), "not a Go test name") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b := new(bytes.Buffer)
+		b.ReadFrom(r.Body)
+		w.Write(bytes.ToUpper(b.Bytes()))
+	})
+	c := New(h)
+	c.PostForm("/", url.Values{"a": {"b"}})
+	if len(c.History()) != 0 {
+		t.Error("history kept without WithHistory")
+	}
+
+	c = New(h, WithHistory())
+	c.PostForm("/a", url.Values{"x": {"1"}})
+	c.PostForm("/b", url.Values{"y": {"2"}})
+	history := c.History()
+	if len(history) != 2 {
+		t.Fatalf("len(History()) = %d, want 2", len(history))
+	}
+	ex := history[0]
+	if ex.Request.URL.Path != "/a" {
+		t.Errorf("first request = %s", ex.Request.URL)
+	}
+	var req bytes.Buffer
+	req.ReadFrom(ex.Request.Body)
+	if req.String() != "x=1" {
+		t.Errorf("request body = %q", req.String())
+	}
+	if got := body(t, ex.Response); got != "X=1" {
+		t.Errorf("response body = %q", got)
+	}
+	// bodies are readable again on every call
+	if got := body(t, c.History()[0].Response); got != "X=1" {
+		t.Errorf("response body on second History() = %q", got)
+	}
+
+	c.ClearHistory()
+	if len(c.History()) != 0 {
+		t.Error("history kept after ClearHistory")
+	}
+}
@@ -0,0 +1,185 @@
+// Package testgomega provides Gomega matchers for testclient responses, so
+// that Ginkgo specs can assert on them in their own idiom:
+//
+//	Expect(c.Get("/users/1", nil)).To(HaveStatus(http.StatusOK))
+//	Expect(c.Response()).To(HaveJSONPath("$.name", "alice"))
+//
+// A matcher takes an *http.Response or a *testclient.Client, meaning its
+// last response. Bodies are left readable.
+package testgomega
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/onsi/gomega/types"
+	testclient "github.com/raksul/go-testclient"
+)
+
+// HaveStatus succeeds if the response has status code.
+func HaveStatus(code int) types.GomegaMatcher {
+	return &statusMatcher{code: code}
+}
+
+// HaveHeader succeeds if the response has header name with value, or,
+// if value is a matcher, with a value it matches.
+func HaveHeader(name string, value any) types.GomegaMatcher {
+	return &headerMatcher{name: name, value: value}
+}
+
+// HaveJSONPath succeeds if the JSON body of the response has a value at
+// path, such as $.items[0].sku, equal to expected once both are JSON, so
+// that 42 matches a number of any Go type; or, if expected is a matcher,
+// one it matches.
+func HaveJSONPath(path string, expected any) types.GomegaMatcher {
+	return &jsonPathMatcher{path: path, expected: expected}
+}
+
+// MatchJSONBody succeeds if the JSON body of the response is the document
+// expected, regardless of formatting and property order.
+func MatchJSONBody(expected string) types.GomegaMatcher {
+	return &jsonPathMatcher{path: "$", expected: json.RawMessage(expected)}
+}
+
+func response(actual any) (*http.Response, error) {
+	switch a := actual.(type) {
+	case *http.Response:
+		if a != nil {
+			return a, nil
+		}
+	case *testclient.Client:
+		if res := a.Response(); res != nil {
+			return res, nil
+		}
+		return nil, fmt.Errorf("testgomega: the client has no response")
+	}
+	return nil, fmt.Errorf("testgomega: expected an *http.Response or *testclient.Client, got %T", actual)
+}
+
+type statusMatcher struct {
+	code int
+	got  int
+}
+
+func (m *statusMatcher) Match(actual any) (bool, error) {
+	res, err := response(actual)
+	if err != nil {
+		return false, err
+	}
+	m.got = res.StatusCode
+	return m.got == m.code, nil
+}
+
+func (m *statusMatcher) FailureMessage(actual any) string {
+	return fmt.Sprintf("expected status %d %s, got %d %s", m.code, http.StatusText(m.code), m.got, http.StatusText(m.got))
+}
+
+func (m *statusMatcher) NegatedFailureMessage(actual any) string {
+	return fmt.Sprintf("expected a status other than %d %s", m.code, http.StatusText(m.code))
+}
+
+type headerMatcher struct {
+	name  string
+	value any
+	got   []string
+}
+
+func (m *headerMatcher) Match(actual any) (bool, error) {
+	res, err := response(actual)
+	if err != nil {
+		return false, err
+	}
+	m.got = res.Header.Values(m.name)
+	for _, v := range m.got {
+		if ok, err := matches(m.value, v); ok || err != nil {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+func (m *headerMatcher) FailureMessage(actual any) string {
+	return fmt.Sprintf("expected header %s %s, got %q", m.name, describe(m.value), m.got)
+}
+
+func (m *headerMatcher) NegatedFailureMessage(actual any) string {
+	return fmt.Sprintf("expected no header %s %s, got %q", m.name, describe(m.value), m.got)
+}
+
+type jsonPathMatcher struct {
+	path     string
+	expected any
+	got      any
+	missing  error
+}
+
+func (m *jsonPathMatcher) Match(actual any) (bool, error) {
+	res, err := response(actual)
+	if err != nil {
+		return false, err
+	}
+	m.got, m.missing = nil, nil
+	if m.missing = testclient.ExtractJSON(res, m.path, &m.got); m.missing != nil {
+		return false, nil
+	}
+	if _, ok := m.expected.(types.GomegaMatcher); ok {
+		return matches(m.expected, m.got)
+	}
+	want, err := normalize(m.expected)
+	if err != nil {
+		return false, err
+	}
+	return reflect.DeepEqual(want, m.got), nil
+}
+
+func (m *jsonPathMatcher) FailureMessage(actual any) string {
+	if m.missing != nil {
+		return fmt.Sprintf("expected %s %s, got %v", m.path, describe(m.expected), m.missing)
+	}
+	return fmt.Sprintf("expected %s %s, got %s", m.path, describe(m.expected), jsonText(m.got))
+}
+
+func (m *jsonPathMatcher) NegatedFailureMessage(actual any) string {
+	return fmt.Sprintf("expected %s not %s", m.path, describe(m.expected))
+}
+
+// normalize returns v as decoded from its JSON encoding.
+func normalize(v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("testgomega: expected value: %w", err)
+	}
+	var out any
+	return out, json.Unmarshal(b, &out)
+}
+
+func matches(expected, actual any) (bool, error) {
+	if m, ok := expected.(types.GomegaMatcher); ok {
+		return m.Match(actual)
+	}
+	return reflect.DeepEqual(expected, actual), nil
+}
+
+func describe(expected any) string {
+	if m, ok := expected.(types.GomegaMatcher); ok {
+		t := reflect.TypeOf(m)
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		return "matching a " + t.Name()
+	}
+	if raw, ok := expected.(json.RawMessage); ok {
+		return string(raw)
+	}
+	return jsonText(expected)
+}
+
+func jsonText(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
@@ -0,0 +1,70 @@
+package testgomega
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	testclient "github.com/raksul/go-testclient"
+)
+
+func newClient() *testclient.Client {
+	return testclient.New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Add("Vary", "Accept")
+		w.Header().Add("Vary", "Origin")
+		if r.URL.Path != "/users/1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `{"name": "alice", "age": 42, "roles": ["admin", "dev"]}`)
+	}))
+}
+
+func TestMatchers(t *testing.T) {
+	g := NewWithT(t)
+	c := newClient()
+
+	g.Expect(c.Get("/users/1", nil)).To(HaveStatus(http.StatusOK))
+	g.Expect(c).To(HaveHeader("Vary", "Origin"))
+	g.Expect(c).To(HaveHeader("Content-Type", HavePrefix("application/")))
+	g.Expect(c).To(HaveJSONPath("$.name", "alice"))
+	g.Expect(c).To(HaveJSONPath("$.age", 42))
+	g.Expect(c).To(HaveJSONPath("$.roles", ContainElement("dev")))
+	g.Expect(c).To(MatchJSONBody(`{"roles": ["admin", "dev"], "age": 42, "name": "alice"}`))
+	g.Expect(c).NotTo(HaveJSONPath("$.name", "bob"))
+	g.Expect(c.Response()).NotTo(HaveStatus(http.StatusNotFound))
+	// the body is still there for the client
+	g.Expect(string(c.BodyBytes())).To(ContainSubstring("alice"))
+}
+
+func TestFailureMessages(t *testing.T) {
+	c := newClient()
+	c.Get("/users/2", nil)
+
+	var failures []string
+	g := NewGomega(func(message string, _ ...int) { failures = append(failures, message) })
+	g.Expect(c).To(HaveStatus(http.StatusOK))
+	g.Expect(c).To(HaveHeader("X-Missing", "1"))
+	g.Expect(c).To(HaveJSONPath("$.name", "alice"))
+	g.Expect("not a response").To(HaveStatus(http.StatusOK))
+	g.Expect(testclient.New(http.NotFoundHandler())).To(HaveStatus(http.StatusOK))
+
+	want := []string{
+		"expected status 200 OK, got 404 Not Found",
+		`expected header X-Missing "1", got []`,
+		`expected $.name "alice", got testclient: body is not JSON`,
+		"expected an *http.Response or *testclient.Client, got string",
+		"the client has no response",
+	}
+	if len(failures) != len(want) {
+		t.Fatalf("failures = %q", failures)
+	}
+	for i, w := range want {
+		if !strings.Contains(failures[i], w) {
+			t.Errorf("failure %d = %q, want it to contain %q", i, failures[i], w)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+// Package testmsgpack adds MessagePack bodies to testclient, mirroring the
+// JSON helpers. It lives apart from the core package so that only tests
+// speaking MessagePack depend on a msgpack library.
+package testmsgpack
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net/http"
+
+	testclient "github.com/raksul/go-testclient"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// ContentType is the media type of MessagePack bodies.
+const ContentType = "application/msgpack"
+
+// PostMsgpack sends payload encoded as MessagePack to uri.
+func PostMsgpack(c *testclient.Client, uri string, payload any, opts ...testclient.RequestOption) error {
+	b, err := msgpack.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req := c.NewRequest(http.MethodPost, uri, bytes.NewReader(b))
+	req.Header.Set("Content-Type", ContentType)
+
+	_, err = c.Request(req, opts...)
+	return err
+}
+
+// DecodeMsgpack decodes the body of the last response into v. The response
+// must have a MessagePack media type: application/msgpack,
+// application/x-msgpack or application/vnd.msgpack.
+func DecodeMsgpack(c *testclient.Client, v any) error {
+	res := c.Response()
+	if res == nil {
+		return fmt.Errorf("testmsgpack: no response to decode")
+	}
+	if ct := res.Header.Get("Content-Type"); !isMsgpack(ct) {
+		return fmt.Errorf("testmsgpack: response Content-Type %q is not MessagePack", ct)
+	}
+	return msgpack.Unmarshal(c.BodyBytes(), v)
+}
+
+func isMsgpack(contentType string) bool {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch mt {
+	case ContentType, "application/x-msgpack", "application/vnd.msgpack":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package testmsgpack
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	testclient "github.com/raksul/go-testclient"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+type item struct {
+	ID   int    `msgpack:"id"`
+	Name string `msgpack:"name"`
+}
+
+func TestPostMsgpack(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != ContentType {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		var in item
+		if err := msgpack.NewDecoder(r.Body).Decode(&in); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		in.Name = strings.ToUpper(in.Name)
+		w.Header().Set("Content-Type", "application/x-msgpack")
+		msgpack.NewEncoder(w).Encode(in)
+	})
+	c := testclient.New(h)
+	if err := PostMsgpack(c, "/items", item{ID: 1, Name: "pen"}); err != nil {
+		t.Fatal(err)
+	}
+	var got item
+	if err := DecodeMsgpack(c, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got != (item{ID: 1, Name: "PEN"}) {
+		t.Errorf("decoded %+v", got)
+	}
+	if err := PostMsgpack(c, "/", make(chan int)); err == nil {
+		t.Error("PostMsgpack accepted an unencodable payload")
+	}
+}
+
+func TestDecodeMsgpackWrongType(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, "{}")
+	})
+	c := testclient.New(h)
+	c.Request(c.NewRequest(http.MethodGet, "/", nil))
+	if err := DecodeMsgpack(c, new(item)); err == nil || !strings.Contains(err.Error(), "not MessagePack") {
+		t.Errorf("err = %v, want a Content-Type error", err)
+	}
+}
@@ -0,0 +1,360 @@
+package testopenapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	testclient "github.com/raksul/go-testclient"
+)
+
+// Case is a request generated for an operation.
+type Case struct {
+	testclient.RequestSpec
+	Operation *Operation
+	// Name is "valid" or the constraint the request breaks.
+	Name string
+	// Valid reports whether the request satisfies the spec. A handler
+	// should reject the others with a 4xx.
+	Valid bool
+}
+
+func (c Case) String() string {
+	return c.Operation.String() + " " + c.Name
+}
+
+// violation is a value breaking one constraint of a schema.
+type violation struct {
+	name  string
+	value any
+}
+
+// draft is the generated content of a request before it is encoded.
+type draft struct {
+	path   testclient.Params
+	query  url.Values
+	header http.Header
+	body   any
+	raw    []byte
+	noBody bool
+}
+
+// Cases returns the requests generated for every operation: one valid
+// request built from the examples and constraints of the spec, then
+// requests that each break a single constraint — a missing required
+// parameter or property, a value of the wrong type, just outside its
+// bounds or its enum, and a malformed JSON body.
+func (s *Spec) Cases() ([]Case, error) {
+	var cases []Case
+	for _, op := range s.operations {
+		cs, err := s.cases(op)
+		if err != nil {
+			return nil, fmt.Errorf("testopenapi: %s: %w", op, err)
+		}
+		cases = append(cases, cs...)
+	}
+	return cases, nil
+}
+
+func (s *Spec) cases(op *Operation) ([]Case, error) {
+	base := draft{path: testclient.Params{}, query: url.Values{}, header: http.Header{}}
+	for _, p := range op.parameters {
+		v, err := s.paramExample(p)
+		if err != nil {
+			return nil, err
+		}
+		switch p.In {
+		case "path":
+			base.path[p.Name] = v
+		case "query":
+			base.query.Set(p.Name, v)
+		case "header":
+			if p.Required {
+				base.header.Set(p.Name, v)
+			}
+		}
+	}
+	var bodySchema *schema
+	if op.requestBody != nil {
+		if media, ok := jsonMedia(op.requestBody.Content); ok {
+			bodySchema = media.Schema
+			base.body = media.Example
+			if base.body == nil {
+				v, err := s.example(media.Schema, 0)
+				if err != nil {
+					return nil, err
+				}
+				base.body = v
+			}
+		}
+	}
+
+	var cases []Case
+	add := func(name string, valid bool, d draft) {
+		spec, ok := s.request(op, d)
+		if ok {
+			cases = append(cases, Case{RequestSpec: spec, Operation: op, Name: name, Valid: valid})
+		}
+	}
+	add("valid", true, base)
+
+	for _, p := range op.parameters {
+		if p.Required && p.In != "path" {
+			d := base.clone()
+			d.query.Del(p.Name)
+			d.header.Del(p.Name)
+			add(fmt.Sprintf("missing required %s parameter %s", p.In, p.Name), false, d)
+		}
+		ps, err := s.resolve(p.Schema)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range s.violations(ps) {
+			text, ok := paramText(v.value)
+			// a number is a fine string once it is in a URL
+			if !ok || len(s.validate(ps, paramValue(ps, text), "$")) == 0 {
+				continue
+			}
+			d := base.clone()
+			switch p.In {
+			case "path":
+				d.path[p.Name] = text
+			case "query":
+				d.query.Set(p.Name, text)
+			case "header":
+				d.header.Set(p.Name, text)
+			default:
+				continue
+			}
+			add(fmt.Sprintf("%s parameter %s %s", p.In, p.Name, v.name), false, d)
+		}
+	}
+
+	if bodySchema != nil {
+		if op.requestBody.Required {
+			d := base.clone()
+			d.noBody = true
+			add("missing required body", false, d)
+		}
+		d := base.clone()
+		d.raw = []byte("{")
+		add("malformed JSON body", false, d)
+
+		bs, err := s.resolve(bodySchema)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range s.violations(bs) {
+			d := base.clone()
+			d.body = v.value
+			add("body "+v.name, false, d)
+		}
+		if obj, ok := base.body.(map[string]any); ok && bs != nil {
+			names := make([]string, 0, len(bs.Properties))
+			for name := range bs.Properties {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range bs.Required {
+				d := base.clone()
+				d.body = without(obj, name)
+				add(fmt.Sprintf("body missing required property %s", name), false, d)
+			}
+			for _, name := range names {
+				prop, err := s.resolve(bs.Properties[name])
+				if err != nil {
+					return nil, err
+				}
+				if prop.ReadOnly {
+					continue
+				}
+				for _, v := range s.violations(prop) {
+					d := base.clone()
+					d.body = with(obj, name, v.value)
+					add(fmt.Sprintf("body property %s %s", name, v.name), false, d)
+				}
+			}
+		}
+	}
+	return cases, nil
+}
+
+// request encodes d as a request for op. It reports false if d cannot be
+// sent, such as an empty path parameter.
+func (s *Spec) request(op *Operation, d draft) (testclient.RequestSpec, bool) {
+	path, err := testclient.ExpandPath(op.Path, d.path)
+	if err != nil {
+		return testclient.RequestSpec{}, false
+	}
+	target := s.BasePath + path
+	if len(d.query) > 0 {
+		target += "?" + d.query.Encode()
+	}
+	// the header of d may be shared with the drafts cloned from it
+	spec := testclient.RequestSpec{Method: op.Method, Target: target, Header: d.header.Clone()}
+	switch {
+	case d.noBody:
+	case d.raw != nil:
+		spec.Body = d.raw
+	case d.body != nil:
+		b, err := json.Marshal(d.body)
+		if err != nil {
+			return testclient.RequestSpec{}, false
+		}
+		spec.Body = b
+	}
+	if spec.Body != nil {
+		spec.Header.Set("Content-Type", "application/json")
+	}
+	return spec, true
+}
+
+func (d draft) clone() draft {
+	path := testclient.Params{}
+	for k, v := range d.path {
+		path[k] = v
+	}
+	return draft{path: path, query: cloneValues(d.query), header: d.header.Clone(), body: d.body}
+}
+
+func cloneValues(v url.Values) url.Values {
+	out := url.Values{}
+	for k, vv := range v {
+		out[k] = append([]string(nil), vv...)
+	}
+	return out
+}
+
+func without(obj map[string]any, name string) map[string]any {
+	out := make(map[string]any, len(obj))
+	for k, v := range obj {
+		if k != name {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func with(obj map[string]any, name string, value any) map[string]any {
+	out := without(obj, name)
+	out[name] = value
+	return out
+}
+
+// paramExample returns a valid value for p as sent in a URL or header.
+func (s *Spec) paramExample(p *parameter) (string, error) {
+	v := p.Example
+	if v == nil {
+		var err error
+		if v, err = s.example(p.Schema, 0); err != nil {
+			return "", err
+		}
+	}
+	text, ok := paramText(v)
+	if !ok {
+		return "", fmt.Errorf("parameter %s: cannot encode %s", p.Name, jsonText(v))
+	}
+	return text, nil
+}
+
+func paramText(v any) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case int:
+		return strconv.Itoa(v), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
+
+// paramValue decodes a parameter as its schema type, as a handler parsing
+// it would.
+func paramValue(sc *schema, text string) any {
+	switch sc.Type.main() {
+	case "integer", "number":
+		if f, err := strconv.ParseFloat(text, 64); err == nil {
+			return f
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(text); err == nil {
+			return b
+		}
+	}
+	return text
+}
+
+// violations returns values that break one constraint of sc each and that
+// validate reports as such.
+func (s *Spec) violations(sc *schema) []violation {
+	if sc == nil {
+		return nil
+	}
+	var vs []violation
+	switch sc.Type.main() {
+	case "string":
+		vs = append(vs, violation{"of the wrong type", 12345.0})
+		if sc.MinLength != nil && *sc.MinLength > 0 {
+			vs = append(vs, violation{fmt.Sprintf("shorter than minLength %d", *sc.MinLength), strings.Repeat("x", *sc.MinLength-1)})
+		}
+		if sc.MaxLength != nil {
+			vs = append(vs, violation{fmt.Sprintf("longer than maxLength %d", *sc.MaxLength), strings.Repeat("x", *sc.MaxLength+1)})
+		}
+		if len(sc.Enum) > 0 {
+			vs = append(vs, violation{"outside the enum", "not-in-enum"})
+		}
+		if sc.Format != "" && checkFormat(sc.Format, "not-a-"+sc.Format) != "" {
+			vs = append(vs, violation{"not a valid " + sc.Format, "not-a-" + sc.Format})
+		}
+	case "integer", "number":
+		vs = append(vs, violation{"of the wrong type", "not-a-number"})
+		min, max, exclMin, exclMax := sc.bounds()
+		if min != nil {
+			v := *min - 1
+			if exclMin {
+				v = *min
+			}
+			vs = append(vs, violation{fmt.Sprintf("below the minimum %v", *min), v})
+		}
+		if max != nil {
+			v := *max + 1
+			if exclMax {
+				v = *max
+			}
+			vs = append(vs, violation{fmt.Sprintf("above the maximum %v", *max), v})
+		}
+		if sc.Type.main() == "integer" {
+			vs = append(vs, violation{"not an integer", 1.5})
+		}
+	case "boolean":
+		vs = append(vs, violation{"of the wrong type", "not-a-boolean"})
+	case "array":
+		vs = append(vs, violation{"of the wrong type", "not-an-array"})
+		if sc.MaxItems != nil {
+			item, _ := s.example(sc.Items, 1)
+			items := make([]any, *sc.MaxItems+1)
+			for i := range items {
+				items[i] = item
+			}
+			vs = append(vs, violation{fmt.Sprintf("with more than maxItems %d", *sc.MaxItems), items})
+		}
+	case "object":
+		vs = append(vs, violation{"of the wrong type", "not-an-object"})
+	}
+	// keep only values the schema really rejects, e.g. not "x" when a
+	// pattern or an enum already rules strings out
+	var out []violation
+	for _, v := range vs {
+		if len(s.validate(sc, normalize(v.value), "$")) > 0 {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,184 @@
+package testopenapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	testclient "github.com/raksul/go-testclient"
+)
+
+type pet struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	Born string `json:"born,omitempty"`
+}
+
+// petsHandler implements testdata/pets.yaml, validating its input.
+func petsHandler() http.Handler {
+	fail := func(w http.ResponseWriter, status int, msg string) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"message": msg})
+	}
+	reply := func(w http.ResponseWriter, status int, v any) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(v)
+	}
+	list := func(w http.ResponseWriter, r *http.Request) {
+		if v := r.URL.Query().Get("limit"); v != "" {
+			if n, err := strconv.Atoi(v); err != nil || n < 1 || n > 100 {
+				fail(w, http.StatusBadRequest, "bad limit")
+				return
+			}
+		}
+		reply(w, http.StatusOK, []pet{{ID: 1, Name: "Tama", Kind: "cat"}})
+	}
+	create := func(w http.ResponseWriter, r *http.Request) {
+		var p pet
+		dec := json.NewDecoder(r.Body)
+		if err := dec.Decode(&p); err != nil {
+			fail(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if n := len([]rune(p.Name)); n < 1 || n > 20 || p.Kind != "cat" && p.Kind != "dog" {
+			fail(w, http.StatusUnprocessableEntity, "invalid pet")
+			return
+		}
+		if _, err := time.Parse(time.DateOnly, p.Born); p.Born != "" && err != nil {
+			fail(w, http.StatusUnprocessableEntity, "invalid born")
+			return
+		}
+		p.ID = 2
+		reply(w, http.StatusCreated, p)
+	}
+	show := func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/v1/pets/"))
+		if err != nil || id < 1 {
+			fail(w, http.StatusBadRequest, "bad id")
+			return
+		}
+		reply(w, http.StatusOK, pet{ID: id, Name: "Tama", Kind: "cat"})
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v1/pets" && r.Method == http.MethodGet:
+			list(w, r)
+		case r.URL.Path == "/v1/pets" && r.Method == http.MethodPost:
+			create(w, r)
+		case strings.HasPrefix(r.URL.Path, "/v1/pets/") && r.Method == http.MethodGet:
+			show(w, r)
+		default:
+			fail(w, http.StatusNotFound, "not found")
+		}
+	})
+}
+
+func TestRun(t *testing.T) {
+	spec, err := Load("testdata/pets.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	Run(t, testclient.New(petsHandler()), spec)
+}
+
+func TestCases(t *testing.T) {
+	spec, err := Load("testdata/pets.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases, err := spec.Cases()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]Case{}
+	for _, c := range cases {
+		got[c.String()] = c
+	}
+	want := map[string]string{
+		"GET /pets valid": "/v1/pets?limit=1",
+		"GET /pets query parameter limit above the maximum 100": "/v1/pets?limit=101",
+		"GET /pets query parameter limit of the wrong type":     "/v1/pets?limit=not-a-number",
+		"GET /pets/{id} valid":                                  "/v1/pets/1",
+		"GET /pets/{id} path parameter id below the minimum 1":  "/v1/pets/0",
+	}
+	for name, target := range want {
+		if c, ok := got[name]; !ok || c.Target != target {
+			t.Errorf("case %q: target %q, want %q", name, c.Target, target)
+		}
+	}
+	bodies := map[string]string{
+		"POST /pets valid": `{"born":"2024-01-02","kind":"cat","name":"example"}`,
+		"POST /pets body missing required property kind":         `{"born":"2024-01-02","name":"example"}`,
+		"POST /pets body property name longer than maxLength 20": `{"born":"2024-01-02","kind":"cat","name":"xxxxxxxxxxxxxxxxxxxxx"}`,
+		"POST /pets body property kind outside the enum":         `{"born":"2024-01-02","kind":"not-in-enum","name":"example"}`,
+		"POST /pets body property born not a valid date":         `{"born":"not-a-date","kind":"cat","name":"example"}`,
+		"POST /pets malformed JSON body":                         `{`,
+		"POST /pets missing required body":                       ``,
+	}
+	for name, body := range bodies {
+		c, ok := got[name]
+		if !ok || string(c.Body) != body {
+			t.Errorf("case %q: body %s, want %s", name, c.Body, body)
+		}
+		if ok && c.Valid != (name == "POST /pets valid") {
+			t.Errorf("case %q: valid = %v", name, c.Valid)
+		}
+		contentType := "application/json"
+		if body == "" {
+			contentType = ""
+		}
+		if ok && c.Header.Get("Content-Type") != contentType {
+			t.Errorf("case %q: Content-Type %q, want %q", name, c.Header.Get("Content-Type"), contentType)
+		}
+	}
+	if _, ok := got["GET /pets query parameter limit not an integer"]; !ok {
+		t.Error("no case for a fractional limit")
+	}
+}
+
+func TestValidateResponse(t *testing.T) {
+	spec, err := Load("testdata/pets.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var show *Operation
+	for _, op := range spec.Operations() {
+		if op.ID == "showPet" {
+			show = op
+		}
+	}
+	tests := []struct {
+		status int
+		ctype  string
+		body   string
+		err    string
+	}{
+		{200, "application/json", `{"id": 1, "name": "Tama", "kind": "cat"}`, ""},
+		{200, "application/json", `{"id": "1", "name": "Tama", "kind": "bird"}`, `$.id: expected integer, got string`},
+		{200, "application/json", `{"name": "Tama", "kind": "cat"}`, `missing required property "id"`},
+		{200, "text/plain", `Tama`, `Content-Type "text/plain" is not documented`},
+		{500, "application/json", `{}`, "status 500 is not documented"},
+		{404, "application/json", `{"message": "no such pet"}`, ""},
+	}
+	for _, tt := range tests {
+		res := &http.Response{StatusCode: tt.status, Header: http.Header{"Content-Type": {tt.ctype}}, Body: io.NopCloser(strings.NewReader(tt.body))}
+		err := spec.ValidateResponse(show, res)
+		if tt.err == "" {
+			if err != nil {
+				t.Errorf("%d %s: %v", tt.status, tt.body, err)
+			}
+		} else if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%d %s: err = %v, want %q", tt.status, tt.body, err, tt.err)
+		}
+		if b, _ := io.ReadAll(res.Body); string(b) != tt.body {
+			t.Errorf("body after validation = %q", b)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package testopenapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"testing"
+
+	testclient "github.com/raksul/go-testclient"
+)
+
+// Run sends every case of spec through c, each in a subtest named after
+// its operation and case, and fails it if the response is not documented
+// for the operation or does not validate against the spec, if a valid
+// request gets a 5xx, or if an invalid one gets anything but a 4xx.
+func Run(t *testing.T, c *testclient.Client, spec *Spec) {
+	t.Helper()
+	cases, err := spec.Cases()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.String(), func(t *testing.T) {
+			req := c.NewRequest(tc.Method, tc.Target, bytes.NewReader(tc.Body))
+			for k, vv := range tc.Header {
+				req.Header[k] = vv
+			}
+			res, err := c.Request(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			switch {
+			case tc.Valid && res.StatusCode >= 500:
+				t.Errorf("%s %s: valid request got %d", tc.Method, tc.Target, res.StatusCode)
+			case !tc.Valid && (res.StatusCode < 400 || res.StatusCode >= 500):
+				t.Errorf("%s %s: expected a 4xx for a request %s, got %d\n%s", tc.Method, tc.Target, tc.Name, res.StatusCode, tc.Body)
+			}
+			if err := spec.ValidateResponse(tc.Operation, res); err != nil {
+				t.Errorf("%s %s: %v", tc.Method, tc.Target, err)
+			}
+		})
+	}
+}
+
+// ValidateResponse checks res against the responses documented for op:
+// the status must be documented, exactly or by its class or a default,
+// and a JSON body must validate against the documented schema. The body
+// of res stays readable.
+func (s *Spec) ValidateResponse(op *Operation, res *http.Response) error {
+	doc, ok := op.response(res.StatusCode)
+	if !ok {
+		return fmt.Errorf("testopenapi: status %d is not documented for %s", res.StatusCode, op)
+	}
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("testopenapi: reading response body: %w", err)
+	}
+	if len(doc.Content) == 0 || len(body) == 0 {
+		return nil
+	}
+	ct := res.Header.Get("Content-Type")
+	media, _, _ := mime.ParseMediaType(ct)
+	if !documented(doc.Content, media) {
+		return fmt.Errorf("testopenapi: Content-Type %q is not documented for %d of %s", ct, res.StatusCode, op)
+	}
+	if !isJSON(media) {
+		return nil
+	}
+	mt, _ := jsonMedia(doc.Content)
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return fmt.Errorf("testopenapi: %d response of %s is not JSON: %w", res.StatusCode, op, err)
+	}
+	if errs := s.validate(mt.Schema, v, "$"); len(errs) > 0 {
+		return fmt.Errorf("testopenapi: %d response of %s does not match the spec:\n\t%s", res.StatusCode, op, strings.Join(errs, "\n\t"))
+	}
+	return nil
+}
+
+// documented reports whether content lists media, directly or by a range
+// such as application/* or */*.
+func documented(content map[string]mediaType, media string) bool {
+	kind, _, _ := strings.Cut(media, "/")
+	for k := range content {
+		k, _, _ = mime.ParseMediaType(k)
+		if k == media || k == "*/*" || k == kind+"/*" {
+			return true
+		}
+	}
+	return false
+}
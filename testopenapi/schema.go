@@ -0,0 +1,508 @@
+package testopenapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+type schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 typeList           `json:"type"`
+	Format               string             `json:"format"`
+	Pattern              string             `json:"pattern"`
+	Enum                 []any              `json:"enum"`
+	Nullable             bool               `json:"nullable"`
+	Example              any                `json:"example"`
+	Default              any                `json:"default"`
+	MinLength            *int               `json:"minLength"`
+	MaxLength            *int               `json:"maxLength"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+	ExclusiveMinimum     exclusiveBound     `json:"exclusiveMinimum"`
+	ExclusiveMaximum     exclusiveBound     `json:"exclusiveMaximum"`
+	MinItems             *int               `json:"minItems"`
+	MaxItems             *int               `json:"maxItems"`
+	Items                *schema            `json:"items"`
+	Properties           map[string]*schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties *additional        `json:"additionalProperties"`
+	AllOf                []*schema          `json:"allOf"`
+	OneOf                []*schema          `json:"oneOf"`
+	AnyOf                []*schema          `json:"anyOf"`
+	ReadOnly             bool               `json:"readOnly"`
+	WriteOnly            bool               `json:"writeOnly"`
+}
+
+// typeList is the OpenAPI 3.0 single type or the 3.1 list of types.
+type typeList []string
+
+func (t *typeList) UnmarshalJSON(b []byte) error {
+	var one string
+	if json.Unmarshal(b, &one) == nil {
+		*t = typeList{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(b, &many); err != nil {
+		return err
+	}
+	*t = many
+	return nil
+}
+
+func (t typeList) has(name string) bool {
+	for _, v := range t {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+// main returns the first non-null type.
+func (t typeList) main() string {
+	for _, v := range t {
+		if v != "null" {
+			return v
+		}
+	}
+	return ""
+}
+
+// exclusiveBound is the OpenAPI 3.0 boolean flag on minimum or maximum, or
+// the 3.1 numeric bound.
+type exclusiveBound struct {
+	flag  bool
+	value *float64
+}
+
+func (e *exclusiveBound) UnmarshalJSON(b []byte) error {
+	if json.Unmarshal(b, &e.flag) == nil {
+		return nil
+	}
+	return json.Unmarshal(b, &e.value)
+}
+
+// additional is additionalProperties: a boolean or a schema.
+type additional struct {
+	allowed bool
+	schema  *schema
+}
+
+func (a *additional) UnmarshalJSON(b []byte) error {
+	if json.Unmarshal(b, &a.allowed) == nil {
+		return nil
+	}
+	a.allowed = true
+	return json.Unmarshal(b, &a.schema)
+}
+
+// resolve follows $refs to a component schema.
+func (s *Spec) resolve(sc *schema) (*schema, error) {
+	for depth := 0; sc != nil && sc.Ref != ""; depth++ {
+		if depth > 32 {
+			return nil, fmt.Errorf("testopenapi: $ref cycle at %s", sc.Ref)
+		}
+		name, err := refName(sc.Ref, "schemas")
+		if err != nil {
+			return nil, err
+		}
+		next, ok := s.components.Schemas[name]
+		if !ok {
+			return nil, fmt.Errorf("testopenapi: unknown schema %s", name)
+		}
+		sc = next
+	}
+	return sc, nil
+}
+
+// bounds returns the inclusive-or-exclusive numeric limits of sc.
+func (sc *schema) bounds() (min, max *float64, exclMin, exclMax bool) {
+	min, max = sc.Minimum, sc.Maximum
+	exclMin, exclMax = sc.ExclusiveMinimum.flag, sc.ExclusiveMaximum.flag
+	if v := sc.ExclusiveMinimum.value; v != nil {
+		min, exclMin = v, true
+	}
+	if v := sc.ExclusiveMaximum.value; v != nil {
+		max, exclMax = v, true
+	}
+	return min, max, exclMin, exclMax
+}
+
+// validate returns the violations of sc by v, a decoded JSON value, each
+// prefixed with its JSON path.
+func (s *Spec) validate(sc *schema, v any, path string) []string {
+	sc, err := s.resolve(sc)
+	if err != nil {
+		return []string{path + ": " + err.Error()}
+	}
+	if sc == nil {
+		return nil
+	}
+	var errs []string
+	fail := func(format string, args ...any) {
+		errs = append(errs, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	for _, sub := range sc.AllOf {
+		errs = append(errs, s.validate(sub, v, path)...)
+	}
+	if len(sc.AnyOf) > 0 {
+		matched := false
+		for _, sub := range sc.AnyOf {
+			if len(s.validate(sub, v, path)) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			fail("matches none of anyOf")
+		}
+	}
+	if len(sc.OneOf) > 0 {
+		matched := 0
+		for _, sub := range sc.OneOf {
+			if len(s.validate(sub, v, path)) == 0 {
+				matched++
+			}
+		}
+		if matched != 1 {
+			fail("matches %d of oneOf, want exactly 1", matched)
+		}
+	}
+
+	if v == nil {
+		if len(sc.Type) > 0 && !sc.Nullable && !sc.Type.has("null") {
+			fail("null is not allowed")
+		}
+		return errs
+	}
+	if len(sc.Enum) > 0 && !inEnum(sc.Enum, v) {
+		fail("%s is not one of the enum values", jsonText(v))
+	}
+	if len(sc.Type) > 0 && !typeMatches(sc.Type, v) {
+		fail("expected %s, got %s", strings.Join(sc.Type, " or "), jsonType(v))
+		return errs
+	}
+
+	switch v := v.(type) {
+	case string:
+		n := len([]rune(v))
+		if sc.MinLength != nil && n < *sc.MinLength {
+			fail("length %d is shorter than minLength %d", n, *sc.MinLength)
+		}
+		if sc.MaxLength != nil && n > *sc.MaxLength {
+			fail("length %d is longer than maxLength %d", n, *sc.MaxLength)
+		}
+		if sc.Pattern != "" {
+			if re, err := regexp.Compile(sc.Pattern); err == nil && !re.MatchString(v) {
+				fail("%q does not match pattern %s", v, sc.Pattern)
+			}
+		}
+		if msg := checkFormat(sc.Format, v); msg != "" {
+			fail("%q is not a valid %s", v, msg)
+		}
+	case float64:
+		min, max, exclMin, exclMax := sc.bounds()
+		if min != nil && (v < *min || exclMin && v == *min) {
+			fail("%v is below the minimum %v", v, *min)
+		}
+		if max != nil && (v > *max || exclMax && v == *max) {
+			fail("%v is above the maximum %v", v, *max)
+		}
+	case []any:
+		if sc.MinItems != nil && len(v) < *sc.MinItems {
+			fail("%d items, fewer than minItems %d", len(v), *sc.MinItems)
+		}
+		if sc.MaxItems != nil && len(v) > *sc.MaxItems {
+			fail("%d items, more than maxItems %d", len(v), *sc.MaxItems)
+		}
+		for i, e := range v {
+			errs = append(errs, s.validate(sc.Items, e, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	case map[string]any:
+		for _, name := range sc.Required {
+			if _, ok := v[name]; !ok {
+				fail("missing required property %q", name)
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if prop, ok := sc.Properties[k]; ok {
+				errs = append(errs, s.validate(prop, v[k], path+"."+k)...)
+				continue
+			}
+			if ap := sc.AdditionalProperties; ap != nil {
+				if !ap.allowed {
+					fail("unexpected property %q", k)
+				} else if ap.schema != nil {
+					errs = append(errs, s.validate(ap.schema, v[k], path+"."+k)...)
+				}
+			}
+		}
+	}
+	return errs
+}
+
+func typeMatches(types typeList, v any) bool {
+	for _, t := range types {
+		switch v := v.(type) {
+		case string:
+			if t == "string" {
+				return true
+			}
+		case bool:
+			if t == "boolean" {
+				return true
+			}
+		case float64:
+			if t == "number" || t == "integer" && v == math.Trunc(v) {
+				return true
+			}
+		case []any:
+			if t == "array" {
+				return true
+			}
+		case map[string]any:
+			if t == "object" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func jsonType(v any) string {
+	switch v.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return "null"
+}
+
+func jsonText(v any) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+func inEnum(enum []any, v any) bool {
+	for _, e := range enum {
+		if reflect.DeepEqual(normalize(e), v) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize round-trips v through JSON so that spec values compare with
+// decoded ones.
+func normalize(v any) any {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out any
+	json.Unmarshal(b, &out)
+	return out
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// checkFormat returns the name of format if v violates it. Unknown formats
+// are not checked.
+func checkFormat(format, v string) string {
+	var ok bool
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, v)
+		ok = err == nil
+	case "date":
+		_, err := time.Parse(time.DateOnly, v)
+		ok = err == nil
+	case "email":
+		local, domain, found := strings.Cut(v, "@")
+		ok = found && local != "" && strings.Contains(domain, ".")
+	case "uuid":
+		ok = uuidPattern.MatchString(v)
+	case "uri":
+		u, err := url.Parse(v)
+		ok = err == nil && u.IsAbs()
+	default:
+		return ""
+	}
+	if ok {
+		return ""
+	}
+	return format
+}
+
+// maxDepth stops example generation in recursive schemas.
+const maxDepth = 8
+
+// example returns a value valid against sc, preferring the examples and
+// defaults of the spec. readOnly properties are left out, as requests
+// must not send them.
+func (s *Spec) example(sc *schema, depth int) (any, error) {
+	sc, err := s.resolve(sc)
+	if err != nil || sc == nil {
+		return nil, err
+	}
+	switch {
+	case sc.Example != nil:
+		return sc.Example, nil
+	case sc.Default != nil:
+		return sc.Default, nil
+	case len(sc.Enum) > 0:
+		return sc.Enum[0], nil
+	case len(sc.OneOf) > 0:
+		return s.example(sc.OneOf[0], depth)
+	case len(sc.AnyOf) > 0:
+		return s.example(sc.AnyOf[0], depth)
+	case len(sc.AllOf) > 0:
+		merged := map[string]any{}
+		for _, sub := range sc.AllOf {
+			v, err := s.example(sub, depth)
+			if err != nil {
+				return nil, err
+			}
+			m, ok := v.(map[string]any)
+			if !ok {
+				return v, nil
+			}
+			for k, e := range m {
+				merged[k] = e
+			}
+		}
+		return merged, nil
+	}
+
+	typ := sc.Type.main()
+	if typ == "" && len(sc.Properties) > 0 {
+		typ = "object"
+	}
+	switch typ {
+	case "string":
+		return exampleString(sc), nil
+	case "integer", "number":
+		return exampleNumber(sc, typ == "integer"), nil
+	case "boolean":
+		return true, nil
+	case "array":
+		n := 1
+		if sc.MinItems != nil && *sc.MinItems > n {
+			n = *sc.MinItems
+		}
+		if sc.MaxItems != nil && *sc.MaxItems < n || depth >= maxDepth {
+			n = 0
+			if sc.MinItems != nil {
+				n = *sc.MinItems
+			}
+		}
+		items := make([]any, n)
+		for i := range items {
+			v, err := s.example(sc.Items, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = v
+		}
+		return items, nil
+	case "object":
+		obj := map[string]any{}
+		for name, prop := range sc.Properties {
+			ps, err := s.resolve(prop)
+			if err != nil {
+				return nil, err
+			}
+			if ps.ReadOnly || depth >= maxDepth && !contains(sc.Required, name) {
+				continue
+			}
+			v, err := s.example(prop, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			obj[name] = v
+		}
+		return obj, nil
+	}
+	return nil, nil
+}
+
+func exampleString(sc *schema) string {
+	switch sc.Format {
+	case "date-time":
+		return "2024-01-02T15:04:05Z"
+	case "date":
+		return "2024-01-02"
+	case "email":
+		return "user@example.com"
+	case "uuid":
+		return "00000000-0000-4000-8000-000000000000"
+	case "uri":
+		return "https://example.com/"
+	}
+	v := "example"
+	if sc.MinLength != nil && len(v) < *sc.MinLength {
+		v += strings.Repeat("x", *sc.MinLength-len(v))
+	}
+	if sc.MaxLength != nil && len(v) > *sc.MaxLength {
+		v = v[:*sc.MaxLength]
+	}
+	return v
+}
+
+func exampleNumber(sc *schema, integer bool) float64 {
+	min, max, exclMin, exclMax := sc.bounds()
+	step := 0.5
+	if integer {
+		step = 1
+	}
+	v := 1.0
+	switch {
+	case min != nil:
+		v = *min
+		if exclMin {
+			v += step
+		}
+		if integer {
+			v = math.Ceil(v)
+		}
+	case max != nil && *max < v:
+		v = *max
+		if exclMax {
+			v -= step
+		}
+		if integer {
+			v = math.Floor(v)
+		}
+	}
+	return v
+}
+
+func contains(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
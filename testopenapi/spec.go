@@ -0,0 +1,294 @@
+// Package testopenapi drives a handler from its OpenAPI 3 description:
+// it generates valid and boundary-invalid requests for every operation,
+// sends them through a testclient.Client and checks the responses against
+// the spec. It lives apart from the core package so that only tests using
+// it depend on a YAML parser.
+//
+// The supported subset covers what request generation and JSON response
+// validation need: paths, operations, path/query/header parameters, JSON
+// request bodies and responses, local $refs, and the common schema
+// keywords. Security requirements are not applied; authenticate the
+// client instead.
+package testopenapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Spec is a parsed OpenAPI document.
+type Spec struct {
+	// BasePath is the path of the first server URL, prefixed to every
+	// operation path.
+	BasePath   string
+	operations []*Operation
+	components components
+}
+
+// Operation is one method of a path.
+type Operation struct {
+	Method string
+	Path   string
+	ID     string
+
+	parameters  []*parameter
+	requestBody *requestBody
+	responses   map[string]*response
+}
+
+type document struct {
+	Servers []struct {
+		URL string `json:"url"`
+	} `json:"servers"`
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components components                            `json:"components"`
+}
+
+type components struct {
+	Schemas       map[string]*schema      `json:"schemas"`
+	Parameters    map[string]*parameter   `json:"parameters"`
+	RequestBodies map[string]*requestBody `json:"requestBodies"`
+	Responses     map[string]*response    `json:"responses"`
+}
+
+type operationDoc struct {
+	OperationID string               `json:"operationId"`
+	Parameters  []*parameter         `json:"parameters"`
+	RequestBody *requestBody         `json:"requestBody"`
+	Responses   map[string]*response `json:"responses"`
+}
+
+type parameter struct {
+	Ref      string  `json:"$ref"`
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *schema `json:"schema"`
+	Example  any     `json:"example"`
+}
+
+type requestBody struct {
+	Ref      string               `json:"$ref"`
+	Required bool                 `json:"required"`
+	Content  map[string]mediaType `json:"content"`
+}
+
+type response struct {
+	Ref     string               `json:"$ref"`
+	Content map[string]mediaType `json:"content"`
+}
+
+type mediaType struct {
+	Schema  *schema `json:"schema"`
+	Example any     `json:"example"`
+}
+
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// Load reads an OpenAPI document in YAML or JSON from path.
+func Load(path string) (*Spec, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(b)
+}
+
+// Parse parses an OpenAPI document in YAML or JSON.
+func Parse(data []byte) (*Spec, error) {
+	var raw any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("testopenapi: parsing spec: %w", err)
+	}
+	b, err := json.Marshal(stringKeys(raw))
+	if err != nil {
+		return nil, fmt.Errorf("testopenapi: parsing spec: %w", err)
+	}
+	var doc document
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("testopenapi: parsing spec: %w", err)
+	}
+
+	s := &Spec{components: doc.Components}
+	if len(doc.Servers) > 0 {
+		if u, err := url.Parse(doc.Servers[0].URL); err == nil {
+			s.BasePath = strings.TrimSuffix(u.Path, "/")
+		}
+	}
+	for path, item := range doc.Paths {
+		var shared []*parameter
+		if p, ok := item["parameters"]; ok {
+			if err := json.Unmarshal(p, &shared); err != nil {
+				return nil, fmt.Errorf("testopenapi: %s: parameters: %w", path, err)
+			}
+		}
+		for _, method := range methods {
+			raw, ok := item[method]
+			if !ok {
+				continue
+			}
+			var od operationDoc
+			if err := json.Unmarshal(raw, &od); err != nil {
+				return nil, fmt.Errorf("testopenapi: %s %s: %w", strings.ToUpper(method), path, err)
+			}
+			op, err := s.newOperation(strings.ToUpper(method), path, shared, od)
+			if err != nil {
+				return nil, err
+			}
+			s.operations = append(s.operations, op)
+		}
+	}
+	sort.Slice(s.operations, func(i, j int) bool {
+		a, b := s.operations[i], s.operations[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Method < b.Method
+	})
+	return s, nil
+}
+
+// stringKeys converts the map[any]any YAML produces for keys such as
+// status codes into JSON-encodable maps.
+func stringKeys(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, e := range v {
+			v[k] = stringKeys(e)
+		}
+		return v
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = stringKeys(e)
+		}
+		return m
+	case []any:
+		for i, e := range v {
+			v[i] = stringKeys(e)
+		}
+	}
+	return v
+}
+
+func (s *Spec) newOperation(method, path string, shared []*parameter, od operationDoc) (*Operation, error) {
+	op := &Operation{Method: method, Path: path, ID: od.OperationID, responses: map[string]*response{}}
+	// operation parameters override path-level ones of the same name and
+	// location
+	byKey := map[string]*parameter{}
+	var order []string
+	for _, list := range [][]*parameter{shared, od.Parameters} {
+		for _, p := range list {
+			p, err := s.parameter(p)
+			if err != nil {
+				return nil, fmt.Errorf("testopenapi: %s %s: %w", method, path, err)
+			}
+			key := p.In + ":" + p.Name
+			if _, ok := byKey[key]; !ok {
+				order = append(order, key)
+			}
+			byKey[key] = p
+		}
+	}
+	for _, key := range order {
+		op.parameters = append(op.parameters, byKey[key])
+	}
+
+	if od.RequestBody != nil {
+		rb := od.RequestBody
+		if rb.Ref != "" {
+			name, err := refName(rb.Ref, "requestBodies")
+			if err != nil {
+				return nil, err
+			}
+			if rb = s.components.RequestBodies[name]; rb == nil {
+				return nil, fmt.Errorf("testopenapi: unknown request body %s", name)
+			}
+		}
+		op.requestBody = rb
+	}
+	for status, res := range od.Responses {
+		if res.Ref != "" {
+			name, err := refName(res.Ref, "responses")
+			if err != nil {
+				return nil, err
+			}
+			if res = s.components.Responses[name]; res == nil {
+				return nil, fmt.Errorf("testopenapi: unknown response %s", name)
+			}
+		}
+		op.responses[strings.ToUpper(status)] = res
+	}
+	return op, nil
+}
+
+func (s *Spec) parameter(p *parameter) (*parameter, error) {
+	if p.Ref == "" {
+		return p, nil
+	}
+	name, err := refName(p.Ref, "parameters")
+	if err != nil {
+		return nil, err
+	}
+	if q := s.components.Parameters[name]; q != nil {
+		return q, nil
+	}
+	return nil, fmt.Errorf("unknown parameter %s", name)
+}
+
+// refName returns the component name of a local reference into kind.
+func refName(ref, kind string) (string, error) {
+	name, ok := strings.CutPrefix(ref, "#/components/"+kind+"/")
+	if !ok || strings.Contains(name, "/") {
+		return "", fmt.Errorf("testopenapi: unsupported $ref %q", ref)
+	}
+	return strings.NewReplacer("~1", "/", "~0", "~").Replace(name), nil
+}
+
+// Operations returns the operations of the spec, sorted by path and method.
+func (s *Spec) Operations() []*Operation {
+	return s.operations
+}
+
+// jsonMedia returns the schema of the JSON media type in content, if any.
+func jsonMedia(content map[string]mediaType) (mediaType, bool) {
+	keys := make([]string, 0, len(content))
+	for k := range content {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if isJSON(k) {
+			return content[k], true
+		}
+	}
+	return mediaType{}, false
+}
+
+func isJSON(mediaType string) bool {
+	mediaType, _, _ = strings.Cut(strings.ToLower(mediaType), ";")
+	mediaType = strings.TrimSpace(mediaType)
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// response returns the documented response for status: the exact code,
+// then its class (e.g. 4XX), then the default.
+func (op *Operation) response(status int) (*response, bool) {
+	code := fmt.Sprint(status)
+	for _, key := range []string{code, code[:1] + "XX", "DEFAULT"} {
+		if res, ok := op.responses[key]; ok {
+			return res, true
+		}
+	}
+	return nil, false
+}
+
+func (op *Operation) String() string {
+	return op.Method + " " + op.Path
+}
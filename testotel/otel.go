@@ -0,0 +1,156 @@
+// Package testotel adds OpenTelemetry tracing to testclient: a client span
+// per request, propagated to the handler as W3C Trace Context, and an
+// in-memory recorder of the spans ended during a test. It lives apart from
+// the core package so that only tests using it depend on OpenTelemetry.
+package testotel
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	testclient "github.com/raksul/go-testclient"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName names the tracer of the client spans.
+const InstrumentationName = "github.com/raksul/go-testclient"
+
+// Recorder is a tracer provider that keeps every span ended on it in
+// memory. Hand it to the handler under test as well as to Instrument.
+type Recorder struct {
+	*sdktrace.TracerProvider
+	spans *tracetest.SpanRecorder
+}
+
+// NewRecorder returns a recorder sampling every span.
+func NewRecorder() *Recorder {
+	spans := tracetest.NewSpanRecorder()
+	return &Recorder{
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(spans)),
+		spans:          spans,
+	}
+}
+
+// Spans returns the ended spans in the order they ended.
+func (r *Recorder) Spans() []sdktrace.ReadOnlySpan {
+	return r.spans.Ended()
+}
+
+// Instrument makes c start a client span with tp around every request and
+// send it to the handler in the traceparent and tracestate headers. Under
+// testclient.WithTraceContext the spans join the trace of the client. The
+// span is named after the method and records the URL and response status;
+// a 5xx status marks it as failed. Call Instrument before Use for the span
+// to enclose the middleware too.
+func Instrument(c *testclient.Client, tp trace.TracerProvider) {
+	tracer := tp.Tracer(InstrumentationName)
+	var prop propagation.TraceContext
+	c.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := prop.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+			ctx, span := tracer.Start(ctx, "HTTP "+r.Method,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					attribute.String("http.request.method", r.Method),
+					attribute.String("url.full", requestURL(r)),
+				))
+			defer span.End()
+
+			// the handler gets the span in its headers only, as over the
+			// network, so that one not extracting them does not join
+			r = r.Clone(r.Context())
+			r.Header.Del("traceparent")
+			r.Header.Del("tracestate")
+			prop.Inject(ctx, propagation.HeaderCarrier(r.Header))
+
+			sw := &statusWriter{ResponseWriter: w}
+			next.ServeHTTP(sw, r)
+			status := sw.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			span.SetAttributes(attribute.Int("http.response.status_code", status))
+			if status >= 500 {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+		})
+	})
+}
+
+func requestURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + r.URL.RequestURI()
+}
+
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if w.status == 0 && code >= 200 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// ExpectServerSpan asserts that a span named name ended on r as the child
+// of a client span, that is, that the handler joined the trace of a
+// request instead of starting its own.
+func ExpectServerSpan(t testing.TB, r *Recorder, name string) sdktrace.ReadOnlySpan {
+	t.Helper()
+	spans := r.Spans()
+	clients := map[trace.SpanID]bool{}
+	for _, s := range spans {
+		if s.SpanKind() == trace.SpanKindClient && s.InstrumentationScope().Name == InstrumentationName {
+			clients[s.SpanContext().SpanID()] = true
+		}
+	}
+	var names []string
+	for _, s := range spans {
+		if s.Name() != name {
+			names = append(names, s.Name())
+			continue
+		}
+		if !clients[s.Parent().SpanID()] {
+			t.Errorf("expected span %s to be the child of a client span, got parent %s", name, describeParent(s))
+		}
+		return s
+	}
+	t.Errorf("expected a span named %s, got [%s]", name, strings.Join(names, ", "))
+	return nil
+}
+
+func describeParent(s sdktrace.ReadOnlySpan) string {
+	if !s.Parent().IsValid() {
+		return "none"
+	}
+	return fmt.Sprintf("%s of trace %s", s.Parent().SpanID(), s.Parent().TraceID())
+}
@@ -0,0 +1,98 @@
+package testotel
+
+import (
+	"net/http"
+	"testing"
+
+	testclient "github.com/raksul/go-testclient"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedHandler starts a server span joining the trace of the request, as
+// otelhttp would, unless join is false.
+func tracedHandler(tp trace.TracerProvider, join bool) http.Handler {
+	tracer := tp.Tracer("app")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if join {
+			ctx = propagation.TraceContext{}.Extract(ctx, propagation.HeaderCarrier(r.Header))
+		}
+		_, span := tracer.Start(ctx, "GET /orders", trace.WithSpanKind(trace.SpanKindServer))
+		defer span.End()
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+}
+
+func TestInstrument(t *testing.T) {
+	rec := NewRecorder()
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	c := testclient.New(tracedHandler(rec, true), testclient.WithTraceContext(traceID, ""))
+	Instrument(c, rec)
+	if _, err := c.Get("/orders", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	server := ExpectServerSpan(t, rec, "GET /orders")
+	if server == nil {
+		return
+	}
+	if got := server.SpanContext().TraceID().String(); got != traceID {
+		t.Errorf("server span of trace %s, want %s", got, traceID)
+	}
+	spans := rec.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	client := spans[1]
+	if client.Name() != "HTTP GET" || client.SpanKind() != trace.SpanKindClient {
+		t.Errorf("client span = %s (%s)", client.Name(), client.SpanKind())
+	}
+	if !hasAttribute(client.Attributes(), attribute.Int("http.response.status_code", 200)) {
+		t.Errorf("client span attributes = %v", client.Attributes())
+	}
+
+	c.Get("/fail", nil)
+	spans = rec.Spans()
+	if failed := spans[len(spans)-1]; failed.Status().Code != codes.Error {
+		t.Errorf("status of a 500 span = %v", failed.Status())
+	}
+}
+
+func TestExpectServerSpanNotJoined(t *testing.T) {
+	rec := NewRecorder()
+	c := testclient.New(tracedHandler(rec, false))
+	Instrument(c, rec)
+	c.Get("/orders", nil)
+
+	ft := &fakeT{TB: t}
+	ExpectServerSpan(ft, rec, "GET /orders")
+	ExpectServerSpan(ft, rec, "GET /missing")
+	if len(ft.failures) != 2 {
+		t.Errorf("failures = %q", ft.failures)
+	}
+}
+
+func hasAttribute(attrs []attribute.KeyValue, want attribute.KeyValue) bool {
+	for _, kv := range attrs {
+		if kv == want {
+			return true
+		}
+	}
+	return false
+}
+
+type fakeT struct {
+	testing.TB
+	failures []string
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Errorf(format string, args ...any) {
+	f.failures = append(f.failures, format)
+}
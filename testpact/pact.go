@@ -0,0 +1,146 @@
+// Package testpact records and verifies consumer-driven contracts in the
+// Pact format, in-process. On the consumer side, a Recorder wraps the fake
+// provider a consumer test talks to and writes the interactions it served
+// to a pact file. On the provider side, Verify replays a pact file against
+// the real handler through a testclient.Client.
+//
+// Pact specification version 3 files are written; version 2 files are
+// read as well. Of the matching rules, "type" and "regex" on response
+// bodies are applied.
+package testpact
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Pact is a contract between a consumer and a provider.
+type Pact struct {
+	Consumer     Pacticipant    `json:"consumer"`
+	Provider     Pacticipant    `json:"provider"`
+	Interactions []Interaction  `json:"interactions"`
+	Metadata     map[string]any `json:"metadata,omitempty"`
+}
+
+// Pacticipant names a party to a pact.
+type Pacticipant struct {
+	Name string `json:"name"`
+}
+
+// Interaction is a request the consumer sends and the response it expects.
+type Interaction struct {
+	Description    string          `json:"description"`
+	ProviderStates []ProviderState `json:"providerStates,omitempty"`
+	// ProviderState is the single state of version 2 pacts.
+	ProviderState string   `json:"providerState,omitempty"`
+	Request       Request  `json:"request"`
+	Response      Response `json:"response"`
+}
+
+// states returns the provider states of i, in either version.
+func (i Interaction) states() []ProviderState {
+	if i.ProviderState != "" {
+		return append([]ProviderState{{Name: i.ProviderState}}, i.ProviderStates...)
+	}
+	return i.ProviderStates
+}
+
+// ProviderState is a state the provider must be in for an interaction.
+type ProviderState struct {
+	Name   string         `json:"name"`
+	Params map[string]any `json:"params,omitempty"`
+}
+
+// Request is the request of an interaction.
+type Request struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Query   Query             `json:"query,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	// Body is a JSON body, or a JSON string holding any other body.
+	Body json.RawMessage `json:"body,omitempty"`
+}
+
+// Response is the expected response of an interaction.
+type Response struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+	// MatchingRules loosen the comparison of the body, in the version 2
+	// or version 3 layout.
+	MatchingRules json.RawMessage `json:"matchingRules,omitempty"`
+}
+
+// Query is the query of a request: a map of values in version 3 pacts,
+// a query string in version 2 ones.
+type Query url.Values
+
+func (q *Query) UnmarshalJSON(b []byte) error {
+	var s string
+	if json.Unmarshal(b, &s) == nil {
+		v, err := url.ParseQuery(s)
+		*q = Query(v)
+		return err
+	}
+	var m map[string][]string
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+	*q = Query(m)
+	return nil
+}
+
+// String returns q encoded, with keys sorted.
+func (q Query) String() string {
+	return url.Values(q).Encode()
+}
+
+// Load reads a pact file.
+func Load(path string) (*Pact, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var p Pact
+	if err := json.Unmarshal(b, &p); err != nil {
+		return nil, fmt.Errorf("testpact: parsing %s: %w", path, err)
+	}
+	return &p, nil
+}
+
+// FileName returns the conventional file name of the pact,
+// consumer-provider.json.
+func (p *Pact) FileName() string {
+	return sanitize(p.Consumer.Name) + "-" + sanitize(p.Provider.Name) + ".json"
+}
+
+func sanitize(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ' ' {
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+// Write writes the pact to path, with interactions sorted by description
+// so that files diff cleanly.
+func (p *Pact) Write(path string) error {
+	out := *p
+	out.Interactions = append([]Interaction(nil), p.Interactions...)
+	sort.SliceStable(out.Interactions, func(i, j int) bool {
+		return out.Interactions[i].Description < out.Interactions[j].Description
+	})
+	if out.Metadata == nil {
+		out.Metadata = map[string]any{"pactSpecification": map[string]string{"version": "3.0.0"}}
+	}
+	b, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o644)
+}
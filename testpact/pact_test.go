@@ -0,0 +1,134 @@
+package testpact
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	testclient "github.com/raksul/go-testclient"
+)
+
+// usersProvider is the real provider: it knows the users in its store.
+func usersProvider(store map[string]string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/users/")
+		name, ok := store[id]
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error": "not found"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"id": %q, "name": %q, "created": "2024-01-01", "roles": [%q]}`, id, name, r.URL.Query().Get("role"))
+	})
+}
+
+func TestRecordAndVerify(t *testing.T) {
+	// consumer side: the consumer's expectations, served by a stub
+	stub := testclient.NewStub()
+	stub.On(http.MethodGet, "/users/1").ReplyJSON(http.StatusOK, map[string]any{"id": "1", "name": "alice", "roles": []string{"admin"}})
+	stub.On(http.MethodGet, "/users/2").ReplyJSON(http.StatusNotFound, map[string]string{"error": "not found"})
+	rec := NewRecorder("web", "users-api")
+	c := testclient.New(rec.Wrap(stub))
+
+	rec.Given("user 1 exists").UponReceiving("a request for user 1")
+	if _, err := c.Request(c.NewRequest(http.MethodGet, "/users/1?role=admin", nil)); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(c.BodyBytes()); !strings.Contains(got, `"alice"`) {
+		t.Fatalf("the consumer got %q through the recorder", got)
+	}
+	c.Request(c.NewRequest(http.MethodGet, "/users/2", nil))
+
+	path, err := rec.WriteFile(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(path) != "web-users-api.json" {
+		t.Errorf("pact written to %s", path)
+	}
+	p, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Interactions) != 2 {
+		t.Fatalf("interactions = %+v", p.Interactions)
+	}
+	first := p.Interactions[0]
+	if first.Description != "GET /users/2" || p.Interactions[1].Description != "a request for user 1" || p.Interactions[1].ProviderStates[0].Name != "user 1 exists" {
+		t.Errorf("interactions = %+v", p.Interactions)
+	}
+	if got := p.Interactions[1].Request.Query.String(); got != "role=admin" {
+		t.Errorf("query = %q", got)
+	}
+
+	// provider side: replay the pact against the real handler
+	store := map[string]string{}
+	Verify(t, testclient.New(usersProvider(store)), path, StateHandlers{
+		"user 1 exists": func(map[string]any) error {
+			store["1"] = "alice"
+			return nil
+		},
+	})
+}
+
+func TestMismatches(t *testing.T) {
+	res := func(status int) *http.Response {
+		return &http.Response{StatusCode: status, Header: http.Header{"Content-Type": {"application/json"}}}
+	}
+	tests := []struct {
+		want   Response
+		status int
+		body   string
+		errs   []string
+	}{
+		{
+			want:   Response{Status: 200, Body: json.RawMessage(`{"id": 1, "tags": ["a"]}`)},
+			status: 200, body: `{"id": 1, "tags": ["a"], "extra": true}`,
+		},
+		{
+			want:   Response{Status: 200, Headers: map[string]string{"Content-Type": "application/json; charset=utf-8"}, Body: json.RawMessage(`{"id": 1, "tags": ["a"]}`)},
+			status: 201, body: `{"id": 2, "tags": ["a", "b"]}`,
+			errs: []string{"expected status 200, got 201", `expected header Content-Type "application/json; charset=utf-8", got "application/json"`, "$.id: expected 1, got 2", "$.tags: expected 1 elements, got 2"},
+		},
+		{
+			want: Response{Status: 200, Body: json.RawMessage(`{"user": {"id": 1, "name": "x"}, "items": [{"n": 1}]}`), MatchingRules: json.RawMessage(`{
+				"body": {"$.user": {"matchers": [{"match": "type"}]}, "$.items": {"matchers": [{"match": "type"}]}, "$.items[*].n": {"matchers": [{"match": "regex", "regex": "^\\d+$"}]}}
+			}`)},
+			status: 200, body: `{"user": {"id": 7, "name": "alice"}, "items": [{"n": "12"}, {"n": "x"}]}`,
+			errs: []string{"$.items[1].n: expected a value matching ^\\d+$, got \"x\""},
+		},
+		{
+			want:   Response{Status: 200, Body: json.RawMessage(`{"name": "x"}`), MatchingRules: json.RawMessage(`{"$.body.name": {"match": "type"}}`)},
+			status: 200, body: `{"name": 5}`,
+			errs: []string{`$.name: expected a value of the type of "x", got 5`},
+		},
+	}
+	for i, tt := range tests {
+		got := Mismatches(tt.want, res(tt.status), []byte(tt.body))
+		if strings.Join(got, "\n") != strings.Join(tt.errs, "\n") {
+			t.Errorf("%d: mismatches =\n%s\nwant\n%s", i, strings.Join(got, "\n"), strings.Join(tt.errs, "\n"))
+		}
+	}
+}
+
+func TestLoadVersion2(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "v2.json")
+	os.WriteFile(path, []byte(`{
+		"consumer": {"name": "web"}, "provider": {"name": "api"},
+		"interactions": [{"description": "d", "providerState": "s", "request": {"method": "GET", "path": "/x", "query": "a=1&b=2"}, "response": {"status": 200}}],
+		"metadata": {"pactSpecification": {"version": "2.0.0"}}
+	}`), 0o644)
+	p, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := p.Interactions[0]
+	if got := in.Request.Query.String(); got != "a=1&b=2" || len(in.states()) != 1 || in.states()[0].Name != "s" {
+		t.Errorf("interaction = %+v", in)
+	}
+}
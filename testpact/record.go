@@ -0,0 +1,147 @@
+package testpact
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Recorder records the interactions a fake provider serves.
+type Recorder struct {
+	mu          sync.Mutex
+	pact        Pact
+	states      []ProviderState
+	description string
+}
+
+// NewRecorder returns a recorder for the pact between consumer and
+// provider.
+func NewRecorder(consumer, provider string) *Recorder {
+	return &Recorder{pact: Pact{Consumer: Pacticipant{consumer}, Provider: Pacticipant{provider}}}
+}
+
+// Given adds a provider state to the next recorded interaction.
+func (r *Recorder) Given(state string) *Recorder {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.states = append(r.states, ProviderState{Name: state})
+	return r
+}
+
+// UponReceiving describes the next recorded interaction. Without it, the
+// description is the method and path of the request.
+func (r *Recorder) UponReceiving(description string) *Recorder {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.description = description
+	return r
+}
+
+// Wrap returns h recording every request it serves and its response as an
+// interaction. The response is buffered, so h cannot stream or hijack.
+// Request headers are recorded except Content-Length, Accept-Encoding and
+// User-Agent; of the response headers, only Content-Type is, as the
+// others rarely concern the consumer.
+func (r *Recorder) Wrap(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var reqBody []byte
+		if req.Body != nil {
+			reqBody, _ = io.ReadAll(req.Body)
+			req.Body = io.NopCloser(bytes.NewReader(reqBody))
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		in := Interaction{
+			Request: Request{
+				Method:  req.Method,
+				Path:    req.URL.Path,
+				Headers: recordHeaders(req.Header, func(k string) bool { return k != "Content-Length" && k != "Accept-Encoding" && k != "User-Agent" }),
+				Body:    recordBody(req.Header.Get("Content-Type"), reqBody),
+			},
+			Response: Response{
+				Status:  rec.Code,
+				Headers: recordHeaders(rec.Header(), func(k string) bool { return k == "Content-Type" }),
+				Body:    recordBody(rec.Header().Get("Content-Type"), rec.Body.Bytes()),
+			},
+		}
+		if q := req.URL.Query(); len(q) > 0 {
+			in.Request.Query = Query(q)
+		}
+		r.mu.Lock()
+		in.Description, in.ProviderStates = r.description, r.states
+		if in.Description == "" {
+			in.Description = req.Method + " " + req.URL.RequestURI()
+		}
+		r.description, r.states = "", nil
+		r.pact.Interactions = append(r.pact.Interactions, in)
+		r.mu.Unlock()
+
+		for k, vv := range rec.Header() {
+			w.Header()[k] = vv
+		}
+		w.WriteHeader(rec.Code)
+		w.Write(rec.Body.Bytes())
+	})
+}
+
+func recordHeaders(h http.Header, keep func(string) bool) map[string]string {
+	out := map[string]string{}
+	for k, vv := range h {
+		if keep(k) {
+			out[k] = strings.Join(vv, ", ")
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// recordBody returns b as a pact body: the JSON itself for a JSON media
+// type, a JSON string otherwise.
+func recordBody(contentType string, b []byte) json.RawMessage {
+	if len(b) == 0 {
+		return nil
+	}
+	if isJSON(contentType) && json.Valid(b) {
+		var buf bytes.Buffer
+		if json.Compact(&buf, b) == nil {
+			return buf.Bytes()
+		}
+	}
+	s, _ := json.Marshal(string(b))
+	return s
+}
+
+func isJSON(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// Pact returns the recorded pact.
+func (r *Recorder) Pact() *Pact {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p := r.pact
+	p.Interactions = append([]Interaction(nil), r.pact.Interactions...)
+	return &p
+}
+
+// WriteFile writes the recorded pact into dir under its conventional name,
+// creating dir if needed, and returns the file path.
+func (r *Recorder) WriteFile(dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	p := r.Pact()
+	path := filepath.Join(dir, p.FileName())
+	return path, p.Write(path)
+}
@@ -0,0 +1,296 @@
+package testpact
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	testclient "github.com/raksul/go-testclient"
+)
+
+// StateHandlers set the provider up for each provider state, by name. A
+// handler gets the params of the state.
+type StateHandlers map[string]func(params map[string]any) error
+
+// Verify replays every interaction of the pact file at path against c, in
+// a subtest named after its description, after running the state handlers
+// of its provider states. A subtest fails unless the response has the
+// expected status, the expected headers, and a body matching the expected
+// one: objects may carry extra properties, arrays match element by element,
+// and the type and regex matching rules are honored.
+func Verify(t *testing.T, c *testclient.Client, path string, states StateHandlers) {
+	t.Helper()
+	p, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, in := range p.Interactions {
+		in := in
+		t.Run(in.Description, func(t *testing.T) {
+			for _, st := range in.states() {
+				setup, ok := states[st.Name]
+				if !ok {
+					t.Fatalf("no handler for provider state %q", st.Name)
+				}
+				if err := setup(st.Params); err != nil {
+					t.Fatalf("setting up provider state %q: %v", st.Name, err)
+				}
+			}
+			res, err := c.Request(newRequest(c, in.Request))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, m := range Mismatches(in.Response, res, c.BodyBytes()) {
+				t.Errorf("%s %s: %s", in.Request.Method, in.Request.Path, m)
+			}
+		})
+	}
+}
+
+func newRequest(c *testclient.Client, r Request) *http.Request {
+	target := r.Path
+	if len(r.Query) > 0 {
+		target += "?" + r.Query.String()
+	}
+	var body []byte
+	if len(r.Body) > 0 {
+		body = r.Body
+		var s string
+		if !isJSON(r.Headers[headerKey(r.Headers, "Content-Type")]) && json.Unmarshal(r.Body, &s) == nil {
+			body = []byte(s)
+		}
+	}
+	req := c.NewRequest(r.Method, target, bytes.NewReader(body))
+	for k, v := range r.Headers {
+		req.Header.Set(k, v)
+	}
+	return req
+}
+
+// headerKey returns the key of headers naming name, in any case.
+func headerKey(headers map[string]string, name string) string {
+	for k := range headers {
+		if strings.EqualFold(k, name) {
+			return k
+		}
+	}
+	return name
+}
+
+// Mismatches compares res and its body with the expected response and
+// returns the differences.
+func Mismatches(want Response, res *http.Response, body []byte) []string {
+	var out []string
+	if res.StatusCode != want.Status {
+		out = append(out, fmt.Sprintf("expected status %d, got %d", want.Status, res.StatusCode))
+	}
+	keys := make([]string, 0, len(want.Headers))
+	for k := range want.Headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if got := strings.Join(res.Header.Values(k), ", "); !headerMatches(k, want.Headers[k], got) {
+			out = append(out, fmt.Sprintf("expected header %s %q, got %q", k, want.Headers[k], got))
+		}
+	}
+	if len(want.Body) == 0 {
+		return out
+	}
+	rules, err := parseRules(want.MatchingRules)
+	if err != nil {
+		return append(out, err.Error())
+	}
+	var expected any
+	if err := json.Unmarshal(want.Body, &expected); err != nil {
+		return append(out, fmt.Sprintf("bad expected body: %v", err))
+	}
+	var actual any
+	if s, ok := expected.(string); ok && !isJSON(res.Header.Get("Content-Type")) {
+		if string(body) != s {
+			out = append(out, fmt.Sprintf("expected body %q, got %q", s, body))
+		}
+		return out
+	}
+	if err := json.Unmarshal(body, &actual); err != nil {
+		return append(out, fmt.Sprintf("expected a JSON body, got %q", body))
+	}
+	return append(out, compare("$", expected, actual, rules, false)...)
+}
+
+// headerMatches compares header values, Content-Type by media type and
+// parameters so that spacing and case do not matter.
+func headerMatches(name, want, got string) bool {
+	if !strings.EqualFold(name, "Content-Type") {
+		return want == got
+	}
+	wt, wp, err1 := mime.ParseMediaType(want)
+	gt, gp, err2 := mime.ParseMediaType(got)
+	if err1 != nil || err2 != nil {
+		return want == got
+	}
+	for k, v := range wp {
+		if !strings.EqualFold(gp[k], v) {
+			return false
+		}
+	}
+	return wt == gt
+}
+
+// rule is a matching rule for the body values at the paths it matches.
+type rule struct {
+	path  *regexp.Regexp
+	match string
+	regex *regexp.Regexp
+}
+
+// parseRules reads body matching rules in the version 2 layout
+// ({"$.body.name": {"match": "type"}}) or the version 3 one
+// ({"body": {"$.name": {"matchers": [{"match": "type"}]}}}).
+func parseRules(raw json.RawMessage) ([]rule, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	type matcher struct {
+		Match string `json:"match"`
+		Regex string `json:"regex"`
+	}
+	var v3 struct {
+		Body map[string]struct {
+			Matchers []matcher `json:"matchers"`
+		} `json:"body"`
+	}
+	var v2 map[string]matcher
+	byPath := map[string][]matcher{}
+	if err := json.Unmarshal(raw, &v3); err == nil && v3.Body != nil {
+		for p, m := range v3.Body {
+			byPath[p] = m.Matchers
+		}
+	} else if err := json.Unmarshal(raw, &v2); err == nil {
+		for p, m := range v2 {
+			if rest, ok := strings.CutPrefix(p, "$.body"); ok {
+				byPath["$"+rest] = []matcher{m}
+			}
+		}
+	} else {
+		return nil, fmt.Errorf("bad matchingRules: %v", err)
+	}
+	var rules []rule
+	for p, ms := range byPath {
+		re, err := pathPattern(p)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range ms {
+			r := rule{path: re, match: m.Match}
+			if m.Match == "regex" {
+				if r.regex, err = regexp.Compile(m.Regex); err != nil {
+					return nil, fmt.Errorf("bad regex matching rule at %s: %v", p, err)
+				}
+			}
+			rules = append(rules, r)
+		}
+	}
+	return rules, nil
+}
+
+// pathPattern compiles a matching rule path, where [*] matches any index
+// and .* any property.
+func pathPattern(p string) (*regexp.Regexp, error) {
+	q := regexp.QuoteMeta(p)
+	q = strings.ReplaceAll(q, `\[\*\]`, `\[\d+\]`)
+	q = strings.ReplaceAll(q, `\.\*`, `\.[^.\[]+`)
+	return regexp.Compile("^" + q + "$")
+}
+
+func rulesAt(rules []rule, path string) []rule {
+	var out []rule
+	for _, r := range rules {
+		if r.path.MatchString(path) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// compare returns how actual differs from expected at path. A type rule
+// holds for everything below its path.
+func compare(path string, expected, actual any, rules []rule, byType bool) []string {
+	for _, r := range rulesAt(rules, path) {
+		switch r.match {
+		case "type":
+			byType = true
+		case "regex":
+			s, ok := actual.(string)
+			if !ok || !r.regex.MatchString(s) {
+				return []string{fmt.Sprintf("%s: expected a value matching %s, got %s", path, r.regex, text(actual))}
+			}
+			return nil
+		}
+	}
+	switch e := expected.(type) {
+	case map[string]any:
+		a, ok := actual.(map[string]any)
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected an object, got %s", path, text(actual))}
+		}
+		keys := make([]string, 0, len(e))
+		for k := range e {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var out []string
+		for _, k := range keys {
+			v, ok := a[k]
+			if !ok {
+				out = append(out, fmt.Sprintf("%s.%s: missing", path, k))
+				continue
+			}
+			out = append(out, compare(path+"."+k, e[k], v, rules, byType)...)
+		}
+		return out
+	case []any:
+		a, ok := actual.([]any)
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected an array, got %s", path, text(actual))}
+		}
+		if byType && len(e) > 0 {
+			// under a type rule, every element matches like the first
+			var out []string
+			for i, v := range a {
+				out = append(out, compare(fmt.Sprintf("%s[%d]", path, i), e[0], v, rules, true)...)
+			}
+			return out
+		}
+		if len(a) != len(e) {
+			return []string{fmt.Sprintf("%s: expected %d elements, got %d", path, len(e), len(a))}
+		}
+		var out []string
+		for i := range e {
+			out = append(out, compare(fmt.Sprintf("%s[%d]", path, i), e[i], a[i], rules, byType)...)
+		}
+		return out
+	}
+	if byType {
+		if reflect.TypeOf(expected) != reflect.TypeOf(actual) {
+			return []string{fmt.Sprintf("%s: expected a value of the type of %s, got %s", path, text(expected), text(actual))}
+		}
+		return nil
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		return []string{fmt.Sprintf("%s: expected %s, got %s", path, text(expected), text(actual))}
+	}
+	return nil
+}
+
+func text(v any) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
@@ -0,0 +1,52 @@
+// Package testprometheus exports the Metrics of a testclient.Client to a
+// Prometheus registry, so that a test suite can scrape or gather them with
+// the tooling it already has. It lives apart from the core package so that
+// only tests using it depend on the Prometheus client.
+package testprometheus
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	testclient "github.com/raksul/go-testclient"
+)
+
+var (
+	requestsDesc = prometheus.NewDesc("testclient_requests_total",
+		"Requests served by the handler under test, by method and status code.",
+		[]string{"method", "code"}, nil)
+	durationDesc = prometheus.NewDesc("testclient_request_duration_seconds",
+		"How long the handler under test took to serve requests.",
+		nil, nil)
+)
+
+// Collector collects the metrics of a client as testclient_requests_total
+// and testclient_request_duration_seconds.
+type Collector struct {
+	client *testclient.Client
+}
+
+// NewCollector returns a collector for the metrics of c:
+//
+//	reg := prometheus.NewRegistry()
+//	reg.MustRegister(testprometheus.NewCollector(c))
+func NewCollector(c *testclient.Client) *Collector {
+	return &Collector{client: c}
+}
+
+func (col *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- requestsDesc
+	ch <- durationDesc
+}
+
+func (col *Collector) Collect(ch chan<- prometheus.Metric) {
+	m := col.client.Metrics()
+	for k, n := range m.Requests {
+		ch <- prometheus.MustNewConstMetric(requestsDesc, prometheus.CounterValue, float64(n), k.Method, strconv.Itoa(k.Status))
+	}
+	buckets := make(map[float64]uint64, len(m.Latency.Bounds))
+	for i, b := range m.Latency.Bounds {
+		buckets[b.Seconds()] = uint64(m.Latency.Counts[i])
+	}
+	ch <- prometheus.MustNewConstHistogram(durationDesc, uint64(m.Latency.Count), m.Latency.Sum.Seconds(), buckets)
+}
@@ -0,0 +1,49 @@
+package testprometheus
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	testclient "github.com/raksul/go-testclient"
+)
+
+func TestCollector(t *testing.T) {
+	c := testclient.New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	c.Get("/", nil)
+	c.Get("/", nil)
+	c.Get("/fail", nil)
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(NewCollector(c))
+	want := `
+# HELP testclient_requests_total Requests served by the handler under test, by method and status code.
+# TYPE testclient_requests_total counter
+testclient_requests_total{code="200",method="GET"} 2
+testclient_requests_total{code="500",method="GET"} 1
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(want), "testclient_requests_total"); err != nil {
+		t.Error(err)
+	}
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range families {
+		if f.GetName() != "testclient_request_duration_seconds" {
+			continue
+		}
+		h := f.GetMetric()[0].GetHistogram()
+		if h.GetSampleCount() != 3 || len(h.GetBucket()) != len(testclient.DefaultLatencyBuckets) {
+			t.Errorf("histogram = %v", h)
+		}
+		return
+	}
+	t.Error("no testclient_request_duration_seconds")
+}
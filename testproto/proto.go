@@ -0,0 +1,56 @@
+// Package testproto adds protobuf bodies to testclient. It lives apart from
+// the core package so that only tests speaking protobuf depend on
+// google.golang.org/protobuf.
+package testproto
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net/http"
+
+	testclient "github.com/raksul/go-testclient"
+	"google.golang.org/protobuf/proto"
+)
+
+// ContentType is the media type of protobuf bodies.
+const ContentType = "application/x-protobuf"
+
+// PostProto sends msg in the protobuf wire format to uri.
+func PostProto(c *testclient.Client, uri string, msg proto.Message, opts ...testclient.RequestOption) error {
+	b, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	req := c.NewRequest(http.MethodPost, uri, bytes.NewReader(b))
+	req.Header.Set("Content-Type", ContentType)
+
+	_, err = c.Request(req, opts...)
+	return err
+}
+
+// DecodeProto decodes the body of the last response into msg. The response
+// must have a protobuf media type: application/x-protobuf,
+// application/protobuf or application/vnd.google.protobuf.
+func DecodeProto(c *testclient.Client, msg proto.Message) error {
+	res := c.Response()
+	if res == nil {
+		return fmt.Errorf("testproto: no response to decode")
+	}
+	if ct := res.Header.Get("Content-Type"); !isProto(ct) {
+		return fmt.Errorf("testproto: response Content-Type %q is not protobuf", ct)
+	}
+	return proto.Unmarshal(c.BodyBytes(), msg)
+}
+
+func isProto(contentType string) bool {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch mt {
+	case ContentType, "application/protobuf", "application/vnd.google.protobuf":
+		return true
+	}
+	return false
+}
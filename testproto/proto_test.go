@@ -0,0 +1,51 @@
+package testproto
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	testclient "github.com/raksul/go-testclient"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestPostProto(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != ContentType {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		b, _ := io.ReadAll(r.Body)
+		var in wrapperspb.StringValue
+		if err := proto.Unmarshal(b, &in); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		out, _ := proto.Marshal(wrapperspb.String(strings.ToUpper(in.GetValue())))
+		w.Header().Set("Content-Type", "application/protobuf")
+		w.Write(out)
+	})
+	c := testclient.New(h)
+	if err := PostProto(c, "/echo", wrapperspb.String("hello")); err != nil {
+		t.Fatal(err)
+	}
+	var got wrapperspb.StringValue
+	if err := DecodeProto(c, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.GetValue() != "HELLO" {
+		t.Errorf("decoded %q, want HELLO", got.GetValue())
+	}
+}
+
+func TestDecodeProtoWrongType(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, "{}")
+	})
+	c := testclient.New(h)
+	c.Request(c.NewRequest(http.MethodGet, "/", nil))
+	if err := DecodeProto(c, new(wrapperspb.StringValue)); err == nil || !strings.Contains(err.Error(), "not protobuf") {
+		t.Errorf("err = %v, want a Content-Type error", err)
+	}
+}
@@ -0,0 +1,62 @@
+package testclient
+
+import (
+	"io"
+	"net/http"
+	"time"
+)
+
+// SlowBody feeds the request body to the handler chunk bytes at a time,
+// waiting delay before every chunk but the first, like a slow upload. The
+// wait ends early with the request context's error if it is canceled.
+func SlowBody(chunk int, delay time.Duration) RequestOption {
+	return func(req *http.Request) {
+		if req.Body == nil || req.Body == http.NoBody || chunk <= 0 {
+			return
+		}
+		req.Body = &slowBody{body: req.Body, chunk: chunk, delay: delay, done: req.Context().Done, err: req.Context().Err}
+	}
+}
+
+// Throttle feeds the request body to the handler at about bytesPerSecond,
+// in chunks of a hundredth of a second.
+func Throttle(bytesPerSecond int) RequestOption {
+	chunk := bytesPerSecond / 100
+	if chunk < 1 {
+		chunk = 1
+	}
+	return SlowBody(chunk, time.Duration(chunk)*time.Second/time.Duration(bytesPerSecond))
+}
+
+type slowBody struct {
+	body    io.ReadCloser
+	chunk   int
+	delay   time.Duration
+	started bool
+	done    func() <-chan struct{}
+	err     func() error
+}
+
+func (b *slowBody) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if b.started && b.delay > 0 {
+		timer := time.NewTimer(b.delay)
+		select {
+		case <-timer.C:
+		case <-b.done():
+			timer.Stop()
+			return 0, b.err()
+		}
+	}
+	b.started = true
+	if len(p) > b.chunk {
+		p = p[:b.chunk]
+	}
+	return b.body.Read(p)
+}
+
+func (b *slowBody) Close() error {
+	return b.body.Close()
+}
@@ -0,0 +1,60 @@
+package testclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSlowBody(t *testing.T) {
+	var reads []int
+	var got string
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf := make([]byte, 64)
+		for {
+			n, err := r.Body.Read(buf)
+			if n > 0 {
+				reads = append(reads, n)
+				got += string(buf[:n])
+			}
+			if err != nil {
+				return
+			}
+		}
+	})
+	c := New(h)
+	start := time.Now()
+	c.Request(c.NewRequest(http.MethodPost, "/upload", strings.NewReader("abcdefghij")), SlowBody(3, 10*time.Millisecond))
+	if got != "abcdefghij" {
+		t.Errorf("handler read %q", got)
+	}
+	if len(reads) != 4 || reads[0] != 3 || reads[3] != 1 {
+		t.Errorf("reads = %v, want chunks of 3", reads)
+	}
+	if d := time.Since(start); d < 30*time.Millisecond {
+		t.Errorf("upload took %v, want at least 30ms", d)
+	}
+}
+
+func TestThrottleCanceled(t *testing.T) {
+	var err error
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err = io.ReadAll(r.Body)
+	})
+	c := New(h)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req := c.NewRequest(http.MethodPost, "/upload", strings.NewReader(strings.Repeat("x", 1000))).WithContext(ctx)
+	start := time.Now()
+	c.Request(req, Throttle(100))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ReadAll error = %v, want the context deadline", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("canceled upload took %v", d)
+	}
+}
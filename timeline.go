@@ -0,0 +1,131 @@
+package testclient
+
+import (
+	"net/http/httptest"
+	"time"
+)
+
+// WriteEventKind tells what a handler did to its ResponseWriter.
+type WriteEventKind int
+
+const (
+	KindWrite WriteEventKind = iota
+	KindFlush
+)
+
+func (k WriteEventKind) String() string {
+	if k == KindFlush {
+		return "flush"
+	}
+	return "write"
+}
+
+// WriteEvent is a single Write or Flush call. For a write, Start and End are
+// the byte range of the body it produced; for a flush both are the number of
+// body bytes written so far.
+type WriteEvent struct {
+	Kind       WriteEventKind
+	Start, End int
+	Time       time.Time
+}
+
+// WriteTimeline is the sequence of writes and flushes during a request.
+type WriteTimeline []WriteEvent
+
+// Flushes returns the body offsets at which the handler flushed.
+func (tl WriteTimeline) Flushes() []int {
+	var offsets []int
+	for _, ev := range tl {
+		if ev.Kind == KindFlush {
+			offsets = append(offsets, ev.End)
+		}
+	}
+	return offsets
+}
+
+// Chunks splits body at the flush points, returning what the handler had
+// written before each flush followed by any unflushed remainder.
+func (tl WriteTimeline) Chunks(body []byte) [][]byte {
+	var chunks [][]byte
+	start := 0
+	for _, off := range tl.Flushes() {
+		if off > len(body) {
+			off = len(body)
+		}
+		chunks = append(chunks, body[start:off])
+		start = off
+	}
+	if start < len(body) {
+		chunks = append(chunks, body[start:])
+	}
+	return chunks
+}
+
+// timelineRecorder records the writes and flushes of the handler.
+type timelineRecorder struct {
+	*httptest.ResponseRecorder
+	timeline    WriteTimeline
+	written     int
+	wroteHeader bool
+	now         func() time.Time
+	// committed is set once the final header is written; superfluous
+	// holds the codes of later WriteHeader calls.
+	committed   bool
+	superfluous []int
+}
+
+func (r *timelineRecorder) WriteHeader(code int) {
+	r.wroteHeader = true
+	if code >= 200 {
+		if r.committed {
+			r.superfluous = append(r.superfluous, code)
+		}
+		r.committed = true
+	}
+	r.ResponseRecorder.WriteHeader(code)
+}
+
+func (r *timelineRecorder) Write(b []byte) (int, error) {
+	n, err := r.ResponseRecorder.Write(b)
+	r.add(KindWrite, n)
+	return n, err
+}
+
+func (r *timelineRecorder) WriteString(s string) (int, error) {
+	n, err := r.ResponseRecorder.WriteString(s)
+	r.add(KindWrite, n)
+	return n, err
+}
+
+func (r *timelineRecorder) Flush() {
+	r.ResponseRecorder.Flush()
+	r.add(KindFlush, 0)
+}
+
+func (r *timelineRecorder) add(kind WriteEventKind, n int) {
+	r.wroteHeader = true
+	r.committed = true
+	r.timeline = append(r.timeline, WriteEvent{
+		Kind:  kind,
+		Start: r.written,
+		End:   r.written + n,
+		Time:  r.now(),
+	})
+	r.written += n
+}
+
+// LastWriteTimeline returns the writes and flushes the handler made while
+// serving the last Request.
+func (c *Client) LastWriteTimeline() WriteTimeline {
+	return c.timeline
+}
+
+// Recorder returns a copy of the recorder the handler wrote the last
+// response to, as it was when the handler returned, for what the response
+// does not show: HeaderMap holds header changes made after the header was
+// written, and Flushed tells whether the handler flushed. It is also set
+// when the handler panicked or dropped the connection, and nil before any
+// Request. The body is shared with the response and must not be modified.
+func (c *Client) Recorder() *httptest.ResponseRecorder {
+	return c.recorder
+}
@@ -0,0 +1,92 @@
+package testclient
+
+import (
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestLastWriteTimeline(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "[")
+		w.Write([]byte(`{"a":1}`))
+		w.(http.Flusher).Flush()
+		w.Write([]byte(`,{"b":2}`))
+		w.(http.Flusher).Flush()
+		io.WriteString(w, "]")
+	})
+
+	c := New(h)
+	c.PostForm("/", nil)
+	tl := c.LastWriteTimeline()
+
+	kinds := []WriteEventKind{KindWrite, KindWrite, KindFlush, KindWrite, KindFlush, KindWrite}
+	if len(tl) != len(kinds) {
+		t.Fatalf("timeline has %d events, want %d: %+v", len(tl), len(kinds), tl)
+	}
+	for i, ev := range tl {
+		if ev.Kind != kinds[i] {
+			t.Errorf("event %d is %v, want %v", i, ev.Kind, kinds[i])
+		}
+		if i > 0 && ev.Time.Before(tl[i-1].Time) {
+			t.Errorf("event %d is earlier than event %d", i, i-1)
+		}
+	}
+	if tl[3].Start != 8 || tl[3].End != 16 {
+		t.Errorf("third write covers %d-%d, want 8-16", tl[3].Start, tl[3].End)
+	}
+
+	b, _ := io.ReadAll(c.Response().Body)
+	want := []string{`[{"a":1}`, `,{"b":2}`, `]`}
+	chunks := tl.Chunks(b)
+	if len(chunks) != len(want) {
+		t.Fatalf("Chunks = %q, want %q", chunks, want)
+	}
+	for i := range want {
+		if string(chunks[i]) != want[i] {
+			t.Errorf("chunk %d = %q, want %q", i, chunks[i], want[i])
+		}
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Before", "1")
+		io.WriteString(w, "partial")
+		// too late to reach the client
+		w.Header().Set("X-After", "1")
+		if r.URL.Path == "/flush" {
+			w.(http.Flusher).Flush()
+		}
+		if r.URL.Path == "/panic" {
+			panic("boom")
+		}
+	})
+	c := New(h)
+	if c.Recorder() != nil {
+		t.Error("Recorder() before any request is not nil")
+	}
+	res, _ := c.Get("/", nil)
+	rec := c.Recorder()
+	if res.Header.Get("X-After") != "" || rec.HeaderMap.Get("X-After") != "1" {
+		t.Errorf("response header %v, recorder header %v", res.Header, rec.HeaderMap)
+	}
+	if rec.Flushed || rec.Code != http.StatusOK || rec.Body.String() != "partial" {
+		t.Errorf("recorder = %d %v %q", rec.Code, rec.Flushed, rec.Body)
+	}
+
+	c.Get("/flush", nil)
+	if !c.Recorder().Flushed {
+		t.Error("Flushed not set after the handler flushed")
+	}
+	if _, err := c.Get("/panic", nil); err == nil {
+		t.Fatal("no error for a panic")
+	}
+	if got := c.Recorder().Body.String(); got != "partial" {
+		t.Errorf("recorder body after a panic = %q", got)
+	}
+	// the first recorder is not overwritten by later requests
+	if rec.HeaderMap.Get("X-Before") != "1" || rec.Body.String() != "partial" {
+		t.Errorf("earlier recorder changed: %v %q", rec.HeaderMap, rec.Body)
+	}
+}
@@ -0,0 +1,49 @@
+package testclient
+
+import (
+	"sort"
+	"time"
+)
+
+// TimingStats summarizes how long the handler took to serve requests.
+type TimingStats struct {
+	Count int
+	Mean  time.Duration
+	P95   time.Duration
+	Max   time.Duration
+}
+
+// LastDuration returns how long the handler took to serve the last request,
+// or the last attempt of it with WithRetry.
+func (c *Client) LastDuration() time.Duration {
+	if len(c.durations) == 0 {
+		return 0
+	}
+	return c.durations[len(c.durations)-1]
+}
+
+// Durations returns the duration of every request served by Request, in
+// order, counting each retry attempt and redirect hop on its own.
+func (c *Client) Durations() []time.Duration {
+	return append([]time.Duration(nil), c.durations...)
+}
+
+// Timings summarizes Durations.
+func (c *Client) Timings() TimingStats {
+	n := len(c.durations)
+	if n == 0 {
+		return TimingStats{}
+	}
+	sorted := c.Durations()
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var sum time.Duration
+	for _, d := range sorted {
+		sum += d
+	}
+	return TimingStats{
+		Count: n,
+		Mean:  sum / time.Duration(n),
+		P95:   percentile(sorted, 0.95),
+		Max:   sorted[n-1],
+	}
+}
@@ -0,0 +1,44 @@
+package testclient
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestTimings(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ms, _ := strconv.Atoi(r.URL.Query().Get("ms"))
+		clock.Advance(time.Duration(ms) * time.Millisecond)
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/?ms=7", http.StatusFound)
+		}
+	})
+	c := New(h, WithClock(clock))
+	if got := c.Timings(); got != (TimingStats{}) {
+		t.Errorf("Timings before any request = %+v", got)
+	}
+	for i := 1; i <= 19; i++ {
+		c.Request(c.NewRequest(http.MethodGet, "/?ms="+strconv.Itoa(i), nil))
+	}
+	c.Request(c.NewRequest(http.MethodGet, "/redirect?ms=100", nil))
+	if got := c.LastDuration(); got != 100*time.Millisecond {
+		t.Errorf("LastDuration = %v, want 100ms", got)
+	}
+	if err := c.FollowRedirect(); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.LastDuration(); got != 7*time.Millisecond {
+		t.Errorf("LastDuration of the redirect hop = %v, want 7ms", got)
+	}
+
+	want := TimingStats{Count: 21, Mean: 297 * time.Millisecond / 21, P95: 19 * time.Millisecond, Max: 100 * time.Millisecond}
+	if got := c.Timings(); got != want {
+		t.Errorf("Timings = %+v, want %+v", got, want)
+	}
+	if got := len(c.Durations()); got != 21 {
+		t.Errorf("%d durations, want 21", got)
+	}
+}
@@ -0,0 +1,110 @@
+package testclient
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// traceContext is shared by the client and its Burst and Load sessions.
+type traceContext struct {
+	once    sync.Once
+	traceID string
+	state   string
+}
+
+// WithTraceContext sends W3C Trace Context headers on every request: a
+// traceparent carrying traceID, as 32 lowercase hex digits, with a fresh
+// parent ID per request, and state as tracestate unless it is empty. An
+// empty traceID generates one, so that all requests of the client belong
+// to one trace. Requests carrying a traceparent already keep their headers.
+func WithTraceContext(traceID, state string) Option {
+	return func(c *Client) {
+		c.trace = &traceContext{traceID: strings.ToLower(traceID), state: state}
+	}
+}
+
+// TraceID returns the trace ID sent under WithTraceContext, or "".
+func (c *Client) TraceID() string {
+	if c.trace == nil {
+		return ""
+	}
+	// generated on first use, once WithRandSeed has taken effect
+	c.trace.once.Do(func() {
+		if c.trace.traceID == "" {
+			c.trace.traceID = randomHex(c.random(), 16)
+		}
+	})
+	return c.trace.traceID
+}
+
+func (c *Client) applyTraceContext(req *http.Request) error {
+	if c.trace == nil || req.Header.Get("traceparent") != "" {
+		return nil
+	}
+	traceID := c.TraceID()
+	if !validTraceID(traceID, 32) {
+		return fmt.Errorf("testclient: invalid trace ID %q", traceID)
+	}
+	req.Header.Set("traceparent", "00-"+traceID+"-"+randomHex(c.random(), 8)+"-01")
+	if c.trace.state != "" {
+		req.Header.Set("tracestate", c.trace.state)
+	}
+	return nil
+}
+
+// TraceParent is a parsed traceparent header.
+type TraceParent struct {
+	TraceID  string
+	ParentID string
+	Sampled  bool
+}
+
+// ParseTraceParent parses a version 00 traceparent header value.
+func ParseTraceParent(v string) (TraceParent, error) {
+	parts := strings.Split(strings.TrimSpace(v), "-")
+	if len(parts) != 4 || parts[0] != "00" || !validTraceID(parts[1], 32) || !validTraceID(parts[2], 16) || len(parts[3]) != 2 {
+		return TraceParent{}, fmt.Errorf("testclient: malformed traceparent %q", v)
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return TraceParent{}, fmt.Errorf("testclient: malformed traceparent %q", v)
+	}
+	return TraceParent{TraceID: parts[1], ParentID: parts[2], Sampled: flags[0]&1 == 1}, nil
+}
+
+// ExpectTraceParent asserts that header, such as that of a request the
+// handler made downstream, carries a traceparent of the trace traceID
+// other than parent, the one the handler received: a handler propagating
+// the trace sends its own span as the parent.
+func ExpectTraceParent(t testing.TB, header http.Header, traceID, parent string) TraceParent {
+	t.Helper()
+	tp, err := ParseTraceParent(header.Get("traceparent"))
+	if err != nil {
+		t.Errorf("expected a traceparent of trace %s, got %q", traceID, header.Get("traceparent"))
+		return tp
+	}
+	if tp.TraceID != traceID {
+		t.Errorf("expected trace %s, got %s", traceID, tp.TraceID)
+	}
+	if tp.ParentID == parent {
+		t.Errorf("expected a parent other than %s, the received one", parent)
+	}
+	return tp
+}
+
+// validTraceID reports whether id is n lowercase hex digits, not all zero.
+func validTraceID(id string, n int) bool {
+	if len(id) != n || strings.Trim(id, "0") == "" {
+		return false
+	}
+	for _, r := range id {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,106 @@
+package testclient
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestWithTraceContext(t *testing.T) {
+	var got []http.Header
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Clone())
+	})
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	c := New(h, WithTraceContext(strings.ToUpper(traceID), "vendor=1"))
+	c.Get("/a", nil)
+	c.Get("/b", nil)
+	c.Get("/c", nil, Header("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"))
+
+	if c.TraceID() != traceID {
+		t.Errorf("TraceID() = %s", c.TraceID())
+	}
+	first, err := ParseTraceParent(got[0].Get("traceparent"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := ParseTraceParent(got[1].Get("traceparent"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.TraceID != traceID || second.TraceID != traceID || !first.Sampled {
+		t.Errorf("traceparents = %+v, %+v", first, second)
+	}
+	if first.ParentID == second.ParentID {
+		t.Error("parent ID reused across requests")
+	}
+	if got[0].Get("tracestate") != "vendor=1" {
+		t.Errorf("tracestate = %q", got[0].Get("tracestate"))
+	}
+	if tp := got[2].Get("traceparent"); !strings.Contains(tp, "0af7651916cd43dd8448eb211c80319c") {
+		t.Errorf("explicit traceparent replaced by %s", tp)
+	}
+
+	generated := New(h, WithTraceContext("", ""))
+	if !validTraceID(generated.TraceID(), 32) {
+		t.Errorf("generated trace ID %q", generated.TraceID())
+	}
+	if _, err := New(h, WithTraceContext("xyz", "")).Get("/", nil); err == nil {
+		t.Error("no error for an invalid trace ID")
+	}
+}
+
+func TestParseTraceParent(t *testing.T) {
+	for _, v := range []string{
+		"",
+		"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-zz",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+	} {
+		if _, err := ParseTraceParent(v); err == nil {
+			t.Errorf("ParseTraceParent(%q) succeeded", v)
+		}
+	}
+}
+
+func TestExpectTraceParent(t *testing.T) {
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	header := http.Header{"Traceparent": {"00-" + traceID + "-00f067aa0ba902b7-01"}}
+	ft := &fakeT{}
+	ExpectTraceParent(ft, header, traceID, "b7ad6b7169203331")
+	if len(ft.failures) != 0 {
+		t.Errorf("unexpected failures: %v", ft.failures)
+	}
+	for _, tt := range []struct {
+		header          http.Header
+		traceID, parent string
+	}{
+		{http.Header{}, traceID, ""},
+		{header, "0af7651916cd43dd8448eb211c80319c", ""},
+		{header, traceID, "00f067aa0ba902b7"},
+	} {
+		ft := &fakeT{}
+		ExpectTraceParent(ft, tt.header, tt.traceID, tt.parent)
+		if len(ft.failures) == 0 {
+			t.Errorf("no failure for %v, %s, %s", tt.header, tt.traceID, tt.parent)
+		}
+	}
+}
+
+func TestTraceContextBurst(t *testing.T) {
+	var mu sync.Mutex
+	traces := map[string]bool{}
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tp, _ := ParseTraceParent(r.Header.Get("traceparent"))
+		mu.Lock()
+		traces[tp.TraceID] = true
+		mu.Unlock()
+	})
+	c := New(h, WithTraceContext("", ""))
+	c.Burst(50, func(int) *http.Request { return c.NewRequest(http.MethodGet, "/", nil) })
+	if len(traces) != 1 || !traces[c.TraceID()] {
+		t.Errorf("burst traces = %v, want only %s", traces, c.TraceID())
+	}
+}
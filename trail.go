@@ -0,0 +1,83 @@
+package testclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// maxTrail limits how many earlier requests a failure message lists.
+const maxTrail = 10
+
+// step is a request of the session and what came of it, as failure
+// messages render it.
+type step struct {
+	method, uri string
+	status      int
+	err         error
+}
+
+func (s step) String() string {
+	if s.err != nil {
+		return fmt.Sprintf("%s %s → error: %v", s.method, s.uri, s.err)
+	}
+	return fmt.Sprintf("%s %s → %d", s.method, s.uri, s.status)
+}
+
+type trailKey struct{}
+
+// applyTrail stores in the context of req the requests the client sent
+// before it, for failure messages about its response.
+func (c *Client) applyTrail(req *http.Request) {
+	if len(c.trail) == 0 {
+		return
+	}
+	// the full slice expression keeps later appends from showing through
+	trail := c.trail[:len(c.trail):len(c.trail)]
+	*req = *req.WithContext(context.WithValue(req.Context(), trailKey{}, trail))
+}
+
+// addStep records req, sent with Request, in the trail.
+func (c *Client) addStep(req *http.Request, res *http.Response, err error) {
+	s := step{method: req.Method, uri: req.URL.RequestURI(), err: err}
+	if res != nil {
+		s.status = res.StatusCode
+	}
+	c.trail = append(c.trail, s)
+}
+
+// trailNote returns the trail of res on a line of its own, for the end of
+// a one-line failure message.
+func trailNote(res *http.Response) string {
+	if s := trailOf(res); s != "" {
+		return "\n" + strings.TrimSuffix(s, "\n")
+	}
+	return ""
+}
+
+// trailOf renders the requests sent before res in its session, or "" if
+// there were none.
+func trailOf(res *http.Response) string {
+	if res == nil || res.Request == nil {
+		return ""
+	}
+	trail, _ := res.Request.Context().Value(trailKey{}).([]step)
+	if len(trail) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	switch {
+	case len(trail) > maxTrail:
+		fmt.Fprintf(&b, "after %d earlier requests, the last %d:\n", len(trail), maxTrail)
+		trail = trail[len(trail)-maxTrail:]
+	case len(trail) == 1:
+		b.WriteString("after 1 earlier request:\n")
+	default:
+		fmt.Fprintf(&b, "after %d earlier requests:\n", len(trail))
+	}
+	for _, s := range trail {
+		fmt.Fprintf(&b, "  %s\n", s)
+	}
+	return b.String()
+}
@@ -0,0 +1,62 @@
+package testclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestFailureTrail(t *testing.T) {
+	s := NewStub()
+	s.On("POST", "/login").Reply(http.StatusFound, "")
+	s.On("GET", "/cart").ReplyJSON(http.StatusOK, map[string]int{"items": 2})
+	s.On("POST", "/checkout").Reply(http.StatusConflict, "stale cart")
+	c := New(s)
+
+	res, _ := c.Request(c.NewRequest(http.MethodGet, "/cart", nil))
+	ft := &fakeT{}
+	ExpectHeader(ft, res, "Cache-Control", "no-store")
+	if len(ft.failures) != 1 || strings.Contains(ft.failures[0], "earlier") {
+		t.Errorf("first request reported a trail: %q", ft.failures)
+	}
+
+	c.PostForm("/login", url.Values{"user": {"alice"}})
+	c.Request(c.NewRequest(http.MethodGet, "/cart?page=1", nil))
+	res, _ = c.Request(c.NewRequest(http.MethodPost, "/checkout", nil))
+	status, header := &fakeT{}, &fakeT{}
+	ExpectStatus(status, res, http.StatusOK)
+	ExpectHeader(header, res, "Cache-Control", "no-store")
+	want := "after 3 earlier requests:\n  GET /cart → 200\n  POST /login → 302\n  GET /cart?page=1 → 200"
+	for _, ft := range []*fakeT{status, header} {
+		if len(ft.failures) != 1 || !strings.Contains(ft.failures[0], want) {
+			t.Errorf("failure does not show the trail: %q", ft.failures)
+		}
+	}
+	if len(status.failures) == 1 && !strings.HasPrefix(status.failures[0], "expected status 200 OK, got 409 Conflict\nafter 3") {
+		t.Errorf("trail not after the status line:\n%s", status.failures[0])
+	}
+}
+
+func TestFailureTrailLimit(t *testing.T) {
+	c := New(http.NotFoundHandler(), WithTraceContext("zz", ""))
+	c.Get("/broken", nil) // fails with an invalid trace ID
+	c.SetHeader("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	for i := 0; i < 12; i++ {
+		c.Get(fmt.Sprintf("/page/%d", i), nil)
+	}
+	res, _ := c.Get("/last", nil)
+	note := trailOf(res)
+	if !strings.HasPrefix(note, "after 13 earlier requests, the last 10:\n  GET /page/2 → 404\n") || !strings.HasSuffix(note, "  GET /page/11 → 404\n") {
+		t.Errorf("trail = %q", note)
+	}
+
+	c = New(http.NotFoundHandler(), WithTraceContext("zz", ""))
+	c.Get("/broken", nil)
+	c.SetHeader("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	res, _ = c.Get("/last", nil)
+	if note := trailOf(res); note != "after 1 earlier request:\n  GET /broken → error: testclient: invalid trace ID \"zz\"\n" {
+		t.Errorf("trail = %q", note)
+	}
+}
@@ -0,0 +1,73 @@
+package testclient
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// declaredTrailers returns the trailer keys announced in the Trailer header.
+func declaredTrailers(header http.Header) []string {
+	var keys []string
+	for _, v := range header["Trailer"] {
+		for _, k := range strings.Split(v, ",") {
+			k = http.CanonicalHeaderKey(strings.TrimSpace(k))
+			switch k {
+			case "", "Transfer-Encoding", "Trailer", "Content-Length":
+				continue
+			}
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// finalTrailers collects the trailer values from the handler's header once
+// it has returned, in the same way net/http does: declared keys plus keys
+// carrying the http.TrailerPrefix.
+func finalTrailers(header http.Header, declared []string) http.Header {
+	var t http.Header
+	for k, vv := range header {
+		if kk, found := strings.CutPrefix(k, http.TrailerPrefix); found {
+			if t == nil {
+				t = http.Header{}
+			}
+			t[kk] = append([]string(nil), vv...)
+		}
+	}
+	for _, k := range declared {
+		if t == nil {
+			t = http.Header{}
+		}
+		if vv, ok := header[k]; ok {
+			t[k] = append([]string(nil), vv...)
+		} else if _, ok := t[k]; !ok {
+			t[k] = nil
+		}
+	}
+	return t
+}
+
+// stripTrailerHeaders removes the trailer bookkeeping from a response header,
+// as http.Transport does on the client side.
+func stripTrailerHeaders(header http.Header) {
+	header.Del("Trailer")
+	for k := range header {
+		if strings.HasPrefix(k, http.TrailerPrefix) {
+			delete(header, k)
+		}
+	}
+}
+
+// ExpectTrailer fails the test unless res carries the trailer name with the
+// value want. For a streamed response the body must have been read to EOF.
+func ExpectTrailer(t testing.TB, res *http.Response, name, want string) {
+	t.Helper()
+	vv, ok := res.Trailer[http.CanonicalHeaderKey(name)]
+	if !ok || len(vv) == 0 {
+		t.Fatalf("expected trailer %s: %q, got none (trailers: %v)", name, want, res.Trailer)
+	}
+	if vv[0] != want {
+		t.Fatalf("expected trailer %s: %q, got %q", name, want, vv[0])
+	}
+}
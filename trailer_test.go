@@ -0,0 +1,51 @@
+package testclient
+
+import (
+	"io"
+	"net/http"
+	"testing"
+)
+
+func trailerHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+		w.Header().Set("Content-Type", "application/grpc")
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, "payload")
+		w.Header().Set("Grpc-Status", "0")
+		w.Header().Set(http.TrailerPrefix+"Checksum", "abc")
+	})
+}
+
+func TestTrailers(t *testing.T) {
+	c := New(trailerHandler())
+	c.PostForm("/", nil)
+	res := c.Response()
+
+	if _, ok := res.Header["Trailer"]; ok {
+		t.Errorf("Trailer left in the response header: %v", res.Header)
+	}
+	ExpectTrailer(t, res, "Grpc-Status", "0")
+	ExpectTrailer(t, res, "checksum", "abc")
+	if vv, ok := res.Trailer["Grpc-Message"]; !ok || vv != nil {
+		t.Errorf("declared but unset trailer = %v, %v; want present and empty", vv, ok)
+	}
+}
+
+func TestStreamTrailers(t *testing.T) {
+	c := New(trailerHandler())
+	res, err := c.Stream(c.NewRequest(http.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	if v := res.Trailer.Get("Grpc-Status"); v != "" {
+		t.Errorf("trailer value %q available before the body was read", v)
+	}
+	if _, err := io.ReadAll(res.Body); err != nil {
+		t.Fatal(err)
+	}
+	ExpectTrailer(t, res, "Grpc-Status", "0")
+	ExpectTrailer(t, res, "Checksum", "abc")
+}
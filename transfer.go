@@ -0,0 +1,143 @@
+package testclient
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+)
+
+// Transfer counts the bytes of a request served by the client and of its
+// response, about as HTTP/1.1 would put them on the wire: header sizes include
+// the request or status line, and the response body is counted as the
+// handler wrote it, compressed if it was.
+type Transfer struct {
+	Method string
+	URL    string
+	// ContentType is the media type of the response, if any.
+	ContentType         string
+	RequestHeaderBytes  int64
+	RequestBodyBytes    int64
+	ResponseHeaderBytes int64
+	ResponseBodyBytes   int64
+}
+
+// Sent returns the bytes of the request.
+func (t Transfer) Sent() int64 { return t.RequestHeaderBytes + t.RequestBodyBytes }
+
+// Received returns the bytes of the response.
+func (t Transfer) Received() int64 { return t.ResponseHeaderBytes + t.ResponseBodyBytes }
+
+// Transfers returns the transfer of every request served by Request, in
+// order, counting each retry attempt and redirect hop on its own.
+func (c *Client) Transfers() []Transfer {
+	return append([]Transfer(nil), c.transfers...)
+}
+
+// LastTransfer returns the transfer of the last request served.
+func (c *Client) LastTransfer() Transfer {
+	if len(c.transfers) == 0 {
+		return Transfer{}
+	}
+	return c.transfers[len(c.transfers)-1]
+}
+
+// BytesSent returns the bytes of all requests of the session.
+func (c *Client) BytesSent() int64 {
+	var n int64
+	for _, t := range c.transfers {
+		n += t.Sent()
+	}
+	return n
+}
+
+// BytesReceived returns the bytes of all responses of the session.
+func (c *Client) BytesReceived() int64 {
+	var n int64
+	for _, t := range c.transfers {
+		n += t.Received()
+	}
+	return n
+}
+
+// ResetTransfers starts counting the session over, say after the login
+// steps of a test.
+func (c *Client) ResetTransfers() {
+	c.transfers = nil
+}
+
+// requestTransfer counts the bytes of req, whose body is taken from its
+// Content-Length or, for a body of unknown length, is the read bytes the
+// handler consumed.
+func requestTransfer(req *http.Request, read int64) Transfer {
+	line := fmt.Sprintf("%s %s HTTP/1.1\r\nHost: %s\r\n", req.Method, req.URL.RequestURI(), req.Host)
+	t := Transfer{
+		Method:             req.Method,
+		URL:                requestURL(req).String(),
+		RequestHeaderBytes: int64(len(line)) + headerBytes(req.Header) + 2,
+		RequestBodyBytes:   read,
+	}
+	if req.ContentLength > 0 {
+		t.RequestBodyBytes = req.ContentLength
+	}
+	return t
+}
+
+// addResponse counts res, whose body as written is body bytes.
+func (t *Transfer) addResponse(res *http.Response, body int) {
+	line := fmt.Sprintf("HTTP/1.1 %03d %s\r\n", res.StatusCode, http.StatusText(res.StatusCode))
+	t.ContentType = res.Header.Get("Content-Type")
+	t.ResponseHeaderBytes = int64(len(line)) + headerBytes(res.Header) + 2
+	t.ResponseBodyBytes = int64(body)
+}
+
+// countingBody counts the bytes read from a request body.
+type countingBody struct {
+	io.ReadCloser
+	n *int64
+}
+
+func (b countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	*b.n += int64(n)
+	return n, err
+}
+
+// headerBytes returns the size of the lines of h, "Name: value\r\n" each.
+func headerBytes(h http.Header) int64 {
+	var n int64
+	for k, vv := range h {
+		for _, v := range vv {
+			n += int64(len(k) + len(": ") + len(v) + len("\r\n"))
+		}
+	}
+	return n
+}
+
+// ExpectMaxBytesReceived fails the test if the responses of the session
+// of c add up to more than max bytes, naming the largest one.
+func ExpectMaxBytesReceived(t testing.TB, c *Client, max int64) {
+	t.Helper()
+	if n := c.BytesReceived(); n > max {
+		t.Errorf("expected the session to receive at most %d bytes, got %d over %d requests; the largest is %s", max, n, len(c.transfers), largestTransfer(c.transfers, Transfer.Received))
+	}
+}
+
+// ExpectMaxBytesSent fails the test if the requests of the session of c
+// add up to more than max bytes, naming the largest one.
+func ExpectMaxBytesSent(t testing.TB, c *Client, max int64) {
+	t.Helper()
+	if n := c.BytesSent(); n > max {
+		t.Errorf("expected the session to send at most %d bytes, got %d over %d requests; the largest is %s", max, n, len(c.transfers), largestTransfer(c.transfers, Transfer.Sent))
+	}
+}
+
+func largestTransfer(transfers []Transfer, size func(Transfer) int64) string {
+	var largest Transfer
+	for _, t := range transfers {
+		if size(t) > size(largest) {
+			largest = t
+		}
+	}
+	return fmt.Sprintf("%s %s with %d bytes", largest.Method, largest.URL, size(largest))
+}
@@ -0,0 +1,98 @@
+package testclient
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestTransfers(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		switch r.URL.Path {
+		case "/dashboard":
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"widgets":[1,2,3]}`)
+		case "/report":
+			w.Header().Set("Content-Encoding", "gzip")
+			gz := gzip.NewWriter(w)
+			io.WriteString(gz, strings.Repeat("row\n", 1000))
+			gz.Close()
+		}
+	})
+	c := New(h, WithDecompression())
+	req := c.NewRequest(http.MethodPost, "/upload", strings.NewReader("hello"))
+	req.Header.Set("X-A", "1")
+	c.Request(req)
+	tr := c.LastTransfer()
+	// "POST /upload HTTP/1.1\r\n" "Host: example.com\r\n" "X-A: 1\r\n" "Accept-Encoding: gzip, br\r\n" "\r\n"
+	wantHeader := int64(len("POST /upload HTTP/1.1\r\nHost: example.com\r\nX-A: 1\r\n\r\n") + len("Accept-Encoding: \r\n") + len(req.Header.Get("Accept-Encoding")))
+	if tr.Method != http.MethodPost || tr.URL != "http://example.com/upload" || tr.RequestBodyBytes != 5 || tr.RequestHeaderBytes != wantHeader {
+		t.Errorf("transfer = %+v, want request header bytes %d", tr, wantHeader)
+	}
+	if tr.ResponseHeaderBytes != int64(len("HTTP/1.1 200 OK\r\n\r\n")) || tr.ResponseBodyBytes != 0 {
+		t.Errorf("response bytes = %d + %d", tr.ResponseHeaderBytes, tr.ResponseBodyBytes)
+	}
+
+	c.Get("/dashboard", nil)
+	if tr := c.LastTransfer(); tr.ContentType != "application/json" || tr.ResponseBodyBytes != int64(len(`{"widgets":[1,2,3]}`)) {
+		t.Errorf("dashboard transfer = %+v", tr)
+	}
+	res, _ := c.Get("/report", nil)
+	if tr := c.LastTransfer(); tr.ResponseBodyBytes >= 4000 || len(bufferedBody(res).data) != 4000 {
+		t.Errorf("compressed body counted as %d bytes", tr.ResponseBodyBytes)
+	}
+
+	var sent, received int64
+	for _, tr := range c.Transfers() {
+		sent += tr.Sent()
+		received += tr.Received()
+	}
+	if len(c.Transfers()) != 3 || c.BytesSent() != sent || c.BytesReceived() != received {
+		t.Errorf("session totals %d / %d, want %d / %d", c.BytesSent(), c.BytesReceived(), sent, received)
+	}
+	c.ResetTransfers()
+	if c.BytesReceived() != 0 || c.LastTransfer() != (Transfer{}) {
+		t.Error("transfers kept after ResetTransfers")
+	}
+}
+
+func TestTransferUnknownLength(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { io.CopyN(io.Discard, r.Body, 3) })
+	c := New(h)
+	req := c.NewRequest(http.MethodPut, "/", io.MultiReader(bytes.NewReader([]byte("abcdef"))))
+	req.ContentLength = -1
+	c.Request(req)
+	if got := c.LastTransfer().RequestBodyBytes; got != 3 {
+		t.Errorf("request body bytes = %d, want the 3 read", got)
+	}
+}
+
+func TestExpectMaxBytes(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/big" {
+			io.WriteString(w, strings.Repeat("x", 500))
+		}
+	})
+	c := New(h)
+	c.Get("/small", nil)
+	c.Get("/big", nil)
+	ft := &fakeT{TB: t}
+	ExpectMaxBytesReceived(ft, c, 100_000)
+	ExpectMaxBytesSent(ft, c, 1000)
+	if len(ft.failures) != 0 {
+		t.Fatalf("failures = %q", ft.failures)
+	}
+	ExpectMaxBytesReceived(ft, c, 500)
+	ExpectMaxBytesSent(ft, c, 10)
+	if len(ft.failures) != 2 ||
+		!strings.Contains(ft.failures[0], "expected the session to receive at most 500 bytes, got ") ||
+		!strings.HasSuffix(ft.failures[0], "over 2 requests; the largest is GET http://example.com/big with "+strconv.FormatInt(c.Transfers()[1].Received(), 10)+" bytes") ||
+		!strings.HasPrefix(ft.failures[1], "expected the session to send at most 10 bytes") {
+		t.Errorf("failures = %q", ft.failures)
+	}
+}
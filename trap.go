@@ -0,0 +1,84 @@
+package testclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Trap is an http.RoundTripper to install as the transport of the
+// http.Client a handler calls third parties with, such as webhook
+// receivers. It records every outbound call and answers it from stub
+// routes, so no call leaves the process.
+type Trap struct {
+	stub     *Stub
+	captured *Captured
+}
+
+// TrapOutbound returns a trap with no routes: calls are answered 404, or
+// fail the test under the Strict option, until routes are added with On.
+func TrapOutbound(opts ...StubOption) *Trap {
+	stub := NewStub(opts...)
+	return &Trap{stub: stub, captured: Capture(stub)}
+}
+
+// On registers a route answering outbound calls to method and path on any
+// host, as Stub.On does.
+func (t *Trap) On(method, path string) *Route {
+	return t.stub.On(method, path)
+}
+
+// Client returns an http.Client using the trap as its transport.
+func (t *Trap) Client() *http.Client {
+	return &http.Client{Transport: t}
+}
+
+// RoundTrip records req and serves it from the routes of the trap.
+func (t *Trap) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+	rec := httptest.NewRecorder()
+	in := req.Clone(req.Context())
+	if in.Body == nil {
+		in.Body = http.NoBody
+	}
+	in.RequestURI = req.URL.RequestURI()
+	t.captured.ServeHTTP(rec, in)
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	res := rec.Result()
+	res.Request = req
+	return res, nil
+}
+
+// Calls returns the recorded outbound calls in order, with their full URL
+// and a fresh body reader each.
+func (t *Trap) Calls() []*http.Request {
+	return t.captured.Requests()
+}
+
+// Reset forgets the recorded calls; the routes stay.
+func (t *Trap) Reset() {
+	t.captured.Reset()
+}
+
+// ExpectOutbound fails the test unless the handler made a method call to
+// target, a full URL or just a path, and returns the last such call.
+func ExpectOutbound(t testing.TB, trap *Trap, method, target string) *http.Request {
+	t.Helper()
+	calls := trap.Calls()
+	for i := len(calls) - 1; i >= 0; i-- {
+		if c := calls[i]; c.Method == method && (c.URL.String() == target || c.URL.Path == target) {
+			return c
+		}
+	}
+	made := make([]string, len(calls))
+	for i, c := range calls {
+		made[i] = c.Method + " " + c.URL.String()
+	}
+	t.Fatalf("expected an outbound call %s %s, got [%s]", method, target, strings.Join(made, ", "))
+	return nil
+}
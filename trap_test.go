@@ -0,0 +1,84 @@
+package testclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// ordersHandler posts an order.created webhook with client after taking
+// an order, and reports the receiver's status.
+func ordersHandler(client *http.Client) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		res, err := client.Post("https://hooks.example.com/events", "application/json", strings.NewReader(`{"type": "order.created"}`))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer res.Body.Close()
+		ack, _ := io.ReadAll(res.Body)
+		w.WriteHeader(http.StatusAccepted)
+		w.Write(ack)
+	})
+}
+
+func TestTrapOutbound(t *testing.T) {
+	trap := TrapOutbound()
+	hook := trap.On("POST", "/events").Reply(http.StatusOK, "ack")
+	c := New(ordersHandler(trap.Client()))
+	if err := c.PostJSON("/orders", map[string]int{"sku": 1}); err != nil {
+		t.Fatal(err)
+	}
+	ExpectStatus(t, c.Response(), http.StatusAccepted)
+	if got := string(c.BodyBytes()); got != "ack" {
+		t.Errorf("handler relayed %q", got)
+	}
+	if hook.Hits() != 1 {
+		t.Errorf("webhook route hit %d times", hook.Hits())
+	}
+
+	call := ExpectOutbound(t, trap, "POST", "https://hooks.example.com/events")
+	ExpectOutbound(t, trap, "POST", "/events")
+	if b, _ := io.ReadAll(call.Body); string(b) != `{"type": "order.created"}` || call.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("call: %s %q", call.Header.Get("Content-Type"), b)
+	}
+
+	ft := &fakeT{}
+	ExpectOutbound(ft, trap, "DELETE", "/events")
+	if len(ft.failures) != 1 || !strings.Contains(ft.failures[0], "expected an outbound call DELETE /events, got [POST https://hooks.example.com/events]") {
+		t.Errorf("failures = %q", ft.failures)
+	}
+
+	trap.Reset()
+	if len(trap.Calls()) != 0 {
+		t.Errorf("%d calls after Reset", len(trap.Calls()))
+	}
+}
+
+func TestTrapOutboundUnmatched(t *testing.T) {
+	trap := TrapOutbound()
+	res, err := trap.Client().Get("https://api.example.com/rates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusNotFound || res.Request.URL.Host != "api.example.com" {
+		t.Errorf("got %d for %s", res.StatusCode, res.Request.URL)
+	}
+
+	strict := &fakeT{}
+	trap = TrapOutbound(Strict(strict))
+	trap.Client().Get("https://api.example.com/rates")
+	if len(strict.failures) != 1 || !strings.Contains(strict.failures[0], "unexpected request GET /rates") {
+		t.Errorf("failures = %q", strict.failures)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.example.com/rates", nil)
+	if _, err := trap.Client().Do(req); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package testclient
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// UploadFile sends the file at path as the body of a method request to
+// uri, streamed from disk rather than read into memory, with the
+// Content-Length of the file and a Content-Type from its extension. The
+// body is buffered after all when retries, history or shadow mode are on.
+func (c *Client) UploadFile(method, uri, path string, opts ...RequestOption) (*http.Response, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	req := c.NewRequest(method, uri, f)
+	req.ContentLength = fi.Size()
+	ct := mime.TypeByExtension(filepath.Ext(path))
+	if ct == "" {
+		ct = "application/octet-stream"
+	}
+	req.Header.Set("Content-Type", ct)
+
+	return c.Request(req, opts...)
+}
+
+// Progress calls fn as the handler reads the request body, with the bytes
+// read so far and the length of the body, or -1 if it is unknown. The
+// last call tells how far a handler that stopped early got.
+func Progress(fn func(read, total int64)) RequestOption {
+	return func(req *http.Request) {
+		if req.Body == nil || req.Body == http.NoBody {
+			return
+		}
+		req.Body = &progressBody{body: req.Body, total: req.ContentLength, fn: fn}
+	}
+}
+
+type progressBody struct {
+	body  io.ReadCloser
+	read  int64
+	total int64
+	fn    func(read, total int64)
+}
+
+func (b *progressBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	if n > 0 {
+		b.read += int64(n)
+		b.fn(b.read, b.total)
+	}
+	return n, err
+}
+
+func (b *progressBody) Close() error {
+	return b.body.Close()
+}
+
+// BodyFailsAfter makes the request body fail with err once the handler has
+// read n bytes of it, like an upload whose connection breaks, to exercise
+// how the handler copes with a partial body.
+func BodyFailsAfter(n int64, err error) RequestOption {
+	return func(req *http.Request) {
+		if req.Body == nil {
+			req.Body = http.NoBody
+		}
+		req.Body = &failingBody{body: req.Body, left: n, err: err}
+	}
+}
+
+type failingBody struct {
+	body io.ReadCloser
+	left int64
+	err  error
+}
+
+func (b *failingBody) Read(p []byte) (int, error) {
+	if b.left <= 0 {
+		return 0, b.err
+	}
+	if int64(len(p)) > b.left {
+		p = p[:b.left]
+	}
+	// a body shorter than n ends normally
+	n, err := b.body.Read(p)
+	b.left -= int64(n)
+	return n, err
+}
+
+func (b *failingBody) Close() error {
+	return b.body.Close()
+}
@@ -0,0 +1,89 @@
+package testclient
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUploadFile(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789abcdef"), 1<<16)
+	path := filepath.Join(t.TempDir(), "photo.png")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var contentType string
+	var length int64
+	var sum [32]byte
+	c := New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType, length = r.Header.Get("Content-Type"), r.ContentLength
+		b, _ := io.ReadAll(r.Body)
+		sum = sha256.Sum256(b)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	var calls int
+	var read, total int64
+	res, err := c.UploadFile(http.MethodPut, "/photos/1", path, Progress(func(n, size int64) {
+		calls++
+		read, total = n, size
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ExpectStatus(t, res, http.StatusCreated)
+	if contentType != "image/png" || length != int64(len(data)) || sum != sha256.Sum256(data) {
+		t.Errorf("handler got %s, %d bytes, SHA-256 %x", contentType, length, sum)
+	}
+	if calls < 2 || read != int64(len(data)) || total != int64(len(data)) {
+		t.Errorf("progress: %d calls, last %d/%d", calls, read, total)
+	}
+
+	if _, err := c.UploadFile(http.MethodPut, "/photos/2", filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("uploading a missing file succeeded")
+	}
+}
+
+func TestProgressStoppedEarly(t *testing.T) {
+	c := New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.CopyN(io.Discard, r.Body, 10)
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+	}))
+	var read, total int64
+	c.Request(c.NewRequest(http.MethodPost, "/upload", io.LimitReader(strings.NewReader(strings.Repeat("x", 100)), 100)), Progress(func(n, size int64) {
+		read, total = n, size
+	}))
+	if read != 10 || total != -1 {
+		t.Errorf("progress = %d/%d, want 10/-1", read, total)
+	}
+}
+
+func TestBodyFailsAfter(t *testing.T) {
+	broken := errors.New("connection reset")
+	var got []byte
+	var readErr error
+	c := New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, readErr = io.ReadAll(r.Body)
+		if readErr != nil {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	res, err := c.Request(c.NewRequest(http.MethodPost, "/upload", strings.NewReader("abcdefghij")), BodyFailsAfter(4, broken))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ExpectStatus(t, res, http.StatusBadRequest)
+	if string(got) != "abcd" || !errors.Is(readErr, broken) {
+		t.Errorf("handler read %q, %v", got, readErr)
+	}
+
+	c.Request(c.NewRequest(http.MethodPost, "/upload", strings.NewReader("ab")), BodyFailsAfter(4, broken))
+	if string(got) != "ab" || readErr != nil {
+		t.Errorf("short body: handler read %q, %v", got, readErr)
+	}
+}
@@ -0,0 +1,425 @@
+package testclient
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// WebSocket message types, as defined in RFC 6455.
+const (
+	TextMessage   = 1
+	BinaryMessage = 2
+	CloseMessage  = 8
+	PingMessage   = 9
+	PongMessage   = 10
+)
+
+const wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// wsTimeout bounds how long Dial, Close, the assertion helpers and the
+// cleanup of a NewT client wait for the handler; a variable for the tests.
+var wsTimeout = 5 * time.Second
+
+// wsMaxMessageSize caps the size of a frame or message read from the handler.
+const wsMaxMessageSize = 32 << 20
+
+// CloseError is returned by ReadMessage when the peer closed the connection.
+type CloseError struct {
+	Code int
+	Text string
+}
+
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("websocket: close %d %s", e.Code, e.Text)
+}
+
+// WSConn is the client end of a WebSocket connection to the wrapped handler.
+type WSConn struct {
+	conn   net.Conn
+	br     *bufio.Reader
+	mu     sync.Mutex
+	closed bool
+
+	done     chan struct{}
+	panicErr error
+	// released is set once Close was called.
+	released bool
+	rand     io.Reader
+}
+
+// Dial performs a WebSocket handshake against the handler over an in-memory
+// connection.
+func (c *Client) Dial(path string, opts ...RequestOption) (*WSConn, error) {
+	var nonce [16]byte
+	if _, err := io.ReadFull(c.random(), nonce[:]); err != nil {
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce[:])
+
+	req := c.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	if err := c.prepare(req, opts); err != nil {
+		return nil, err
+	}
+
+	clientConn, serverConn := newConnPair()
+	w := &hijackWriter{
+		ResponseRecorder: httptest.NewRecorder(),
+		conn:             serverConn,
+		hijacked:         make(chan struct{}),
+	}
+	ws := &WSConn{conn: clientConn, done: make(chan struct{}), rand: c.random()}
+	handler := c.handlerFor(req)
+	go func() {
+		defer close(ws.done)
+		defer func() {
+			if p := recover(); p != nil {
+				ws.panicErr = newPanicError(p, req)
+				serverConn.Close()
+			}
+		}()
+		handler.ServeHTTP(w, req)
+	}()
+
+	select {
+	case <-w.hijacked:
+	case <-ws.done:
+		select {
+		case <-w.hijacked:
+		default:
+			clientConn.Close()
+			if ws.panicErr != nil {
+				return nil, ws.panicErr
+			}
+			res := w.Result()
+			c.record(req, res)
+			return nil, fmt.Errorf("websocket: handler did not upgrade the connection: %d", res.StatusCode)
+		}
+	case <-time.After(wsTimeout):
+		clientConn.Close()
+		return nil, fmt.Errorf("websocket: handler neither upgraded the connection nor returned within %v", wsTimeout)
+	}
+
+	ws.br = bufio.NewReader(clientConn)
+	clientConn.SetReadDeadline(time.Now().Add(wsTimeout))
+	res, err := http.ReadResponse(ws.br, req)
+	clientConn.SetReadDeadline(time.Time{})
+	if err != nil {
+		clientConn.Close()
+		return nil, fmt.Errorf("websocket: bad handshake response: %w", err)
+	}
+	c.record(req, res)
+	if res.StatusCode != http.StatusSwitchingProtocols {
+		clientConn.Close()
+		return nil, fmt.Errorf("websocket: bad handshake status: %d", res.StatusCode)
+	}
+	sum := sha1.Sum([]byte(key + wsGUID))
+	if res.Header.Get("Sec-WebSocket-Accept") != base64.StdEncoding.EncodeToString(sum[:]) {
+		clientConn.Close()
+		return nil, fmt.Errorf("websocket: bad Sec-WebSocket-Accept header")
+	}
+
+	c.track(closerFunc(ws.closeUnreleased))
+	return ws, nil
+}
+
+// hijackWriter is a ResponseRecorder that can hand its connection over to
+// the handler.
+type hijackWriter struct {
+	*httptest.ResponseRecorder
+	conn     net.Conn
+	status   int
+	once     sync.Once
+	hijacked chan struct{}
+}
+
+func (w *hijackWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseRecorder.WriteHeader(code)
+}
+
+// Hijack hands over the connection. Like net/http, a status and headers
+// written before the hijack are sent to the client first.
+func (w *hijackWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	var rw *bufio.ReadWriter
+	w.once.Do(func() {
+		if w.status != 0 {
+			var buf bytes.Buffer
+			fmt.Fprintf(&buf, "HTTP/1.1 %d %s\r\n", w.status, http.StatusText(w.status))
+			w.Header().Write(&buf)
+			buf.WriteString("\r\n")
+			w.conn.Write(buf.Bytes())
+		}
+		rw = bufio.NewReadWriter(bufio.NewReader(w.conn), bufio.NewWriter(w.conn))
+		close(w.hijacked)
+	})
+	if rw == nil {
+		return nil, nil, http.ErrHijacked
+	}
+	return w.conn, rw, nil
+}
+
+// WriteMessage sends a single frame of the given message type.
+func (ws *WSConn) WriteMessage(messageType int, data []byte) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if ws.closed {
+		return net.ErrClosed
+	}
+	if messageType == CloseMessage {
+		ws.closed = true
+	}
+	return ws.writeFrame(messageType, data)
+}
+
+func (ws *WSConn) writeFrame(opcode int, data []byte) error {
+	var buf bytes.Buffer
+	buf.WriteByte(0x80 | byte(opcode))
+	switch n := len(data); {
+	case n < 126:
+		buf.WriteByte(0x80 | byte(n))
+	case n <= 0xffff:
+		buf.WriteByte(0x80 | 126)
+		binary.Write(&buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(0x80 | 127)
+		binary.Write(&buf, binary.BigEndian, uint64(n))
+	}
+	var mask [4]byte
+	if _, err := io.ReadFull(ws.rand, mask[:]); err != nil {
+		return err
+	}
+	buf.Write(mask[:])
+	for i, b := range data {
+		buf.WriteByte(b ^ mask[i%4])
+	}
+	_, err := ws.conn.Write(buf.Bytes())
+	return err
+}
+
+// ReadMessage returns the next data message. Pings are answered and pongs
+// skipped, also between the fragments of a message; a close frame is
+// acknowledged and returned as a *CloseError.
+func (ws *WSConn) ReadMessage() (messageType int, p []byte, err error) {
+	for {
+		fin, opcode, payload, err := ws.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+		if opcode >= CloseMessage {
+			if err := ws.handleControl(opcode, payload); err != nil {
+				return CloseMessage, payload, err
+			}
+			continue
+		}
+
+		switch {
+		case messageType == 0 && opcode == 0:
+			return 0, nil, errors.New("websocket: continuation frame without a message")
+		case messageType != 0 && opcode != 0:
+			return 0, nil, fmt.Errorf("websocket: unexpected opcode %d in fragmented message", opcode)
+		case messageType == 0:
+			messageType = opcode
+		}
+		if len(p)+len(payload) > wsMaxMessageSize {
+			return 0, nil, errors.New("websocket: message too large")
+		}
+		p = append(p, payload...)
+		if fin {
+			return messageType, p, nil
+		}
+	}
+}
+
+func (ws *WSConn) handleControl(opcode int, payload []byte) error {
+	switch opcode {
+	case PingMessage:
+		ws.mu.Lock()
+		defer ws.mu.Unlock()
+		if ws.closed {
+			return nil
+		}
+		return ws.writeFrame(PongMessage, payload)
+	case CloseMessage:
+		ce := &CloseError{Code: 1005}
+		if len(payload) >= 2 {
+			ce.Code = int(binary.BigEndian.Uint16(payload))
+			ce.Text = string(payload[2:])
+		}
+		ws.mu.Lock()
+		if !ws.closed {
+			ws.closed = true
+			ws.writeFrame(CloseMessage, payload)
+		}
+		ws.mu.Unlock()
+		return ce
+	}
+	return nil
+}
+
+func (ws *WSConn) readFrame() (fin bool, opcode int, payload []byte, err error) {
+	var head [2]byte
+	if _, err = io.ReadFull(ws.br, head[:]); err != nil {
+		return
+	}
+	fin = head[0]&0x80 != 0
+	opcode = int(head[0] & 0x0f)
+	n := uint64(head[1] & 0x7f)
+	switch n {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(ws.br, ext[:]); err != nil {
+			return
+		}
+		n = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(ws.br, ext[:]); err != nil {
+			return
+		}
+		n = binary.BigEndian.Uint64(ext[:])
+	}
+	if n > wsMaxMessageSize {
+		err = fmt.Errorf("websocket: frame of %d bytes is too large", n)
+		return
+	}
+	if opcode >= CloseMessage && (n > 125 || !fin) {
+		err = errors.New("websocket: bad control frame")
+		return
+	}
+	var mask [4]byte
+	masked := head[1]&0x80 != 0
+	if masked {
+		if _, err = io.ReadFull(ws.br, mask[:]); err != nil {
+			return
+		}
+	}
+	payload = make([]byte, n)
+	if _, err = io.ReadFull(ws.br, payload); err != nil {
+		return
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return
+}
+
+// WriteText sends a text message.
+func (ws *WSConn) WriteText(text string) error {
+	return ws.WriteMessage(TextMessage, []byte(text))
+}
+
+// WriteJSON sends v encoded as JSON in a text message.
+func (ws *WSConn) WriteJSON(v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return ws.WriteMessage(TextMessage, b)
+}
+
+// closeUnreleased closes ws unless Close was called already.
+func (ws *WSConn) closeUnreleased() error {
+	ws.mu.Lock()
+	released := ws.released
+	ws.mu.Unlock()
+	if released {
+		return nil
+	}
+	return ws.Close()
+}
+
+// Close sends a normal closure frame, closes the connection and waits for the
+// handler to return. A panic in the handler is returned as an error.
+func (ws *WSConn) Close() error {
+	ws.mu.Lock()
+	ws.released = true
+	if !ws.closed {
+		ws.closed = true
+		ws.writeFrame(CloseMessage, []byte{0x03, 0xe8})
+	}
+	ws.mu.Unlock()
+	ws.conn.Close()
+
+	select {
+	case <-ws.done:
+		return ws.panicErr
+	case <-time.After(wsTimeout):
+		return errors.New("websocket: handler did not return after close")
+	}
+}
+
+func (ws *WSConn) expectMessage(t testing.TB) (int, []byte) {
+	t.Helper()
+	ws.conn.SetReadDeadline(time.Now().Add(wsTimeout))
+	defer ws.conn.SetReadDeadline(time.Time{})
+	messageType, p, err := ws.ReadMessage()
+	if err != nil {
+		t.Fatalf("websocket: reading message: %v", err)
+	}
+	return messageType, p
+}
+
+// ExpectText fails the test unless the next message is the text want.
+func (ws *WSConn) ExpectText(t testing.TB, want string) {
+	t.Helper()
+	messageType, p := ws.expectMessage(t)
+	if messageType != TextMessage || string(p) != want {
+		t.Fatalf("websocket: expected text message %q, got type %d %q", want, messageType, p)
+	}
+}
+
+// ExpectJSON fails the test unless the next message is JSON equal to want.
+func (ws *WSConn) ExpectJSON(t testing.TB, want any) {
+	t.Helper()
+	_, p := ws.expectMessage(t)
+	b, err := json.Marshal(want)
+	if err != nil {
+		t.Fatalf("websocket: encoding expected JSON: %v", err)
+	}
+	var got, exp any
+	if err := json.Unmarshal(p, &got); err != nil {
+		t.Fatalf("websocket: message is not JSON: %v: %q", err, p)
+	}
+	json.Unmarshal(b, &exp)
+	if !reflect.DeepEqual(got, exp) {
+		t.Fatalf("websocket: expected JSON message %s, got %s", b, p)
+	}
+}
+
+// ExpectClose fails the test unless the handler closes the connection with
+// the given status code.
+func (ws *WSConn) ExpectClose(t testing.TB, code int) {
+	t.Helper()
+	ws.conn.SetReadDeadline(time.Now().Add(wsTimeout))
+	defer ws.conn.SetReadDeadline(time.Time{})
+	messageType, p, err := ws.ReadMessage()
+	var ce *CloseError
+	if !errors.As(err, &ce) {
+		t.Fatalf("websocket: expected close %d, got type %d %q (err: %v)", code, messageType, p, err)
+	}
+	if ce.Code != code {
+		t.Fatalf("websocket: expected close %d, got %d %s", code, ce.Code, ce.Text)
+	}
+}
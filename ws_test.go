@@ -0,0 +1,240 @@
+package testclient
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// wsAccept upgrades the connection the way nhooyr/coder websocket does:
+// headers and WriteHeader(101) first, then Hijack.
+func wsAccept(t *testing.T, w http.ResponseWriter, r *http.Request) (net.Conn, *bufio.ReadWriter) {
+	t.Helper()
+	sum := sha1.Sum([]byte(r.Header.Get("Sec-WebSocket-Key") + wsGUID))
+	w.Header().Set("Upgrade", "websocket")
+	w.Header().Set("Connection", "Upgrade")
+	w.Header().Set("Sec-WebSocket-Accept", base64.StdEncoding.EncodeToString(sum[:]))
+	w.WriteHeader(http.StatusSwitchingProtocols)
+	conn, rw, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		t.Errorf("Hijack: %v", err)
+		return nil, nil
+	}
+	return conn, rw
+}
+
+func serverWrite(rw *bufio.ReadWriter, fin bool, opcode int, payload []byte) {
+	b0 := byte(opcode)
+	if fin {
+		b0 |= 0x80
+	}
+	rw.WriteByte(b0)
+	switch n := len(payload); {
+	case n < 126:
+		rw.WriteByte(byte(n))
+	case n <= 0xffff:
+		rw.WriteByte(126)
+		binary.Write(rw, binary.BigEndian, uint16(n))
+	default:
+		rw.WriteByte(127)
+		binary.Write(rw, binary.BigEndian, uint64(n))
+	}
+	rw.Write(payload)
+	rw.Flush()
+}
+
+func serverRead(rw *bufio.ReadWriter) (int, []byte, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(rw, head[:]); err != nil {
+		return 0, nil, err
+	}
+	n := uint64(head[1] & 0x7f)
+	switch n {
+	case 126:
+		var ext uint16
+		binary.Read(rw, binary.BigEndian, &ext)
+		n = uint64(ext)
+	case 127:
+		binary.Read(rw, binary.BigEndian, &n)
+	}
+	var mask [4]byte
+	io.ReadFull(rw, mask[:])
+	p := make([]byte, n)
+	if _, err := io.ReadFull(rw, p); err != nil {
+		return 0, nil, err
+	}
+	for i := range p {
+		p[i] ^= mask[i%4]
+	}
+	return int(head[0] & 0x0f), p, nil
+}
+
+func TestDialEcho(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw := wsAccept(t, w, r)
+		if conn == nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			op, p, err := serverRead(rw)
+			if err != nil || op == CloseMessage {
+				return
+			}
+			serverWrite(rw, true, op, p)
+		}
+	})
+
+	ws, err := New(h).Dial("/ws")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws.WriteText("hello")
+	ws.ExpectText(t, "hello")
+	ws.WriteJSON(map[string]int{"a": 1})
+	ws.ExpectJSON(t, map[string]int{"a": 1})
+
+	big := []byte(strings.Repeat("x", 70000))
+	ws.WriteMessage(BinaryMessage, big)
+	op, p, err := ws.ReadMessage()
+	if err != nil || op != BinaryMessage || len(p) != len(big) {
+		t.Errorf("ReadMessage = %d, %d bytes, %v; want binary %d bytes", op, len(p), err, len(big))
+	}
+	if err := ws.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+}
+
+func TestDialFragmentsAndControl(t *testing.T) {
+	pong := make(chan string, 1)
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw := wsAccept(t, w, r)
+		if conn == nil {
+			return
+		}
+		defer conn.Close()
+		serverWrite(rw, false, TextMessage, []byte("hel"))
+		serverWrite(rw, true, PingMessage, []byte("p"))
+		serverWrite(rw, true, 0, []byte("lo"))
+		if op, p, _ := serverRead(rw); op == PongMessage {
+			pong <- string(p)
+		}
+		serverWrite(rw, true, CloseMessage, []byte{0x0f, 0xa0, 'b', 'y', 'e'})
+		serverRead(rw)
+	})
+
+	ws, err := New(h).Dial("/ws")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws.ExpectText(t, "hello")
+	select {
+	case p := <-pong:
+		if p != "p" {
+			t.Errorf("pong payload = %q, want p", p)
+		}
+	case <-time.After(time.Second):
+		t.Error("no pong received")
+	}
+	ws.ExpectClose(t, 4000)
+	if err := ws.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+}
+
+func TestDialCloseWithUnreadMessages(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw := wsAccept(t, w, r)
+		if conn == nil {
+			return
+		}
+		defer conn.Close()
+		serverWrite(rw, true, TextMessage, []byte("one"))
+		serverWrite(rw, true, TextMessage, []byte("two"))
+		serverRead(rw)
+	})
+
+	ws, err := New(h).Dial("/ws")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws.ExpectText(t, "one")
+	if err := ws.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+}
+
+func TestDialOversizedFrame(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw := wsAccept(t, w, r)
+		if conn == nil {
+			return
+		}
+		defer conn.Close()
+		rw.Write([]byte{0x82, 127, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+		rw.Flush()
+		serverRead(rw)
+	})
+
+	ws, err := New(h).Dial("/ws")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := ws.ReadMessage(); err == nil {
+		t.Error("ReadMessage accepted an oversized frame")
+	}
+	ws.Close()
+}
+
+func TestDialNotUpgraded(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no", http.StatusBadRequest)
+	})
+	c := New(h)
+	if _, err := c.Dial("/ws"); err == nil {
+		t.Fatal("Dial succeeded without an upgrade")
+	}
+	if got := c.Response().StatusCode; got != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", got, http.StatusBadRequest)
+	}
+}
+
+func TestDialTimeout(t *testing.T) {
+	defer func(d time.Duration) { wsTimeout = d }(wsTimeout)
+	wsTimeout = 50 * time.Millisecond
+	release := make(chan struct{})
+	defer close(release)
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { <-release })
+	if _, err := New(h).Dial("/ws"); err == nil || !strings.Contains(err.Error(), "neither upgraded") {
+		t.Errorf("Dial error = %v, want a timeout", err)
+	}
+}
+
+func TestDialHandlerPanics(t *testing.T) {
+	before := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+	if _, err := New(before).Dial("/ws"); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Dial error = %v, want the handler panic", err)
+	}
+
+	after := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if conn, _ := wsAccept(t, w, r); conn != nil {
+			panic("after hijack")
+		}
+	})
+	ws, err := New(after).Dial("/ws")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.Close(); err == nil || !strings.Contains(err.Error(), "after hijack") {
+		t.Errorf("Close error = %v, want the handler panic", err)
+	}
+}
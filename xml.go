@@ -0,0 +1,103 @@
+package testclient
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// PostXML sends payload encoded as XML to uri.
+func (c *Client) PostXML(uri string, payload any, opts ...RequestOption) error {
+	b, err := xml.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req := c.NewRequest(http.MethodPost, uri, bytes.NewReader(append([]byte(xml.Header), b...)))
+	req.Header.Set("Content-Type", "application/xml; charset=utf-8")
+
+	_, err = c.Request(req, opts...)
+	return err
+}
+
+// DecodeXML decodes the body of the last response into v. The response must
+// have an XML media type: application/xml, text/xml or a +xml suffix.
+func (c *Client) DecodeXML(v any) error {
+	if c.response == nil {
+		return fmt.Errorf("testclient: no response to decode")
+	}
+	if ct := c.response.Header.Get("Content-Type"); !isXML(ct) {
+		return fmt.Errorf("testclient: response Content-Type %q is not XML", ct)
+	}
+	return xml.Unmarshal(c.BodyBytes(), v)
+}
+
+func isXML(contentType string) bool {
+	mt, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mt == "application/xml" || mt == "text/xml" || strings.HasSuffix(mt, "+xml"))
+}
+
+// ExpectXMLEqual fails the test unless the body of res is the XML document
+// want, ignoring attribute order, whitespace between elements, comments and
+// the XML declaration.
+func ExpectXMLEqual(t testing.TB, res *http.Response, want string) {
+	t.Helper()
+	w, err := canonicalXML(strings.NewReader(want))
+	if err != nil {
+		t.Fatalf("bad expected XML: %v", err)
+	}
+	g, err := canonicalXML(bytes.NewReader(bufferedBody(res).data))
+	if err != nil {
+		t.Fatalf("response body is not XML: %v", err)
+	}
+	if g != w {
+		t.Fatalf("expected XML body\n%s\ngot\n%s", w, g)
+	}
+}
+
+// canonicalXML rewrites a document with sorted attributes, trimmed text and
+// no comments, directives or processing instructions, one token per line.
+func canonicalXML(r io.Reader) (string, error) {
+	var b strings.Builder
+	d := xml.NewDecoder(r)
+	depth := 0
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return b.String(), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		indent := strings.Repeat("  ", depth)
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			attrs := make([]string, 0, len(tok.Attr))
+			for _, a := range tok.Attr {
+				attrs = append(attrs, fmt.Sprintf(" %s=%q", xmlName(a.Name), a.Value))
+			}
+			sort.Strings(attrs)
+			fmt.Fprintf(&b, "%s<%s%s>\n", indent, xmlName(tok.Name), strings.Join(attrs, ""))
+			depth++
+		case xml.EndElement:
+			depth--
+			fmt.Fprintf(&b, "%s</%s>\n", strings.Repeat("  ", depth), xmlName(tok.Name))
+		case xml.CharData:
+			if text := strings.TrimSpace(string(tok)); text != "" {
+				fmt.Fprintf(&b, "%s%q\n", indent, text)
+			}
+		}
+	}
+}
+
+func xmlName(n xml.Name) string {
+	if n.Space == "" {
+		return n.Local
+	}
+	return "{" + n.Space + "}" + n.Local
+}
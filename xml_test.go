@@ -0,0 +1,76 @@
+package testclient
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type xmlItem struct {
+	XMLName xml.Name `xml:"item"`
+	ID      string   `xml:"id,attr"`
+	Name    string   `xml:"name"`
+}
+
+func TestPostXML(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isXML(r.Header.Get("Content-Type")) {
+			t.Errorf("Content-Type = %q", r.Header.Get("Content-Type"))
+		}
+		var item xmlItem
+		if err := xml.NewDecoder(r.Body).Decode(&item); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		item.Name = strings.ToUpper(item.Name)
+		w.Header().Set("Content-Type", "application/atom+xml")
+		xml.NewEncoder(w).Encode(item)
+	})
+	c := New(h)
+	if err := c.PostXML("/items", xmlItem{ID: "1", Name: "pen"}); err != nil {
+		t.Fatal(err)
+	}
+	var got xmlItem
+	if err := c.DecodeXML(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != "1" || got.Name != "PEN" {
+		t.Errorf("decoded %+v", got)
+	}
+	if err := c.PostXML("/", make(chan int)); err == nil {
+		t.Error("PostXML accepted an unencodable payload")
+	}
+}
+
+func TestDecodeXMLWrongType(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, "{}")
+	})
+	c := New(h)
+	c.Request(c.NewRequest(http.MethodGet, "/", nil))
+	if err := c.DecodeXML(new(xmlItem)); err == nil || !strings.Contains(err.Error(), "not XML") {
+		t.Errorf("err = %v, want a Content-Type error", err)
+	}
+}
+
+func TestExpectXMLEqual(t *testing.T) {
+	s := NewStub()
+	s.On("GET", "/feed").Reply(http.StatusOK, `<?xml version="1.0"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <!-- generated -->
+  <entry id="1" lang="en">
+    <title> Hello </title>
+  </entry>
+</feed>`)
+	c := New(s)
+	res, _ := c.Request(c.NewRequest(http.MethodGet, "/feed", nil))
+	ExpectXMLEqual(t, res, `<feed xmlns="http://www.w3.org/2005/Atom"><entry lang="en" id="1"><title>Hello</title></entry></feed>`)
+
+	ft := &fakeT{}
+	ExpectXMLEqual(ft, res, `<feed xmlns="http://www.w3.org/2005/Atom"><entry id="2" lang="en"><title>Hello</title></entry></feed>`)
+	if len(ft.failures) != 1 || !strings.Contains(ft.failures[0], `id="1"`) {
+		t.Errorf("failures = %q", ft.failures)
+	}
+}